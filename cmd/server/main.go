@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	"os"
 	"time"
+	_ "time/tzdata" // Property time zones must resolve on hosts without zoneinfo
 
 	"hudini-breakfast-module/internal/api"
 	"hudini-breakfast-module/internal/cache"
 	"hudini-breakfast-module/internal/config"
 	"hudini-breakfast-module/internal/database"
 	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/middleware"
 	"hudini-breakfast-module/internal/services"
 	"hudini-breakfast-module/internal/websocket"
 
@@ -93,6 +96,15 @@ func main() {
 		logging.Info("Breakfast service initialized")
 	}
 
+	// Initialize daily and hourly rollups, refreshed as visits change and in the background
+	rollupService := services.NewRollupService(db, cfg.Rollup)
+	if vipCache != nil {
+		rollupService.SetCache(vipCache)
+	}
+	breakfastService.SetRollups(rollupService)
+	go rollupService.StartScheduler(context.Background())
+	logging.Info("Rollup scheduler started")
+
 	// Initialize guest service
 	guestService := services.NewGuestService(db)
 	logging.Info("Guest service initialized")
@@ -100,6 +112,36 @@ func main() {
 	// Initialize audit service
 	auditService := services.NewAuditService(db)
 	logging.Info("Audit service initialized")
+
+	// Initialize PMS integration and void service. Visit charges are posted
+	// through the PMS API and order overages through the integration service,
+	// so each is reversed through the same client.
+	pmsIntegrationService := services.NewPMSIntegrationService(cfg, logging.GetLogger())
+	pmsService := services.NewPMSService(cfg)
+	breakfastService.SetChargePoster(pmsService)
+	voidService := services.NewVoidService(db, ohipService, auditService, cfg.Void)
+	voidService.SetVisitChargeReverser(pmsService)
+	voidService.SetOrderChargeReverser(pmsIntegrationService)
+	voidService.SetConsumptionListener(rollupService)
+	logging.Info("Void service initialized")
+
+	// Initialize property, outlet, price book and eligibility services
+	propertyService := services.NewPropertyService(db)
+	outletService := services.NewOutletService(db)
+	priceBookService := services.NewPriceBookService(db)
+	eligibilityService := services.NewEligibilityService(db)
+
+	// Initialize close-out service and its scheduler
+	closeOutService := services.NewCloseOutService(db, auditService, cfg.CloseOut)
+	closeOutService.SetConsumptionListener(rollupService)
+	if cfg.CloseOut.SchedulerEnabled {
+		go closeOutService.StartScheduler(context.Background())
+		logging.Info("Close-out scheduler started")
+	}
+
+	// Initialize offline batch sync service
+	syncService := services.NewSyncService(db)
+	syncService.SetConsumptionListener(rollupService)
 	
 	// Initialize notification service
 	notificationService := services.NewNotificationService(db, redisCache)
@@ -112,11 +154,78 @@ func main() {
 	notificationService.SetWebSocketHub(wsHub)
 	logging.Info("Notification service initialized")
 
+	// Initialize breakfast pass service, delivering passes through the same providers
+	passService := services.NewPassService(db, cfg.Passes)
+	passService.SetProviders(emailProvider, smsProvider)
+	passService.SetConsumptionListener(rollupService)
+
+	// Initialize table service, pushing table boards over the WebSocket hub
+	tableService := services.NewTableService(db)
+	tableService.SetPublisher(wsHub)
+
+	// Initialize waitlist service, calling waiting parties by SMS as tables free up
+	waitlistService := services.NewWaitlistService(db, tableService, notificationService)
+	waitlistService.SetPublisher(wsHub)
+	tableService.SetTableListener(waitlistService)
+
+	// Initialize à-la-carte ordering, charging overages to the room through the PMS
+	orderService := services.NewOrderService(db)
+	orderService.SetChargePoster(pmsIntegrationService)
+
+	// Initialize kitchen displays, fed by ticket changes and seatings over the WebSocket hub
+	kitchenService := services.NewKitchenDisplayService(db, orderService)
+	kitchenService.SetPublisher(wsHub)
+	orderService.SetTicketListener(kitchenService)
+	tableService.SetSeatingListener(kitchenService)
+
+	// Initialize guest allergen profiles and allergy acknowledgements
+	allergenService := services.NewAllergenService(db)
+
+	// Initialize outlet inventory and its low-stock alert scheduler
+	inventoryService := services.NewInventoryService(db, auditService, notificationService, cfg.Inventory)
+	go inventoryService.StartAlertScheduler(context.Background())
+	logging.Info("Inventory alert scheduler started")
+
+	// Initialize cover forecasting from occupancy and PMS reservations
+	forecastService := services.NewForecastService(db)
+	forecastService.SetReservationSource(pmsIntegrationService)
+
+	// Initialize staffing and prep planning and the next-day plan emails
+	planningService := services.NewPlanningService(db, forecastService, cfg.Planning)
+	planningService.SetEmailProvider(emailProvider)
+	go planningService.StartEmailScheduler(context.Background())
+	logging.Info("Plan email scheduler started")
+
+	// Initialize executive dashboard aggregates
+	executiveService := services.NewExecutiveService(db, rollupService)
+
+	// Initialize analytics over arbitrary date ranges
+	analyticsService := services.NewAnalyticsService(db)
+
+	// Initialize visit service-time tracking and service delay alerts
+	serviceTimeService := services.NewServiceTimeService(db, notificationService, cfg.ServiceTime)
+	serviceTimeService.SetConsumptionListener(rollupService)
+
+	// Initialize report exports, audited as they are downloaded
+	exportService := services.NewExportService(db, auditService)
+
+	// Initialize report subscriptions, emailing reports as attachments on their schedules
+	subscriptionService := services.NewReportSubscriptionService(db, breakfastService, exportService, cfg.Subscriptions)
+	subscriptionService.SetEmailProvider(emailProvider)
+	go subscriptionService.StartScheduler(context.Background())
+	logging.Info("Report subscription scheduler started")
+
+	// Initialize portfolio KPIs, ranking and benchmarking properties against each other
+	portfolioService := services.NewPortfolioService(db, executiveService, rollupService)
+
+	// Initialize idempotency keys for retried mutations
+	idempotencyStore := middleware.NewIdempotencyStore(db, cfg.Idempotency)
+
 	// Setup router
 	router := gin.Default()
 
 	// Setup API routes
-	api.SetupRoutes(router, breakfastService, guestService, auditService, notificationService, db, cfg.JWTSecret, wsHub)
+	api.SetupRoutes(router, breakfastService, guestService, auditService, notificationService, voidService, outletService, priceBookService, closeOutService, propertyService, syncService, eligibilityService, passService, tableService, waitlistService, orderService, kitchenService, allergenService, inventoryService, forecastService, planningService, executiveService, analyticsService, serviceTimeService, exportService, subscriptionService, portfolioService, db, cfg.JWTSecret, idempotencyStore, wsHub)
 	logging.Info("API routes configured")
 
 	// Start server
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AllergenHandler handles the allergen taxonomy, guest dietary profiles and allergy acknowledgements
+type AllergenHandler struct {
+	allergenService *services.AllergenService
+}
+
+// NewAllergenHandler creates a new allergen handler
+func NewAllergenHandler(allergenService *services.AllergenService) *AllergenHandler {
+	return &AllergenHandler{
+		allergenService: allergenService,
+	}
+}
+
+// AcknowledgeAllergiesRequest is the payload for acknowledging a guest's allergy warnings
+type AcknowledgeAllergiesRequest struct {
+	OutletID *uint `json:"outlet_id"`
+}
+
+// GET /api/allergens
+func (h *AllergenHandler) GetTaxonomy(c *gin.Context) {
+	SuccessResponse(c, gin.H{
+		"allergens":  services.AllergenTaxonomy,
+		"dietary":    services.DietaryTaxonomy,
+		"severities": []string{services.AllergySeverityMild, services.AllergySeveritySevere, services.AllergySeverityCritical},
+	})
+}
+
+// GET /api/guests/:id/dietary-profile
+func (h *AllergenHandler) GetProfile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid guest ID")
+		return
+	}
+
+	profile, err := h.allergenService.GetProfile(uint(id))
+	if err != nil {
+		h.allergenError(c, err)
+		return
+	}
+
+	SuccessResponse(c, profile)
+}
+
+// PUT /api/guests/:id/dietary-profile
+func (h *AllergenHandler) UpdateProfile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid guest ID")
+		return
+	}
+
+	var req services.DietaryProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	profile, err := h.allergenService.UpdateProfile(uint(id), req)
+	if err != nil {
+		h.allergenError(c, err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Dietary profile updated", profile)
+}
+
+// GET /api/rooms/:room_number/allergy-check?property_id=&outlet_id=
+//
+// Called when staff open a room on the grid. When acknowledgement_required is
+// set, staff must confirm the warnings before serving the guest.
+func (h *AllergenHandler) CheckRoom(c *gin.Context) {
+	propertyID := c.Query("property_id")
+	if propertyID == "" {
+		ValidationErrorResponse(c, "property_id is required")
+		return
+	}
+
+	var outletID uint64
+	if value := c.Query("outlet_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			ValidationErrorResponse(c, "Invalid outlet ID")
+			return
+		}
+		outletID = parsed
+	}
+
+	check, err := h.allergenService.CheckRoom(propertyID, c.Param("room_number"), uint(outletID))
+	if err != nil {
+		h.allergenError(c, err)
+		return
+	}
+
+	SuccessResponse(c, check)
+}
+
+// POST /api/guests/:id/allergies/acknowledge
+func (h *AllergenHandler) Acknowledge(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid guest ID")
+		return
+	}
+
+	var req AcknowledgeAllergiesRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	acknowledgement, err := h.allergenService.Acknowledge(uint(id), req.OutletID, c.GetUint("user_id"))
+	if err != nil {
+		h.allergenError(c, err)
+		return
+	}
+
+	CreatedResponse(c, acknowledgement)
+}
+
+// allergenError maps dietary profile and allergy check failures to responses
+func (h *AllergenHandler) allergenError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrDietaryGuestNotFound),
+		errors.Is(err, services.ErrNoGuestInRoom):
+		NotFoundResponse(c, "Guest")
+	case errors.Is(err, services.ErrUnknownAllergen),
+		errors.Is(err, services.ErrUnknownDietary),
+		errors.Is(err, services.ErrUnknownSeverity):
+		ValidationErrorResponse(c, err.Error())
+	default:
+		InternalErrorResponse(c, err)
+	}
+}
+
+// allergenConflictResponse refuses a request whose items conflict with the
+// guest's allergies, returning the conflicts for staff to acknowledge
+func allergenConflictResponse(c *gin.Context, err *services.AllergenConflictError) {
+	c.JSON(http.StatusConflict, APIResponse{
+		Success: false,
+		Data:    gin.H{"conflicts": err.Conflicts},
+		Error: &APIError{
+			Code:    "ALLERGY_ACKNOWLEDGEMENT_REQUIRED",
+			Message: err.Error(),
+		},
+		Timestamp: time.Now(),
+		RequestID: getRequestID(c),
+	})
+}
@@ -1,11 +1,18 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
 
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/services"
+
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 )
 
 // AnalyticsData represents comprehensive analytics information
@@ -80,18 +87,76 @@ type AnalyticsForecast struct {
 	CreatedAt  time.Time `json:"created_at"`
 }
 
-// GetAdvancedAnalytics provides comprehensive analytics data
+// GetAdvancedAnalytics computes revenue, take-up, occupancy, stay and cost
+// analytics for a range of business dates against a comparison window
+// GET /api/analytics/advanced?from=YYYY-MM-DD&to=YYYY-MM-DD&period=week&comparison=previous|year
 func (h *BreakfastHandler) GetAdvancedAnalytics(c *gin.Context) {
-	propertyID := c.DefaultQuery("property_id", "HOTEL001")
+	propertyID, ok := signedInPropertyID(c)
+	if !ok {
+		return
+	}
+
+	window, ok := h.analyticsWindow(c, propertyID)
+	if !ok {
+		return
+	}
+
+	report, err := h.analyticsService.Report(propertyID, window)
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":     "GetAdvancedAnalytics",
+			"property_id": propertyID,
+			"error":       err.Error(),
+		}).Error("Failed to compute analytics")
+		InternalErrorResponse(c, err)
+		return
+	}
+
 	period := c.DefaultQuery("period", "week")
-	comparison := c.DefaultQuery("comparison", "previous")
+	if c.Query("from") != "" {
+		period = "custom"
+	}
+	analytics := AnalyticsData{
+		Period:     period,
+		PropertyID: propertyID,
+		Timestamp:  time.Now(),
+		Metrics: AnalyticsMetrics{
+			Revenue:              metricValue(report.Current.Revenue, report.Comparison.Revenue),
+			OccupancyRate:        metricValue(report.Current.OccupancyRate, report.Comparison.OccupancyRate),
+			BreakfastTakeup:      metricValue(report.Current.TakeUpRate, report.Comparison.TakeUpRate),
+			AverageStayDuration:  metricValue(report.Current.AverageStay, report.Comparison.AverageStay),
+			CustomerSatisfaction: metricValue(report.Current.SatisfactionRate, report.Comparison.SatisfactionRate),
+			CostPerBreakfast:     metricValue(report.Current.CostPerBreakfast, report.Comparison.CostPerBreakfast),
+			TotalRooms:           report.Snapshot.TotalRooms,
+			OccupiedRooms:        report.Snapshot.OccupiedRooms,
+			BreakfastPackages:    report.Snapshot.PackageGuests,
+			ConsumedToday:        report.Snapshot.ConsumedToday,
+		},
+		Charts: AnalyticsCharts{
+			RevenueTimeline:     chartPoints(report.RevenueByDate),
+			PackageDistribution: chartSlices(report.PackageMix),
+			HourlyConsumption:   chartPoints(report.CoversByHour),
+			MonthlyTrends:       chartPoints(report.MonthlyRevenue),
+		},
+		Insights:  analyticsInsights(report),
+		Forecasts: []AnalyticsForecast{},
+	}
 
-	// Generate comprehensive analytics
-	analytics := generateAdvancedAnalytics(propertyID, period, comparison)
+	forecast, err := h.forecastService.Forecast(c.Request.Context(), propertyID, 7)
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":     "GetAdvancedAnalytics",
+			"property_id": propertyID,
+			"error":       err.Error(),
+		}).Warn("Failed to forecast covers")
+	} else {
+		analytics.Forecasts = forecastSummary(forecast)
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    analytics,
+		"window":  report.Window,
 	})
 }
 
@@ -113,32 +178,78 @@ func (h *BreakfastHandler) GetPredictiveInsights(c *gin.Context) {
 	propertyID := c.DefaultQuery("property_id", "HOTEL001")
 	horizon := c.DefaultQuery("horizon", "30") // days
 
-	horizonDays, _ := strconv.Atoi(horizon)
-	insights := generatePredictiveInsights(propertyID, horizonDays)
+	horizonDays, err := strconv.Atoi(horizon)
+	if err != nil || horizonDays <= 0 {
+		ValidationErrorResponse(c, "horizon must be a positive number of days")
+		return
+	}
+
+	forecast, err := h.forecastService.Forecast(c.Request.Context(), propertyID, horizonDays)
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":     "GetPredictiveInsights",
+			"property_id": propertyID,
+			"error":       err.Error(),
+		}).Error("Failed to forecast covers")
+		InternalErrorResponse(c, err)
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success":      true,
-		"data":         insights,
-		"horizon_days": horizonDays,
+		"data":         forecast,
+		"horizon_days": forecast.HorizonDays,
 	})
 }
 
-// GetBusinessIntelligence provides comprehensive BI dashboard data
+// GetBusinessIntelligence provides KPIs, segments and peer benchmarks for a
+// range of business dates against a comparison window
+// GET /api/analytics/business-intelligence?from=YYYY-MM-DD&to=YYYY-MM-DD&period=month&comparison=previous|year
 func (h *BreakfastHandler) GetBusinessIntelligence(c *gin.Context) {
-	propertyID := c.DefaultQuery("property_id", "HOTEL001")
+	propertyID, ok := signedInPropertyID(c)
+	if !ok {
+		return
+	}
+
+	window, ok := h.analyticsWindow(c, propertyID)
+	if !ok {
+		return
+	}
+
+	report, err := h.analyticsService.Report(propertyID, window)
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":     "GetBusinessIntelligence",
+			"property_id": propertyID,
+			"error":       err.Error(),
+		}).Error("Failed to compute analytics")
+		InternalErrorResponse(c, err)
+		return
+	}
+	benchmarks, err := h.analyticsService.Benchmarks(propertyID, window.From, window.To)
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":     "GetBusinessIntelligence",
+			"property_id": propertyID,
+			"error":       err.Error(),
+		}).Error("Failed to benchmark property")
+		InternalErrorResponse(c, err)
+		return
+	}
 
 	bi := BusinessIntelligenceData{
 		PropertyID:   propertyID,
 		GeneratedAt:  time.Now(),
-		KPIs:         generateKPIs(propertyID),
-		Segments:     generateCustomerSegments(propertyID),
+		KPIs:         analyticsKPIs(report),
+		Segments:     customerSegments(report),
 		Optimization: generateOptimizationRecommendations(propertyID),
-		Competitive:  generateCompetitiveAnalysis(propertyID),
+		Competitive:  competitiveAnalysis(benchmarks),
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    bi,
+		"window":  report.Window,
 	})
 }
 
@@ -197,152 +308,42 @@ type BenchmarkMetric struct {
 	Ranking     string  `json:"ranking"` // "top_quartile", "above_avg", "below_avg", "bottom_quartile"
 }
 
-// Helper functions to generate analytics data
-func generateAdvancedAnalytics(propertyID, period, comparison string) AnalyticsData {
-	now := time.Now()
+// forecastSummary condenses a cover forecast into the analytics forecast cards
+func forecastSummary(forecast *services.DemandForecast) []AnalyticsForecast {
+	if len(forecast.Days) == 0 {
+		return []AnalyticsForecast{}
+	}
 
-	return AnalyticsData{
-		Period:     period,
-		PropertyID: propertyID,
-		Timestamp:  now,
-		Metrics: AnalyticsMetrics{
-			Revenue: MetricValue{
-				Current:       25450.00,
-				Previous:      23200.00,
-				Change:        2250.00,
-				ChangePercent: 9.7,
-				Trend:         "up",
-			},
-			OccupancyRate: MetricValue{
-				Current:       87.5,
-				Previous:      82.3,
-				Change:        5.2,
-				ChangePercent: 6.3,
-				Trend:         "up",
-			},
-			BreakfastTakeup: MetricValue{
-				Current:       73.2,
-				Previous:      69.8,
-				Change:        3.4,
-				ChangePercent: 4.9,
-				Trend:         "up",
-			},
-			AverageStayDuration: MetricValue{
-				Current:       2.8,
-				Previous:      2.6,
-				Change:        0.2,
-				ChangePercent: 7.7,
-				Trend:         "up",
-			},
-			CustomerSatisfaction: MetricValue{
-				Current:       4.6,
-				Previous:      4.4,
-				Change:        0.2,
-				ChangePercent: 4.5,
-				Trend:         "up",
-			},
-			CostPerBreakfast: MetricValue{
-				Current:       12.50,
-				Previous:      13.20,
-				Change:        -0.70,
-				ChangePercent: -5.3,
-				Trend:         "down",
-			},
-			TotalRooms:        120,
-			OccupiedRooms:     105,
-			BreakfastPackages: 77,
-			ConsumedToday:     65,
-		},
-		Charts:    generateChartData(),
-		Insights:  generateInsights(),
-		Forecasts: generateForecasts(),
+	covers := 0.0
+	arriving := 0
+	for _, day := range forecast.Days {
+		covers += day.Predicted
+		arriving += day.ArrivingCovers
 	}
-}
 
-func generateChartData() AnalyticsCharts {
-	return AnalyticsCharts{
-		RevenueTimeline: []ChartDataPoint{
-			{Label: "Week 1", Value: 18500, Date: "2024-01-01"},
-			{Label: "Week 2", Value: 21200, Date: "2024-01-08"},
-			{Label: "Week 3", Value: 23400, Date: "2024-01-15"},
-			{Label: "Week 4", Value: 25450, Date: "2024-01-22"},
-		},
-		PackageDistribution: []ChartPieSlice{
-			{Label: "Standard Package", Value: 45, Percentage: 45.0, Color: "#4ade80"},
-			{Label: "Premium Package", Value: 25, Percentage: 25.0, Color: "#22d3ee"},
-			{Label: "VIP Package", Value: 15, Percentage: 15.0, Color: "#a855f7"},
-			{Label: "No Package", Value: 15, Percentage: 15.0, Color: "#6b7280"},
-		},
-		HourlyConsumption: []ChartDataPoint{
-			{Label: "6 AM", Value: 15},
-			{Label: "7 AM", Value: 45},
-			{Label: "8 AM", Value: 85},
-			{Label: "9 AM", Value: 75},
-			{Label: "10 AM", Value: 35},
-			{Label: "11 AM", Value: 10},
-		},
-		MonthlyTrends: []ChartDataPoint{
-			{Label: "Jan", Value: 82500},
-			{Label: "Feb", Value: 78200},
-			{Label: "Mar", Value: 89100},
-			{Label: "Apr", Value: 92800},
-			{Label: "May", Value: 88900},
-			{Label: "Jun", Value: 95200},
-		},
+	trend := "stable"
+	first, last := forecast.Days[0].Predicted, forecast.Days[len(forecast.Days)-1].Predicted
+	switch {
+	case last > first*1.05:
+		trend = "increasing"
+	case last < first*0.95:
+		trend = "decreasing"
 	}
-}
 
-func generateInsights() []AnalyticsInsight {
-	return []AnalyticsInsight{
-		{
-			Type:        "opportunity",
-			Title:       "Revenue Growth Opportunity",
-			Description: "Breakfast take-up rate is 15% below industry average",
-			Impact:      "Potential $2,400/month additional revenue",
-			Action:      "Implement targeted promotional campaign",
-			Confidence:  0.87,
-			CreatedAt:   time.Now(),
-		},
-		{
-			Type:        "warning",
-			Title:       "Peak Hour Capacity",
-			Description: "8-9 AM shows 23% higher demand than optimal capacity",
-			Impact:      "Potential guest dissatisfaction",
-			Action:      "Consider extending breakfast hours or increasing staff",
-			Confidence:  0.92,
-			CreatedAt:   time.Now(),
-		},
-		{
-			Type:        "info",
-			Title:       "Cost Optimization Success",
-			Description: "Food waste reduction initiatives showing positive results",
-			Impact:      "12% cost reduction without satisfaction impact",
-			Action:      "Continue current waste reduction strategies",
-			Confidence:  0.94,
-			CreatedAt:   time.Now(),
-		},
+	factors := []string{"in_house_occupancy", "weekday_take_up"}
+	if arriving > 0 {
+		factors = append(factors, "pms_arrivals")
 	}
-}
 
-func generateForecasts() []AnalyticsForecast {
 	return []AnalyticsForecast{
 		{
-			Metric:     "revenue",
-			Period:     "next_week",
-			Predicted:  27650.00,
-			Confidence: 0.85,
-			Trend:      "increasing",
-			Factors:    []string{"seasonal_demand", "promotional_campaign", "weather_forecast"},
-			CreatedAt:  time.Now(),
-		},
-		{
-			Metric:     "occupancy",
-			Period:     "next_month",
-			Predicted:  89.2,
-			Confidence: 0.78,
-			Trend:      "stable",
-			Factors:    []string{"booking_patterns", "market_events", "competitor_analysis"},
-			CreatedAt:  time.Now(),
+			Metric:     "covers",
+			Period:     fmt.Sprintf("next_%d_days", forecast.HorizonDays),
+			Predicted:  covers,
+			Confidence: forecast.Backtest.Accuracy,
+			Trend:      trend,
+			Factors:    factors,
+			CreatedAt:  forecast.GeneratedAt,
 		},
 	}
 }
@@ -361,105 +362,6 @@ func generateRealtimeMetrics(propertyID string) map[string]interface{} {
 	}
 }
 
-func generatePredictiveInsights(propertyID string, horizonDays int) map[string]interface{} {
-	return map[string]interface{}{
-		"demand_forecast": map[string]interface{}{
-			"next_7_days": []float64{85, 78, 82, 90, 88, 92, 87},
-			"confidence":  0.83,
-			"trend":       "seasonal_increase",
-		},
-		"revenue_prediction": map[string]interface{}{
-			"expected_daily": 3650.00,
-			"range_low":      3200.00,
-			"range_high":     4100.00,
-			"confidence":     0.79,
-		},
-		"optimization_opportunities": []map[string]interface{}{
-			{
-				"area":           "pricing",
-				"potential_lift": 8.5,
-				"confidence":     0.72,
-				"implementation": "dynamic_pricing_weekends",
-			},
-			{
-				"area":             "operations",
-				"potential_saving": 12.3,
-				"confidence":       0.84,
-				"implementation":   "staff_scheduling_optimization",
-			},
-		},
-	}
-}
-
-func generateKPIs(propertyID string) []KPIMetric {
-	return []KPIMetric{
-		{
-			Name:        "Monthly Revenue",
-			Value:       95200,
-			Target:      90000,
-			Performance: 105.8,
-			Status:      "above",
-			Unit:        "USD",
-		},
-		{
-			Name:        "Breakfast Take-up Rate",
-			Value:       73.2,
-			Target:      75.0,
-			Performance: 97.6,
-			Status:      "on_target",
-			Unit:        "percent",
-		},
-		{
-			Name:        "Customer Satisfaction",
-			Value:       4.6,
-			Target:      4.5,
-			Performance: 102.2,
-			Status:      "above",
-			Unit:        "score",
-		},
-		{
-			Name:        "Cost per Breakfast",
-			Value:       12.50,
-			Target:      13.00,
-			Performance: 96.2,
-			Status:      "above",
-			Unit:        "USD",
-		},
-	}
-}
-
-func generateCustomerSegments(propertyID string) []CustomerSegment {
-	return []CustomerSegment{
-		{
-			Name:            "Business Travelers",
-			Size:            45,
-			Revenue:         42500,
-			BreakfastRate:   85.2,
-			Satisfaction:    4.4,
-			LoyaltyScore:    7.8,
-			Characteristics: []string{"early_breakfast", "premium_packages", "efficiency_focused"},
-		},
-		{
-			Name:            "Leisure Families",
-			Size:            35,
-			Revenue:         28900,
-			BreakfastRate:   92.1,
-			Satisfaction:    4.7,
-			LoyaltyScore:    8.2,
-			Characteristics: []string{"weekend_stays", "variety_seekers", "value_conscious"},
-		},
-		{
-			Name:            "Conference Attendees",
-			Size:            20,
-			Revenue:         18600,
-			BreakfastRate:   67.8,
-			Satisfaction:    4.2,
-			LoyaltyScore:    6.1,
-			Characteristics: []string{"group_bookings", "schedule_dependent", "cost_sensitive"},
-		},
-	}
-}
-
 func generateOptimizationRecommendations(propertyID string) []OptimizationRecommendation {
 	return []OptimizationRecommendation{
 		{
@@ -492,41 +394,331 @@ func generateOptimizationRecommendations(propertyID string) []OptimizationRecomm
 	}
 }
 
-func generateCompetitiveAnalysis(propertyID string) CompetitiveAnalysis {
-	return CompetitiveAnalysis{
-		MarketPosition: "Upper Middle Tier",
-		Benchmarks: []BenchmarkMetric{
-			{
-				Metric:      "Breakfast Take-up Rate",
-				OurValue:    73.2,
-				IndustryAvg: 68.5,
-				BestInClass: 82.1,
-				Gap:         8.9,
-				Ranking:     "above_avg",
-			},
-			{
-				Metric:      "Cost per Breakfast",
-				OurValue:    12.50,
-				IndustryAvg: 14.20,
-				BestInClass: 10.80,
-				Gap:         1.70,
-				Ranking:     "above_avg",
-			},
-		},
-		Opportunities: []string{
-			"Premium breakfast packages for business travelers",
-			"Family-friendly breakfast experiences",
-			"Healthy/dietary restriction options expansion",
-		},
-		Threats: []string{
-			"New boutique hotels with unique breakfast offerings",
-			"Rising food costs impacting margins",
-			"Changing guest preferences toward lighter meals",
-		},
-		Recommendations: []string{
-			"Invest in breakfast experience differentiation",
-			"Develop loyalty program integration",
-			"Implement sustainability initiatives",
-		},
+// analyticsWindow parses the from, to, period and comparison query parameters
+func (h *BreakfastHandler) analyticsWindow(c *gin.Context, propertyID string) (services.AnalyticsWindow, bool) {
+	var from, to time.Time
+	if c.Query("from") != "" || c.Query("to") != "" {
+		var err error
+		if from, err = time.Parse("2006-01-02", c.Query("from")); err != nil {
+			ValidationErrorResponse(c, "from must be in YYYY-MM-DD format")
+			return services.AnalyticsWindow{}, false
+		}
+		if to, err = time.Parse("2006-01-02", c.Query("to")); err != nil {
+			ValidationErrorResponse(c, "to must be in YYYY-MM-DD format")
+			return services.AnalyticsWindow{}, false
+		}
+	}
+
+	comparison := c.DefaultQuery("comparison", "previous")
+	if comparison != "previous" && comparison != "year" {
+		ValidationErrorResponse(c, "comparison must be previous or year")
+		return services.AnalyticsWindow{}, false
+	}
+
+	window, err := h.analyticsService.Window(propertyID, c.DefaultQuery("period", "week"), comparison, from, to)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAnalyticsRange) {
+			ValidationErrorResponse(c, err.Error())
+		} else {
+			InternalErrorResponse(c, err)
+		}
+		return services.AnalyticsWindow{}, false
+	}
+	return window, true
+}
+
+// metricValue compares a metric with its value over the comparison window
+func metricValue(current, previous float64) MetricValue {
+	value := MetricValue{
+		Current:  current,
+		Previous: previous,
+		Change:   math.Round((current-previous)*100) / 100,
+		Trend:    "stable",
+	}
+	if previous != 0 {
+		value.ChangePercent = math.Round((current-previous)/math.Abs(previous)*1000) / 10
+	}
+	switch {
+	case value.Change > 0:
+		value.Trend = "up"
+	case value.Change < 0:
+		value.Trend = "down"
+	}
+	return value
+}
+
+func chartPoints(points []services.AnalyticsPoint) []ChartDataPoint {
+	chart := make([]ChartDataPoint, 0, len(points))
+	for _, point := range points {
+		chart = append(chart, ChartDataPoint{Label: point.Label, Value: point.Value, Date: point.Date})
+	}
+	return chart
+}
+
+func chartSlices(points []services.AnalyticsPoint) []ChartPieSlice {
+	colors := []string{"#4ade80", "#22d3ee", "#6b7280", "#a855f7"}
+	total := 0.0
+	for _, point := range points {
+		total += point.Value
+	}
+
+	slices := make([]ChartPieSlice, 0, len(points))
+	for i, point := range points {
+		slice := ChartPieSlice{Label: point.Label, Value: point.Value, Color: colors[i%len(colors)]}
+		if total > 0 {
+			slice.Percentage = math.Round(point.Value/total*1000) / 10
+		}
+		slices = append(slices, slice)
+	}
+	return slices
+}
+
+// analyticsInsights flags the notable movements in a report
+func analyticsInsights(report *services.AnalyticsReport) []AnalyticsInsight {
+	current, previous := report.Current, report.Comparison
+	now := time.Now()
+	insights := []AnalyticsInsight{}
+
+	// Confidence grows with the number of entitled covers behind the rates
+	confidence := math.Round(float64(current.EntitledCovers)/float64(current.EntitledCovers+20)*100) / 100
+
+	if current.EntitledCovers > 0 {
+		if change := current.TakeUpRate - previous.TakeUpRate; previous.EntitledCovers > 0 && change <= -5 {
+			insights = append(insights, AnalyticsInsight{
+				Type:        "warning",
+				Title:       "Breakfast Take-up Falling",
+				Description: fmt.Sprintf("Take-up fell from %.1f%% to %.1f%% of entitled covers", previous.TakeUpRate, current.TakeUpRate),
+				Impact:      fmt.Sprintf("%d package covers went unused", current.EntitledCovers-current.TakenCovers),
+				Action:      "Remind package guests of breakfast at check-in and review no-show follow-up",
+				Confidence:  confidence,
+				CreatedAt:   now,
+			})
+		} else if change >= 5 {
+			insights = append(insights, AnalyticsInsight{
+				Type:        "info",
+				Title:       "Breakfast Take-up Rising",
+				Description: fmt.Sprintf("Take-up rose from %.1f%% to %.1f%% of entitled covers", previous.TakeUpRate, current.TakeUpRate),
+				Impact:      "More covers to staff and stock for",
+				Action:      "Check staffing ratios and par levels against the new demand",
+				Confidence:  confidence,
+				CreatedAt:   now,
+			})
+		}
+	}
+
+	peak, total := services.AnalyticsPoint{}, 0.0
+	for _, hour := range report.CoversByHour {
+		total += hour.Value
+		if hour.Value > peak.Value {
+			peak = hour
+		}
+	}
+	if total > 0 && peak.Value/total >= 0.35 {
+		insights = append(insights, AnalyticsInsight{
+			Type:        "warning",
+			Title:       "Peak Hour Capacity",
+			Description: fmt.Sprintf("%.0f%% of covers are served in the %s hour", peak.Value/total*100, peak.Label),
+			Impact:      "Longer waits and slower service at peak",
+			Action:      "Add staff for the peak hour or encourage earlier and later seatings",
+			Confidence:  math.Round(total/(total+20)*100) / 100,
+			CreatedAt:   now,
+		})
+	}
+
+	if current.CostPerBreakfast > 0 && previous.CostPerBreakfast > 0 {
+		if change := (current.CostPerBreakfast - previous.CostPerBreakfast) / previous.CostPerBreakfast * 100; change >= 5 {
+			insights = append(insights, AnalyticsInsight{
+				Type:        "warning",
+				Title:       "Cost per Breakfast Rising",
+				Description: fmt.Sprintf("Food cost per cover rose %.1f%% to %.2f", change, current.CostPerBreakfast),
+				Impact:      fmt.Sprintf("%.2f in food cost over the period", current.FoodCost),
+				Action:      "Review usage ratios and supplier prices",
+				Confidence:  math.Round(float64(current.Covers)/float64(current.Covers+20)*100) / 100,
+				CreatedAt:   now,
+			})
+		} else if change <= -5 {
+			insights = append(insights, AnalyticsInsight{
+				Type:        "info",
+				Title:       "Cost per Breakfast Falling",
+				Description: fmt.Sprintf("Food cost per cover fell %.1f%% to %.2f", -change, current.CostPerBreakfast),
+				Impact:      fmt.Sprintf("%.2f in food cost over the period", current.FoodCost),
+				Action:      "Check guest satisfaction has held up",
+				Confidence:  math.Round(float64(current.Covers)/float64(current.Covers+20)*100) / 100,
+				CreatedAt:   now,
+			})
+		}
+	}
+
+	if unused := current.EntitledCovers - current.TakenCovers; current.EntitledCovers > 0 && current.TakeUpRate < 60 {
+		insights = append(insights, AnalyticsInsight{
+			Type:        "opportunity",
+			Title:       "Unused Package Breakfasts",
+			Description: fmt.Sprintf("Only %.1f%% of entitled covers were taken", current.TakeUpRate),
+			Impact:      fmt.Sprintf("%d package covers were paid for but not served", unused),
+			Action:      "Offer grab-and-go options for guests who skip breakfast",
+			Confidence:  confidence,
+			CreatedAt:   now,
+		})
+	}
+
+	return insights
+}
+
+// analyticsKPIs scores the period's KPIs against the comparison window
+func analyticsKPIs(report *services.AnalyticsReport) []KPIMetric {
+	current, previous := report.Current, report.Comparison
+	return []KPIMetric{
+		kpiMetric("Breakfast Revenue", current.Revenue, previous.Revenue, "currency", false),
+		kpiMetric("Breakfast Take-up Rate", current.TakeUpRate, previous.TakeUpRate, "percent", false),
+		kpiMetric("Occupancy Rate", current.OccupancyRate, previous.OccupancyRate, "percent", false),
+		kpiMetric("Guest Satisfaction", current.SatisfactionRate, previous.SatisfactionRate, "percent", false),
+		kpiMetric("Cost per Breakfast", current.CostPerBreakfast, previous.CostPerBreakfast, "currency", true),
+	}
+}
+
+// kpiMetric scores a value against a target, within 2% counting as on target
+func kpiMetric(name string, value, target float64, unit string, lowerIsBetter bool) KPIMetric {
+	kpi := KPIMetric{Name: name, Value: value, Target: target, Performance: 100, Status: "on_target", Unit: unit}
+	switch {
+	case target == 0 && value == 0:
+	case target == 0 || value == 0:
+		kpi.Performance = 0
+		if (value > target) != lowerIsBetter {
+			kpi.Performance = 200
+		}
+	case lowerIsBetter:
+		kpi.Performance = target / value * 100
+	default:
+		kpi.Performance = value / target * 100
+	}
+	kpi.Performance = math.Round(kpi.Performance*10) / 10
+
+	switch {
+	case kpi.Performance > 102:
+		kpi.Status = "above"
+	case kpi.Performance < 98:
+		kpi.Status = "below"
+	}
+	return kpi
+}
+
+// customerSegments describes each segment relative to the whole property
+func customerSegments(report *services.AnalyticsReport) []CustomerSegment {
+	overall := report.Current
+	labels := map[string]string{"room_type": "Room type", "loyalty_tier": "Loyalty tier"}
+
+	segments := make([]CustomerSegment, 0, len(report.Segments))
+	for _, segment := range report.Segments {
+		name := segment.Name
+		if label, ok := labels[segment.Dimension]; ok {
+			name = label + ": " + segment.Name
+		}
+
+		characteristics := []string{}
+		switch {
+		case segment.EntitledCovers > 0 && segment.TakeUpRate >= overall.TakeUpRate+10:
+			characteristics = append(characteristics, "high_take_up")
+		case segment.EntitledCovers > 0 && segment.TakeUpRate <= overall.TakeUpRate-10:
+			characteristics = append(characteristics, "low_take_up")
+		}
+		if overall.Guests > 0 && segment.Guests > 0 {
+			average := overall.Revenue / float64(overall.Guests)
+			if spend := segment.Revenue / float64(segment.Guests); average > 0 && spend >= average*1.2 {
+				characteristics = append(characteristics, "high_spend")
+			} else if spend <= average*0.8 {
+				characteristics = append(characteristics, "low_spend")
+			}
+		}
+		switch {
+		case segment.AverageStay > 0 && segment.AverageStay >= overall.AverageStay*1.2:
+			characteristics = append(characteristics, "long_stays")
+		case segment.AverageStay > 0 && segment.AverageStay <= overall.AverageStay*0.8:
+			characteristics = append(characteristics, "short_stays")
+		}
+		if segment.SatisfactionRate > 0 && segment.SatisfactionRate <= overall.SatisfactionRate-10 {
+			characteristics = append(characteristics, "complaint_prone")
+		}
+
+		segments = append(segments, CustomerSegment{
+			Name:            name,
+			Size:            segment.Guests,
+			Revenue:         segment.Revenue,
+			BreakfastRate:   segment.TakeUpRate,
+			Satisfaction:    segment.SatisfactionRate,
+			LoyaltyScore:    math.Round(segment.LoyaltyShare) / 10,
+			Characteristics: characteristics,
+		})
+	}
+	return segments
+}
+
+// competitiveAnalysis positions the property among the other properties
+func competitiveAnalysis(benchmarks []services.AnalyticsBenchmark) CompetitiveAnalysis {
+	analysis := CompetitiveAnalysis{
+		MarketPosition:  "No peer properties to compare with",
+		Benchmarks:      []BenchmarkMetric{},
+		Opportunities:   []string{},
+		Threats:         []string{},
+		Recommendations: []string{},
+	}
+	if len(benchmarks) == 0 {
+		return analysis
+	}
+
+	recommendations := map[string]string{
+		"Breakfast Take-up Rate":              "Promote breakfast to package guests at check-in and in-room",
+		"Cost per Breakfast":                  "Review usage ratios, waste and supplier prices",
+		"Breakfast Revenue per Occupied Room": "Upsell breakfast to room-only guests",
+		"Occupancy Rate":                      "Share breakfast packages with revenue management",
+		"Guest Satisfaction":                  "Review complaints logged by staff with the outlet managers",
+	}
+
+	score := 0.0
+	for _, benchmark := range benchmarks {
+		properties := benchmark.Peers + 1
+		gap := benchmark.Value - benchmark.PeerAverage
+		if benchmark.LowerIsBetter {
+			gap = -gap
+		}
+
+		ranking := "below_avg"
+		switch position := float64(benchmark.Rank-1) / float64(properties); {
+		case position < 0.25:
+			ranking = "top_quartile"
+		case position >= 0.75:
+			ranking = "bottom_quartile"
+		case gap >= 0:
+			ranking = "above_avg"
+		}
+		score += float64(benchmark.Rank) / float64(properties)
+
+		analysis.Benchmarks = append(analysis.Benchmarks, BenchmarkMetric{
+			Metric:      benchmark.Metric,
+			OurValue:    benchmark.Value,
+			IndustryAvg: benchmark.PeerAverage,
+			BestInClass: benchmark.PeerBest,
+			Gap:         math.Round(gap*100) / 100,
+			Ranking:     ranking,
+		})
+
+		description := fmt.Sprintf("%s of %.2f against a peer average of %.2f", benchmark.Metric, benchmark.Value, benchmark.PeerAverage)
+		if gap >= 0 {
+			analysis.Opportunities = append(analysis.Opportunities, fmt.Sprintf("Ranked %d of %d: %s", benchmark.Rank, properties, description))
+		} else {
+			analysis.Threats = append(analysis.Threats, fmt.Sprintf("Ranked %d of %d: %s", benchmark.Rank, properties, description))
+			analysis.Recommendations = append(analysis.Recommendations, recommendations[benchmark.Metric])
+		}
+	}
+
+	switch score /= float64(len(benchmarks)); {
+	case score <= 0.25:
+		analysis.MarketPosition = "Leading the portfolio"
+	case score <= 0.5:
+		analysis.MarketPosition = "Above the portfolio average"
+	case score <= 0.75:
+		analysis.MarketPosition = "Below the portfolio average"
+	default:
+		analysis.MarketPosition = "Trailing the portfolio"
 	}
+	return analysis
 }
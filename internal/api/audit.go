@@ -1,11 +1,13 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"hudini-breakfast-module/internal/audit"
+	"hudini-breakfast-module/internal/export"
 	"hudini-breakfast-module/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -13,13 +15,15 @@ import (
 
 // AuditHandler handles audit log API endpoints
 type AuditHandler struct {
-	auditService *services.AuditService
+	auditService  *services.AuditService
+	exportService *services.ExportService
 }
 
 // NewAuditHandler creates a new audit handler
-func NewAuditHandler(auditService *services.AuditService) *AuditHandler {
+func NewAuditHandler(auditService *services.AuditService, exportService *services.ExportService) *AuditHandler {
 	return &AuditHandler{
-		auditService: auditService,
+		auditService:  auditService,
+		exportService: exportService,
 	}
 }
 
@@ -98,6 +102,21 @@ func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
 		filters.SortOrder = sortOrder
 	}
 
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if format != "" {
+		// Exports cover every matching entry, not just one page
+		h.export(c, format, "Audit log", "audit-logs", c.Query("start_date"), c.Query("end_date"), func(w export.Writer) (int, error) {
+			return h.auditService.ExportAuditLogs(c.Request.Context(), filters, w)
+		})
+		return
+	}
+
 	// Get audit logs
 	logs, total, err := h.auditService.GetAuditLogs(c.Request.Context(), filters)
 	if err != nil {
@@ -138,6 +157,14 @@ func (h *AuditHandler) GetUserActivity(c *gin.Context) {
 		}
 	}
 
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
 	// Get user activity
 	logs, err := h.auditService.GetUserActivity(c.Request.Context(), uint(userID), limit)
 	if err != nil {
@@ -147,6 +174,14 @@ func (h *AuditHandler) GetUserActivity(c *gin.Context) {
 		return
 	}
 
+	if format != "" {
+		title := fmt.Sprintf("Activity of user %d", userID)
+		h.export(c, format, title, fmt.Sprintf("user-%d-activity", userID), "", "", func(w export.Writer) (int, error) {
+			return services.WriteAuditLogs(w, title, logs)
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
@@ -188,6 +223,14 @@ func (h *AuditHandler) GetResourceHistory(c *gin.Context) {
 		return
 	}
 
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
 	// Get resource history
 	logs, err := h.auditService.GetResourceHistory(c.Request.Context(), auditResource, resourceID)
 	if err != nil {
@@ -197,6 +240,14 @@ func (h *AuditHandler) GetResourceHistory(c *gin.Context) {
 		return
 	}
 
+	if format != "" {
+		title := fmt.Sprintf("History of %s %s", resource, resourceID)
+		h.export(c, format, title, fmt.Sprintf("%s-%s-history", resource, resourceID), "", "", func(w export.Writer) (int, error) {
+			return services.WriteAuditLogs(w, title, logs)
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
@@ -208,6 +259,17 @@ func (h *AuditHandler) GetResourceHistory(c *gin.Context) {
 	})
 }
 
+// export streams audit log entries, branded with the exporting user's property
+func (h *AuditHandler) export(c *gin.Context, format export.Format, title, filename, from, to string, write func(export.Writer) (int, error)) {
+	propertyID := c.GetString("property_id")
+	subtitle := ""
+	if from != "" || to != "" {
+		subtitle = from + " to " + to
+	}
+	record := services.ExportRecord{Report: "audit_logs", Format: format, PropertyID: propertyID, From: from, To: to}
+	streamExport(c, h.exportService, audit.ResourceAuditLog, record, h.exportService.Branding(propertyID, title, subtitle), filename, write)
+}
+
 // GetAuditSummary provides a summary of audit activity
 // GET /api/audit/summary
 func (h *AuditHandler) GetAuditSummary(c *gin.Context) {
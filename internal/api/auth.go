@@ -208,6 +208,10 @@ func (h *AuthHandler) generateToken(staff models.Staff) (string, error) {
 func (h *AuthHandler) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" && c.IsWebsocket() && c.Query("token") != "" {
+			// Browsers cannot set headers on a websocket handshake
+			authHeader = "Bearer " + c.Query("token")
+		}
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
 			c.Abort()
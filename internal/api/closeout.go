@@ -0,0 +1,186 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// CloseOutHandler handles end-of-service close-outs
+type CloseOutHandler struct {
+	closeOutService *services.CloseOutService
+}
+
+// NewCloseOutHandler creates a new close-out handler
+func NewCloseOutHandler(closeOutService *services.CloseOutService) *CloseOutHandler {
+	return &CloseOutHandler{
+		closeOutService: closeOutService,
+	}
+}
+
+type CloseOutServiceRequest struct {
+	PropertyID string `json:"property_id" binding:"required"`
+	OutletID   *uint  `json:"outlet_id"`
+	Date       string `json:"date"` // YYYY-MM-DD, defaults to today
+}
+
+type ReopenServiceRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// POST /api/close-outs
+func (h *CloseOutHandler) CloseOut(c *gin.Context) {
+	var req CloseOutServiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	date := h.closeOutService.BusinessDate(req.PropertyID)
+	if req.Date != "" {
+		parsed, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			ValidationErrorResponse(c, "Invalid date format. Use YYYY-MM-DD")
+			return
+		}
+		date = parsed
+	}
+
+	staffID := c.GetUint("user_id")
+	if staffID == 0 {
+		UnauthorizedResponse(c)
+		return
+	}
+
+	closeOut, err := h.closeOutService.CloseOut(c.Request.Context(), services.CloseOutRequest{
+		PropertyID:   req.PropertyID,
+		OutletID:     req.OutletID,
+		BusinessDate: date,
+		StaffID:      &staffID,
+		Trigger:      "manual",
+		IPAddress:    c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+	})
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":     "CloseOut",
+			"property_id": req.PropertyID,
+			"outlet_id":   req.OutletID,
+			"error":       err.Error(),
+		}).Warn("Failed to close out service")
+
+		switch {
+		case errors.Is(err, services.ErrOutletNotFound):
+			NotFoundResponse(c, "Outlet")
+		case errors.Is(err, services.ErrAlreadyClosed):
+			ErrorResponse(c, http.StatusConflict, "CLOSE_OUT_ERROR", err.Error())
+		case errors.Is(err, services.ErrOutletPropertyMismatch):
+			ValidationErrorResponse(c, err.Error())
+		default:
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	CreatedResponse(c, closeOut)
+}
+
+// GET /api/close-outs
+func (h *CloseOutHandler) GetCloseOuts(c *gin.Context) {
+	propertyID := c.Query("property_id")
+	if propertyID == "" {
+		ValidationErrorResponse(c, "property_id is required")
+		return
+	}
+
+	var date *time.Time
+	if value := c.Query("date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			ValidationErrorResponse(c, "Invalid date format. Use YYYY-MM-DD")
+			return
+		}
+		date = &parsed
+	}
+
+	closeOuts, err := h.closeOutService.GetCloseOuts(propertyID, date)
+	if err != nil {
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, gin.H{"close_outs": closeOuts})
+}
+
+// GET /api/close-outs/:id
+func (h *CloseOutHandler) GetCloseOut(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid close-out ID")
+		return
+	}
+
+	closeOut, err := h.closeOutService.GetCloseOut(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrCloseOutNotFound) {
+			NotFoundResponse(c, "Close-out")
+		} else {
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponse(c, closeOut)
+}
+
+// POST /api/close-outs/:id/reopen
+func (h *CloseOutHandler) Reopen(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid close-out ID")
+		return
+	}
+
+	var req ReopenServiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	staffID := c.GetUint("user_id")
+	if staffID == 0 {
+		UnauthorizedResponse(c)
+		return
+	}
+
+	closeOut, err := h.closeOutService.Reopen(c.Request.Context(), uint(id), staffID, req.Reason, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":      "Reopen",
+			"close_out_id": id,
+			"staff_id":     staffID,
+			"error":        err.Error(),
+		}).Warn("Failed to re-open service")
+
+		switch {
+		case errors.Is(err, services.ErrCloseOutNotFound):
+			NotFoundResponse(c, "Close-out")
+		case errors.Is(err, services.ErrReopenReasonRequired):
+			ValidationErrorResponse(c, err.Error())
+		case errors.Is(err, services.ErrNotReopenable), errors.Is(err, services.ErrPropertyClosedOut):
+			ErrorResponse(c, http.StatusConflict, "REOPEN_ERROR", err.Error())
+		default:
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Breakfast service re-opened", closeOut)
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// EligibilityHandler handles breakfast eligibility rules and checks
+type EligibilityHandler struct {
+	eligibilityService *services.EligibilityService
+}
+
+// NewEligibilityHandler creates a new eligibility handler
+func NewEligibilityHandler(eligibilityService *services.EligibilityService) *EligibilityHandler {
+	return &EligibilityHandler{
+		eligibilityService: eligibilityService,
+	}
+}
+
+type EligibilityRuleRequest struct {
+	PropertyID  string `json:"property_id" binding:"required"`
+	RuleType    string `json:"rule_type" binding:"required"`
+	Value       string `json:"value"`
+	Description string `json:"description"`
+	IsActive    *bool  `json:"is_active"`
+}
+
+func (r *EligibilityRuleRequest) toModel() *models.EligibilityRule {
+	rule := &models.EligibilityRule{
+		PropertyID:  r.PropertyID,
+		RuleType:    r.RuleType,
+		Value:       r.Value,
+		Description: r.Description,
+		IsActive:    true,
+	}
+	if r.IsActive != nil {
+		rule.IsActive = *r.IsActive
+	}
+	return rule
+}
+
+// GET /api/eligibility-rules
+func (h *EligibilityHandler) GetRules(c *gin.Context) {
+	propertyID := c.Query("property_id")
+	if propertyID == "" {
+		ValidationErrorResponse(c, "property_id is required")
+		return
+	}
+
+	rules, err := h.eligibilityService.GetRules(propertyID)
+	if err != nil {
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, gin.H{
+		"rules":      rules,
+		"rule_types": h.eligibilityService.RuleTypes(),
+	})
+}
+
+// GET /api/eligibility/check
+func (h *EligibilityHandler) CheckRoom(c *gin.Context) {
+	propertyID := c.Query("property_id")
+	roomNumber := c.Query("room_number")
+	if propertyID == "" || roomNumber == "" {
+		ValidationErrorResponse(c, "property_id and room_number are required")
+		return
+	}
+
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			ValidationErrorResponse(c, "at must be an RFC3339 timestamp")
+			return
+		}
+		at = parsed
+	}
+
+	guest, decision, err := h.eligibilityService.CheckRoom(propertyID, roomNumber, at)
+	if err != nil {
+		if errors.Is(err, services.ErrNoGuestInRoom) {
+			NotFoundResponse(c, "Guest")
+		} else {
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponse(c, gin.H{
+		"room_number": roomNumber,
+		"guest_id":    guest.ID,
+		"guest_name":  guest.FirstName + " " + guest.LastName,
+		"decision":    decision,
+	})
+}
+
+// POST /api/eligibility-rules
+func (h *EligibilityHandler) CreateRule(c *gin.Context) {
+	var req EligibilityRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	rule := req.toModel()
+	if err := h.eligibilityService.CreateRule(rule); err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":     "CreateRule",
+			"property_id": req.PropertyID,
+			"rule_type":   req.RuleType,
+			"error":       err.Error(),
+		}).Error("Failed to create eligibility rule")
+		ErrorResponse(c, http.StatusBadRequest, "CREATE_RULE_ERROR", err.Error())
+		return
+	}
+
+	CreatedResponse(c, rule)
+}
+
+// PUT /api/eligibility-rules/:id
+func (h *EligibilityHandler) UpdateRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid rule ID")
+		return
+	}
+
+	var req EligibilityRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	updated, err := h.eligibilityService.UpdateRule(uint(id), req.toModel())
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler": "UpdateRule",
+			"rule_id": id,
+			"error":   err.Error(),
+		}).Error("Failed to update eligibility rule")
+
+		if errors.Is(err, services.ErrEligibilityRuleNotFound) {
+			NotFoundResponse(c, "Eligibility rule")
+		} else {
+			ErrorResponse(c, http.StatusBadRequest, "UPDATE_RULE_ERROR", err.Error())
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Eligibility rule updated successfully", updated)
+}
+
+// DELETE /api/eligibility-rules/:id
+func (h *EligibilityHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid rule ID")
+		return
+	}
+
+	if err := h.eligibilityService.DeleteRule(uint(id)); err != nil {
+		if errors.Is(err, services.ErrEligibilityRuleNotFound) {
+			NotFoundResponse(c, "Eligibility rule")
+		} else {
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Eligibility rule deleted successfully", gin.H{"rule_id": id})
+}
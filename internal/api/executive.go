@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/http"
 	"time"
 
@@ -16,20 +17,35 @@ import (
 type ExecutiveHandler struct {
 	breakfastService *services.BreakfastService
 	guestService     *services.GuestService
+	waitlistService  *services.WaitlistService
+	executiveService *services.ExecutiveService
 }
 
-func NewExecutiveHandler(breakfastService *services.BreakfastService, guestService *services.GuestService) *ExecutiveHandler {
+func NewExecutiveHandler(breakfastService *services.BreakfastService, guestService *services.GuestService, waitlistService *services.WaitlistService, executiveService *services.ExecutiveService) *ExecutiveHandler {
 	return &ExecutiveHandler{
 		breakfastService: breakfastService,
 		guestService:     guestService,
+		waitlistService:  waitlistService,
+		executiveService: executiveService,
 	}
 }
 
+// signedInPropertyID returns the signed-in user's property, which dashboards
+// report on. It answers 400 and reports false when the token names no property.
+func signedInPropertyID(c *gin.Context) (string, bool) {
+	propertyID := c.GetString("property_id")
+	if propertyID == "" {
+		ValidationErrorResponse(c, "property_id could not be resolved for the signed-in user")
+		return "", false
+	}
+	return propertyID, true
+}
+
 // GetExecutiveKPIs returns key performance indicators for executives
 func (h *ExecutiveHandler) GetExecutiveKPIs(c *gin.Context) {
-	propertyID := c.Query("property_id")
-	if propertyID == "" {
-		propertyID = "HOTEL001"
+	propertyID, ok := signedInPropertyID(c)
+	if !ok {
+		return
 	}
 
 	ctx := context.Background()
@@ -41,28 +57,40 @@ func (h *ExecutiveHandler) GetExecutiveKPIs(c *gin.Context) {
 		vipMetrics = &cache.VIPMetrics{} // Use empty metrics on error
 	}
 
-	// Get today's report
-	todayReport, err := h.breakfastService.GetDailyReport(propertyID, time.Now())
+	// Compare the current window with the one before it
+	period := c.DefaultQuery("period", "week") // day, week, month
+	days, comparison := 7, "vs last week"
+	switch period {
+	case "day":
+		days, comparison = 1, "vs yesterday"
+	case "month":
+		days, comparison = 30, "vs last month"
+	default:
+		period = "week"
+	}
+
+	current, previous, err := h.executiveService.Compare(propertyID, days)
 	if err != nil {
-		logging.WithError(err).Error("Failed to fetch daily report")
-		todayReport = &services.DailyBreakfastReport{}
+		logging.WithError(err).Error("Failed to aggregate executive metrics")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to aggregate executive metrics"})
+		return
 	}
 
-	// Calculate additional KPIs
 	kpis := ExecutiveKPIs{
 		TotalVIPs:        vipMetrics.TotalVIPs,
 		UpsetGuests:      vipMetrics.TotalUpset,
-		SatisfactionRate: calculateSatisfactionRate(vipMetrics),
-		AvgServiceTime:   12, // Mock data - would come from service analytics
-		Revenue:          calculateRevenue(todayReport),
-		OccupancyRate:    calculateOccupancyRate(propertyID),
+		SatisfactionRate: current.SatisfactionRate,
+		AvgServiceTime:   current.AvgServiceTime,
+		Revenue:          current.Revenue,
+		OccupancyRate:    int(math.Round(current.OccupancyRate)),
+		Period:           period,
 		Trends: KPITrends{
-			VIPTrend:          TrendData{Value: 12, Direction: "up", Period: "vs last week"},
-			UpsetTrend:        TrendData{Value: -8, Direction: "down", Period: "vs last week"},
-			SatisfactionTrend: TrendData{Value: 5, Direction: "up", Period: "vs last month"},
-			ServiceTimeTrend:  TrendData{Value: -2, Direction: "down", Period: "improvement"},
-			RevenueTrend:      TrendData{Value: 18, Direction: "up", Period: "vs last month"},
-			OccupancyTrend:    TrendData{Value: 3, Direction: "up", Period: "vs last week"},
+			VIPTrend:          percentTrend(float64(current.VIPGuests), float64(previous.VIPGuests), comparison),
+			UpsetTrend:        percentTrend(float64(current.UpsetVIPs), float64(previous.UpsetVIPs), comparison),
+			SatisfactionTrend: pointTrend(current.SatisfactionRate, previous.SatisfactionRate, comparison),
+			ServiceTimeTrend:  percentTrend(current.AvgServiceTime, previous.AvgServiceTime, comparison),
+			RevenueTrend:      percentTrend(current.Revenue, previous.Revenue, comparison),
+			OccupancyTrend:    pointTrend(current.OccupancyRate, previous.OccupancyRate, comparison),
 		},
 		LastUpdated: time.Now(),
 	}
@@ -72,9 +100,9 @@ func (h *ExecutiveHandler) GetExecutiveKPIs(c *gin.Context) {
 
 // GetVIPTrends returns VIP guest trends over time
 func (h *ExecutiveHandler) GetVIPTrends(c *gin.Context) {
-	propertyID := c.Query("property_id")
-	if propertyID == "" {
-		propertyID = "HOTEL001"
+	propertyID, ok := signedInPropertyID(c)
+	if !ok {
+		return
 	}
 	
 	period := c.Query("period") // week, month, year
@@ -82,31 +110,26 @@ func (h *ExecutiveHandler) GetVIPTrends(c *gin.Context) {
 		period = "week"
 	}
 
-	// Generate trend data based on period
-	var trends VIPTrends
-	
-	switch period {
-	case "week":
-		trends = VIPTrends{
-			Labels:     []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
-			VIPCounts:  []int{42, 45, 43, 47, 48, 52, 47},
-			UpsetCounts: []int{2, 1, 3, 2, 1, 2, 3},
-			Period:     period,
-		}
-	case "month":
-		trends = VIPTrends{
-			Labels:     []string{"Week 1", "Week 2", "Week 3", "Week 4"},
-			VIPCounts:  []int{180, 195, 188, 203},
-			UpsetCounts: []int{8, 6, 9, 7},
-			Period:     period,
-		}
-	case "year":
-		trends = VIPTrends{
-			Labels:     []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun"},
-			VIPCounts:  []int{720, 780, 810, 890, 920, 960},
-			UpsetCounts: []int{35, 28, 32, 25, 22, 20},
-			Period:     period,
-		}
+	if period != "month" && period != "year" {
+		period = "week"
+	}
+
+	series, err := h.executiveService.Series(propertyID, period)
+	if err != nil {
+		logging.WithError(err).Error("Failed to aggregate VIP trends")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to aggregate VIP trends"})
+		return
+	}
+
+	trends := VIPTrends{
+		Labels:      series.Labels,
+		VIPCounts:   make([]int, len(series.Metrics)),
+		UpsetCounts: make([]int, len(series.Metrics)),
+		Period:      series.Period,
+	}
+	for i, metrics := range series.Metrics {
+		trends.VIPCounts[i] = metrics.VIPGuests
+		trends.UpsetCounts[i] = metrics.UpsetVIPs
 	}
 
 	c.JSON(http.StatusOK, trends)
@@ -114,9 +137,9 @@ func (h *ExecutiveHandler) GetVIPTrends(c *gin.Context) {
 
 // GetServicePerformance returns service performance metrics
 func (h *ExecutiveHandler) GetServicePerformance(c *gin.Context) {
-	propertyID := c.Query("property_id")
-	if propertyID == "" {
-		propertyID = "HOTEL001"
+	propertyID, ok := signedInPropertyID(c)
+	if !ok {
+		return
 	}
 	
 	period := c.Query("period") // today, week, month
@@ -125,28 +148,57 @@ func (h *ExecutiveHandler) GetServicePerformance(c *gin.Context) {
 	}
 
 	var performance ServicePerformance
-	
-	switch period {
-	case "today":
-		performance = ServicePerformance{
-			Labels: []string{"6AM", "7AM", "8AM", "9AM", "10AM", "11AM"},
-			ServiceTimes: []float64{8, 12, 15, 13, 10, 8},
-			Period: period,
-			AverageTime: 11,
+
+	if period == "week" || period == "month" {
+		series, err := h.executiveService.Series(propertyID, period)
+		if err != nil {
+			logging.WithError(err).Error("Failed to aggregate service times")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to aggregate service times"})
+			return
 		}
-	case "week":
+
 		performance = ServicePerformance{
-			Labels: []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
-			ServiceTimes: []float64{12, 11, 13, 12, 11, 14, 12},
-			Period: period,
-			AverageTime: 12.1,
+			Labels:       series.Labels,
+			ServiceTimes: make([]float64, len(series.Metrics)),
+			Period:       series.Period,
 		}
-	case "month":
+		total, samples := 0.0, 0
+		for i, metrics := range series.Metrics {
+			performance.ServiceTimes[i] = metrics.AvgServiceTime
+			total += metrics.AvgServiceTime * float64(metrics.ServiceSamples)
+			samples += metrics.ServiceSamples
+		}
+		if samples > 0 {
+			performance.AverageTime = math.Round(total/float64(samples)*10) / 10
+		}
+	} else {
+		period = "today"
+		hourly, err := h.executiveService.ServiceTimesToday(propertyID)
+		if err != nil {
+			logging.WithError(err).Error("Failed to aggregate service times")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to aggregate service times"})
+			return
+		}
+
 		performance = ServicePerformance{
-			Labels: []string{"Week 1", "Week 2", "Week 3", "Week 4"},
-			ServiceTimes: []float64{12.5, 12.2, 11.8, 12.0},
-			Period: period,
-			AverageTime: 12.1,
+			Labels:       hourly.Labels,
+			ServiceTimes: hourly.ServiceTimes,
+			Period:       period,
+			AverageTime:  hourly.AverageTime,
+		}
+	}
+
+	// Wait times come from the outlet waitlists
+	if h.waitlistService != nil {
+		stats, err := h.waitlistService.WaitTimeStats(propertyID, period)
+		if err != nil {
+			logging.WithError(err).Error("Failed to fetch wait time stats")
+		} else {
+			performance.WaitTimes = stats.AverageWaits
+			performance.AverageWait = stats.AverageWait
+			performance.LongestWait = stats.LongestWait
+			performance.PartiesWaited = stats.PartiesWaited
+			performance.NoShows = stats.NoShows
 		}
 	}
 
@@ -155,9 +207,9 @@ func (h *ExecutiveHandler) GetServicePerformance(c *gin.Context) {
 
 // GetRevenueAnalysis returns revenue analysis data
 func (h *ExecutiveHandler) GetRevenueAnalysis(c *gin.Context) {
-	propertyID := c.Query("property_id")
-	if propertyID == "" {
-		propertyID = "HOTEL001"
+	propertyID, ok := signedInPropertyID(c)
+	if !ok {
+		return
 	}
 	
 	period := c.Query("period") // week, month, quarter
@@ -165,33 +217,29 @@ func (h *ExecutiveHandler) GetRevenueAnalysis(c *gin.Context) {
 		period = "week"
 	}
 
-	var revenue RevenueAnalysis
-	
-	switch period {
-	case "week":
-		revenue = RevenueAnalysis{
-			Labels: []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
-			Amounts: []float64{1850, 2100, 1950, 2200, 2350, 2800, 2450},
-			Total: 15700,
-			Average: 2242.86,
-			Period: period,
-		}
-	case "month":
-		revenue = RevenueAnalysis{
-			Labels: []string{"Week 1", "Week 2", "Week 3", "Week 4"},
-			Amounts: []float64{14200, 15300, 14800, 16200},
-			Total: 60500,
-			Average: 15125,
-			Period: period,
-		}
-	case "quarter":
-		revenue = RevenueAnalysis{
-			Labels: []string{"Jan", "Feb", "Mar"},
-			Amounts: []float64{58000, 62000, 65500},
-			Total: 185500,
-			Average: 61833.33,
-			Period: period,
-		}
+	if period != "month" && period != "quarter" {
+		period = "week"
+	}
+
+	series, err := h.executiveService.Series(propertyID, period)
+	if err != nil {
+		logging.WithError(err).Error("Failed to aggregate revenue")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to aggregate revenue"})
+		return
+	}
+
+	revenue := RevenueAnalysis{
+		Labels:  series.Labels,
+		Amounts: make([]float64, len(series.Metrics)),
+		Period:  series.Period,
+	}
+	for i, metrics := range series.Metrics {
+		revenue.Amounts[i] = metrics.Revenue
+		revenue.Total += metrics.Revenue
+	}
+	revenue.Total = math.Round(revenue.Total*100) / 100
+	if len(revenue.Amounts) > 0 {
+		revenue.Average = math.Round(revenue.Total/float64(len(revenue.Amounts))*100) / 100
 	}
 
 	c.JSON(http.StatusOK, revenue)
@@ -199,15 +247,22 @@ func (h *ExecutiveHandler) GetRevenueAnalysis(c *gin.Context) {
 
 // GetGuestPreferences returns guest preference distribution
 func (h *ExecutiveHandler) GetGuestPreferences(c *gin.Context) {
-	propertyID := c.Query("property_id")
-	if propertyID == "" {
-		propertyID = "HOTEL001"
+	propertyID, ok := signedInPropertyID(c)
+	if !ok {
+		return
+	}
+
+	counts, total, err := h.executiveService.GuestPreferences(propertyID)
+	if err != nil {
+		logging.WithError(err).Error("Failed to aggregate guest preferences")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to aggregate guest preferences"})
+		return
 	}
 
-	preferences := GuestPreferences{
-		Labels: []string{"Continental", "American", "Healthy", "Vegetarian", "Special Diet"},
-		Counts: []int{35, 25, 20, 15, 5},
-		Total: 100,
+	preferences := GuestPreferences{Total: total}
+	for _, count := range counts {
+		preferences.Labels = append(preferences.Labels, count.Label)
+		preferences.Counts = append(preferences.Counts, count.Count)
 	}
 
 	c.JSON(http.StatusOK, preferences)
@@ -215,9 +270,9 @@ func (h *ExecutiveHandler) GetGuestPreferences(c *gin.Context) {
 
 // GetUpsetVIPGuests returns VIP guests requiring attention
 func (h *ExecutiveHandler) GetUpsetVIPGuests(c *gin.Context) {
-	propertyID := c.Query("property_id")
-	if propertyID == "" {
-		propertyID = "HOTEL001"
+	propertyID, ok := signedInPropertyID(c)
+	if !ok {
+		return
 	}
 
 	ctx := context.Background()
@@ -261,9 +316,9 @@ func (h *ExecutiveHandler) GetUpsetVIPGuests(c *gin.Context) {
 
 // GetExecutiveAlerts returns active alerts for executives
 func (h *ExecutiveHandler) GetExecutiveAlerts(c *gin.Context) {
-	propertyID := c.Query("property_id")
-	if propertyID == "" {
-		propertyID = "HOTEL001"
+	propertyID, ok := signedInPropertyID(c)
+	if !ok {
+		return
 	}
 
 	ctx := context.Background()
@@ -285,9 +340,15 @@ func (h *ExecutiveHandler) GetExecutiveAlerts(c *gin.Context) {
 	}
 
 	// Add other alerts based on business rules
+	today, _, err := h.executiveService.Compare(propertyID, 1)
+	if err != nil {
+		logging.WithError(err).Error("Failed to aggregate today's metrics")
+		today = &services.ExecutiveMetrics{}
+	}
+
 	// Low occupancy alert
-	occupancyRate := calculateOccupancyRate(propertyID)
-	if occupancyRate < 70 {
+	occupancyRate := int(math.Round(today.OccupancyRate))
+	if today.OccupancyRate > 0 && occupancyRate < 70 {
 		alerts = append(alerts, ExecutiveAlert{
 			Type:     "low_occupancy",
 			Severity: "medium",
@@ -298,8 +359,7 @@ func (h *ExecutiveHandler) GetExecutiveAlerts(c *gin.Context) {
 	}
 
 	// Service time alert
-	avgServiceTime := 12.0 // This would come from actual metrics
-	if avgServiceTime > 15 {
+	if today.AvgServiceTime > 15 {
 		alerts = append(alerts, ExecutiveAlert{
 			Type:     "service_delay",
 			Severity: "medium",
@@ -316,23 +376,32 @@ func (h *ExecutiveHandler) GetExecutiveAlerts(c *gin.Context) {
 }
 
 // Helper functions
-func calculateSatisfactionRate(metrics *cache.VIPMetrics) float64 {
-	if metrics.TotalVIPs == 0 {
-		return 100.0
+
+// percentTrend reports the percentage change between two periods
+func percentTrend(current, previous float64, period string) TrendData {
+	change := 0.0
+	if previous != 0 {
+		change = (current - previous) / previous * 100
+	} else if current != 0 {
+		change = 100
 	}
-	return float64(metrics.TotalVIPs-metrics.TotalUpset) / float64(metrics.TotalVIPs) * 100
+	return trendData(change, period)
 }
 
-func calculateRevenue(report *services.DailyBreakfastReport) float64 {
-	// Base breakfast price * consumed count
-	basePrice := 25.0
-	return float64(report.TotalConsumed) * basePrice
+// pointTrend reports the change in percentage points between two rates
+func pointTrend(current, previous float64, period string) TrendData {
+	return trendData(current-previous, period)
 }
 
-func calculateOccupancyRate(propertyID string) int {
-	// This would query actual room occupancy
-	// For demo, return a realistic value
-	return 87
+func trendData(change float64, period string) TrendData {
+	trend := TrendData{Value: math.Round(change*10) / 10, Direction: "flat", Period: period}
+	switch {
+	case trend.Value > 0:
+		trend.Direction = "up"
+	case trend.Value < 0:
+		trend.Direction = "down"
+	}
+	return trend
 }
 
 func calculateStayDuration(checkIn, checkOut time.Time) string {
@@ -349,9 +418,10 @@ type ExecutiveKPIs struct {
 	TotalVIPs        int       `json:"total_vips"`
 	UpsetGuests      int       `json:"upset_guests"`
 	SatisfactionRate float64   `json:"satisfaction_rate"`
-	AvgServiceTime   int       `json:"avg_service_time"`
+	AvgServiceTime   float64   `json:"avg_service_time"` // Minutes from seated to first served
 	Revenue          float64   `json:"revenue"`
 	OccupancyRate    int       `json:"occupancy_rate"`
+	Period           string    `json:"period"` // Window the values and trends cover: day, week, month
 	Trends           KPITrends `json:"trends"`
 	LastUpdated      time.Time `json:"last_updated"`
 }
@@ -366,7 +436,7 @@ type KPITrends struct {
 }
 
 type TrendData struct {
-	Value     float64 `json:"value"`
+	Value     float64 `json:"value"` // Percent change, or percentage points for rates
 	Direction string  `json:"direction"`
 	Period    string  `json:"period"`
 }
@@ -380,9 +450,14 @@ type VIPTrends struct {
 
 type ServicePerformance struct {
 	Labels       []string  `json:"labels"`
-	ServiceTimes []float64 `json:"service_times"`
-	Period       string    `json:"period"`
-	AverageTime  float64   `json:"average_time"`
+	ServiceTimes  []float64 `json:"service_times"`
+	Period        string    `json:"period"`
+	AverageTime   float64   `json:"average_time"`
+	WaitTimes     []float64 `json:"wait_times"` // Average waitlist wait in minutes per label
+	AverageWait   float64   `json:"average_wait"`
+	LongestWait   float64   `json:"longest_wait"`
+	PartiesWaited int       `json:"parties_waited"`
+	NoShows       int       `json:"no_shows"`
 }
 
 type RevenueAnalysis struct {
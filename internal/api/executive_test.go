@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSignedInPropertyID(t *testing.T) {
+	tests := []struct {
+		name     string
+		signedIn string
+		query    string
+		status   int
+		property string
+	}{
+		{"signed-in property", "HOTEL1", "", http.StatusOK, "HOTEL1"},
+		{"another property asked for", "HOTEL1", "?property_id=HOTEL2", http.StatusOK, "HOTEL1"},
+		{"no property on the token", "", "?property_id=HOTEL2", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/api/executive/kpis", func(c *gin.Context) {
+				c.Set("property_id", tt.signedIn)
+			}, func(c *gin.Context) {
+				propertyID, ok := signedInPropertyID(c)
+				if !ok {
+					return
+				}
+				c.String(http.StatusOK, propertyID)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/executive/kpis"+tt.query, nil))
+			if w.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status == http.StatusOK && w.Body.String() != tt.property {
+				t.Errorf("dashboard for %q, want %q", w.Body.String(), tt.property)
+			}
+		})
+	}
+}
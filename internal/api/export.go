@@ -0,0 +1,47 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"hudini-breakfast-module/internal/audit"
+	"hudini-breakfast-module/internal/export"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportFormat reads the format query parameter. JSON, the default, is
+// returned as an empty format; anything else must be csv, xlsx or pdf.
+func exportFormat(c *gin.Context) (export.Format, error) {
+	value := c.DefaultQuery("format", "json")
+	if value == "json" {
+		return "", nil
+	}
+	format, err := export.ParseFormat(value)
+	if err != nil {
+		return "", fmt.Errorf("format must be json, csv, xlsx or pdf")
+	}
+	return format, nil
+}
+
+// streamExport writes an export straight to the response as it is produced
+// and records it in the audit log. Once streaming starts the status can no
+// longer change, so a failure part way through only shows in the audit log.
+func streamExport(c *gin.Context, exportService *services.ExportService, resource audit.AuditResource, record services.ExportRecord, branding export.Branding, filename string, write func(export.Writer) (int, error)) {
+	writer, err := export.NewWriter(c.Writer, record.Format, branding)
+	if err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	c.Header("Content-Type", record.Format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, record.Format.Filename(filename)))
+	c.Status(http.StatusOK)
+
+	record.Rows, err = write(writer)
+	if err == nil {
+		err = writer.Close()
+	}
+	exportService.Record(c.Request.Context(), c.GetUint("user_id"), resource, record, c.ClientIP(), c.Request.UserAgent(), err)
+}
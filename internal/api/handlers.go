@@ -1,11 +1,14 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"hudini-breakfast-module/internal/audit"
+	"hudini-breakfast-module/internal/export"
 	"hudini-breakfast-module/internal/logging"
 	"hudini-breakfast-module/internal/models"
 	"hudini-breakfast-module/internal/services"
@@ -16,11 +19,17 @@ import (
 
 type BreakfastHandler struct {
 	breakfastService *services.BreakfastService
+	forecastService  *services.ForecastService
+	analyticsService *services.AnalyticsService
+	exportService    *services.ExportService
 }
 
-func NewBreakfastHandler(breakfastService *services.BreakfastService) *BreakfastHandler {
+func NewBreakfastHandler(breakfastService *services.BreakfastService, forecastService *services.ForecastService, analyticsService *services.AnalyticsService, exportService *services.ExportService) *BreakfastHandler {
 	return &BreakfastHandler{
 		breakfastService: breakfastService,
+		forecastService:  forecastService,
+		analyticsService: analyticsService,
+		exportService:    exportService,
 	}
 }
 
@@ -76,9 +85,9 @@ func (h *BreakfastHandler) MarkBreakfastConsumed(c *gin.Context) {
 		return
 	}
 
-	// Get staff ID from JWT token (this would come from auth middleware)
-	staffIDInterface, exists := c.Get("staff_id")
-	if !exists {
+	// The signed-in staff member serving the guest, set by the auth middleware
+	staffID := c.GetUint("user_id")
+	if staffID == 0 {
 		logging.WithFields(logrus.Fields{
 			"handler":     "MarkBreakfastConsumed",
 			"room_number": roomNumber,
@@ -88,27 +97,30 @@ func (h *BreakfastHandler) MarkBreakfastConsumed(c *gin.Context) {
 		UnauthorizedResponse(c)
 		return
 	}
-	
-	staffID, ok := staffIDInterface.(uint)
-	if !ok {
-		logging.WithFields(logrus.Fields{
-			"handler":     "MarkBreakfastConsumed",
-			"room_number": roomNumber,
-			"property_id": propertyID,
-			"error":       "invalid staff ID type",
-		}).Error("Invalid staff ID")
-		InternalErrorResponse(c, fmt.Errorf("invalid staff ID"))
-		return
+
+	// Covers and outlet are optional; an empty body serves all remaining covers
+	var req struct {
+		OutletID uint `json:"outlet_id"`
+		services.CoverRequest
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			ValidationErrorResponse(c, err.Error())
+			return
+		}
 	}
 
 	logging.WithFields(logrus.Fields{
-		"handler":     "MarkBreakfastConsumed",
-		"room_number": roomNumber,
-		"property_id": propertyID,
-		"staff_id":    staffID,
+		"handler":      "MarkBreakfastConsumed",
+		"room_number":  roomNumber,
+		"property_id":  propertyID,
+		"staff_id":     staffID,
+		"outlet_id":    req.OutletID,
+		"adult_covers": req.Adults,
+		"child_covers": req.Children,
 	}).Info("Marking breakfast as consumed")
 
-	err := h.breakfastService.MarkBreakfastConsumed(propertyID, roomNumber, staffID)
+	consumption, err := h.breakfastService.MarkBreakfastConsumed(propertyID, roomNumber, staffID, req.OutletID, req.CoverRequest)
 	if err != nil {
 		logging.WithFields(logrus.Fields{
 			"handler":     "MarkBreakfastConsumed",
@@ -117,6 +129,10 @@ func (h *BreakfastHandler) MarkBreakfastConsumed(c *gin.Context) {
 			"staff_id":    staffID,
 			"error":       err.Error(),
 		}).Error("Failed to mark breakfast as consumed")
+		if errors.Is(err, services.ErrNoPriceConfigured) {
+			ErrorResponse(c, http.StatusConflict, "NO_PRICE_CONFIGURED", "No breakfast price is configured for this property; add one to the price book before serving")
+			return
+		}
 		ErrorResponse(c, http.StatusBadRequest, "CONSUMPTION_ERROR", err.Error())
 		return
 	}
@@ -128,7 +144,7 @@ func (h *BreakfastHandler) MarkBreakfastConsumed(c *gin.Context) {
 		"staff_id":    staffID,
 	}).Info("Successfully marked breakfast as consumed")
 
-	SuccessResponseWithMessage(c, "Breakfast consumption marked successfully", nil)
+	SuccessResponseWithMessage(c, "Breakfast consumption marked successfully", consumption)
 }
 
 // GET /api/consumption/history
@@ -140,8 +156,8 @@ func (h *BreakfastHandler) GetConsumptionHistory(c *gin.Context) {
 	}
 
 	// Parse date range
-	startDateStr := c.DefaultQuery("start_date", time.Now().AddDate(0, 0, -7).Format("2006-01-02"))
-	endDateStr := c.DefaultQuery("end_date", time.Now().Format("2006-01-02"))
+	startDateStr := c.DefaultQuery("start_date", h.breakfastService.BusinessDate(propertyID).AddDate(0, 0, -7).Format("2006-01-02"))
+	endDateStr := c.DefaultQuery("end_date", h.breakfastService.BusinessDate(propertyID).Format("2006-01-02"))
 	
 	startDate, err := time.Parse("2006-01-02", startDateStr)
 	if err != nil {
@@ -155,6 +171,22 @@ func (h *BreakfastHandler) GetConsumptionHistory(c *gin.Context) {
 		return
 	}
 
+	format, err := exportFormat(c)
+	if err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+	if format != "" {
+		record := services.ExportRecord{Report: "consumption_history", Format: format, PropertyID: propertyID, From: startDateStr, To: endDateStr}
+		branding := h.exportService.Branding(propertyID, "Consumption history", startDateStr+" to "+endDateStr)
+		streamExport(c, h.exportService, audit.ResourceConsumption, record, branding,
+			fmt.Sprintf("consumption-%s-%s-%s", propertyID, startDateStr, endDateStr),
+			func(w export.Writer) (int, error) {
+				return h.breakfastService.ExportConsumptionHistory(c.Request.Context(), propertyID, startDate, endDate, w)
+			})
+		return
+	}
+
 	consumptions, err := h.breakfastService.GetConsumptionHistory(propertyID, startDate, endDate)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -172,18 +204,35 @@ func (h *BreakfastHandler) GetDailyReport(c *gin.Context) {
 		return
 	}
 
-	dateStr := c.DefaultQuery("date", time.Now().Format("2006-01-02"))
+	dateStr := c.DefaultQuery("date", h.breakfastService.BusinessDate(propertyID).Format("2006-01-02"))
 	date, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format (YYYY-MM-DD)"})
 		return
 	}
 
+	format, err := exportFormat(c)
+	if err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
 	report, err := h.breakfastService.GetDailyReport(propertyID, date)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+
+	if format != "" {
+		record := services.ExportRecord{Report: "daily_report", Format: format, PropertyID: propertyID, From: dateStr, To: dateStr}
+		branding := h.exportService.Branding(propertyID, "Daily breakfast report", dateStr)
+		streamExport(c, h.exportService, audit.ResourceReport, record, branding,
+			fmt.Sprintf("daily-report-%s-%s", propertyID, dateStr),
+			func(w export.Writer) (int, error) {
+				return services.WriteDailyReport(w, report)
+			})
+		return
+	}
 	
 	c.JSON(http.StatusOK, gin.H{"report": report})
 }
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+	"hudini-breakfast-module/internal/services"
+	"hudini-breakfast-module/internal/validation"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	logging.InitLogger(logging.LoggingConfig{Level: "error"})
+	os.Exit(m.Run())
+}
+
+// newTestDB opens a private in-memory database with the schema the handler
+// tests need. A single connection keeps every query on the same database.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	err = db.AutoMigrate(
+		&models.Property{},
+		&models.Room{},
+		&models.Guest{},
+		&models.Staff{},
+		&models.DailyBreakfastConsumption{},
+		&models.Outlet{},
+		&models.InventoryItem{},
+		&models.InventoryUsage{},
+		&models.InventoryMovement{},
+		&models.BreakfastPrice{},
+		&models.EligibilityRule{},
+		&models.ServiceCloseOut{},
+		&models.DailyRollup{},
+		&models.HourlyRollup{},
+	)
+	if err != nil {
+		t.Fatalf("migrating database: %v", err)
+	}
+	return db
+}
+
+// mustCreate inserts a record, failing the test if it cannot
+func mustCreate(t *testing.T, db *gorm.DB, value interface{}) {
+	t.Helper()
+	if err := db.Create(value).Error; err != nil {
+		t.Fatalf("creating %T: %v", value, err)
+	}
+}
+
+// signIn returns a bearer token for a staff member
+func signIn(t *testing.T, auth *AuthHandler, staff models.Staff) string {
+	t.Helper()
+	token, err := auth.generateToken(staff)
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return "Bearer " + token
+}
+
+func TestMarkBreakfastConsumedThroughAuth(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now().UTC()
+	mustCreate(t, db, &models.Property{PropertyID: "HOTEL1", Name: "Harbour Hotel", TimeZone: "UTC"})
+	mustCreate(t, db, &models.BreakfastPrice{PropertyID: "HOTEL1", GuestType: "adult", Price: 20, EffectiveFrom: now.AddDate(-1, 0, 0)})
+	mustCreate(t, db, &models.Guest{
+		PMSGuestID: "G1", ReservationID: "R1", RoomNumber: "101", FirstName: "Ada", LastName: "Guest",
+		PropertyID: "HOTEL1", IsActive: true, BreakfastPackage: true, AdultCount: 2,
+		CheckInDate: now.AddDate(0, 0, -1), CheckOutDate: now.AddDate(0, 0, 2),
+	})
+	staff := models.Staff{Email: "host@example.com", Password: "x", FirstName: "Sam", LastName: "Host", Role: "staff", PropertyID: "HOTEL1", IsActive: true}
+	mustCreate(t, db, &staff)
+
+	auth := NewAuthHandler(db, "test-secret")
+	handler := NewBreakfastHandler(services.NewBreakfastService(db, nil), nil, nil, nil)
+	router := gin.New()
+	router.POST("/api/rooms/:room_number/consume",
+		auth.AuthMiddleware(),
+		auth.RequireRole("staff", "manager", "admin"),
+		validation.ValidatePropertyID(),
+		validation.ValidateRoomNumber(),
+		handler.MarkBreakfastConsumed)
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{"without a token", "", http.StatusUnauthorized},
+		{"with a forged token", "Bearer not-a-token", http.StatusUnauthorized},
+		{"signed in", signIn(t, auth, staff), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/rooms/101/consume?property_id=HOTEL1", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+		})
+	}
+
+	// The visit is recorded against the signed-in staff member
+	var visit models.DailyBreakfastConsumption
+	if err := db.First(&visit).Error; err != nil {
+		t.Fatalf("fetching the recorded visit: %v", err)
+	}
+	if visit.ConsumedBy == nil || *visit.ConsumedBy != staff.ID || visit.AdultCovers != 2 {
+		t.Errorf("visit = consumed by %v, %d adults, want staff %d, 2 adults", visit.ConsumedBy, visit.AdultCovers, staff.ID)
+	}
+
+	var body struct {
+		Success bool                             `json:"success"`
+		Data    models.DailyBreakfastConsumption `json:"data"`
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/rooms/101/consume?property_id=HOTEL1", nil)
+	req.Header.Set("Authorization", signIn(t, auth, staff))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if w.Code == http.StatusOK || body.Success {
+		t.Errorf("serving a room whose covers are used up: status %d, success %v", w.Code, body.Success)
+	}
+}
+
+func TestMarkBreakfastConsumedWithoutPrice(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now().UTC()
+	mustCreate(t, db, &models.Property{PropertyID: "HOTEL1", Name: "Harbour Hotel", TimeZone: "UTC"})
+	mustCreate(t, db, &models.Guest{
+		PMSGuestID: "G1", ReservationID: "R1", RoomNumber: "101", FirstName: "Ada", LastName: "Guest",
+		PropertyID: "HOTEL1", IsActive: true, BreakfastPackage: true, AdultCount: 2,
+		CheckInDate: now.AddDate(0, 0, -1), CheckOutDate: now.AddDate(0, 0, 2),
+	})
+
+	handler := NewBreakfastHandler(services.NewBreakfastService(db, nil), nil, nil, nil)
+	router := gin.New()
+	router.POST("/api/rooms/:room_number/consume", func(c *gin.Context) {
+		c.Set("user_id", uint(1))
+	}, handler.MarkBreakfastConsumed)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/rooms/101/consume?property_id=HOTEL1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if w.Code != http.StatusConflict || body.Error.Code != "NO_PRICE_CONFIGURED" {
+		t.Errorf("status %d, code %q, want 409 NO_PRICE_CONFIGURED", w.Code, body.Error.Code)
+	}
+}
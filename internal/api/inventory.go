@@ -0,0 +1,327 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// InventoryHandler handles outlet supplies, usage ratios and stock counts
+type InventoryHandler struct {
+	inventoryService *services.InventoryService
+}
+
+// NewInventoryHandler creates a new inventory handler
+func NewInventoryHandler(inventoryService *services.InventoryService) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+	}
+}
+
+// InventoryItemRequest is the payload for creating or updating an inventory item.
+// Pointer fields distinguish omitted values from explicit zero values.
+type InventoryItemRequest struct {
+	Code     *string  `json:"code"`
+	Name     *string  `json:"name"`
+	Category *string  `json:"category"`
+	Unit     *string  `json:"unit"`
+	ParLevel *float64 `json:"par_level"`
+	UnitCost *float64 `json:"unit_cost"`
+	IsActive *bool    `json:"is_active"`
+}
+
+type InventoryUsageRequest struct {
+	PropertyID string  `json:"property_id" binding:"required"`
+	MenuType   string  `json:"menu_type" binding:"required"`
+	Code       string  `json:"code" binding:"required"`
+	PerCover   float64 `json:"per_cover" binding:"required"`
+}
+
+type StockMovementRequest struct {
+	Quantity *float64 `json:"quantity" binding:"required"`
+	Reason   string   `json:"reason"`
+}
+
+// GET /api/outlets/:id/inventory?below_par=true
+func (h *InventoryHandler) GetItems(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	items, err := h.inventoryService.GetItems(uint(outletID), c.Query("below_par") == "true")
+	if err != nil {
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, items)
+}
+
+// POST /api/outlets/:id/inventory
+func (h *InventoryHandler) CreateItem(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	var req InventoryItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	item := models.InventoryItem{OutletID: uint(outletID), IsActive: true}
+	if req.Code != nil {
+		item.Code = *req.Code
+	}
+	if req.Name != nil {
+		item.Name = *req.Name
+	}
+	if req.Category != nil {
+		item.Category = *req.Category
+	}
+	if req.Unit != nil {
+		item.Unit = *req.Unit
+	}
+	if req.ParLevel != nil {
+		item.ParLevel = *req.ParLevel
+	}
+	if req.UnitCost != nil {
+		item.UnitCost = *req.UnitCost
+	}
+	if req.IsActive != nil {
+		item.IsActive = *req.IsActive
+	}
+
+	if err := h.inventoryService.CreateItem(&item); err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":   "CreateInventoryItem",
+			"outlet_id": outletID,
+			"error":     err.Error(),
+		}).Error("Failed to create inventory item")
+
+		h.inventoryError(c, "CREATE_INVENTORY_ITEM_ERROR", err)
+		return
+	}
+
+	CreatedResponse(c, item)
+}
+
+// PUT /api/inventory/:id
+func (h *InventoryHandler) UpdateItem(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid inventory item ID")
+		return
+	}
+
+	var req InventoryItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Code != nil {
+		updates["code"] = *req.Code
+	}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Category != nil {
+		updates["category"] = *req.Category
+	}
+	if req.Unit != nil {
+		updates["unit"] = *req.Unit
+	}
+	if req.ParLevel != nil {
+		updates["par_level"] = *req.ParLevel
+	}
+	if req.UnitCost != nil {
+		updates["unit_cost"] = *req.UnitCost
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	item, err := h.inventoryService.UpdateItem(uint(id), updates)
+	if err != nil {
+		h.inventoryError(c, "UPDATE_INVENTORY_ITEM_ERROR", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Inventory item updated successfully", item)
+}
+
+// DELETE /api/inventory/:id
+func (h *InventoryHandler) DeleteItem(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid inventory item ID")
+		return
+	}
+
+	if err := h.inventoryService.DeleteItem(uint(id)); err != nil {
+		h.inventoryError(c, "DELETE_INVENTORY_ITEM_ERROR", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Inventory item deleted successfully", nil)
+}
+
+// GET /api/inventory/:id/movements
+func (h *InventoryHandler) GetMovements(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid inventory item ID")
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	movements, err := h.inventoryService.GetMovements(uint(id), limit)
+	if err != nil {
+		h.inventoryError(c, "INVENTORY_ERROR", err)
+		return
+	}
+
+	SuccessResponse(c, movements)
+}
+
+// POST /api/inventory/:id/count
+func (h *InventoryHandler) RecordCount(c *gin.Context) {
+	h.moveStock(c, "RecordCount", "Stock count recorded", h.inventoryService.RecordCount)
+}
+
+// POST /api/inventory/:id/receive
+func (h *InventoryHandler) ReceiveStock(c *gin.Context) {
+	h.moveStock(c, "ReceiveStock", "Stock received", h.inventoryService.ReceiveStock)
+}
+
+// POST /api/inventory/:id/adjust
+func (h *InventoryHandler) AdjustStock(c *gin.Context) {
+	h.moveStock(c, "AdjustStock", "Stock adjusted", h.inventoryService.AdjustStock)
+}
+
+// GET /api/inventory-usage?property_id=
+func (h *InventoryHandler) GetUsage(c *gin.Context) {
+	propertyID := c.Query("property_id")
+	if propertyID == "" {
+		ValidationErrorResponse(c, "property_id is required")
+		return
+	}
+
+	usage, err := h.inventoryService.GetUsage(propertyID)
+	if err != nil {
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, usage)
+}
+
+// PUT /api/inventory-usage
+func (h *InventoryHandler) SetUsage(c *gin.Context) {
+	var req InventoryUsageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	usage := models.InventoryUsage{
+		PropertyID: req.PropertyID,
+		MenuType:   req.MenuType,
+		Code:       req.Code,
+		PerCover:   req.PerCover,
+	}
+	if err := h.inventoryService.SetUsage(&usage); err != nil {
+		h.inventoryError(c, "INVENTORY_USAGE_ERROR", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Inventory usage saved", usage)
+}
+
+// DELETE /api/inventory-usage/:id
+func (h *InventoryHandler) DeleteUsage(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid inventory usage ID")
+		return
+	}
+
+	if err := h.inventoryService.DeleteUsage(uint(id)); err != nil {
+		h.inventoryError(c, "INVENTORY_USAGE_ERROR", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Inventory usage deleted successfully", nil)
+}
+
+// moveStock binds a stock movement request and applies it with the given service method
+func (h *InventoryHandler) moveStock(c *gin.Context, handler, message string, apply func(ctx context.Context, req services.StockRequest) (*models.InventoryItem, error)) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid inventory item ID")
+		return
+	}
+
+	var req StockMovementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	staffID := c.GetUint("user_id")
+	if staffID == 0 {
+		UnauthorizedResponse(c)
+		return
+	}
+
+	item, err := apply(c.Request.Context(), services.StockRequest{
+		ItemID:    uint(id),
+		Quantity:  *req.Quantity,
+		Reason:    req.Reason,
+		StaffID:   staffID,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":           handler,
+			"inventory_item_id": id,
+			"error":             err.Error(),
+		}).Warn("Stock movement refused")
+
+		h.inventoryError(c, "STOCK_ERROR", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, message, item)
+}
+
+// inventoryError maps inventory failures to responses
+func (h *InventoryHandler) inventoryError(c *gin.Context, code string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInventoryItemNotFound):
+		NotFoundResponse(c, "Inventory item")
+	case errors.Is(err, services.ErrInventoryUsageNotFound):
+		NotFoundResponse(c, "Inventory usage")
+	case errors.Is(err, services.ErrOutletNotFound):
+		NotFoundResponse(c, "Outlet")
+	case errors.Is(err, services.ErrDuplicateInventoryCode):
+		ErrorResponse(c, http.StatusConflict, "DUPLICATE_CODE", err.Error())
+	default:
+		ErrorResponse(c, http.StatusBadRequest, code, err.Error())
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KitchenHandler handles kitchen display state and ticket bump and recall actions
+type KitchenHandler struct {
+	kitchenService *services.KitchenDisplayService
+}
+
+// NewKitchenHandler creates a new kitchen display handler
+func NewKitchenHandler(kitchenService *services.KitchenDisplayService) *KitchenHandler {
+	return &KitchenHandler{
+		kitchenService: kitchenService,
+	}
+}
+
+// GET /api/outlets/:id/kitchen?station=
+//
+// Lets a display that has just connected to its websocket room rebuild its
+// current state; later changes arrive over the websocket.
+func (h *KitchenHandler) GetDisplay(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	display, err := h.kitchenService.GetDisplay(uint(outletID), c.Query("station"))
+	if err != nil {
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, display)
+}
+
+// POST /api/tickets/:id/bump
+func (h *KitchenHandler) BumpTicket(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid ticket ID")
+		return
+	}
+
+	ticket, err := h.kitchenService.Bump(uint(id))
+	if err != nil {
+		h.ticketError(c, err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Ticket bumped", ticket)
+}
+
+// POST /api/tickets/:id/recall
+func (h *KitchenHandler) RecallTicket(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid ticket ID")
+		return
+	}
+
+	ticket, err := h.kitchenService.Recall(uint(id))
+	if err != nil {
+		h.ticketError(c, err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Ticket recalled", ticket)
+}
+
+// ticketError maps bump and recall failures to responses
+func (h *KitchenHandler) ticketError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTicketNotFound):
+		NotFoundResponse(c, "Kitchen ticket")
+	case errors.Is(err, services.ErrTicketState):
+		ErrorResponse(c, http.StatusConflict, "TICKET_STATE", err.Error())
+	default:
+		InternalErrorResponse(c, err)
+	}
+}
@@ -0,0 +1,425 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// OrderHandler handles à-la-carte menus, orders and kitchen tickets
+type OrderHandler struct {
+	orderService *services.OrderService
+}
+
+// NewOrderHandler creates a new order handler
+func NewOrderHandler(orderService *services.OrderService) *OrderHandler {
+	return &OrderHandler{
+		orderService: orderService,
+	}
+}
+
+// MenuItemRequest is the payload for creating or updating a menu item.
+// Pointer fields distinguish omitted values from explicit zero values.
+type MenuItemRequest struct {
+	Name         *string  `json:"name"`
+	Description  *string  `json:"description"`
+	Category     *string  `json:"category"`
+	Price        *float64 `json:"price"`
+	Station      *string  `json:"station"`
+	Modifiers    *string  `json:"modifiers"`
+	AllergenTags *string  `json:"allergen_tags"`
+	DietaryTags  *string  `json:"dietary_tags"`
+	IsAvailable  *bool    `json:"is_available"`
+	SortOrder    *int     `json:"sort_order"`
+}
+
+type AddOrderItemsRequest struct {
+	Items                []services.OrderItemRequest `json:"items" binding:"required,dive"`
+	AcknowledgeAllergies bool                        `json:"acknowledge_allergies"` // Staff have warned the guest about allergen conflicts
+}
+
+// GET /api/outlets/:id/menu
+func (h *OrderHandler) GetMenu(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	items, err := h.orderService.GetMenu(uint(outletID), c.Query("available") == "true")
+	if err != nil {
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, items)
+}
+
+// POST /api/outlets/:id/menu
+func (h *OrderHandler) CreateMenuItem(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	var req MenuItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	item := models.MenuItem{OutletID: uint(outletID), IsAvailable: true}
+	if req.Name != nil {
+		item.Name = *req.Name
+	}
+	if req.Description != nil {
+		item.Description = *req.Description
+	}
+	if req.Category != nil {
+		item.Category = *req.Category
+	}
+	if req.Price != nil {
+		item.Price = *req.Price
+	}
+	if req.Station != nil {
+		item.Station = *req.Station
+	}
+	if req.Modifiers != nil {
+		item.Modifiers = *req.Modifiers
+	}
+	if req.AllergenTags != nil {
+		item.AllergenTags = *req.AllergenTags
+	}
+	if req.DietaryTags != nil {
+		item.DietaryTags = *req.DietaryTags
+	}
+	if req.IsAvailable != nil {
+		item.IsAvailable = *req.IsAvailable
+	}
+	if req.SortOrder != nil {
+		item.SortOrder = *req.SortOrder
+	}
+
+	if err := h.orderService.CreateMenuItem(&item); err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":   "CreateMenuItem",
+			"outlet_id": outletID,
+			"error":     err.Error(),
+		}).Error("Failed to create menu item")
+
+		if errors.Is(err, services.ErrOutletNotFound) {
+			NotFoundResponse(c, "Outlet")
+		} else {
+			ErrorResponse(c, http.StatusBadRequest, "CREATE_MENU_ITEM_ERROR", err.Error())
+		}
+		return
+	}
+
+	CreatedResponse(c, item)
+}
+
+// PUT /api/menu-items/:id
+func (h *OrderHandler) UpdateMenuItem(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid menu item ID")
+		return
+	}
+
+	var req MenuItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Category != nil {
+		updates["category"] = *req.Category
+	}
+	if req.Price != nil {
+		updates["price"] = *req.Price
+	}
+	if req.Station != nil {
+		updates["station"] = *req.Station
+	}
+	if req.Modifiers != nil {
+		updates["modifiers"] = *req.Modifiers
+	}
+	if req.AllergenTags != nil {
+		updates["allergen_tags"] = *req.AllergenTags
+	}
+	if req.DietaryTags != nil {
+		updates["dietary_tags"] = *req.DietaryTags
+	}
+	if req.IsAvailable != nil {
+		updates["is_available"] = *req.IsAvailable
+	}
+	if req.SortOrder != nil {
+		updates["sort_order"] = *req.SortOrder
+	}
+
+	item, err := h.orderService.UpdateMenuItem(uint(id), updates)
+	if err != nil {
+		if errors.Is(err, services.ErrMenuItemNotFound) {
+			NotFoundResponse(c, "Menu item")
+		} else {
+			ErrorResponse(c, http.StatusBadRequest, "UPDATE_MENU_ITEM_ERROR", err.Error())
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Menu item updated successfully", item)
+}
+
+// DELETE /api/menu-items/:id
+func (h *OrderHandler) DeleteMenuItem(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid menu item ID")
+		return
+	}
+
+	if err := h.orderService.DeleteMenuItem(uint(id)); err != nil {
+		if errors.Is(err, services.ErrMenuItemNotFound) {
+			NotFoundResponse(c, "Menu item")
+		} else {
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Menu item deleted successfully", gin.H{"menu_item_id": id})
+}
+
+// POST /api/orders
+func (h *OrderHandler) CreateOrder(c *gin.Context) {
+	var req services.OrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	order, err := h.orderService.CreateOrder(req, c.GetUint("user_id"))
+	if err != nil {
+		h.orderError(c, "CreateOrder", err)
+		return
+	}
+
+	CreatedResponse(c, order)
+}
+
+// GET /api/orders?consumption_id=
+func (h *OrderHandler) GetOrders(c *gin.Context) {
+	consumptionID, err := strconv.ParseUint(c.Query("consumption_id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "consumption_id is required")
+		return
+	}
+
+	orders, err := h.orderService.GetOrders(uint(consumptionID))
+	if err != nil {
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, orders)
+}
+
+// GET /api/orders/:id
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid order ID")
+		return
+	}
+
+	order, err := h.orderService.GetOrder(uint(id))
+	if err != nil {
+		h.orderError(c, "GetOrder", err)
+		return
+	}
+
+	SuccessResponse(c, order)
+}
+
+// POST /api/orders/:id/items
+func (h *OrderHandler) AddItems(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid order ID")
+		return
+	}
+
+	var req AddOrderItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	order, err := h.orderService.AddItems(uint(id), req.Items, req.AcknowledgeAllergies, c.GetUint("user_id"))
+	if err != nil {
+		h.orderError(c, "AddItems", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Items added to order", order)
+}
+
+// POST /api/orders/:id/fire
+func (h *OrderHandler) FireOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid order ID")
+		return
+	}
+
+	order, err := h.orderService.FireOrder(uint(id), c.GetUint("user_id"))
+	if err != nil {
+		h.orderError(c, "FireOrder", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Order sent to the kitchen", order)
+}
+
+// POST /api/orders/:id/cancel
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid order ID")
+		return
+	}
+
+	order, err := h.orderService.CancelOrder(uint(id))
+	if err != nil {
+		h.orderError(c, "CancelOrder", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Order cancelled", order)
+}
+
+// POST /api/orders/:id/post-charge
+func (h *OrderHandler) PostOverage(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid order ID")
+		return
+	}
+
+	order, err := h.orderService.PostOverage(c.Request.Context(), uint(id))
+	if err != nil {
+		h.orderError(c, "PostOverage", err)
+		return
+	}
+
+	SuccessResponse(c, order)
+}
+
+// GET /api/outlets/:id/tickets?station=&status=
+func (h *OrderHandler) GetTickets(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	// Open tickets by default; status accepts a comma-separated list
+	statuses := []string{services.TicketStatusFired, services.TicketStatusReady}
+	if value := c.Query("status"); value != "" {
+		statuses = strings.Split(value, ",")
+	}
+
+	tickets, err := h.orderService.GetTickets(uint(outletID), c.Query("station"), statuses)
+	if err != nil {
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, tickets)
+}
+
+// POST /api/tickets/:id/ready
+func (h *OrderHandler) MarkTicketReady(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid ticket ID")
+		return
+	}
+
+	ticket, err := h.orderService.MarkTicketReady(uint(id))
+	if err != nil {
+		h.orderError(c, "MarkTicketReady", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Ticket ready", ticket)
+}
+
+// POST /api/tickets/:id/served
+func (h *OrderHandler) MarkTicketServed(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid ticket ID")
+		return
+	}
+
+	ticket, err := h.orderService.MarkTicketServed(c.Request.Context(), uint(id))
+	if err != nil {
+		h.orderError(c, "MarkTicketServed", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Ticket served", ticket)
+}
+
+// orderError maps ordering failures to responses
+func (h *OrderHandler) orderError(c *gin.Context, handler string, err error) {
+	logging.WithFields(logrus.Fields{
+		"handler": handler,
+		"error":   err.Error(),
+	}).Warn("Order request refused")
+
+	var conflictErr *services.AllergenConflictError
+	switch {
+	case errors.As(err, &conflictErr):
+		allergenConflictResponse(c, conflictErr)
+	case errors.Is(err, services.ErrOrderNotFound):
+		NotFoundResponse(c, "Order")
+	case errors.Is(err, services.ErrTicketNotFound):
+		NotFoundResponse(c, "Kitchen ticket")
+	case errors.Is(err, services.ErrMenuItemNotFound):
+		NotFoundResponse(c, "Menu item")
+	case errors.Is(err, services.ErrConsumptionNotFound):
+		NotFoundResponse(c, "Consumption")
+	case errors.Is(err, services.ErrOutletNotFound):
+		NotFoundResponse(c, "Outlet")
+	case errors.Is(err, services.ErrOrderClosed),
+		errors.Is(err, services.ErrOrderAlreadyFired),
+		errors.Is(err, services.ErrNothingToFire),
+		errors.Is(err, services.ErrTicketState),
+		errors.Is(err, services.ErrVisitNotActive):
+		ErrorResponse(c, http.StatusConflict, "ORDER_STATE", err.Error())
+	case errors.Is(err, services.ErrDayClosed):
+		ErrorResponse(c, http.StatusConflict, "DAY_CLOSED", err.Error())
+	case errors.Is(err, services.ErrNoChargePoster):
+		ErrorResponse(c, http.StatusServiceUnavailable, "PMS_UNAVAILABLE", err.Error())
+	default:
+		ErrorResponse(c, http.StatusBadRequest, "ORDER_ERROR", err.Error())
+	}
+}
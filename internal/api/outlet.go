@@ -0,0 +1,257 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// OutletHandler handles outlet management endpoints
+type OutletHandler struct {
+	outletService *services.OutletService
+}
+
+// NewOutletHandler creates a new outlet handler
+func NewOutletHandler(outletService *services.OutletService) *OutletHandler {
+	return &OutletHandler{
+		outletService: outletService,
+	}
+}
+
+// OutletRequest is the payload for creating or updating an outlet.
+// Pointer fields distinguish omitted values from explicit zero values.
+type OutletRequest struct {
+	PropertyID       string   `json:"property_id"`
+	Name             *string  `json:"name"`
+	Location         *string  `json:"location"`
+	AcceptsPackage   *bool    `json:"accepts_breakfast_package"`
+	OpenTime         *string  `json:"open_time"`
+	CloseTime        *string  `json:"close_time"`
+	Capacity         *int     `json:"capacity"`
+	MenuType         *string  `json:"menu_type"`
+	PackageAllowance *float64 `json:"package_allowance"` // À-la-carte value covered per package cover
+	ManagerID        *uint    `json:"manager_id"`        // Staff member sent the next day's plan; 0 clears it
+	IsActive         *bool    `json:"is_active"`
+}
+
+var validMenuTypes = map[string]bool{
+	"buffet":      true,
+	"a_la_carte":  true,
+	"continental": true,
+}
+
+// GET /api/outlets
+func (h *OutletHandler) GetOutlets(c *gin.Context) {
+	propertyID := c.Query("property_id")
+	if propertyID == "" {
+		ValidationErrorResponse(c, "property_id is required")
+		return
+	}
+
+	outlets, err := h.outletService.GetOutlets(propertyID)
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":     "GetOutlets",
+			"property_id": propertyID,
+			"error":       err.Error(),
+		}).Error("Failed to get outlets")
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, gin.H{"outlets": outlets})
+}
+
+// GET /api/outlets/:id
+func (h *OutletHandler) GetOutlet(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	outlet, err := h.outletService.GetOutlet(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrOutletNotFound) {
+			NotFoundResponse(c, "Outlet")
+		} else {
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponse(c, outlet)
+}
+
+// POST /api/outlets
+func (h *OutletHandler) CreateOutlet(c *gin.Context) {
+	var req OutletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+	if req.MenuType != nil && !validMenuTypes[*req.MenuType] {
+		ValidationErrorResponse(c, "menu_type must be buffet, a_la_carte or continental")
+		return
+	}
+	if req.PackageAllowance != nil && *req.PackageAllowance < 0 {
+		ValidationErrorResponse(c, "package_allowance cannot be negative")
+		return
+	}
+
+	outlet := models.Outlet{
+		PropertyID:     req.PropertyID,
+		AcceptsPackage: true,
+		IsActive:       true,
+	}
+	if req.Name != nil {
+		outlet.Name = *req.Name
+	}
+	if req.Location != nil {
+		outlet.Location = *req.Location
+	}
+	if req.AcceptsPackage != nil {
+		outlet.AcceptsPackage = *req.AcceptsPackage
+	}
+	if req.OpenTime != nil {
+		outlet.OpenTime = *req.OpenTime
+	}
+	if req.CloseTime != nil {
+		outlet.CloseTime = *req.CloseTime
+	}
+	if req.Capacity != nil {
+		outlet.Capacity = *req.Capacity
+	}
+	if req.MenuType != nil {
+		outlet.MenuType = *req.MenuType
+	}
+	if req.PackageAllowance != nil {
+		outlet.PackageAllowance = req.PackageAllowance
+	}
+	if req.ManagerID != nil && *req.ManagerID != 0 {
+		outlet.ManagerID = req.ManagerID
+	}
+	if req.IsActive != nil {
+		outlet.IsActive = *req.IsActive
+	}
+
+	if err := h.outletService.CreateOutlet(&outlet); err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":     "CreateOutlet",
+			"property_id": req.PropertyID,
+			"error":       err.Error(),
+		}).Error("Failed to create outlet")
+		ErrorResponse(c, http.StatusBadRequest, "CREATE_OUTLET_ERROR", err.Error())
+		return
+	}
+
+	CreatedResponse(c, outlet)
+}
+
+// PUT /api/outlets/:id
+func (h *OutletHandler) UpdateOutlet(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	var req OutletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+	if req.MenuType != nil && !validMenuTypes[*req.MenuType] {
+		ValidationErrorResponse(c, "menu_type must be buffet, a_la_carte or continental")
+		return
+	}
+	if req.PackageAllowance != nil && *req.PackageAllowance < 0 {
+		ValidationErrorResponse(c, "package_allowance cannot be negative")
+		return
+	}
+	if req.Name != nil && *req.Name == "" {
+		ValidationErrorResponse(c, "name cannot be empty")
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Location != nil {
+		updates["location"] = *req.Location
+	}
+	if req.AcceptsPackage != nil {
+		updates["accepts_package"] = *req.AcceptsPackage
+	}
+	if req.OpenTime != nil {
+		updates["open_time"] = *req.OpenTime
+	}
+	if req.CloseTime != nil {
+		updates["close_time"] = *req.CloseTime
+	}
+	if req.Capacity != nil {
+		updates["capacity"] = *req.Capacity
+	}
+	if req.MenuType != nil {
+		updates["menu_type"] = *req.MenuType
+	}
+	if req.PackageAllowance != nil {
+		updates["package_allowance"] = *req.PackageAllowance
+	}
+	if req.ManagerID != nil {
+		if *req.ManagerID == 0 {
+			updates["manager_id"] = nil
+		} else {
+			updates["manager_id"] = *req.ManagerID
+		}
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	outlet, err := h.outletService.UpdateOutlet(uint(id), updates)
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":   "UpdateOutlet",
+			"outlet_id": id,
+			"error":     err.Error(),
+		}).Error("Failed to update outlet")
+
+		if errors.Is(err, services.ErrOutletNotFound) {
+			NotFoundResponse(c, "Outlet")
+		} else {
+			ErrorResponse(c, http.StatusBadRequest, "UPDATE_OUTLET_ERROR", err.Error())
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Outlet updated successfully", outlet)
+}
+
+// DELETE /api/outlets/:id
+func (h *OutletHandler) DeleteOutlet(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	if err := h.outletService.DeleteOutlet(uint(id)); err != nil {
+		if errors.Is(err, services.ErrOutletNotFound) {
+			NotFoundResponse(c, "Outlet")
+		} else {
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Outlet deleted successfully", gin.H{"outlet_id": id})
+}
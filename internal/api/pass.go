@@ -0,0 +1,234 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PassHandler handles signed QR breakfast passes
+type PassHandler struct {
+	passService *services.PassService
+}
+
+// NewPassHandler creates a new breakfast pass handler
+func NewPassHandler(passService *services.PassService) *PassHandler {
+	return &PassHandler{
+		passService: passService,
+	}
+}
+
+type IssuePassesRequest struct {
+	PerCover bool `json:"per_cover"` // Issue one pass per cover instead of one for the room
+}
+
+type SendPassesRequest struct {
+	Channel string `json:"channel" binding:"required,oneof=email sms"`
+}
+
+type RedeemPassRequest struct {
+	Token    string `json:"token" binding:"required"`
+	OutletID uint   `json:"outlet_id"`
+	services.CoverRequest
+}
+
+// POST /api/guests/:id/passes
+func (h *PassHandler) IssuePasses(c *gin.Context) {
+	guestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid guest ID")
+		return
+	}
+
+	var req IssuePassesRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			ValidationErrorResponse(c, err.Error())
+			return
+		}
+	}
+
+	passes, err := h.passService.IssuePasses(uint(guestID), req.PerCover, c.GetUint("user_id"))
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":   "IssuePasses",
+			"guest_id":  guestID,
+			"per_cover": req.PerCover,
+			"error":     err.Error(),
+		}).Error("Failed to issue breakfast passes")
+
+		switch {
+		case errors.Is(err, services.ErrPassGuestNotFound):
+			NotFoundResponse(c, "Guest")
+		case errors.Is(err, services.ErrPassExpired):
+			ErrorResponse(c, http.StatusBadRequest, "STAY_ENDED", "guest's stay has already ended")
+		default:
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	CreatedResponse(c, passes)
+}
+
+// GET /api/guests/:id/passes
+func (h *PassHandler) GetPasses(c *gin.Context) {
+	guestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid guest ID")
+		return
+	}
+
+	passes, err := h.passService.GetPasses(uint(guestID), c.Query("active") == "true")
+	if err != nil {
+		if errors.Is(err, services.ErrPassGuestNotFound) {
+			NotFoundResponse(c, "Guest")
+		} else {
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponse(c, passes)
+}
+
+// POST /api/guests/:id/passes/send
+func (h *PassHandler) SendPasses(c *gin.Context) {
+	guestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid guest ID")
+		return
+	}
+
+	var req SendPassesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	passes, err := h.passService.SendPasses(c.Request.Context(), uint(guestID), req.Channel)
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":  "SendPasses",
+			"guest_id": guestID,
+			"channel":  req.Channel,
+			"error":    err.Error(),
+		}).Error("Failed to send breakfast passes")
+
+		switch {
+		case errors.Is(err, services.ErrPassGuestNotFound):
+			NotFoundResponse(c, "Guest")
+		case errors.Is(err, services.ErrPassNoneActive),
+			errors.Is(err, services.ErrPassNoContact),
+			errors.Is(err, services.ErrPassChannel):
+			ErrorResponse(c, http.StatusBadRequest, "SEND_PASS_ERROR", err.Error())
+		default:
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Breakfast passes sent by "+req.Channel, passes)
+}
+
+// POST /api/passes/:id/revoke
+func (h *PassHandler) RevokePass(c *gin.Context) {
+	passID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid pass ID")
+		return
+	}
+
+	pass, err := h.passService.RevokePass(uint(passID), c.GetUint("user_id"))
+	if err != nil {
+		if errors.Is(err, services.ErrPassNotFound) {
+			NotFoundResponse(c, "Breakfast pass")
+		} else {
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Breakfast pass revoked", pass)
+}
+
+// POST /api/passes/redeem
+func (h *PassHandler) RedeemPass(c *gin.Context) {
+	var req RedeemPassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	staffID := c.GetUint("user_id")
+	if staffID == 0 {
+		UnauthorizedResponse(c)
+		return
+	}
+
+	redemption, err := h.passService.Redeem(c.Request.Context(), services.RedeemPassRequest{
+		Token:    req.Token,
+		StaffID:  staffID,
+		OutletID: req.OutletID,
+		Covers:   req.CoverRequest,
+	})
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":   "RedeemPass",
+			"staff_id":  staffID,
+			"outlet_id": req.OutletID,
+			"error":     err.Error(),
+		}).Warn("Breakfast pass refused")
+
+		switch {
+		case errors.Is(err, services.ErrPassInvalid):
+			ErrorResponse(c, http.StatusForbidden, "PASS_INVALID", err.Error())
+		case errors.Is(err, services.ErrPassRevoked):
+			ErrorResponse(c, http.StatusForbidden, "PASS_REVOKED", err.Error())
+		case errors.Is(err, services.ErrPassExpired), errors.Is(err, services.ErrPassNotYetValid):
+			ErrorResponse(c, http.StatusForbidden, "PASS_EXPIRED", err.Error())
+		case errors.Is(err, services.ErrNotEligible):
+			ErrorResponse(c, http.StatusForbidden, "NOT_ELIGIBLE", err.Error())
+		case errors.Is(err, services.ErrPassUsedToday),
+			errors.Is(err, services.ErrBreakfastConsumed),
+			errors.Is(err, services.ErrCoversExceeded):
+			ErrorResponse(c, http.StatusConflict, "ALREADY_CONSUMED", err.Error())
+		case errors.Is(err, services.ErrDayClosed):
+			ErrorResponse(c, http.StatusConflict, "DAY_CLOSED", err.Error())
+		case errors.Is(err, services.ErrOutletNotFound), errors.Is(err, services.ErrOutletClosed),
+			errors.Is(err, services.ErrOutletRejectsPackage), errors.Is(err, services.ErrOutletPropertyMismatch):
+			ErrorResponse(c, http.StatusBadRequest, "OUTLET_UNAVAILABLE", err.Error())
+		default:
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Breakfast pass redeemed", redemption)
+}
+
+// GET /api/passes/qr.png
+//
+// Public so that links in pass emails and texts open without a login; the
+// signed token is the credential and revoked passes are not rendered.
+func (h *PassHandler) GetPassImage(c *gin.Context) {
+	image, err := h.passService.PassImage(c.Query("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPassInvalid), errors.Is(err, services.ErrPassRevoked):
+			NotFoundResponse(c, "Breakfast pass")
+		default:
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "image/png", image)
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PlanningHandler handles staffing and prep plans built from cover forecasts
+type PlanningHandler struct {
+	planningService *services.PlanningService
+}
+
+// NewPlanningHandler creates a new planning handler
+func NewPlanningHandler(planningService *services.PlanningService) *PlanningHandler {
+	return &PlanningHandler{
+		planningService: planningService,
+	}
+}
+
+type StaffingRatioRequest struct {
+	FOHCoversPerHour     float64 `json:"foh_covers_per_hour" binding:"required"`
+	KitchenCoversPerHour float64 `json:"kitchen_covers_per_hour" binding:"required"`
+	MinFOH               int     `json:"min_foh"`
+	MinKitchen           int     `json:"min_kitchen"`
+	ShiftMinutes         int     `json:"shift_minutes"`
+	PrepBuffer           float64 `json:"prep_buffer"`
+}
+
+// GET /api/outlets/:id/plan?date=YYYY-MM-DD&format=json|csv
+func (h *PlanningHandler) GetPlan(c *gin.Context) {
+	outletID, date, ok := h.planParams(c)
+	if !ok {
+		return
+	}
+
+	plan, err := h.planningService.Plan(c.Request.Context(), outletID, date)
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":   "GetPlan",
+			"outlet_id": outletID,
+			"error":     err.Error(),
+		}).Warn("Failed to build staffing plan")
+
+		h.planningError(c, err)
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "csv":
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="plan-%d-%s.csv"`, plan.OutletID, plan.Date))
+		c.Status(http.StatusOK)
+		if err := services.WritePlanCSV(c.Writer, plan); err != nil {
+			logging.WithError(err).Error("Failed to write staffing plan CSV")
+		}
+	case "json":
+		SuccessResponse(c, plan)
+	default:
+		ValidationErrorResponse(c, "format must be json or csv")
+	}
+}
+
+// POST /api/outlets/:id/plan/email?date=YYYY-MM-DD
+func (h *PlanningHandler) EmailPlan(c *gin.Context) {
+	outletID, date, ok := h.planParams(c)
+	if !ok {
+		return
+	}
+
+	record, err := h.planningService.EmailPlan(c.Request.Context(), outletID, date)
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":   "EmailPlan",
+			"outlet_id": outletID,
+			"error":     err.Error(),
+		}).Warn("Failed to email staffing plan")
+
+		h.planningError(c, err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Plan emailed to "+record.SentTo, record)
+}
+
+// GET /api/outlets/:id/staffing-ratios
+func (h *PlanningHandler) GetRatios(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	ratio, err := h.planningService.GetRatios(uint(outletID))
+	if err != nil {
+		h.planningError(c, err)
+		return
+	}
+
+	SuccessResponse(c, ratio)
+}
+
+// PUT /api/outlets/:id/staffing-ratios
+func (h *PlanningHandler) SetRatios(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	var req StaffingRatioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	ratio, err := h.planningService.SetRatios(uint(outletID), &models.StaffingRatio{
+		FOHCoversPerHour:     req.FOHCoversPerHour,
+		KitchenCoversPerHour: req.KitchenCoversPerHour,
+		MinFOH:               req.MinFOH,
+		MinKitchen:           req.MinKitchen,
+		ShiftMinutes:         req.ShiftMinutes,
+		PrepBuffer:           req.PrepBuffer,
+	})
+	if err != nil {
+		h.planningError(c, err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Staffing ratios saved", ratio)
+}
+
+// planParams parses the outlet ID and the plan date, which defaults to tomorrow
+func (h *PlanningHandler) planParams(c *gin.Context) (uint, time.Time, bool) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return 0, time.Time{}, false
+	}
+
+	date := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if value := c.Query("date"); value != "" {
+		date, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			ValidationErrorResponse(c, "date must be in YYYY-MM-DD format")
+			return 0, time.Time{}, false
+		}
+	}
+	return uint(outletID), date, true
+}
+
+// planningError maps planning failures to responses
+func (h *PlanningHandler) planningError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrOutletNotFound):
+		NotFoundResponse(c, "Outlet")
+	case errors.Is(err, services.ErrPlanDateOutOfRange),
+		errors.Is(err, services.ErrOutletNotForecast),
+		errors.Is(err, services.ErrInvalidRatio):
+		ValidationErrorResponse(c, err.Error())
+	case errors.Is(err, services.ErrNoOutletManager):
+		ErrorResponse(c, http.StatusConflict, "NO_OUTLET_MANAGER", err.Error())
+	case errors.Is(err, services.ErrEmailNotConfigured):
+		ErrorResponse(c, http.StatusServiceUnavailable, "EMAIL_UNAVAILABLE", err.Error())
+	default:
+		InternalErrorResponse(c, err)
+	}
+}
@@ -248,6 +248,7 @@ func (h *PMSIntegrationHandler) PostBreakfastCharge(c *gin.Context) {
 		GuestID    string  `json:"guest_id" binding:"required"`
 		RoomNumber string  `json:"room_number" binding:"required"`
 		Amount     float64 `json:"amount" binding:"required"`
+		TaxAmount  float64 `json:"tax_amount"`
 	}
 	
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -261,7 +262,7 @@ func (h *PMSIntegrationHandler) PostBreakfastCharge(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	
-	if err := h.pmsService.PostBreakfastCharge(ctx, request.GuestID, request.RoomNumber, request.Amount); err != nil {
+	if err := h.pmsService.PostBreakfastCharge(ctx, request.GuestID, request.RoomNumber, request.Amount, request.TaxAmount); err != nil {
 		logging.Error("Failed to post breakfast charge:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to post charge",
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PortfolioHandler handles KPIs compared across a set of properties
+type PortfolioHandler struct {
+	portfolioService *services.PortfolioService
+}
+
+// NewPortfolioHandler creates a new portfolio handler
+func NewPortfolioHandler(portfolioService *services.PortfolioService) *PortfolioHandler {
+	return &PortfolioHandler{
+		portfolioService: portfolioService,
+	}
+}
+
+// PropertyGroupRequest is the payload for creating or updating a property group.
+// Omitted fields are unchanged on update; property_ids replaces the members.
+type PropertyGroupRequest struct {
+	Name        *string  `json:"name"`
+	Description *string  `json:"description"`
+	PropertyIDs []string `json:"property_ids"`
+}
+
+// GET /api/portfolio/kpis?region=&brand=&group_id=&property_ids=A,B&from=YYYY-MM-DD&to=YYYY-MM-DD&rank_by=revenue
+func (h *PortfolioHandler) GetKPIs(c *gin.Context) {
+	selection, from, to, ok := h.portfolioParams(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.portfolioService.Report(selection, from, to, c.Query("rank_by"))
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler": "GetPortfolioKPIs",
+			"error":   err.Error(),
+		}).Warn("Failed to build portfolio report")
+
+		h.portfolioError(c, "PORTFOLIO_ERROR", err)
+		return
+	}
+
+	SuccessResponse(c, report)
+}
+
+// GET /api/portfolio/properties/:property_id?region=&brand=&group_id=&property_ids=&from=&to=&rank_by=
+func (h *PortfolioHandler) GetProperty(c *gin.Context) {
+	selection, from, to, ok := h.portfolioParams(c)
+	if !ok {
+		return
+	}
+	propertyID := c.Param("property_id")
+
+	drilldown, err := h.portfolioService.Drilldown(selection, propertyID, from, to, c.Query("rank_by"))
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":     "GetPortfolioProperty",
+			"property_id": propertyID,
+			"error":       err.Error(),
+		}).Warn("Failed to drill down into portfolio property")
+
+		h.portfolioError(c, "PORTFOLIO_ERROR", err)
+		return
+	}
+
+	SuccessResponse(c, drilldown)
+}
+
+// GET /api/portfolio/groups
+func (h *PortfolioHandler) GetGroups(c *gin.Context) {
+	groups, err := h.portfolioService.GetGroups()
+	if err != nil {
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, groups)
+}
+
+// GET /api/portfolio/groups/:id
+func (h *PortfolioHandler) GetGroup(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid group ID")
+		return
+	}
+
+	group, err := h.portfolioService.GetGroup(uint(id))
+	if err != nil {
+		h.portfolioError(c, "PROPERTY_GROUP_ERROR", err)
+		return
+	}
+
+	SuccessResponse(c, group)
+}
+
+// POST /api/portfolio/groups
+func (h *PortfolioHandler) CreateGroup(c *gin.Context) {
+	var req PropertyGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	group := models.PropertyGroup{CreatedBy: c.GetUint("user_id")}
+	if req.Name != nil {
+		group.Name = *req.Name
+	}
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+
+	if err := h.portfolioService.CreateGroup(&group, req.PropertyIDs); err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler": "CreatePropertyGroup",
+			"error":   err.Error(),
+		}).Warn("Failed to create property group")
+
+		h.portfolioError(c, "CREATE_PROPERTY_GROUP_ERROR", err)
+		return
+	}
+
+	CreatedResponse(c, group)
+}
+
+// PUT /api/portfolio/groups/:id
+func (h *PortfolioHandler) UpdateGroup(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid group ID")
+		return
+	}
+
+	var req PropertyGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	group, err := h.portfolioService.UpdateGroup(uint(id), req.Name, req.Description, req.PropertyIDs)
+	if err != nil {
+		h.portfolioError(c, "UPDATE_PROPERTY_GROUP_ERROR", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Property group updated successfully", group)
+}
+
+// DELETE /api/portfolio/groups/:id
+func (h *PortfolioHandler) DeleteGroup(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid group ID")
+		return
+	}
+
+	if err := h.portfolioService.DeleteGroup(uint(id)); err != nil {
+		h.portfolioError(c, "DELETE_PROPERTY_GROUP_ERROR", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Property group deleted successfully", nil)
+}
+
+// portfolioParams reads the portfolio selection and date range. The range
+// defaults to the seven days before today.
+func (h *PortfolioHandler) portfolioParams(c *gin.Context) (services.PortfolioSelection, time.Time, time.Time, bool) {
+	selection := services.PortfolioSelection{
+		Region: c.Query("region"),
+		Brand:  c.Query("brand"),
+	}
+	if value := c.Query("group_id"); value != "" {
+		groupID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			ValidationErrorResponse(c, "Invalid group ID")
+			return selection, time.Time{}, time.Time{}, false
+		}
+		selection.GroupID = uint(groupID)
+	}
+	for _, propertyID := range strings.Split(c.Query("property_ids"), ",") {
+		if propertyID = strings.TrimSpace(propertyID); propertyID != "" {
+			selection.PropertyIDs = append(selection.PropertyIDs, propertyID)
+		}
+	}
+
+	to := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			ValidationErrorResponse(c, "Invalid to date format. Use YYYY-MM-DD")
+			return selection, time.Time{}, time.Time{}, false
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -6)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			ValidationErrorResponse(c, "Invalid from date format. Use YYYY-MM-DD")
+			return selection, time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+	return selection, from, to, true
+}
+
+// portfolioError maps portfolio failures to responses
+func (h *PortfolioHandler) portfolioError(c *gin.Context, code string, err error) {
+	switch {
+	case errors.Is(err, services.ErrPropertyGroupNotFound):
+		NotFoundResponse(c, "Property group")
+	case errors.Is(err, services.ErrNotInPortfolio), errors.Is(err, services.ErrEmptyPortfolio),
+		errors.Is(err, services.ErrPropertyNotFound):
+		ErrorResponse(c, http.StatusNotFound, "NOT_FOUND", err.Error())
+	case errors.Is(err, services.ErrUnknownPortfolioMetric), errors.Is(err, services.ErrInvalidPortfolioRange),
+		errors.Is(err, services.ErrGroupNameRequired):
+		ValidationErrorResponse(c, err.Error())
+	case errors.Is(err, services.ErrDuplicateGroupName):
+		ErrorResponse(c, http.StatusConflict, "DUPLICATE_NAME", err.Error())
+	default:
+		ErrorResponse(c, http.StatusBadRequest, code, err.Error())
+	}
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PriceBookHandler handles breakfast price book endpoints
+type PriceBookHandler struct {
+	priceBookService *services.PriceBookService
+}
+
+// NewPriceBookHandler creates a new price book handler
+func NewPriceBookHandler(priceBookService *services.PriceBookService) *PriceBookHandler {
+	return &PriceBookHandler{
+		priceBookService: priceBookService,
+	}
+}
+
+// GET /api/price-book
+func (h *PriceBookHandler) GetPrices(c *gin.Context) {
+	propertyID := c.Query("property_id")
+	if propertyID == "" {
+		ValidationErrorResponse(c, "property_id is required")
+		return
+	}
+
+	prices, err := h.priceBookService.GetPrices(propertyID)
+	if err != nil {
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, gin.H{"prices": prices})
+}
+
+// GET /api/price-book/quote
+func (h *PriceBookHandler) QuoteVisit(c *gin.Context) {
+	propertyID := c.Query("property_id")
+	if propertyID == "" {
+		ValidationErrorResponse(c, "property_id is required")
+		return
+	}
+
+	adults, err := strconv.Atoi(c.DefaultQuery("adults", "1"))
+	if err != nil || adults < 0 {
+		ValidationErrorResponse(c, "adults must be a non-negative number")
+		return
+	}
+	children, err := strconv.Atoi(c.DefaultQuery("children", "0"))
+	if err != nil || children < 0 {
+		ValidationErrorResponse(c, "children must be a non-negative number")
+		return
+	}
+
+	var outletID *uint
+	if value := c.Query("outlet_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			ValidationErrorResponse(c, "Invalid outlet ID")
+			return
+		}
+		outlet := uint(id)
+		outletID = &outlet
+	}
+
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		at, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			ValidationErrorResponse(c, "at must be an RFC3339 timestamp")
+			return
+		}
+	}
+
+	quote, err := h.priceBookService.QuoteVisit(propertyID, outletID, c.Query("room_type"), adults, children, at)
+	if err != nil {
+		if errors.Is(err, services.ErrNoPriceConfigured) {
+			ErrorResponse(c, http.StatusUnprocessableEntity, "NO_PRICE_CONFIGURED", err.Error())
+			return
+		}
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, quote)
+}
+
+// POST /api/price-book
+func (h *PriceBookHandler) CreatePrice(c *gin.Context) {
+	var price models.BreakfastPrice
+	if err := c.ShouldBindJSON(&price); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	if err := h.priceBookService.CreatePrice(&price); err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":     "CreatePrice",
+			"property_id": price.PropertyID,
+			"error":       err.Error(),
+		}).Error("Failed to create price book entry")
+		ErrorResponse(c, http.StatusBadRequest, "CREATE_PRICE_ERROR", err.Error())
+		return
+	}
+
+	CreatedResponse(c, price)
+}
+
+// PUT /api/price-book/:id
+func (h *PriceBookHandler) UpdatePrice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid price ID")
+		return
+	}
+
+	var price models.BreakfastPrice
+	if err := c.ShouldBindJSON(&price); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	updated, err := h.priceBookService.UpdatePrice(uint(id), &price)
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":  "UpdatePrice",
+			"price_id": id,
+			"error":    err.Error(),
+		}).Error("Failed to update price book entry")
+
+		if errors.Is(err, services.ErrPriceNotFound) {
+			NotFoundResponse(c, "Price")
+		} else {
+			ErrorResponse(c, http.StatusBadRequest, "UPDATE_PRICE_ERROR", err.Error())
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Price updated successfully", updated)
+}
+
+// DELETE /api/price-book/:id
+func (h *PriceBookHandler) DeletePrice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid price ID")
+		return
+	}
+
+	if err := h.priceBookService.DeletePrice(uint(id)); err != nil {
+		if errors.Is(err, services.ErrPriceNotFound) {
+			NotFoundResponse(c, "Price")
+		} else {
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Price deleted successfully", gin.H{"price_id": id})
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PropertyHandler handles property settings endpoints
+type PropertyHandler struct {
+	propertyService *services.PropertyService
+}
+
+// NewPropertyHandler creates a new property handler
+func NewPropertyHandler(propertyService *services.PropertyService) *PropertyHandler {
+	return &PropertyHandler{
+		propertyService: propertyService,
+	}
+}
+
+type BusinessDayRequest struct {
+	TimeZone    string `json:"time_zone" binding:"required"`
+	CutoverHour int    `json:"cutover_hour"`
+}
+
+// PropertyTagsRequest sets a property's portfolio tags; omitted tags are unchanged
+type PropertyTagsRequest struct {
+	Region *string `json:"region"`
+	Brand  *string `json:"brand"`
+}
+
+// GET /api/properties/:property_id
+func (h *PropertyHandler) GetProperty(c *gin.Context) {
+	propertyID := c.Param("property_id")
+
+	property, err := h.propertyService.GetProperty(propertyID)
+	if err != nil {
+		if errors.Is(err, services.ErrPropertyNotFound) {
+			NotFoundResponse(c, "Property")
+		} else {
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	businessDate, err := h.propertyService.BusinessDate(propertyID)
+	if err != nil {
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, gin.H{
+		"property":      property,
+		"business_date": businessDate.Format("2006-01-02"),
+	})
+}
+
+// PUT /api/properties/:property_id/business-day
+func (h *PropertyHandler) UpdateBusinessDay(c *gin.Context) {
+	propertyID := c.Param("property_id")
+
+	var req BusinessDayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	property, err := h.propertyService.UpdateBusinessDay(propertyID, req.TimeZone, req.CutoverHour)
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":     "UpdateBusinessDay",
+			"property_id": propertyID,
+			"error":       err.Error(),
+		}).Warn("Failed to update business day settings")
+
+		if errors.Is(err, services.ErrPropertyNotFound) {
+			NotFoundResponse(c, "Property")
+		} else {
+			ErrorResponse(c, http.StatusBadRequest, "UPDATE_PROPERTY_ERROR", err.Error())
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Business day settings updated", property)
+}
+
+// PUT /api/properties/:property_id/tags
+func (h *PropertyHandler) UpdateTags(c *gin.Context) {
+	propertyID := c.Param("property_id")
+
+	var req PropertyTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	property, err := h.propertyService.UpdateTags(propertyID, req.Region, req.Brand)
+	if err != nil {
+		if errors.Is(err, services.ErrPropertyNotFound) {
+			NotFoundResponse(c, "Property")
+		} else {
+			ErrorResponse(c, http.StatusBadRequest, "UPDATE_PROPERTY_ERROR", err.Error())
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Portfolio tags updated", property)
+}
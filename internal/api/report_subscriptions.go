@@ -0,0 +1,312 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"hudini-breakfast-module/internal/export"
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ReportSubscriptionHandler handles scheduled report emails and their delivery history
+type ReportSubscriptionHandler struct {
+	subscriptionService *services.ReportSubscriptionService
+}
+
+// NewReportSubscriptionHandler creates a new report subscription handler
+func NewReportSubscriptionHandler(subscriptionService *services.ReportSubscriptionService) *ReportSubscriptionHandler {
+	return &ReportSubscriptionHandler{
+		subscriptionService: subscriptionService,
+	}
+}
+
+// ReportSubscriptionRequest is the payload for creating or updating a subscription.
+// Pointer fields distinguish omitted values from explicit zero values. Only
+// admins may subscribe other staff or other properties.
+type ReportSubscriptionRequest struct {
+	StaffID    *uint   `json:"staff_id"`
+	PropertyID *string `json:"property_id"`
+	Report     *string `json:"report"`
+	Format     *string `json:"format"`
+	Frequency  *string `json:"frequency"`
+	SendTime   *string `json:"send_time"`
+	Weekday    *int    `json:"weekday"`
+	IsActive   *bool   `json:"is_active"`
+}
+
+// GET /api/report-subscriptions?staff_id=&property_id=
+func (h *ReportSubscriptionHandler) GetSubscriptions(c *gin.Context) {
+	filter := services.SubscriptionFilter{PropertyID: c.Query("property_id")}
+	if c.GetString("user_role") == "admin" {
+		if staffID, err := strconv.ParseUint(c.Query("staff_id"), 10, 32); err == nil {
+			filter.StaffID = uint(staffID)
+		}
+	} else {
+		filter.StaffID = c.GetUint("user_id")
+	}
+
+	subscriptions, err := h.subscriptionService.GetSubscriptions(filter)
+	if err != nil {
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, subscriptions)
+}
+
+// GET /api/report-subscriptions/:id
+func (h *ReportSubscriptionHandler) GetSubscription(c *gin.Context) {
+	subscription, ok := h.subscription(c)
+	if !ok {
+		return
+	}
+
+	SuccessResponse(c, subscription)
+}
+
+// POST /api/report-subscriptions
+func (h *ReportSubscriptionHandler) CreateSubscription(c *gin.Context) {
+	var req ReportSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	subscription := models.ReportSubscription{
+		StaffID:    c.GetUint("user_id"),
+		PropertyID: c.GetString("property_id"),
+		Format:     string(export.PDF),
+		IsActive:   true,
+	}
+	if req.StaffID != nil {
+		subscription.StaffID = *req.StaffID
+	}
+	if req.PropertyID != nil {
+		subscription.PropertyID = *req.PropertyID
+	}
+	if !h.canManage(c, subscription.StaffID, subscription.PropertyID) {
+		ForbiddenResponse(c)
+		return
+	}
+	if req.Report != nil {
+		subscription.Report = *req.Report
+	}
+	if req.Format != nil {
+		subscription.Format = *req.Format
+	}
+	if req.Frequency != nil {
+		subscription.Frequency = *req.Frequency
+	}
+	if req.SendTime != nil {
+		subscription.SendTime = *req.SendTime
+	}
+	if req.Weekday != nil {
+		subscription.Weekday = *req.Weekday
+	}
+	if req.IsActive != nil {
+		subscription.IsActive = *req.IsActive
+	}
+
+	if err := h.subscriptionService.CreateSubscription(&subscription); err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":  "CreateReportSubscription",
+			"staff_id": subscription.StaffID,
+			"error":    err.Error(),
+		}).Warn("Failed to create report subscription")
+
+		h.subscriptionError(c, "CREATE_SUBSCRIPTION_ERROR", err)
+		return
+	}
+
+	CreatedResponse(c, subscription)
+}
+
+// PUT /api/report-subscriptions/:id
+func (h *ReportSubscriptionHandler) UpdateSubscription(c *gin.Context) {
+	subscription, ok := h.subscription(c)
+	if !ok {
+		return
+	}
+
+	var req ReportSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	staffID, propertyID := subscription.StaffID, subscription.PropertyID
+	updates := map[string]interface{}{}
+	if req.StaffID != nil {
+		staffID = *req.StaffID
+		updates["staff_id"] = staffID
+	}
+	if req.PropertyID != nil {
+		propertyID = *req.PropertyID
+		updates["property_id"] = propertyID
+	}
+	if !h.canManage(c, staffID, propertyID) {
+		ForbiddenResponse(c)
+		return
+	}
+	if req.Report != nil {
+		updates["report"] = *req.Report
+	}
+	if req.Format != nil {
+		updates["format"] = *req.Format
+	}
+	if req.Frequency != nil {
+		updates["frequency"] = *req.Frequency
+	}
+	if req.SendTime != nil {
+		updates["send_time"] = *req.SendTime
+	}
+	if req.Weekday != nil {
+		updates["weekday"] = *req.Weekday
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	updated, err := h.subscriptionService.UpdateSubscription(subscription.ID, updates)
+	if err != nil {
+		h.subscriptionError(c, "UPDATE_SUBSCRIPTION_ERROR", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Report subscription updated successfully", updated)
+}
+
+// DELETE /api/report-subscriptions/:id
+func (h *ReportSubscriptionHandler) DeleteSubscription(c *gin.Context) {
+	subscription, ok := h.subscription(c)
+	if !ok {
+		return
+	}
+
+	if err := h.subscriptionService.DeleteSubscription(subscription.ID); err != nil {
+		h.subscriptionError(c, "DELETE_SUBSCRIPTION_ERROR", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Report subscription deleted successfully", nil)
+}
+
+// POST /api/report-subscriptions/:id/send
+func (h *ReportSubscriptionHandler) SendNow(c *gin.Context) {
+	subscription, ok := h.subscription(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.subscriptionService.SendNow(c.Request.Context(), subscription.ID)
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":         "SendReportSubscription",
+			"subscription_id": subscription.ID,
+			"error":           err.Error(),
+		}).Warn("Failed to send subscribed report")
+
+		if delivery != nil {
+			ErrorResponse(c, http.StatusBadGateway, "DELIVERY_FAILED", err.Error())
+			return
+		}
+		h.subscriptionError(c, "DELIVERY_FAILED", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Report emailed to "+delivery.SentTo, delivery)
+}
+
+// GET /api/report-subscriptions/:id/deliveries?status=sent|failed&limit=100
+func (h *ReportSubscriptionHandler) GetSubscriptionDeliveries(c *gin.Context) {
+	subscription, ok := h.subscription(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	deliveries, err := h.subscriptionService.GetDeliveries(services.DeliveryFilter{
+		SubscriptionID: subscription.ID,
+		Status:         c.Query("status"),
+		Limit:          limit,
+	})
+	if err != nil {
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, deliveries)
+}
+
+// GET /api/report-deliveries?status=failed&property_id=&staff_id=&limit=100
+func (h *ReportSubscriptionHandler) GetDeliveries(c *gin.Context) {
+	filter := services.DeliveryFilter{
+		PropertyID: c.Query("property_id"),
+		Status:     c.Query("status"),
+	}
+	if staffID, err := strconv.ParseUint(c.Query("staff_id"), 10, 32); err == nil {
+		filter.StaffID = uint(staffID)
+	}
+	filter.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	deliveries, err := h.subscriptionService.GetDeliveries(filter)
+	if err != nil {
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, deliveries)
+}
+
+// subscription loads the subscription named in the path, which only its
+// subscriber and admins may see
+func (h *ReportSubscriptionHandler) subscription(c *gin.Context) (*models.ReportSubscription, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid subscription ID")
+		return nil, false
+	}
+
+	subscription, err := h.subscriptionService.GetSubscription(uint(id))
+	if err != nil {
+		h.subscriptionError(c, "SUBSCRIPTION_ERROR", err)
+		return nil, false
+	}
+	if c.GetString("user_role") != "admin" && subscription.StaffID != c.GetUint("user_id") {
+		NotFoundResponse(c, "Report subscription")
+		return nil, false
+	}
+	return subscription, true
+}
+
+// canManage reports whether the caller may subscribe a staff member to a
+// property's reports. Staff can only subscribe themselves to their own property.
+func (h *ReportSubscriptionHandler) canManage(c *gin.Context, staffID uint, propertyID string) bool {
+	if c.GetString("user_role") == "admin" {
+		return true
+	}
+	return staffID == c.GetUint("user_id") && propertyID == c.GetString("property_id")
+}
+
+// subscriptionError maps report subscription failures to responses
+func (h *ReportSubscriptionHandler) subscriptionError(c *gin.Context, code string, err error) {
+	switch {
+	case errors.Is(err, services.ErrSubscriptionNotFound):
+		NotFoundResponse(c, "Report subscription")
+	case errors.Is(err, services.ErrPropertyNotFound):
+		NotFoundResponse(c, "Property")
+	case errors.Is(err, services.ErrUnknownReport), errors.Is(err, services.ErrInvalidSchedule),
+		errors.Is(err, services.ErrNoSubscriber), errors.Is(err, export.ErrUnknownFormat):
+		ValidationErrorResponse(c, err.Error())
+	case errors.Is(err, services.ErrEmailNotConfigured):
+		ErrorResponse(c, http.StatusServiceUnavailable, code, err.Error())
+	default:
+		ErrorResponse(c, http.StatusBadRequest, code, err.Error())
+	}
+}
@@ -30,7 +30,7 @@ func (h *RoomGridHandler) GetRoomGrid(c *gin.Context) {
 	}
 
 	// Parse date parameter (default to today)
-	dateStr := c.DefaultQuery("date", time.Now().Format("2006-01-02"))
+	dateStr := c.DefaultQuery("date", h.roomGridService.BusinessDate(propertyID).Format("2006-01-02"))
 	date, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
@@ -60,7 +60,7 @@ func (h *RoomGridHandler) GetRoomDetails(c *gin.Context) {
 		return
 	}
 
-	dateStr := c.DefaultQuery("date", time.Now().Format("2006-01-02"))
+	dateStr := c.DefaultQuery("date", h.roomGridService.BusinessDate(propertyID).Format("2006-01-02"))
 	date, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
@@ -96,6 +96,8 @@ func (h *RoomGridHandler) MarkBreakfastConsumed(c *gin.Context) {
 	var req struct {
 		PaymentMethod string `json:"payment_method" binding:"required"` // room_charge, ohip, comp, cash
 		Notes         string `json:"notes"`
+		OutletID      uint   `json:"outlet_id"`
+		services.CoverRequest
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -115,12 +117,14 @@ func (h *RoomGridHandler) MarkBreakfastConsumed(c *gin.Context) {
 		return
 	}
 
-	err := h.roomGridService.MarkBreakfastConsumed(
+	consumption, err := h.roomGridService.MarkBreakfastConsumed(
 		propertyID, 
 		roomNumber, 
 		staffID.(uint), 
+		req.OutletID,
 		req.PaymentMethod, 
 		req.Notes,
+		req.CoverRequest,
 	)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -128,10 +132,14 @@ func (h *RoomGridHandler) MarkBreakfastConsumed(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":     "Breakfast consumption recorded successfully",
-		"property_id": propertyID,
-		"room_number": roomNumber,
-		"consumed_at": time.Now(),
+		"message":       "Breakfast consumption recorded successfully",
+		"property_id":   propertyID,
+		"room_number":   roomNumber,
+		"consumed_at":   consumption.ConsumedAt,
+		"outlet_id":     consumption.OutletID,
+		"adult_covers":  consumption.AdultCovers,
+		"child_covers":  consumption.ChildCovers,
+		"upsell_covers": consumption.UpsellCovers,
 	})
 }
 
@@ -172,8 +180,8 @@ func (h *RoomGridHandler) GetConsumptionHistory(c *gin.Context) {
 	}
 
 	// Parse date range
-	startDateStr := c.DefaultQuery("start_date", time.Now().AddDate(0, 0, -7).Format("2006-01-02"))
-	endDateStr := c.DefaultQuery("end_date", time.Now().Format("2006-01-02"))
+	startDateStr := c.DefaultQuery("start_date", h.roomGridService.BusinessDate(propertyID).AddDate(0, 0, -7).Format("2006-01-02"))
+	endDateStr := c.DefaultQuery("end_date", h.roomGridService.BusinessDate(propertyID).Format("2006-01-02"))
 
 	startDate, err := time.Parse("2006-01-02", startDateStr)
 	if err != nil {
@@ -239,7 +247,7 @@ func (h *RoomGridHandler) GetDailyReport(c *gin.Context) {
 		return
 	}
 
-	dateStr := c.DefaultQuery("date", time.Now().Format("2006-01-02"))
+	dateStr := c.DefaultQuery("date", h.roomGridService.BusinessDate(propertyID).Format("2006-01-02"))
 	date, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
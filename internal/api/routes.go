@@ -4,7 +4,7 @@ import (
 	"os"
 	"strings"
 
-	// "hudini-breakfast-module/internal/middleware"
+	"hudini-breakfast-module/internal/middleware"
 	"hudini-breakfast-module/internal/services"
 	"hudini-breakfast-module/internal/validation"
 	"hudini-breakfast-module/internal/websocket"
@@ -14,7 +14,7 @@ import (
 	"gorm.io/gorm"
 )
 
-func SetupRoutes(router *gin.Engine, breakfastService *services.BreakfastService, guestService *services.GuestService, auditService *services.AuditService, notificationService *services.NotificationService, db *gorm.DB, jwtSecret string, wsHub *websocket.Hub) {
+func SetupRoutes(router *gin.Engine, breakfastService *services.BreakfastService, guestService *services.GuestService, auditService *services.AuditService, notificationService *services.NotificationService, voidService *services.VoidService, outletService *services.OutletService, priceBookService *services.PriceBookService, closeOutService *services.CloseOutService, propertyService *services.PropertyService, syncService *services.SyncService, eligibilityService *services.EligibilityService, passService *services.PassService, tableService *services.TableService, waitlistService *services.WaitlistService, orderService *services.OrderService, kitchenService *services.KitchenDisplayService, allergenService *services.AllergenService, inventoryService *services.InventoryService, forecastService *services.ForecastService, planningService *services.PlanningService, executiveService *services.ExecutiveService, analyticsService *services.AnalyticsService, serviceTimeService *services.ServiceTimeService, exportService *services.ExportService, subscriptionService *services.ReportSubscriptionService, portfolioService *services.PortfolioService, db *gorm.DB, jwtSecret string, idempotencyStore *middleware.IdempotencyStore, wsHub *websocket.Hub) {
 	// CORS middleware with security improvements
 	config := cors.DefaultConfig()
 
@@ -27,7 +27,7 @@ func SetupRoutes(router *gin.Engine, breakfastService *services.BreakfastService
 		config.AllowOrigins = []string{"http://localhost:3000", "http://localhost:3001", "http://localhost:8080"}
 	}
 
-	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
+	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", middleware.IdempotencyKeyHeader}
 	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
 	config.AllowCredentials = true
 	router.Use(cors.New(config))
@@ -37,11 +37,30 @@ func SetupRoutes(router *gin.Engine, breakfastService *services.BreakfastService
 
 	// Initialize handlers
 	authHandler := NewAuthHandler(db, jwtSecret)
-	breakfastHandler := NewBreakfastHandler(breakfastService)
+	breakfastHandler := NewBreakfastHandler(breakfastService, forecastService, analyticsService, exportService)
 	guestHandler := NewGuestHandler(guestService)
-	auditHandler := NewAuditHandler(auditService)
-	executiveHandler := NewExecutiveHandler(breakfastService, guestService)
+	auditHandler := NewAuditHandler(auditService, exportService)
+	executiveHandler := NewExecutiveHandler(breakfastService, guestService, waitlistService, executiveService)
 	notificationHandler := NewNotificationHandler(notificationService)
+	voidHandler := NewVoidHandler(voidService)
+	outletHandler := NewOutletHandler(outletService)
+	priceBookHandler := NewPriceBookHandler(priceBookService)
+	closeOutHandler := NewCloseOutHandler(closeOutService)
+	propertyHandler := NewPropertyHandler(propertyService)
+	syncHandler := NewSyncHandler(syncService)
+	eligibilityHandler := NewEligibilityHandler(eligibilityService)
+	passHandler := NewPassHandler(passService)
+	tableHandler := NewTableHandler(tableService)
+	waitlistHandler := NewWaitlistHandler(waitlistService)
+	orderHandler := NewOrderHandler(orderService)
+	kitchenHandler := NewKitchenHandler(kitchenService)
+	allergenHandler := NewAllergenHandler(allergenService)
+	inventoryHandler := NewInventoryHandler(inventoryService)
+	planningHandler := NewPlanningHandler(planningService)
+	serviceTimeHandler := NewServiceTimeHandler(serviceTimeService)
+	subscriptionHandler := NewReportSubscriptionHandler(subscriptionService)
+	portfolioHandler := NewPortfolioHandler(portfolioService)
+	wsHandler := NewWebSocketHandler(wsHub, outletService)
 
 	// Public routes
 	api := router.Group("/api")
@@ -59,27 +78,19 @@ func SetupRoutes(router *gin.Engine, breakfastService *services.BreakfastService
 			demo.GET("/rooms/breakfast-status", 
 				validation.ValidatePropertyID(),
 				breakfastHandler.GetRoomBreakfastStatus)
-			demo.GET("/analytics/advanced", 
-				validation.ValidatePropertyID(),
-				breakfastHandler.GetAdvancedAnalytics)
 			demo.GET("/analytics/realtime", 
 				validation.ValidatePropertyID(),
 				breakfastHandler.GetRealtimeMetrics)
-			
-			// Executive demo routes
-			demo.GET("/executive/kpis", executiveHandler.GetExecutiveKPIs)
-			demo.GET("/executive/vip-trends", executiveHandler.GetVIPTrends)
-			demo.GET("/executive/service-performance", executiveHandler.GetServicePerformance)
-			demo.GET("/executive/revenue-analysis", executiveHandler.GetRevenueAnalysis)
-			demo.GET("/executive/guest-preferences", executiveHandler.GetGuestPreferences)
-			demo.GET("/executive/upset-guests", executiveHandler.GetUpsetVIPGuests)
-			demo.GET("/executive/alerts", executiveHandler.GetExecutiveAlerts)
 		}
+
+		// Breakfast pass QR images linked from pass emails and texts
+		api.GET("/passes/qr.png", passHandler.GetPassImage)
 	}
 
 	// Protected routes (require authentication)
 	protected := api.Group("")
 	protected.Use(authHandler.AuthMiddleware())
+	protected.Use(middleware.Idempotency(idempotencyStore)) // Replays retried mutations that carry an Idempotency-Key
 	{
 		// User profile
 		protected.GET("/auth/me", authHandler.GetProfile)
@@ -98,22 +109,27 @@ func SetupRoutes(router *gin.Engine, breakfastService *services.BreakfastService
 			validation.ValidateDateFormat("date"),
 			breakfastHandler.GetDailyReport)
 
+		// Scheduled report emails for the signed-in staff member
+		protected.GET("/report-subscriptions", subscriptionHandler.GetSubscriptions)
+		protected.POST("/report-subscriptions", subscriptionHandler.CreateSubscription)
+		protected.GET("/report-subscriptions/:id", subscriptionHandler.GetSubscription)
+		protected.PUT("/report-subscriptions/:id", subscriptionHandler.UpdateSubscription)
+		protected.DELETE("/report-subscriptions/:id", subscriptionHandler.DeleteSubscription)
+		protected.POST("/report-subscriptions/:id/send", subscriptionHandler.SendNow)
+		protected.GET("/report-subscriptions/:id/deliveries", subscriptionHandler.GetSubscriptionDeliveries)
+
 		// Analytics and Business Intelligence endpoints
 		protected.GET("/analytics", 
 			validation.ValidatePropertyID(),
 			breakfastHandler.GetAnalytics)
-		protected.GET("/analytics/advanced", 
-			validation.ValidatePropertyID(),
-			breakfastHandler.GetAdvancedAnalytics)
+		protected.GET("/analytics/advanced", breakfastHandler.GetAdvancedAnalytics)
 		protected.GET("/analytics/realtime", 
 			validation.ValidatePropertyID(),
 			breakfastHandler.GetRealtimeMetrics)
 		protected.GET("/analytics/predictive", 
 			validation.ValidatePropertyID(),
 			breakfastHandler.GetPredictiveInsights)
-		protected.GET("/analytics/business-intelligence", 
-			validation.ValidatePropertyID(),
-			breakfastHandler.GetBusinessIntelligence)
+		protected.GET("/analytics/business-intelligence", breakfastHandler.GetBusinessIntelligence)
 
 		// Guest Management
 		protected.GET("/guests", 
@@ -125,6 +141,130 @@ func SetupRoutes(router *gin.Engine, breakfastService *services.BreakfastService
 		protected.PUT("/guests/:id", 
 			validation.RequestSizeLimit(1024*1024), // 1MB limit
 			guestHandler.UpdateGuest)
+		protected.GET("/guests/:id/passes", passHandler.GetPasses)
+
+		// Allergens and guest dietary profiles
+		protected.GET("/allergens", allergenHandler.GetTaxonomy)
+		protected.GET("/guests/:id/dietary-profile", allergenHandler.GetProfile)
+
+		// Property settings
+		protected.GET("/properties/:property_id", propertyHandler.GetProperty)
+
+		// Outlet Management
+		protected.GET("/outlets", 
+			validation.ValidatePropertyID(),
+			outletHandler.GetOutlets)
+		protected.GET("/outlets/:id", outletHandler.GetOutlet)
+
+		outlets := protected.Group("/outlets")
+		outlets.Use(authHandler.RequireRole("manager", "admin"))
+		{
+			outlets.POST("", outletHandler.CreateOutlet)
+			outlets.PUT("/:id", outletHandler.UpdateOutlet)
+			outlets.DELETE("/:id", outletHandler.DeleteOutlet)
+			outlets.POST("/:id/tables", tableHandler.CreateTable)
+			outlets.POST("/:id/menu", orderHandler.CreateMenuItem)
+			outlets.POST("/:id/inventory", inventoryHandler.CreateItem)
+			outlets.PUT("/:id/staffing-ratios", planningHandler.SetRatios)
+			outlets.POST("/:id/plan/email", planningHandler.EmailPlan)
+		}
+
+		// Outlet table maps and the live table board
+		protected.GET("/outlets/:id/tables", tableHandler.GetTables)
+		protected.GET("/outlets/:id/tables/board", tableHandler.GetBoard)
+		protected.GET("/outlets/:id/waitlist", waitlistHandler.GetWaitlist)
+
+		// À-la-carte menus, orders and kitchen tickets
+		protected.GET("/outlets/:id/menu", orderHandler.GetMenu)
+		protected.GET("/outlets/:id/tickets", orderHandler.GetTickets)
+		protected.GET("/outlets/:id/kitchen", kitchenHandler.GetDisplay)
+		protected.GET("/orders", orderHandler.GetOrders)
+		protected.GET("/orders/:id", orderHandler.GetOrder)
+
+		menuItems := protected.Group("/menu-items")
+		menuItems.Use(authHandler.RequireRole("manager", "admin"))
+		{
+			menuItems.PUT("/:id", orderHandler.UpdateMenuItem)
+			menuItems.DELETE("/:id", orderHandler.DeleteMenuItem)
+		}
+
+		// Outlet supplies, usage ratios and stock movements
+		protected.GET("/outlets/:id/inventory", inventoryHandler.GetItems)
+		protected.GET("/inventory/:id/movements", inventoryHandler.GetMovements)
+		protected.GET("/inventory-usage", inventoryHandler.GetUsage)
+
+		// Staffing and prep plans from cover forecasts
+		protected.GET("/outlets/:id/plan", planningHandler.GetPlan)
+		protected.GET("/outlets/:id/staffing-ratios", planningHandler.GetRatios)
+
+		// Rolling service time per outlet
+		protected.GET("/outlets/:id/service-time", serviceTimeHandler.GetOutletServiceTime)
+
+		inventory := protected.Group("/")
+		inventory.Use(authHandler.RequireRole("manager", "admin"))
+		{
+			inventory.PUT("/inventory/:id", inventoryHandler.UpdateItem)
+			inventory.DELETE("/inventory/:id", inventoryHandler.DeleteItem)
+			inventory.POST("/inventory/:id/adjust", inventoryHandler.AdjustStock)
+			inventory.PUT("/inventory-usage", inventoryHandler.SetUsage)
+			inventory.DELETE("/inventory-usage/:id", inventoryHandler.DeleteUsage)
+		}
+
+		tables := protected.Group("/tables")
+		tables.Use(authHandler.RequireRole("manager", "admin"))
+		{
+			tables.PUT("/:id", tableHandler.UpdateTable)
+			tables.DELETE("/:id", tableHandler.DeleteTable)
+		}
+
+		// Price Book Management
+		protected.GET("/price-book", 
+			validation.ValidatePropertyID(),
+			priceBookHandler.GetPrices)
+		protected.GET("/price-book/quote", 
+			validation.ValidatePropertyID(),
+			priceBookHandler.QuoteVisit)
+
+		priceBook := protected.Group("/price-book")
+		priceBook.Use(authHandler.RequireRole("manager", "admin"))
+		{
+			priceBook.POST("", 
+				validation.RequestSizeLimit(1024*1024), // 1MB limit
+				priceBookHandler.CreatePrice)
+			priceBook.PUT("/:id", 
+				validation.RequestSizeLimit(1024*1024), // 1MB limit
+				priceBookHandler.UpdatePrice)
+			priceBook.DELETE("/:id", priceBookHandler.DeletePrice)
+		}
+
+		// Breakfast eligibility rules
+		protected.GET("/eligibility-rules", 
+			validation.ValidatePropertyID(),
+			eligibilityHandler.GetRules)
+		protected.GET("/eligibility/check", 
+			validation.ValidatePropertyID(),
+			eligibilityHandler.CheckRoom)
+
+		eligibilityRules := protected.Group("/eligibility-rules")
+		eligibilityRules.Use(authHandler.RequireRole("manager", "admin"))
+		{
+			eligibilityRules.POST("", eligibilityHandler.CreateRule)
+			eligibilityRules.PUT("/:id", eligibilityHandler.UpdateRule)
+			eligibilityRules.DELETE("/:id", eligibilityHandler.DeleteRule)
+		}
+
+		// End-of-service close-outs
+		protected.GET("/close-outs", 
+			validation.ValidatePropertyID(),
+			closeOutHandler.GetCloseOuts)
+		protected.GET("/close-outs/:id", closeOutHandler.GetCloseOut)
+
+		closeOuts := protected.Group("/close-outs")
+		closeOuts.Use(authHandler.RequireRole("manager", "admin"))
+		{
+			closeOuts.POST("", closeOutHandler.CloseOut)
+			closeOuts.POST("/:id/reopen", closeOutHandler.Reopen)
+		}
 
 		// Staff actions (require staff role)
 		staff := protected.Group("/")
@@ -134,6 +274,50 @@ func SetupRoutes(router *gin.Engine, breakfastService *services.BreakfastService
 				validation.ValidatePropertyID(),
 				validation.ValidateRoomNumber(),
 				breakfastHandler.MarkBreakfastConsumed)
+			staff.POST("/consumption/:id/void", voidHandler.VoidConsumption)
+			staff.POST("/consumption/:id/events", serviceTimeHandler.RecordEvent)
+
+			// Allergy warnings when a room is opened
+			staff.GET("/rooms/:room_number/allergy-check", allergenHandler.CheckRoom)
+			staff.POST("/guests/:id/allergies/acknowledge", allergenHandler.Acknowledge)
+			staff.PUT("/guests/:id/dietary-profile", allergenHandler.UpdateProfile)
+
+			// Offline batch sync for mobile and PWA devices
+			staff.POST("/sync/consumptions", 
+				validation.RequestSizeLimit(1024*1024), // 1MB limit
+				syncHandler.SyncConsumptions)
+
+			// Signed QR breakfast passes
+			staff.POST("/guests/:id/passes", passHandler.IssuePasses)
+			staff.POST("/guests/:id/passes/send", passHandler.SendPasses)
+			staff.POST("/passes/:id/revoke", passHandler.RevokePass)
+			staff.POST("/passes/redeem", passHandler.RedeemPass)
+
+			// Seating
+			staff.GET("/outlets/:id/tables/suggest", tableHandler.SuggestTables)
+			staff.POST("/outlets/:id/seat", tableHandler.SeatParty)
+			staff.POST("/tables/:id/clear", tableHandler.ClearTable)
+
+			// Walk-in waitlist
+			staff.POST("/outlets/:id/waitlist", waitlistHandler.AddParty)
+			staff.POST("/waitlist/:id/notify", waitlistHandler.NotifyParty)
+			staff.POST("/waitlist/:id/seat", waitlistHandler.SeatParty)
+			staff.POST("/waitlist/:id/cancel", waitlistHandler.CancelEntry)
+
+			// À-la-carte orders and kitchen tickets
+			staff.POST("/orders", orderHandler.CreateOrder)
+			staff.POST("/orders/:id/items", orderHandler.AddItems)
+			staff.POST("/orders/:id/fire", orderHandler.FireOrder)
+			staff.POST("/orders/:id/cancel", orderHandler.CancelOrder)
+			staff.POST("/orders/:id/post-charge", orderHandler.PostOverage)
+			staff.POST("/tickets/:id/ready", orderHandler.MarkTicketReady)
+			staff.POST("/tickets/:id/served", orderHandler.MarkTicketServed)
+			staff.POST("/tickets/:id/bump", kitchenHandler.BumpTicket)
+			staff.POST("/tickets/:id/recall", kitchenHandler.RecallTicket)
+
+			// Stock counts and deliveries
+			staff.POST("/inventory/:id/count", inventoryHandler.RecordCount)
+			staff.POST("/inventory/:id/receive", inventoryHandler.ReceiveStock)
 		}
 		
 		// Admin-only routes
@@ -145,6 +329,13 @@ func SetupRoutes(router *gin.Engine, breakfastService *services.BreakfastService
 			admin.GET("/audit/users/:user_id/activity", auditHandler.GetUserActivity)
 			admin.GET("/audit/resources/:resource/:resource_id/history", auditHandler.GetResourceHistory)
 			admin.GET("/audit/summary", auditHandler.GetAuditSummary)
+
+			// Report subscription deliveries across all staff, including failures
+			admin.GET("/report-deliveries", subscriptionHandler.GetDeliveries)
+
+			// Property business day settings
+			admin.PUT("/properties/:property_id/business-day", propertyHandler.UpdateBusinessDay)
+			admin.PUT("/properties/:property_id/tags", propertyHandler.UpdateTags)
 		}
 		
 		// Executive routes (require manager or admin role)
@@ -158,6 +349,20 @@ func SetupRoutes(router *gin.Engine, breakfastService *services.BreakfastService
 			executive.GET("/guest-preferences", executiveHandler.GetGuestPreferences)
 			executive.GET("/upset-guests", executiveHandler.GetUpsetVIPGuests)
 			executive.GET("/alerts", executiveHandler.GetExecutiveAlerts)
+			executive.GET("/service-times", serviceTimeHandler.GetDistribution)
+		}
+		
+		// Portfolio KPIs across properties (require manager or admin role)
+		portfolio := protected.Group("/portfolio")
+		portfolio.Use(authHandler.RequireRole("manager", "admin"))
+		{
+			portfolio.GET("/kpis", portfolioHandler.GetKPIs)
+			portfolio.GET("/properties/:property_id", portfolioHandler.GetProperty)
+			portfolio.GET("/groups", portfolioHandler.GetGroups)
+			portfolio.GET("/groups/:id", portfolioHandler.GetGroup)
+			portfolio.POST("/groups", portfolioHandler.CreateGroup)
+			portfolio.PUT("/groups/:id", portfolioHandler.UpdateGroup)
+			portfolio.DELETE("/groups/:id", portfolioHandler.DeleteGroup)
 		}
 		
 		// Notification routes
@@ -185,10 +390,8 @@ func SetupRoutes(router *gin.Engine, breakfastService *services.BreakfastService
 		}
 	}
 
-	// WebSocket endpoint
-	router.GET("/ws", func(c *gin.Context) {
-		wsHub.ServeWS(c.Writer, c.Request)
-	})
+	// WebSocket endpoint, signed in with the token query parameter
+	router.GET("/ws", authHandler.AuthMiddleware(), wsHandler.Connect)
 
 	// Health check
 	router.GET("/health", HealthCheck)
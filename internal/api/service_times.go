@@ -0,0 +1,150 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ServiceTimeHandler handles visit lifecycle events and service-time reporting
+type ServiceTimeHandler struct {
+	serviceTimeService *services.ServiceTimeService
+}
+
+// NewServiceTimeHandler creates a new service-time handler
+func NewServiceTimeHandler(serviceTimeService *services.ServiceTimeService) *ServiceTimeHandler {
+	return &ServiceTimeHandler{
+		serviceTimeService: serviceTimeService,
+	}
+}
+
+type VisitEventRequest struct {
+	Event string     `json:"event" binding:"required"` // arrived, seated, served, left
+	At    *time.Time `json:"at"`                       // Defaults to now; lets offline devices send when it happened
+}
+
+// POST /api/consumption/:id/events
+func (h *ServiceTimeHandler) RecordEvent(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid consumption ID")
+		return
+	}
+
+	var req VisitEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+	var at time.Time
+	if req.At != nil {
+		at = *req.At
+	}
+
+	visit, err := h.serviceTimeService.RecordEvent(c.Request.Context(), uint(id), req.Event, at, c.GetUint("user_id"))
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":        "RecordEvent",
+			"consumption_id": id,
+			"event":          req.Event,
+			"error":          err.Error(),
+		}).Warn("Failed to record visit event")
+
+		switch {
+		case errors.Is(err, services.ErrConsumptionNotFound):
+			NotFoundResponse(c, "Consumption")
+		case errors.Is(err, services.ErrUnknownVisitEvent),
+			errors.Is(err, services.ErrVisitEventOrder),
+			errors.Is(err, services.ErrVisitNotConsumed):
+			ValidationErrorResponse(c, err.Error())
+		default:
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponse(c, visit)
+}
+
+// GET /api/outlets/:id/service-time
+func (h *ServiceTimeHandler) GetOutletServiceTime(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	metric, err := h.serviceTimeService.OutletServiceTime(uint(outletID))
+	if err != nil {
+		if errors.Is(err, services.ErrOutletNotFound) {
+			NotFoundResponse(c, "Outlet")
+			return
+		}
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, metric)
+}
+
+// GET /api/executive/service-times?property_id=X&from=YYYY-MM-DD&to=YYYY-MM-DD&by=staff|hour&outlet_id=N
+func (h *ServiceTimeHandler) GetDistribution(c *gin.Context) {
+	propertyID := c.DefaultQuery("property_id", "HOTEL001")
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			ValidationErrorResponse(c, "to must be in YYYY-MM-DD format")
+			return
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -6)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			ValidationErrorResponse(c, "from must be in YYYY-MM-DD format")
+			return
+		}
+		from = parsed
+	}
+	if from.After(to) {
+		ValidationErrorResponse(c, "from must be on or before to")
+		return
+	}
+
+	var outletID *uint
+	if value := c.Query("outlet_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			ValidationErrorResponse(c, "Invalid outlet ID")
+			return
+		}
+		outlet := uint(id)
+		outletID = &outlet
+	}
+
+	distribution, err := h.serviceTimeService.Distribution(propertyID, outletID, from, to, c.DefaultQuery("by", "staff"))
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownGrouping) {
+			ValidationErrorResponse(c, err.Error())
+			return
+		}
+		logging.WithFields(logrus.Fields{
+			"handler":     "GetDistribution",
+			"property_id": propertyID,
+			"error":       err.Error(),
+		}).Error("Failed to build service time distribution")
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, distribution)
+}
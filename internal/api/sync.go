@@ -0,0 +1,73 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// SyncHandler handles offline batch sync from mobile and PWA devices
+type SyncHandler struct {
+	syncService *services.SyncService
+}
+
+// NewSyncHandler creates a new sync handler
+func NewSyncHandler(syncService *services.SyncService) *SyncHandler {
+	return &SyncHandler{
+		syncService: syncService,
+	}
+}
+
+type SyncBatchRequest struct {
+	PropertyID string                      `json:"property_id" binding:"required"`
+	DeviceID   string                      `json:"device_id" binding:"required"`
+	Events     []services.SyncEventRequest `json:"events" binding:"required"`
+}
+
+// POST /api/sync/consumptions
+func (h *SyncHandler) SyncConsumptions(c *gin.Context) {
+	var req SyncBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	staffID := c.GetUint("user_id")
+	if staffID == 0 {
+		UnauthorizedResponse(c)
+		return
+	}
+
+	result, err := h.syncService.SyncBatch(c.Request.Context(), services.SyncBatchRequest{
+		PropertyID: req.PropertyID,
+		DeviceID:   req.DeviceID,
+		StaffID:    staffID,
+		Events:     req.Events,
+	})
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":     "SyncConsumptions",
+			"property_id": req.PropertyID,
+			"device_id":   req.DeviceID,
+			"events":      len(req.Events),
+			"error":       err.Error(),
+		}).Warn("Failed to sync consumption events")
+
+		switch {
+		case errors.Is(err, services.ErrSyncDeviceRequired),
+			errors.Is(err, services.ErrSyncBatchEmpty),
+			errors.Is(err, services.ErrSyncBatchTooLarge):
+			ValidationErrorResponse(c, err.Error())
+		default:
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, fmt.Sprintf("Synced %d events", len(result.Results)), result)
+}
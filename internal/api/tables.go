@@ -0,0 +1,326 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// TableHandler handles outlet table maps, seating and the live table board
+type TableHandler struct {
+	tableService *services.TableService
+}
+
+// NewTableHandler creates a new table handler
+func NewTableHandler(tableService *services.TableService) *TableHandler {
+	return &TableHandler{
+		tableService: tableService,
+	}
+}
+
+// TableRequest is the payload for creating or updating a table.
+// Pointer fields distinguish omitted values from explicit zero values.
+type TableRequest struct {
+	Name       *string `json:"name"`
+	Capacity   *int    `json:"capacity"`
+	Attributes *string `json:"attributes"`
+	Section    *string `json:"section"`
+	PosX       *int    `json:"pos_x"`
+	PosY       *int    `json:"pos_y"`
+	Status     *string `json:"status"`
+}
+
+// GET /api/outlets/:id/tables
+func (h *TableHandler) GetTables(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	tables, err := h.tableService.GetTables(uint(outletID))
+	if err != nil {
+		InternalErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, gin.H{
+		"tables":             tables,
+		"seating_attributes": services.SeatingAttributes,
+	})
+}
+
+// GET /api/outlets/:id/tables/board
+func (h *TableHandler) GetBoard(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	board, err := h.tableService.GetBoard(uint(outletID))
+	if err != nil {
+		if errors.Is(err, services.ErrOutletNotFound) {
+			NotFoundResponse(c, "Outlet")
+		} else {
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponse(c, gin.H{
+		"board":          board,
+		"websocket_room": services.TableBoardRoom(uint(outletID)),
+	})
+}
+
+// POST /api/outlets/:id/tables
+func (h *TableHandler) CreateTable(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	var req TableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	table := models.DiningTable{OutletID: uint(outletID)}
+	if req.Name != nil {
+		table.Name = *req.Name
+	}
+	if req.Capacity != nil {
+		table.Capacity = *req.Capacity
+	}
+	if req.Attributes != nil {
+		table.Attributes = *req.Attributes
+	}
+	if req.Section != nil {
+		table.Section = *req.Section
+	}
+	if req.PosX != nil {
+		table.PosX = *req.PosX
+	}
+	if req.PosY != nil {
+		table.PosY = *req.PosY
+	}
+
+	if err := h.tableService.CreateTable(&table); err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":   "CreateTable",
+			"outlet_id": outletID,
+			"error":     err.Error(),
+		}).Error("Failed to create table")
+
+		if errors.Is(err, services.ErrOutletNotFound) {
+			NotFoundResponse(c, "Outlet")
+		} else {
+			ErrorResponse(c, http.StatusBadRequest, "CREATE_TABLE_ERROR", err.Error())
+		}
+		return
+	}
+
+	CreatedResponse(c, table)
+}
+
+// PUT /api/tables/:id
+func (h *TableHandler) UpdateTable(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid table ID")
+		return
+	}
+
+	var req TableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Capacity != nil {
+		updates["capacity"] = *req.Capacity
+	}
+	if req.Attributes != nil {
+		updates["attributes"] = *req.Attributes
+	}
+	if req.Section != nil {
+		updates["section"] = *req.Section
+	}
+	if req.PosX != nil {
+		updates["pos_x"] = *req.PosX
+	}
+	if req.PosY != nil {
+		updates["pos_y"] = *req.PosY
+	}
+	if req.Status != nil {
+		updates["status"] = *req.Status
+	}
+
+	table, err := h.tableService.UpdateTable(uint(id), updates)
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":  "UpdateTable",
+			"table_id": id,
+			"error":    err.Error(),
+		}).Error("Failed to update table")
+
+		switch {
+		case errors.Is(err, services.ErrTableNotFound):
+			NotFoundResponse(c, "Table")
+		case errors.Is(err, services.ErrTableOccupied):
+			ErrorResponse(c, http.StatusConflict, "TABLE_OCCUPIED", err.Error())
+		default:
+			ErrorResponse(c, http.StatusBadRequest, "UPDATE_TABLE_ERROR", err.Error())
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Table updated successfully", table)
+}
+
+// DELETE /api/tables/:id
+func (h *TableHandler) DeleteTable(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid table ID")
+		return
+	}
+
+	if err := h.tableService.DeleteTable(uint(id)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrTableNotFound):
+			NotFoundResponse(c, "Table")
+		case errors.Is(err, services.ErrTableOccupied):
+			ErrorResponse(c, http.StatusConflict, "TABLE_OCCUPIED", err.Error())
+		default:
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Table deleted successfully", gin.H{"table_id": id})
+}
+
+// GET /api/outlets/:id/tables/suggest
+func (h *TableHandler) SuggestTables(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	req := services.SeatingRequest{
+		RoomNumber: c.Query("room_number"),
+		Preference: c.Query("seating_preference"),
+	}
+	if value := c.Query("guest_id"); value != "" {
+		guestID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			ValidationErrorResponse(c, "Invalid guest ID")
+			return
+		}
+		req.GuestID = uint(guestID)
+	}
+	if value := c.Query("party_size"); value != "" {
+		partySize, err := strconv.Atoi(value)
+		if err != nil {
+			ValidationErrorResponse(c, "party_size must be a number")
+			return
+		}
+		req.PartySize = partySize
+	}
+
+	suggestions, err := h.tableService.SuggestTables(uint(outletID), req)
+	if err != nil {
+		h.seatingError(c, "SuggestTables", uint(outletID), err)
+		return
+	}
+
+	SuccessResponse(c, suggestions)
+}
+
+// POST /api/outlets/:id/seat
+func (h *TableHandler) SeatParty(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	var req services.SeatingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	turn, err := h.tableService.SeatParty(uint(outletID), req, c.GetUint("user_id"))
+	if err != nil {
+		h.seatingError(c, "SeatParty", uint(outletID), err)
+		return
+	}
+
+	CreatedResponse(c, turn)
+}
+
+// POST /api/tables/:id/clear
+func (h *TableHandler) ClearTable(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid table ID")
+		return
+	}
+
+	turn, err := h.tableService.ClearTable(uint(id), c.GetUint("user_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTableNotFound):
+			NotFoundResponse(c, "Table")
+		case errors.Is(err, services.ErrTableNotOccupied):
+			ErrorResponse(c, http.StatusConflict, "TABLE_NOT_OCCUPIED", err.Error())
+		default:
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Table cleared", turn)
+}
+
+// seatingError maps seating failures to responses
+func (h *TableHandler) seatingError(c *gin.Context, handler string, outletID uint, err error) {
+	logging.WithFields(logrus.Fields{
+		"handler":   handler,
+		"outlet_id": outletID,
+		"error":     err.Error(),
+	}).Warn("Seating request refused")
+
+	switch {
+	case errors.Is(err, services.ErrOutletNotFound):
+		NotFoundResponse(c, "Outlet")
+	case errors.Is(err, services.ErrTableNotFound):
+		NotFoundResponse(c, "Table")
+	case errors.Is(err, services.ErrNoGuestInRoom):
+		NotFoundResponse(c, "Guest")
+	case errors.Is(err, services.ErrTableOccupied),
+		errors.Is(err, services.ErrTableOutOfService),
+		errors.Is(err, services.ErrGuestAlreadySeated),
+		errors.Is(err, services.ErrNoTableAvailable):
+		ErrorResponse(c, http.StatusConflict, "SEATING_UNAVAILABLE", err.Error())
+	default:
+		ErrorResponse(c, http.StatusBadRequest, "SEATING_ERROR", err.Error())
+	}
+}
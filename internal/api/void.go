@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// VoidHandler handles voiding of breakfast consumptions
+type VoidHandler struct {
+	voidService *services.VoidService
+}
+
+// NewVoidHandler creates a new void handler
+func NewVoidHandler(voidService *services.VoidService) *VoidHandler {
+	return &VoidHandler{
+		voidService: voidService,
+	}
+}
+
+type VoidConsumptionRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// POST /api/consumption/:id/void
+func (h *VoidHandler) VoidConsumption(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid consumption ID")
+		return
+	}
+
+	var req VoidConsumptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	staffID := c.GetUint("user_id")
+	if staffID == 0 {
+		UnauthorizedResponse(c)
+		return
+	}
+
+	consumption, err := h.voidService.VoidConsumption(c.Request.Context(), services.VoidRequest{
+		ConsumptionID: uint(id),
+		StaffID:       staffID,
+		Role:          c.GetString("user_role"),
+		PropertyID:    c.GetString("property_id"),
+		Reason:        req.Reason,
+		IPAddress:     c.ClientIP(),
+		UserAgent:     c.Request.UserAgent(),
+	})
+	if err != nil {
+		logging.WithFields(logrus.Fields{
+			"handler":        "VoidConsumption",
+			"consumption_id": id,
+			"staff_id":       staffID,
+			"error":          err.Error(),
+		}).Warn("Failed to void consumption")
+
+		switch {
+		case errors.Is(err, services.ErrConsumptionNotFound):
+			NotFoundResponse(c, "Consumption")
+		case errors.Is(err, services.ErrVoidOtherProperty):
+			ForbiddenResponse(c)
+		case errors.Is(err, services.ErrVoidWindowExpired):
+			ErrorResponse(c, http.StatusForbidden, "VOID_WINDOW_EXPIRED", err.Error())
+		case errors.Is(err, services.ErrVoidReasonRequired):
+			ValidationErrorResponse(c, err.Error())
+		case errors.Is(err, services.ErrNotVoidable), errors.Is(err, services.ErrDayClosed):
+			ErrorResponse(c, http.StatusConflict, "VOID_ERROR", err.Error())
+		default:
+			ErrorResponse(c, http.StatusBadGateway, "VOID_ERROR", err.Error())
+		}
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Breakfast consumption voided", consumption)
+}
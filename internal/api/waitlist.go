@@ -0,0 +1,180 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// WaitlistHandler handles the walk-in waitlist for outlet tables
+type WaitlistHandler struct {
+	waitlistService *services.WaitlistService
+}
+
+// NewWaitlistHandler creates a new waitlist handler
+func NewWaitlistHandler(waitlistService *services.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{
+		waitlistService: waitlistService,
+	}
+}
+
+type WaitlistTableRequest struct {
+	TableID uint `json:"table_id"` // Optional; defaults to the table the party was called for
+}
+
+type CancelWaitlistRequest struct {
+	NoShow bool `json:"no_show"`
+}
+
+// GET /api/outlets/:id/waitlist
+func (h *WaitlistHandler) GetWaitlist(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	waitlist, err := h.waitlistService.GetWaitlist(uint(outletID))
+	if err != nil {
+		if errors.Is(err, services.ErrOutletNotFound) {
+			NotFoundResponse(c, "Outlet")
+		} else {
+			InternalErrorResponse(c, err)
+		}
+		return
+	}
+
+	SuccessResponse(c, waitlist)
+}
+
+// POST /api/outlets/:id/waitlist
+func (h *WaitlistHandler) AddParty(c *gin.Context) {
+	outletID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid outlet ID")
+		return
+	}
+
+	var req services.WaitlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	entry, err := h.waitlistService.AddParty(uint(outletID), req, c.GetUint("user_id"))
+	if err != nil {
+		h.waitlistError(c, "AddParty", err)
+		return
+	}
+
+	CreatedResponse(c, entry)
+}
+
+// POST /api/waitlist/:id/notify
+func (h *WaitlistHandler) NotifyParty(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid waitlist entry ID")
+		return
+	}
+
+	var req WaitlistTableRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			ValidationErrorResponse(c, err.Error())
+			return
+		}
+	}
+
+	entry, err := h.waitlistService.NotifyParty(c.Request.Context(), uint(id), req.TableID)
+	if err != nil {
+		h.waitlistError(c, "NotifyParty", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Party notified", entry)
+}
+
+// POST /api/waitlist/:id/seat
+func (h *WaitlistHandler) SeatParty(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid waitlist entry ID")
+		return
+	}
+
+	var req WaitlistTableRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			ValidationErrorResponse(c, err.Error())
+			return
+		}
+	}
+
+	turn, err := h.waitlistService.SeatEntry(uint(id), req.TableID, c.GetUint("user_id"))
+	if err != nil {
+		h.waitlistError(c, "SeatParty", err)
+		return
+	}
+
+	CreatedResponse(c, turn)
+}
+
+// POST /api/waitlist/:id/cancel
+func (h *WaitlistHandler) CancelEntry(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		ValidationErrorResponse(c, "Invalid waitlist entry ID")
+		return
+	}
+
+	var req CancelWaitlistRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			ValidationErrorResponse(c, err.Error())
+			return
+		}
+	}
+
+	entry, err := h.waitlistService.CloseEntry(uint(id), req.NoShow)
+	if err != nil {
+		h.waitlistError(c, "CancelEntry", err)
+		return
+	}
+
+	SuccessResponseWithMessage(c, "Party removed from waitlist", entry)
+}
+
+// waitlistError maps waitlist failures to responses
+func (h *WaitlistHandler) waitlistError(c *gin.Context, handler string, err error) {
+	logging.WithFields(logrus.Fields{
+		"handler": handler,
+		"error":   err.Error(),
+	}).Warn("Waitlist request refused")
+
+	switch {
+	case errors.Is(err, services.ErrWaitlistEntryNotFound):
+		NotFoundResponse(c, "Waitlist entry")
+	case errors.Is(err, services.ErrOutletNotFound):
+		NotFoundResponse(c, "Outlet")
+	case errors.Is(err, services.ErrTableNotFound):
+		NotFoundResponse(c, "Table")
+	case errors.Is(err, services.ErrNoGuestInRoom):
+		NotFoundResponse(c, "Guest")
+	case errors.Is(err, services.ErrWaitlistEntryClosed):
+		ErrorResponse(c, http.StatusConflict, "WAITLIST_ENTRY_CLOSED", err.Error())
+	case errors.Is(err, services.ErrTableOccupied),
+		errors.Is(err, services.ErrTableOutOfService),
+		errors.Is(err, services.ErrGuestAlreadySeated),
+		errors.Is(err, services.ErrNoTableAvailable):
+		ErrorResponse(c, http.StatusConflict, "SEATING_UNAVAILABLE", err.Error())
+	default:
+		ErrorResponse(c, http.StatusBadRequest, "WAITLIST_ERROR", err.Error())
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"errors"
+
+	"hudini-breakfast-module/internal/services"
+	"hudini-breakfast-module/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebSocketHandler connects signed-in staff to the websocket hub
+type WebSocketHandler struct {
+	hub           *websocket.Hub
+	outletService *services.OutletService
+}
+
+// NewWebSocketHandler creates a new websocket handler
+func NewWebSocketHandler(hub *websocket.Hub, outletService *services.OutletService) *WebSocketHandler {
+	return &WebSocketHandler{
+		hub:           hub,
+		outletService: outletService,
+	}
+}
+
+// GET /ws?token=&room=tables:1&room=kitchen:1:grill
+//
+// Staff may only join the rooms of their own property's outlets; admins may
+// join any outlet's rooms. The connection is refused before the upgrade if
+// any requested room is not allowed.
+func (h *WebSocketHandler) Connect(c *gin.Context) {
+	var rooms []string
+	for _, room := range c.QueryArray("room") {
+		if room == "" {
+			continue
+		}
+
+		outletID, ok := services.RoomOutlet(room)
+		if !ok {
+			ValidationErrorResponse(c, "Unknown room "+room)
+			return
+		}
+
+		outlet, err := h.outletService.GetOutlet(outletID)
+		if err != nil {
+			if errors.Is(err, services.ErrOutletNotFound) {
+				NotFoundResponse(c, "Outlet")
+				return
+			}
+			InternalErrorResponse(c, err)
+			return
+		}
+		if c.GetString("user_role") != "admin" && outlet.PropertyID != c.GetString("property_id") {
+			ForbiddenResponse(c)
+			return
+		}
+		rooms = append(rooms, room)
+	}
+
+	h.hub.ServeWS(c.Writer, c.Request, rooms)
+}
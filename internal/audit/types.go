@@ -11,8 +11,13 @@ const (
 	ActionLogin    AuditAction = "LOGIN"
 	ActionLogout   AuditAction = "LOGOUT"
 	ActionConsume  AuditAction = "CONSUME_BREAKFAST"
+	ActionVoid     AuditAction = "VOID_BREAKFAST"
+	ActionCloseOut AuditAction = "CLOSE_OUT_SERVICE"
+	ActionReopen   AuditAction = "REOPEN_SERVICE"
 	ActionExport   AuditAction = "EXPORT"
 	ActionReport   AuditAction = "GENERATE_REPORT"
+	ActionCount    AuditAction = "COUNT_STOCK"
+	ActionAdjust   AuditAction = "ADJUST_STOCK"
 )
 
 // AuditResource represents the resources that can be audited
@@ -25,7 +30,10 @@ const (
 	ResourceStaff       AuditResource = "STAFF"
 	ResourceProperty    AuditResource = "PROPERTY"
 	ResourceOutlet      AuditResource = "OUTLET"
+	ResourceCloseOut    AuditResource = "SERVICE_CLOSE_OUT"
 	ResourceAuth        AuditResource = "AUTHENTICATION"
 	ResourceReport      AuditResource = "REPORT"
 	ResourceAnalytics   AuditResource = "ANALYTICS"
+	ResourceInventory   AuditResource = "INVENTORY"
+	ResourceAuditLog    AuditResource = "AUDIT_LOG"
 )
\ No newline at end of file
@@ -1,13 +1,22 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"golang.org/x/crypto/hkdf"
 )
 
+// passSigningLabel separates the key derived for breakfast passes from the JWT key
+const passSigningLabel = "hudini-breakfast-pass-signing-v1"
+
 type Config struct {
 	Port           string
 	DatabaseURL    string
@@ -20,6 +29,15 @@ type Config struct {
 	Security       SecurityConfig
 	Database       DatabaseConfig
 	Logging        LoggingConfig
+	Void           VoidConfig
+	CloseOut       CloseOutConfig
+	Passes         PassConfig
+	Inventory      InventoryConfig
+	Planning       PlanningConfig
+	ServiceTime    ServiceTimeConfig
+	Rollup         RollupConfig
+	Subscriptions  SubscriptionConfig
+	Idempotency    IdempotencyConfig
 }
 
 type OHIPConfig struct {
@@ -83,6 +101,52 @@ type DatabaseConfig struct {
 	BackupPath      string
 }
 
+type VoidConfig struct {
+	Window        time.Duration // How long after consumption any staff member may void it
+	OverrideRoles []string      // Roles allowed to void outside the window
+}
+
+type CloseOutConfig struct {
+	SchedulerEnabled bool
+	Grace            time.Duration // Wait after an outlet's close time before closing it out
+	Interval         time.Duration // How often the scheduler looks for outlets to close
+}
+
+type PassConfig struct {
+	SigningSecret string // HMAC key for breakfast pass tokens
+	PublicURL     string // Base URL guests open to fetch their pass image
+}
+
+type InventoryConfig struct {
+	AlertInterval time.Duration // How often stock is checked against par levels for low_supplies alerts
+}
+
+type PlanningConfig struct {
+	EmailTime     string        // Property-local time, e.g. "16:00", after which the next day's plan is emailed
+	EmailInterval time.Duration // How often the scheduler looks for plans to email
+}
+
+type ServiceTimeConfig struct {
+	DelayThreshold time.Duration // Rolling average seating-to-served time above which a service delay alert fires
+	Window         time.Duration // How far back the rolling average looks
+	MinSamples     int           // Visits served within the window before an alert can fire
+}
+
+type RollupConfig struct {
+	Interval time.Duration // How often today's and yesterday's rollups are refreshed in the background
+	Debounce time.Duration // How long visit changes are gathered before a business date's rollups are refreshed
+}
+
+type SubscriptionConfig struct {
+	Interval    time.Duration // How often the scheduler looks for subscribed reports that are due
+	MaxAttempts int           // Failed deliveries of a report period before it is given up until the next one
+}
+
+type IdempotencyConfig struct {
+	TTL   time.Duration // How long a completed response is replayed for its Idempotency-Key
+	Lease time.Duration // How long a request may hold its key before a retry can reclaim it
+}
+
 type LoggingConfig struct {
 	Level      string
 	Format     string // json, text
@@ -107,6 +171,18 @@ func Load() *Config {
 	maxIdleConns, _ := strconv.Atoi(getEnvOrDefault("DB_MAX_IDLE_CONNS", "5"))
 	connMaxLifetime, _ := time.ParseDuration(getEnvOrDefault("DB_CONN_MAX_LIFETIME", "5m"))
 	backupInterval, _ := time.ParseDuration(getEnvOrDefault("DB_BACKUP_INTERVAL", "24h"))
+	voidWindow, _ := time.ParseDuration(getEnvOrDefault("VOID_WINDOW", "15m"))
+	closeOutGrace, _ := time.ParseDuration(getEnvOrDefault("CLOSE_OUT_GRACE", "30m"))
+	closeOutInterval, _ := time.ParseDuration(getEnvOrDefault("CLOSE_OUT_INTERVAL", "5m"))
+	inventoryAlertInterval, _ := time.ParseDuration(getEnvOrDefault("INVENTORY_ALERT_INTERVAL", "1m"))
+	planEmailInterval, _ := time.ParseDuration(getEnvOrDefault("PLAN_EMAIL_INTERVAL", "10m"))
+	serviceDelayThreshold, _ := time.ParseDuration(getEnvOrDefault("SERVICE_DELAY_THRESHOLD", "15m"))
+	serviceTimeWindow, _ := time.ParseDuration(getEnvOrDefault("SERVICE_TIME_WINDOW", "30m"))
+	rollupInterval, _ := time.ParseDuration(getEnvOrDefault("ROLLUP_INTERVAL", "15m"))
+	rollupDebounce, _ := time.ParseDuration(getEnvOrDefault("ROLLUP_DEBOUNCE", "5s"))
+	subscriptionInterval, _ := time.ParseDuration(getEnvOrDefault("REPORT_SUBSCRIPTION_INTERVAL", "5m"))
+	idempotencyTTL, _ := time.ParseDuration(getEnvOrDefault("IDEMPOTENCY_KEY_TTL", "24h"))
+	idempotencyLease, _ := time.ParseDuration(getEnvOrDefault("IDEMPOTENCY_KEY_LEASE", "5m"))
 
 	ohipTimeout, _ := strconv.Atoi(getEnvOrDefault("OHIP_TIMEOUT", "30"))
 	pmsTimeout, _ := strconv.Atoi(getEnvOrDefault("PMS_TIMEOUT", "30"))
@@ -209,6 +285,43 @@ func Load() *Config {
 			MaxBackups: getEnvInt("LOG_MAX_BACKUPS", 3),
 			MaxAge:     getEnvInt("LOG_MAX_AGE", 28),
 		},
+		Void: VoidConfig{
+			Window:        voidWindow,
+			OverrideRoles: strings.Split(getEnvOrDefault("VOID_OVERRIDE_ROLES", "manager,admin"), ","),
+		},
+		CloseOut: CloseOutConfig{
+			SchedulerEnabled: getEnvBool("CLOSE_OUT_SCHEDULER_ENABLED", true),
+			Grace:            closeOutGrace,
+			Interval:         closeOutInterval,
+		},
+		Passes: PassConfig{
+			SigningSecret: passSigningSecret(),
+			PublicURL:     strings.TrimRight(getEnvOrDefault("PASS_PUBLIC_URL", ""), "/"),
+		},
+		Inventory: InventoryConfig{
+			AlertInterval: inventoryAlertInterval,
+		},
+		Planning: PlanningConfig{
+			EmailTime:     getEnvOrDefault("PLAN_EMAIL_TIME", "16:00"),
+			EmailInterval: planEmailInterval,
+		},
+		ServiceTime: ServiceTimeConfig{
+			DelayThreshold: serviceDelayThreshold,
+			Window:         serviceTimeWindow,
+			MinSamples:     getEnvInt("SERVICE_TIME_MIN_SAMPLES", 3),
+		},
+		Rollup: RollupConfig{
+			Interval: rollupInterval,
+			Debounce: rollupDebounce,
+		},
+		Subscriptions: SubscriptionConfig{
+			Interval:    subscriptionInterval,
+			MaxAttempts: getEnvInt("REPORT_SUBSCRIPTION_MAX_ATTEMPTS", 3),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:   idempotencyTTL,
+			Lease: idempotencyLease,
+		},
 	}
 }
 
@@ -229,9 +342,33 @@ func ValidateRequiredEnvVars() error {
 		return fmt.Errorf("JWT_SECRET must be at least 32 characters long for security")
 	}
 
+	// Guest passes must not be signed with the key that signs staff tokens
+	if secret := os.Getenv("PASS_SIGNING_SECRET"); secret != "" {
+		if secret == os.Getenv("JWT_SECRET") {
+			return fmt.Errorf("PASS_SIGNING_SECRET must differ from JWT_SECRET")
+		}
+		if len(secret) < 32 {
+			return fmt.Errorf("PASS_SIGNING_SECRET must be at least 32 characters long for security")
+		}
+	}
+
 	return nil
 }
 
+// passSigningSecret returns PASS_SIGNING_SECRET, or when it is not set a key
+// derived from JWT_SECRET with HKDF under its own label, so that a token signed
+// for one purpose never verifies for the other
+func passSigningSecret() string {
+	if secret := os.Getenv("PASS_SIGNING_SECRET"); secret != "" {
+		return secret
+	}
+	key := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(os.Getenv("JWT_SECRET")), nil, []byte(passSigningLabel)), key); err != nil {
+		log.Fatalf("Failed to derive pass signing key: %v", err)
+	}
+	return hex.EncodeToString(key)
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -84,9 +84,34 @@ func InitializeWithConfig(databaseURL string, config DatabaseConfig) (*gorm.DB,
 		&models.OHIPTransaction{},
 		&models.GuestPreference{},
 		&models.Outlet{},
+		&models.DiningTable{},
+		&models.TableTurn{},
+		&models.WaitlistEntry{},
+		&models.MenuItem{},
+		&models.Order{},
+		&models.OrderItem{},
+		&models.KitchenTicket{},
+		&models.AllergyAcknowledgement{},
+		&models.InventoryItem{},
+		&models.InventoryUsage{},
+		&models.InventoryMovement{},
+		&models.StaffingRatio{},
+		&models.PlanEmail{},
+		&models.DailyRollup{},
+		&models.HourlyRollup{},
+		&models.ReportSubscription{},
+		&models.ReportDelivery{},
+		&models.PropertyGroup{},
+		&models.PropertyGroupMember{},
+		&models.BreakfastPrice{},
+		&models.EligibilityRule{},
+		&models.ServiceCloseOut{},
 		&models.StaffComment{},
 		&models.AuditLog{},
 		&models.UserDevice{},
+		&models.IdempotencyKey{},
+		&models.SyncEvent{},
+		&models.BreakfastPass{},
 		&services.Notification{},
 		&services.NotificationPreference{},
 	)
@@ -100,6 +125,11 @@ func InitializeWithConfig(databaseURL string, config DatabaseConfig) (*gorm.DB,
 		fmt.Printf("Warning: Failed to create some indexes: %v\n", err)
 	}
 
+	// Properties set up before the price book keep their flat breakfast price
+	if err := SeedDefaultPrices(db); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
 
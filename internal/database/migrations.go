@@ -2,7 +2,10 @@ package database
 
 import (
 	"fmt"
+	"time"
+
 	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
 	
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -94,6 +97,15 @@ func CreateIndexes(db *gorm.DB) error {
 			SQL:   "CREATE INDEX IF NOT EXISTS idx_outlets_property_active ON outlets(property_id, is_active) WHERE is_active = 1",
 		},
 		
+		// Close-out indexes. A property close-out has no outlet, so outlets
+		// compare as 0 for its rows to collide too; re-opened close-outs are
+		// kept as history and left out.
+		{
+			Table: "service_close_outs",
+			Name:  "idx_close_outs_closed_day",
+			SQL:   "CREATE UNIQUE INDEX IF NOT EXISTS idx_close_outs_closed_day ON service_close_outs(property_id, COALESCE(outlet_id, 0), business_date) WHERE status = 'closed'",
+		},
+
 		// Audit log indexes
 		{
 			Table: "audit_logs",
@@ -170,6 +182,7 @@ func DropIndexes(db *gorm.DB) error {
 		"idx_comments_guest",
 		"idx_comments_unresolved",
 		"idx_outlets_property_active",
+		"idx_close_outs_closed_day",
 		"idx_audit_user_action",
 		"idx_audit_resource",
 		"idx_audit_date_range",
@@ -186,4 +199,37 @@ func DropIndexes(db *gorm.DB) error {
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
+// legacyCoverPrice is the flat price per cover charged before properties had
+// a price book
+const legacyCoverPrice = 25.00
+
+// SeedDefaultPrices gives every property that has never had a price book an
+// adult price at the flat rate it was charged before, so its visits are still
+// priced after upgrading. Properties whose entries were removed are left alone.
+func SeedDefaultPrices(db *gorm.DB) error {
+	var propertyIDs []string
+	err := db.Model(&models.Property{}).
+		Where("property_id NOT IN (?)", db.Unscoped().Model(&models.BreakfastPrice{}).Select("property_id")).
+		Pluck("property_id", &propertyIDs).Error
+	if err != nil {
+		return fmt.Errorf("failed to find properties without prices: %w", err)
+	}
+
+	for _, propertyID := range propertyIDs {
+		price := models.BreakfastPrice{
+			PropertyID:    propertyID,
+			GuestType:     "adult",
+			Price:         legacyCoverPrice,
+			EffectiveFrom: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		if err := db.Create(&price).Error; err != nil {
+			return fmt.Errorf("failed to seed breakfast price for property %s: %w", propertyID, err)
+		}
+		logging.WithFields(logrus.Fields{
+			"property_id": propertyID,
+			"price":       price.Price,
+		}).Info("Seeded default breakfast price")
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	logging.InitLogger(logging.LoggingConfig{Level: "error"})
+	os.Exit(m.Run())
+}
+
+// newTestDB opens a private in-memory database with the given models migrated
+func newTestDB(t *testing.T, values ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(values...); err != nil {
+		t.Fatalf("migrating database: %v", err)
+	}
+	return db
+}
+
+func TestSeedDefaultPrices(t *testing.T) {
+	db := newTestDB(t, &models.Property{}, &models.BreakfastPrice{})
+
+	for _, property := range []models.Property{
+		{PropertyID: "LEGACY", Name: "Legacy Hotel"},
+		{PropertyID: "PRICED", Name: "Priced Hotel"},
+		{PropertyID: "CLEARED", Name: "Cleared Hotel"},
+	} {
+		if err := db.Create(&property).Error; err != nil {
+			t.Fatalf("creating property: %v", err)
+		}
+	}
+	priced := models.BreakfastPrice{PropertyID: "PRICED", GuestType: "adult", Price: 30, EffectiveFrom: time.Now()}
+	cleared := models.BreakfastPrice{PropertyID: "CLEARED", GuestType: "adult", Price: 30, EffectiveFrom: time.Now()}
+	if err := db.Create(&priced).Error; err != nil {
+		t.Fatalf("creating price: %v", err)
+	}
+	if err := db.Create(&cleared).Error; err != nil {
+		t.Fatalf("creating price: %v", err)
+	}
+	db.Delete(&cleared)
+
+	// Seeding twice adds nothing the second time
+	for i := 0; i < 2; i++ {
+		if err := SeedDefaultPrices(db); err != nil {
+			t.Fatalf("SeedDefaultPrices: %v", err)
+		}
+	}
+
+	tests := []struct {
+		propertyID string
+		prices     []float64
+	}{
+		{"LEGACY", []float64{legacyCoverPrice}},
+		{"PRICED", []float64{30}},
+		{"CLEARED", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.propertyID, func(t *testing.T) {
+			var prices []models.BreakfastPrice
+			db.Where("property_id = ?", tt.propertyID).Find(&prices)
+			if len(prices) != len(tt.prices) {
+				t.Fatalf("%d prices, want %d", len(prices), len(tt.prices))
+			}
+			for i, price := range prices {
+				if price.Price != tt.prices[i] || price.GuestType != "adult" {
+					t.Errorf("price %d = %s %v, want adult %v", i, price.GuestType, price.Price, tt.prices[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCloseOutDayIndex(t *testing.T) {
+	db := newTestDB(t, &models.ServiceCloseOut{})
+	if err := CreateIndexes(db); err != nil {
+		t.Fatalf("CreateIndexes: %v", err)
+	}
+
+	date := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	terrace, lounge := uint(1), uint(2)
+	closeOut := func(outletID *uint, status string) error {
+		return db.Create(&models.ServiceCloseOut{PropertyID: "P1", OutletID: outletID, BusinessDate: date, Status: status, ClosedAt: time.Now()}).Error
+	}
+	for _, outletID := range []*uint{nil, &terrace, &lounge} {
+		if err := closeOut(outletID, "closed"); err != nil {
+			t.Fatalf("first close-out: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		outletID  *uint
+		status    string
+		duplicate bool
+	}{
+		{"property closed twice", nil, "closed", true},
+		{"outlet closed twice", &terrace, "closed", true},
+		{"re-opened property kept as history", nil, "reopened", false},
+		{"re-opened outlet kept as history", &lounge, "reopened", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := closeOut(tt.outletID, tt.status)
+			if tt.duplicate && err == nil {
+				t.Error("duplicate close-out was stored")
+			}
+			if !tt.duplicate && err != nil {
+				t.Errorf("close-out refused: %v", err)
+			}
+		})
+	}
+}
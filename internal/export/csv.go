@@ -0,0 +1,51 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// csvWriter writes tables as CSV, separated by a blank line
+type csvWriter struct {
+	out    io.Writer
+	writer *csv.Writer
+	tables int
+	rows   int
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{out: w, writer: csv.NewWriter(w)}
+}
+
+func (w *csvWriter) Table(title string, columns []string) error {
+	if w.tables > 0 {
+		if err := w.writer.Write(nil); err != nil {
+			return err
+		}
+	}
+	w.tables++
+	return w.writer.Write(columns)
+}
+
+func (w *csvWriter) Row(values ...interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = cellText(value)
+	}
+	if err := w.writer.Write(record); err != nil {
+		return err
+	}
+
+	w.rows++
+	if w.rows%flushEvery == 0 {
+		w.writer.Flush()
+		flush(w.out)
+	}
+	return w.writer.Error()
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	flush(w.out)
+	return w.writer.Error()
+}
@@ -0,0 +1,159 @@
+// Package export writes tabular reports as CSV, XLSX or PDF. Rows are written
+// to the underlying writer as they are produced, so large reports stream to
+// the client instead of being built in memory.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnknownFormat is returned for formats other than csv, xlsx and pdf
+var ErrUnknownFormat = errors.New("export: format must be csv, xlsx or pdf")
+
+// Format is an export file format
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+	PDF  Format = "pdf"
+)
+
+// flushEvery is how many rows are buffered before being flushed to the client
+const flushEvery = 500
+
+// ParseFormat parses a format query value
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(value))); format {
+	case CSV, XLSX, PDF:
+		return format, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case PDF:
+		return "application/pdf"
+	default:
+		return "text/csv"
+	}
+}
+
+// Filename returns a file name for a report in the format
+func (f Format) Filename(name string) string {
+	return fmt.Sprintf("%s.%s", name, f)
+}
+
+// Branding is shown in the header of every PDF page
+type Branding struct {
+	PropertyName string
+	Address      string
+	Title        string // Report name
+	Subtitle     string // Usually the date range covered
+}
+
+// Writer writes a document of one or more tables, one row at a time
+type Writer interface {
+	// Table starts a new table with a title and column headings
+	Table(title string, columns []string) error
+	// Row writes a row of the current table. Values may be strings, integers,
+	// floats, booleans, times or nil.
+	Row(values ...interface{}) error
+	// Close finishes the document; nothing is complete until it returns
+	Close() error
+}
+
+// NewWriter creates a writer for the format
+func NewWriter(w io.Writer, format Format, branding Branding) (Writer, error) {
+	switch format {
+	case CSV:
+		return newCSVWriter(w), nil
+	case XLSX:
+		return newXLSXWriter(w, branding), nil
+	case PDF:
+		return newPDFWriter(w, branding), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// flusher is implemented by writers, such as HTTP responses, that buffer output
+type flusher interface {
+	Flush()
+}
+
+// flush pushes buffered output on to the client, if the writer buffers it
+func flush(w io.Writer) {
+	if f, ok := w.(flusher); ok {
+		f.Flush()
+	}
+}
+
+// text formats a cell value for display
+func text(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format("2006-01-02 15:04:05")
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return text(*v)
+	case *uint:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatUint(uint64(*v), 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// cellText formats a cell value for a spreadsheet. Text that a spreadsheet
+// would read as a formula is prefixed with a quote; numbers are left alone.
+func cellText(value interface{}) string {
+	if n, ok := number(value); ok {
+		return n
+	}
+	cell := text(value)
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// number returns a numeric cell value, for formats that keep numbers apart from text
+func number(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case int, int64, uint, uint64:
+		return fmt.Sprint(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
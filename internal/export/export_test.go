@@ -0,0 +1,361 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testBranding = Branding{
+	PropertyName: "Harbour Hotel",
+	Address:      "1 Quay Street",
+	Title:        "Breakfast Visits",
+	Subtitle:     "2026-10-01 to 2026-10-07",
+}
+
+var servedAt = time.Date(2026, 10, 3, 7, 45, 0, 0, time.UTC)
+
+// testTable is a report table written to every format
+type testTable struct {
+	title   string
+	columns []string
+	rows    [][]interface{}
+}
+
+var testTables = []testTable{
+	{
+		title:   "Visits",
+		columns: []string{"Room", "Guest", "Covers", "Amount", "VIP", "Served"},
+		rows: [][]interface{}{
+			{"101", "Smith, Anne", 2, 51.5, true, servedAt},
+			{"102", `Says "hi"`, uint(1), -12.25, false, nil},
+			{"103", "=HYPERLINK(\"http://evil\")", int64(0), 0.0, false, (*time.Time)(nil)},
+			{"104", "+1 555", 3, 1e6, true, &servedAt},
+			{"105", "-cmd", 1, 25.0, false, "@SUM(A1:A2)"},
+			{"106", "line one\nline two", 2, 10.1, false, "\tindented"},
+		},
+	},
+	{
+		title:   "Totals",
+		columns: []string{"Metric", "Value"},
+		rows: [][]interface{}{
+			{"Covers", 9},
+			{"Revenue", 1000074.35},
+		},
+	},
+}
+
+// wantCells is what a spreadsheet shows for each row: text with formulas
+// quoted, and numbers as written
+var wantCells = [][][]string{
+	{
+		{"101", "Smith, Anne", "2", "51.5", "yes", "2026-10-03 07:45:00"},
+		{"102", `Says "hi"`, "1", "-12.25", "no", ""},
+		{"103", "'=HYPERLINK(\"http://evil\")", "0", "0", "no", ""},
+		{"104", "'+1 555", "3", "1000000", "yes", "2026-10-03 07:45:00"},
+		{"105", "'-cmd", "1", "25", "no", "'@SUM(A1:A2)"},
+		{"106", "line one\nline two", "2", "10.1", "no", "'\tindented"},
+	},
+	{
+		{"Covers", "9"},
+		{"Revenue", "1000074.35"},
+	},
+}
+
+func writeReport(t *testing.T, format Format) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, format, testBranding)
+	if err != nil {
+		t.Fatalf("NewWriter(%s): %v", format, err)
+	}
+	for _, table := range testTables {
+		if err := w.Table(table.title, table.columns); err != nil {
+			t.Fatalf("Table(%q): %v", table.title, err)
+		}
+		for _, row := range table.rows {
+			if err := w.Row(row...); err != nil {
+				t.Fatalf("Row(%v): %v", row, err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseFormat(t *testing.T) {
+	for value, want := range map[string]Format{"csv": CSV, " XLSX ": XLSX, "Pdf": PDF} {
+		if got, err := ParseFormat(value); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("docx"); err != ErrUnknownFormat {
+		t.Errorf("ParseFormat(docx) error = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	reader := csv.NewReader(bytes.NewReader(writeReport(t, CSV)))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+
+	// Tables follow each other with a blank record between them, which the
+	// reader skips, so each table is its headings followed by its rows
+	var want [][]string
+	for i, table := range testTables {
+		want = append(want, table.columns)
+		want = append(want, wantCells[i]...)
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Fatalf("CSV records =\n%q\nwant\n%q", records, want)
+	}
+}
+
+// xlsxSheet is the part of a worksheet the round trip reads back
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string `xml:"r,attr"`
+			T      string `xml:"t,attr"`
+			S      int    `xml:"s,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestXLSXRoundTrip(t *testing.T) {
+	data := writeReport(t, XLSX)
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("opening workbook: %v", err)
+	}
+
+	parts := make(map[string][]byte)
+	for _, file := range archive.File {
+		f, err := file.Open()
+		if err != nil {
+			t.Fatalf("opening %s: %v", file.Name, err)
+		}
+		body, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("reading %s: %v", file.Name, err)
+		}
+		parts[file.Name] = body
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		body, ok := parts[name]
+		if !ok {
+			t.Fatalf("workbook is missing %s", name)
+		}
+		if err := xml.Unmarshal(body, new(interface{})); err != nil {
+			t.Fatalf("%s is not well-formed XML: %v", name, err)
+		}
+	}
+	if !bytes.Contains(parts["xl/workbook.xml"], []byte(`<sheet name="Breakfast Visits"`)) {
+		t.Errorf("workbook does not name the sheet after the report: %s", parts["xl/workbook.xml"])
+	}
+
+	var sheet xlsxSheet
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatalf("parsing worksheet: %v", err)
+	}
+
+	// Each table is its title and headings in bold, then its rows; a blank
+	// row separates tables
+	var want [][]string
+	var bold []bool
+	for i, table := range testTables {
+		if i > 0 {
+			want = append(want, nil)
+			bold = append(bold, false)
+		}
+		want = append(want, []string{table.title}, table.columns)
+		bold = append(bold, true, true)
+		for _, row := range wantCells[i] {
+			want = append(want, row)
+			bold = append(bold, false)
+		}
+	}
+
+	got := make([][]string, len(want))
+	for _, row := range sheet.Rows {
+		if row.R < 1 || row.R > len(want) {
+			t.Fatalf("unexpected row %d", row.R)
+		}
+		for _, cell := range row.Cells {
+			column := 0
+			for _, letter := range strings.TrimRight(cell.R, "0123456789") {
+				column = column*26 + int(letter-'A') + 1
+			}
+			if wantRef := fmt.Sprintf("%s%d", columnName(column-1), row.R); cell.R != wantRef {
+				t.Errorf("cell reference %s does not round-trip, got %s", cell.R, wantRef)
+			}
+			for len(got[row.R-1]) < column {
+				got[row.R-1] = append(got[row.R-1], "")
+			}
+			value := cell.Value
+			if cell.T == "inlineStr" {
+				value = cell.Inline
+				if (cell.S == 1) != bold[row.R-1] {
+					t.Errorf("cell %s style = %d, want bold %v", cell.R, cell.S, bold[row.R-1])
+				}
+			} else if _, err := strconv.ParseFloat(value, 64); err != nil {
+				t.Errorf("numeric cell %s = %q is not a number", cell.R, value)
+			}
+			got[row.R-1][column-1] = value
+		}
+	}
+
+	// Empty cells are left out of the sheet, so trim them from the expected rows
+	for i := range want {
+		row := append([]string(nil), want[i]...)
+		for len(row) > 0 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+		want[i] = row
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("worksheet rows =\n%q\nwant\n%q", got, want)
+	}
+}
+
+var (
+	pdfObject  = regexp.MustCompile(`(?m)^(\d+) 0 obj\n`)
+	pdfXref    = regexp.MustCompile(`(?s)xref\n0 (\d+)\n(.*?)trailer\n`)
+	pdfStart   = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+	pdfCount   = regexp.MustCompile(`/Type /Pages /Kids \[[^\]]*\] /Count (\d+)`)
+	pdfShowing = regexp.MustCompile(`\(((?:\\.|[^\\)])*)\) Tj`)
+	pdfLength  = regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n`)
+)
+
+func TestPDFStructure(t *testing.T) {
+	data := writeReport(t, PDF)
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) {
+		t.Fatalf("PDF does not start with a header: %q", data[:16])
+	}
+
+	// The cross-reference table must point at every object
+	start := pdfStart.FindSubmatch(data)
+	if start == nil {
+		t.Fatal("PDF does not end with startxref and EOF")
+	}
+	xrefAt, _ := strconv.Atoi(string(start[1]))
+	xref := pdfXref.FindSubmatchIndex(data)
+	if xref == nil || xref[0] != xrefAt {
+		t.Fatalf("startxref %d does not point at the xref table", xrefAt)
+	}
+	size, _ := strconv.Atoi(string(data[xref[2]:xref[3]]))
+	entries := strings.Split(strings.TrimSuffix(string(data[xref[4]:xref[5]]), "\n"), "\n")
+	if len(entries) != size {
+		t.Fatalf("xref has %d entries, want %d", len(entries), size)
+	}
+	offsets := make(map[int]int)
+	for _, match := range pdfObject.FindAllSubmatchIndex(data, -1) {
+		number, _ := strconv.Atoi(string(data[match[2]:match[3]]))
+		offsets[number] = match[0]
+	}
+	if len(offsets) != size-1 {
+		t.Fatalf("PDF has %d objects, xref lists %d", len(offsets), size-1)
+	}
+	for number := 1; number < size; number++ {
+		var offset int
+		fmt.Sscanf(entries[number], "%010d", &offset)
+		if offsets[number] != offset {
+			t.Errorf("xref offset of object %d = %d, object is at %d", number, offset, offsets[number])
+		}
+	}
+
+	// Content streams must be exactly as long as they say
+	for _, match := range pdfLength.FindAllSubmatchIndex(data, -1) {
+		length, _ := strconv.Atoi(string(data[match[2]:match[3]]))
+		if !bytes.HasPrefix(data[match[1]+length:], []byte("endstream")) {
+			t.Errorf("stream at %d is not %d bytes long", match[0], length)
+		}
+	}
+	if count := pdfCount.FindSubmatch(data); count == nil || string(count[1]) != "1" {
+		t.Errorf("page count = %s, want 1", count)
+	}
+
+	// Every value is shown as written; formulas are not quoted in a PDF
+	shown := make(map[string]bool)
+	for _, match := range pdfShowing.FindAllSubmatch(data, -1) {
+		shown[string(match[1])] = true
+	}
+	for _, want := range []string{
+		"Harbour Hotel", "1 Quay Street", "Breakfast Visits - 2026-10-01 to 2026-10-07",
+		"Visits", "Totals", "Room", "Smith, Anne", "51.5", "-12.25", "2026-10-03 07:45:00",
+		`=HYPERLINK\("http://evil"\)`, "+1 555", "-cmd", "@SUM\\(A1:A2\\)", "line one line two", "Page 1",
+	} {
+		if !shown[want] {
+			t.Errorf("PDF does not show %q", want)
+		}
+	}
+}
+
+func TestPDFPages(t *testing.T) {
+	var buf bytes.Buffer
+	w := newPDFWriter(&buf, testBranding)
+	if err := w.Table("Visits", []string{"Room", "Covers"}); err != nil {
+		t.Fatalf("Table: %v", err)
+	}
+	for i := 0; i < 200; i++ {
+		if err := w.Row(strconv.Itoa(100+i), i); err != nil {
+			t.Fatalf("Row: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	count := pdfCount.FindSubmatch(buf.Bytes())
+	if count == nil {
+		t.Fatal("PDF has no page tree")
+	}
+	pages, _ := strconv.Atoi(string(count[1]))
+	if pages < 2 || pages != len(w.pages) {
+		t.Fatalf("page count = %d, want the %d pages written and more than one", pages, len(w.pages))
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(fmt.Sprintf("(Page %d) Tj", pages))) {
+		t.Errorf("last page is not numbered %d", pages)
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	tests := map[string]string{
+		"plain":        "plain",
+		"(a) \\ b":     `\(a\) \\ b`,
+		"café – 5 €":   `caf\351 \226 5 \200`,
+		"tab\there":    "tab here",
+		"emoji 🍳 eggs": "emoji ? eggs",
+	}
+	for value, want := range tests {
+		if got := pdfString(value); got != want {
+			t.Errorf("pdfString(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(index); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", index, got, want)
+		}
+	}
+}
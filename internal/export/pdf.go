@@ -0,0 +1,284 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// A4 landscape page layout, in points
+const (
+	pdfPageWidth  = 842.0
+	pdfPageHeight = 595.0
+	pdfMargin     = 36.0
+	pdfRowHeight  = 14.0
+	pdfFontSize   = 8.0
+	pdfCharWidth  = 0.5 // Average Helvetica glyph width, in ems
+)
+
+// Objects written before the pages; the page tree and catalog come last,
+// once every page is known
+const (
+	pdfCatalogObject = 1
+	pdfPagesObject   = 2
+	pdfFontObject    = 3
+	pdfBoldObject    = 4
+)
+
+// pdfWriter lays tables out on branded A4 pages. Each page is written as soon
+// as it is full, so only one page is ever held in memory.
+type pdfWriter struct {
+	out      *countingWriter
+	branding Branding
+	created  time.Time
+	offsets  map[int]int64
+	next     int   // Next free object number
+	pages    []int // Page object numbers
+	page     *bytes.Buffer
+	y        float64
+	title    string
+	columns  []string
+	err      error
+}
+
+func newPDFWriter(w io.Writer, branding Branding) *pdfWriter {
+	return &pdfWriter{
+		out:      &countingWriter{w: w},
+		branding: branding,
+		created:  time.Now(),
+		offsets:  make(map[int]int64),
+		next:     pdfBoldObject + 1,
+	}
+}
+
+func (w *pdfWriter) Table(title string, columns []string) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	w.title, w.columns = title, columns
+
+	// Keep a table's title and headings with at least one of its rows
+	if w.page == nil || w.y-3*pdfRowHeight-6 < pdfMargin+pdfRowHeight {
+		w.newPage()
+	} else {
+		w.y -= 6
+	}
+	if title != "" {
+		w.text(pdfMargin, w.y, 10, true, title)
+		w.y -= pdfRowHeight
+	}
+	w.headings()
+	return w.err
+}
+
+func (w *pdfWriter) Row(values ...interface{}) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if w.page == nil || w.y-pdfRowHeight < pdfMargin+pdfRowHeight {
+		w.newPage()
+		w.headings()
+	}
+
+	width := w.columnWidth()
+	for i, value := range values {
+		w.text(pdfMargin+float64(i)*width+2, w.y-10, pdfFontSize, false, fit(text(value), width-4, pdfFontSize))
+	}
+	w.y -= pdfRowHeight
+	return w.err
+}
+
+func (w *pdfWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if w.page == nil {
+		w.newPage()
+	}
+	w.endPage()
+
+	kids := make([]string, len(w.pages))
+	for i, page := range w.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	w.object(pdfPagesObject, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(w.pages)))
+	w.object(pdfCatalogObject, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObject))
+	if w.err != nil {
+		return w.err
+	}
+
+	xref := w.out.n
+	fmt.Fprintf(w.out, "xref\n0 %d\n0000000000 65535 f \n", w.next)
+	for object := 1; object < w.next; object++ {
+		fmt.Fprintf(w.out, "%010d 00000 n \n", w.offsets[object])
+	}
+	fmt.Fprintf(w.out, "trailer\n<< /Size %d /Root %d 0 R /Info << /Title (%s) /Producer (Hudini Breakfast) >> >>\nstartxref\n%d\n%%%%EOF\n",
+		w.next, pdfCatalogObject, pdfString(w.branding.Title), xref)
+	if w.out.err != nil {
+		return w.out.err
+	}
+	flush(w.out.w)
+	return nil
+}
+
+// start writes the file header and fonts, once
+func (w *pdfWriter) start() error {
+	if w.out.n > 0 || w.err != nil {
+		return w.err
+	}
+	io.WriteString(w.out, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	w.object(pdfFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	w.object(pdfBoldObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	return w.err
+}
+
+// newPage finishes the current page and starts another under the property header
+func (w *pdfWriter) newPage() {
+	if w.page != nil {
+		w.endPage()
+	}
+	w.page = &bytes.Buffer{}
+	y := pdfPageHeight - pdfMargin
+
+	name := w.branding.PropertyName
+	w.text(pdfMargin, y-14, 16, true, name)
+	if w.branding.Address != "" {
+		w.text(pdfMargin, y-27, 9, false, w.branding.Address)
+	}
+	title := w.branding.Title
+	if w.branding.Subtitle != "" {
+		title += " - " + w.branding.Subtitle
+	}
+	w.textRight(pdfPageWidth-pdfMargin, y-14, 12, true, title)
+	w.textRight(pdfPageWidth-pdfMargin, y-27, 8, false, "Generated "+w.created.Format("2006-01-02 15:04 MST"))
+	fmt.Fprintf(w.page, "0.5 w %.2f %.2f m %.2f %.2f l S\n", pdfMargin, y-34, pdfPageWidth-pdfMargin, y-34)
+
+	w.textRight(pdfPageWidth-pdfMargin, pdfMargin-14, 8, false, fmt.Sprintf("Page %d", len(w.pages)+1))
+	w.y = y - 44
+}
+
+// headings writes the current table's column headings on a shaded row
+func (w *pdfWriter) headings() {
+	if len(w.columns) == 0 {
+		return
+	}
+	fmt.Fprintf(w.page, "0.9 g %.2f %.2f %.2f %.2f re f 0 g\n", pdfMargin, w.y-pdfRowHeight, pdfPageWidth-2*pdfMargin, pdfRowHeight)
+	width := w.columnWidth()
+	for i, column := range w.columns {
+		w.text(pdfMargin+float64(i)*width+2, w.y-10, pdfFontSize, true, fit(column, width-4, pdfFontSize))
+	}
+	w.y -= pdfRowHeight
+}
+
+// endPage writes the current page's content stream and page object
+func (w *pdfWriter) endPage() {
+	content, page := w.next, w.next+1
+	w.next += 2
+	w.object(content, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", w.page.Len(), w.page.String()))
+	w.object(page, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.0f %.0f] /Contents %d 0 R /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> >>",
+		pdfPagesObject, pdfPageWidth, pdfPageHeight, content, pdfFontObject, pdfBoldObject))
+	w.pages = append(w.pages, page)
+	w.page = nil
+	if w.err == nil {
+		flush(w.out.w)
+	}
+}
+
+func (w *pdfWriter) object(number int, body string) {
+	if w.err != nil {
+		return
+	}
+	w.offsets[number] = w.out.n
+	fmt.Fprintf(w.out, "%d 0 obj\n%s\nendobj\n", number, body)
+	w.err = w.out.err
+}
+
+func (w *pdfWriter) text(x, y, size float64, bold bool, value string) {
+	if value == "" {
+		return
+	}
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(w.page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(value))
+}
+
+func (w *pdfWriter) textRight(x, y, size float64, bold bool, value string) {
+	w.text(x-textWidth(value, size), y, size, bold, value)
+}
+
+func (w *pdfWriter) columnWidth() float64 {
+	if len(w.columns) == 0 {
+		return pdfPageWidth - 2*pdfMargin
+	}
+	return (pdfPageWidth - 2*pdfMargin) / float64(len(w.columns))
+}
+
+// fit shortens a value to the width of a column
+func fit(value string, width, size float64) string {
+	if textWidth(value, size) <= width {
+		return value
+	}
+	runes := []rune(value)
+	for len(runes) > 0 && textWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+func textWidth(value string, size float64) float64 {
+	return float64(len([]rune(value))) * size * pdfCharWidth
+}
+
+// pdfString encodes a value as the body of a PDF literal string in WinAnsi
+func pdfString(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		c, ok := winAnsi[r]
+		switch {
+		case ok:
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			c = byte(r)
+		case r == '\n' || r == '\t':
+			c = ' '
+		default:
+			c = '?'
+		}
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c >= 0x80:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// winAnsi maps the punctuation WinAnsiEncoding places outside Latin-1
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '•': 0x95, '–': 0x96, '—': 0x97,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '™': 0x99,
+}
+
+// countingWriter tracks the byte offsets PDF cross-references need
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
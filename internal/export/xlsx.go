@@ -0,0 +1,198 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The fixed parts of a single-sheet workbook. Style 1 is bold, for titles and headings.
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// xlsxWriter writes tables one under the other on a single worksheet. The
+// worksheet is the last part of the archive, so its rows stream straight out.
+type xlsxWriter struct {
+	out      io.Writer
+	zip      *zip.Writer
+	sheet    io.Writer
+	branding Branding
+	row      int
+	err      error
+}
+
+func newXLSXWriter(w io.Writer, branding Branding) *xlsxWriter {
+	return &xlsxWriter{out: w, zip: zip.NewWriter(w), branding: branding}
+}
+
+func (w *xlsxWriter) Table(title string, columns []string) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if w.row > 0 {
+		w.row++ // Blank row between tables
+	}
+	if title != "" {
+		w.writeRow(1, title)
+	}
+	headings := make([]interface{}, len(columns))
+	for i, column := range columns {
+		headings[i] = column
+	}
+	w.writeRow(1, headings...)
+	return w.err
+}
+
+func (w *xlsxWriter) Row(values ...interface{}) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	w.writeRow(0, values...)
+	if w.err == nil && w.row%flushEvery == 0 {
+		w.err = w.zip.Flush()
+		flush(w.out)
+	}
+	return w.err
+}
+
+func (w *xlsxWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w.sheet, xlsxSheetEnd); err != nil {
+		return err
+	}
+	if err := w.zip.Close(); err != nil {
+		return err
+	}
+	flush(w.out)
+	return nil
+}
+
+// start writes the workbook parts ahead of the worksheet, once
+func (w *xlsxWriter) start() error {
+	if w.sheet != nil || w.err != nil {
+		return w.err
+	}
+
+	workbook := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`+
+		`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`, escapeXML(sheetName(w.branding.Title)))
+	for _, part := range []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	} {
+		f, err := w.zip.Create(part.name)
+		if err != nil {
+			w.err = err
+			return err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			w.err = err
+			return err
+		}
+	}
+
+	sheet, err := w.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		w.err = err
+		return err
+	}
+	if _, err := io.WriteString(sheet, xlsxSheetStart); err != nil {
+		w.err = err
+		return err
+	}
+	w.sheet = sheet
+	return nil
+}
+
+// writeRow writes the next row in a cell style; numbers are kept as numbers
+func (w *xlsxWriter) writeRow(style int, values ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	w.row++
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="%d">`, w.row)
+	for i, value := range values {
+		ref := fmt.Sprintf("%s%d", columnName(i), w.row)
+		if n, ok := number(value); ok && style == 0 {
+			fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, n)
+			continue
+		}
+		cell := cellText(value)
+		if cell == "" {
+			continue
+		}
+		fmt.Fprintf(&b, `<c r="%s" t="inlineStr" s="%d"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escapeXML(cell))
+	}
+	b.WriteString(`</row>`)
+	_, w.err = io.WriteString(w.sheet, b.String())
+}
+
+// columnName returns the spreadsheet letters of a zero-based column index
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// sheetName makes a title a valid worksheet name
+func sheetName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(title))
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if name == "" {
+		name = "Report"
+	}
+	return name
+}
+
+func escapeXML(value string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"hudini-breakfast-module/internal/config"
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// IdempotencyKeyHeader is the request header carrying the client-chosen idempotency key
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type IdempotencyStore struct {
+	db    *gorm.DB
+	ttl   time.Duration
+	lease time.Duration
+}
+
+func NewIdempotencyStore(db *gorm.DB, cfg config.IdempotencyConfig) *IdempotencyStore {
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour // Default
+	}
+	lease := cfg.Lease
+	if lease <= 0 {
+		lease = 5 * time.Minute
+	}
+
+	store := &IdempotencyStore{
+		db:    db,
+		ttl:   ttl,
+		lease: lease,
+	}
+
+	// Purge expired keys every hour
+	go store.cleanup()
+
+	return store
+}
+
+func (s *IdempotencyStore) cleanup() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.db.Where("expires_at < ?", time.Now()).Delete(&models.IdempotencyKey{}).Error; err != nil {
+			logging.WithError(err).Warn("Failed to purge expired idempotency keys")
+		}
+	}
+}
+
+// idempotencyWriter captures the response body so it can be replayed
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyWriter) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// Idempotency replays the stored response for mutating requests that repeat an
+// Idempotency-Key, so retried requests do not run their side effects twice.
+// It must run after authentication, as keys are scoped to the calling user.
+func Idempotency(store *IdempotencyStore) gin.HandlerFunc {
+	db := store.db
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > 255 {
+			abortIdempotency(c, http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY", "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortIdempotency(c, http.StatusBadRequest, "INVALID_REQUEST", "Failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		record := models.IdempotencyKey{
+			UserID:      c.GetUint("user_id"),
+			Key:         key,
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.RequestURI(),
+			RequestHash: requestHash,
+			Status:      "processing",
+			ExpiresAt:   time.Now().Add(store.ttl),
+		}
+
+		// The unique index on (user, key) lets only one request claim the key
+		if err := store.claim(&record); err != nil {
+			var existing models.IdempotencyKey
+			if err := db.Where("user_id = ? AND idempotency_key = ?", record.UserID, key).First(&existing).Error; err != nil {
+				logging.WithError(err).Error("Failed to load idempotency key")
+				abortIdempotency(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to check idempotency key")
+				return
+			}
+
+			switch {
+			case existing.RequestHash != requestHash:
+				abortIdempotency(c, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was already used for a different request")
+			case existing.Status != "completed":
+				abortIdempotency(c, http.StatusConflict, "IDEMPOTENCY_IN_PROGRESS", "A request with this Idempotency-Key is still being processed")
+			default:
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(existing.StatusCode, existing.ContentType, []byte(existing.ResponseBody))
+				c.Abort()
+			}
+			return
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		// Release the key if the handler panics or the response is not stored,
+		// so the retry runs the request instead of waiting out the TTL
+		completed := false
+		defer func() {
+			if !completed {
+				db.Delete(&record)
+			}
+		}()
+
+		c.Next()
+
+		// Server errors are not stored so the client can safely retry them
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		err = db.Model(&record).Updates(map[string]interface{}{
+			"status":        "completed",
+			"status_code":   status,
+			"content_type":  writer.Header().Get("Content-Type"),
+			"response_body": writer.body.String(),
+		}).Error
+		if err != nil {
+			logging.WithError(err).Error("Failed to store idempotent response")
+			return
+		}
+		completed = true
+	}
+}
+
+// claim inserts the key, replacing an expired record with the same key. A key
+// still processing after the lease is also replaced, as the request holding it
+// was lost, for example when the server restarted part way through.
+func (s *IdempotencyStore) claim(record *models.IdempotencyKey) error {
+	now := time.Now()
+	s.db.Where("user_id = ? AND idempotency_key = ?", record.UserID, record.Key).
+		Where("expires_at < ? OR (status = 'processing' AND created_at < ?)", now, now.Add(-s.lease)).
+		Delete(&models.IdempotencyKey{})
+	return s.db.Create(record).Error
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func abortIdempotency(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"success": false,
+		"error": gin.H{
+			"code":    code,
+			"message": message,
+		},
+		"timestamp": time.Now(),
+	})
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"hudini-breakfast-module/internal/config"
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	logging.InitLogger(logging.LoggingConfig{Level: "error"})
+	os.Exit(m.Run())
+}
+
+// newIdempotencyRouter serves POST /visits behind the idempotency middleware.
+// The handler answers each call with the next status in statuses, or panics
+// for a status of 0, and the returned counter tracks how often it ran.
+func newIdempotencyRouter(t *testing.T, statuses ...int) (*gin.Engine, *gorm.DB, *int) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&models.IdempotencyKey{}); err != nil {
+		t.Fatalf("migrating database: %v", err)
+	}
+
+	calls := 0
+	router := gin.New()
+	router.Use(gin.Recovery(), func(c *gin.Context) {
+		userID, _ := strconv.Atoi(c.GetHeader("X-User"))
+		c.Set("user_id", uint(userID))
+	})
+	router.Use(Idempotency(NewIdempotencyStore(db, config.IdempotencyConfig{Lease: time.Minute})))
+	router.POST("/visits", func(c *gin.Context) {
+		calls++
+		status := statuses[len(statuses)-1]
+		if calls <= len(statuses) {
+			status = statuses[calls-1]
+		}
+		if status == 0 {
+			panic("handler failed")
+		}
+		c.JSON(status, gin.H{"visit": calls})
+	})
+	return router, db, &calls
+}
+
+type idempotentRequest struct {
+	user, key, body string
+}
+
+func (r idempotentRequest) send(router *gin.Engine) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/visits", strings.NewReader(r.body))
+	req.Header.Set("X-User", r.user)
+	if r.key != "" {
+		req.Header.Set(IdempotencyKeyHeader, r.key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency(t *testing.T) {
+	first := idempotentRequest{"1", "key-1", `{"room":"101"}`}
+
+	tests := []struct {
+		name     string
+		statuses []int
+		retry    idempotentRequest
+		status   int
+		body     string
+		replayed bool
+		calls    int
+	}{
+		{"retry replays the stored response", []int{201}, first, 201, `{"visit":1}`, true, 1},
+		{"client errors are replayed too", []int{409}, first, 409, `{"visit":1}`, true, 1},
+		{"key reused for another request", []int{201}, idempotentRequest{"1", "key-1", `{"room":"102"}`}, 422, "IDEMPOTENCY_KEY_REUSED", false, 1},
+		{"same key from another user", []int{201}, idempotentRequest{"2", "key-1", `{"room":"101"}`}, 201, `{"visit":2}`, false, 2},
+		{"without a key", []int{201}, idempotentRequest{"1", "", `{"room":"101"}`}, 201, `{"visit":2}`, false, 2},
+		{"server error releases the key", []int{500, 201}, first, 201, `{"visit":2}`, false, 2},
+		{"panic releases the key", []int{0, 201}, first, 201, `{"visit":2}`, false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _, calls := newIdempotencyRouter(t, tt.statuses...)
+			first.send(router)
+
+			w := tt.retry.send(router)
+			if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.body) {
+				t.Errorf("retry = %d %s, want %d %s", w.Code, w.Body.String(), tt.status, tt.body)
+			}
+			if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != tt.replayed {
+				t.Errorf("replayed = %v, want %v", replayed, tt.replayed)
+			}
+			if *calls != tt.calls {
+				t.Errorf("handler ran %d times, want %d", *calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeyInProgress(t *testing.T) {
+	request := idempotentRequest{"1", "key-1", `{"room":"101"}`}
+
+	tests := []struct {
+		name    string
+		claimed time.Duration
+		status  int
+		calls   int
+	}{
+		{"still within the lease", -10 * time.Second, http.StatusConflict, 0},
+		{"lease ran out", -2 * time.Minute, http.StatusCreated, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, db, calls := newIdempotencyRouter(t, http.StatusCreated)
+
+			// Another request claimed the key and has not finished
+			request.send(router)
+			db.Model(&models.IdempotencyKey{}).Where("idempotency_key = ?", request.key).
+				Updates(map[string]interface{}{"status": "processing", "created_at": time.Now().Add(tt.claimed)})
+			*calls = 0
+
+			if w := request.send(router); w.Code != tt.status {
+				t.Errorf("retry = %d %s, want %d", w.Code, w.Body.String(), tt.status)
+			}
+			if *calls != tt.calls {
+				t.Errorf("handler ran %d times, want %d", *calls, tt.calls)
+			}
+		})
+	}
+}
@@ -13,6 +13,10 @@ type Property struct {
 	Address      string    `json:"address"`
 	TotalRooms   int       `json:"total_rooms"`
 	FloorCount   int       `json:"floor_count"`
+	TimeZone     string    `json:"time_zone" gorm:"default:'UTC'"` // IANA zone, e.g. Asia/Tokyo
+	CutoverHour  int       `json:"cutover_hour" gorm:"default:0"`  // Local hour at which the business date rolls over
+	Region       string    `json:"region" gorm:"index"`            // Portfolio tag, e.g. "EMEA North"
+	Brand        string    `json:"brand" gorm:"index"`             // Portfolio tag, e.g. "Hudini Resorts"
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -46,6 +50,9 @@ type Guest struct {
 	ChildCount      int       `json:"child_count" gorm:"default:0"`
 	BreakfastPackage bool     `json:"breakfast_package" gorm:"default:false"`
 	BreakfastCount   int      `json:"breakfast_count" gorm:"default:0"` // Number of breakfasts included
+	ChildAges       string    `json:"child_ages"` // Comma-separated ages, e.g. "4,9"
+	RateCode        string    `json:"rate_code"`
+	LoyaltyTier     string    `json:"loyalty_tier"`
 	OHIPNumber      string    `json:"ohip_number"`
 	PropertyID      string    `json:"property_id" gorm:"not null"`
 	IsActive        bool      `json:"is_active" gorm:"default:true"`
@@ -85,14 +92,34 @@ type DailyBreakfastConsumption struct {
 	ConsumedAt       *time.Time       `json:"consumed_at,omitempty"` // Actual timestamp when consumed
 	ConsumedBy       *uint            `json:"consumed_by,omitempty"` // Staff member who marked it
 	Staff            *Staff           `json:"staff,omitempty" gorm:"foreignKey:ConsumedBy"`
-	Status           string           `json:"status" gorm:"default:'available'"` // available, consumed, no_show
+	ArrivedAt        *time.Time       `json:"arrived_at,omitempty"` // Visit lifecycle, captured from the staff app
+	SeatedAt         *time.Time       `json:"seated_at,omitempty"`
+	FirstServedAt    *time.Time       `json:"first_served_at,omitempty"`
+	LeftAt           *time.Time       `json:"left_at,omitempty"`
+	ServedBy         *uint            `json:"served_by,omitempty"` // Staff member who first served the party
+	OutletID         *uint            `json:"outlet_id,omitempty" gorm:"index"` // Outlet where the breakfast was served
+	Outlet           *Outlet          `json:"outlet,omitempty" gorm:"foreignKey:OutletID"`
+	Status           string           `json:"status" gorm:"default:'available'"` // available, consumed, no_show, void_pending, voided
+	AdultCovers      int              `json:"adult_covers" gorm:"default:0"`
+	ChildCovers      int              `json:"child_covers" gorm:"default:0"`
+	UpsellCovers     int              `json:"upsell_covers" gorm:"default:0"` // Covers served beyond the daily entitlement
 	Notes            string           `json:"notes"`
 	PaymentMethod    string           `json:"payment_method"` // room_charge, ohip, comp, cash
 	OHIPCovered      bool             `json:"ohip_covered" gorm:"default:false"`
 	OHIPTransaction  *OHIPTransaction `json:"ohip_transaction,omitempty" gorm:"foreignKey:ConsumptionID"`
 	PMSPosted        bool             `json:"pms_posted" gorm:"default:false"`
 	PMSTransactionID string           `json:"pms_transaction_id"`
-	Amount           float64          `json:"amount"`
+	Amount           float64          `json:"amount"` // Net of service charge and tax
+	PriceID          *uint            `json:"price_id,omitempty"` // Price book entry used for adult covers
+	AdultPrice       float64          `json:"adult_price"`
+	ChildPrice       float64          `json:"child_price"`
+	ServiceCharge    float64          `json:"service_charge"`
+	TaxAmount        float64          `json:"tax_amount"`
+	VoidedAt         *time.Time       `json:"voided_at,omitempty"`
+	VoidedBy         *uint            `json:"voided_by,omitempty"`
+	VoidReason       string           `json:"void_reason,omitempty"`
+	CloseOutID       *uint            `json:"close_out_id,omitempty"` // Close-out that recorded this no-show
+	PassID           *uint            `json:"pass_id,omitempty" gorm:"index"` // Breakfast pass redeemed for this visit
 	CreatedAt        time.Time        `json:"created_at"`
 	UpdatedAt        time.Time        `json:"updated_at"`
 	DeletedAt        gorm.DeletedAt   `json:"-" gorm:"index"`
@@ -104,7 +131,7 @@ type OHIPTransaction struct {
 	OHIPNumber        string    `json:"ohip_number" gorm:"not null"`
 	TransactionType   string    `json:"transaction_type"` // claim, refund, adjustment
 	Amount            float64   `json:"amount" gorm:"not null"`
-	Status            string    `json:"status"`           // pending, approved, denied, processed
+	Status            string    `json:"status"`           // pending, approved, denied, processed, cancelled
 	OHIPResponseCode  string    `json:"ohip_response_code"`
 	OHIPMessage       string    `json:"ohip_message"`
 	SubmittedAt       time.Time `json:"submitted_at"`
@@ -130,20 +157,417 @@ type GuestPreference struct {
 
 // Outlet represents a dining outlet that accepts breakfast packages
 type Outlet struct {
-	ID              uint           `json:"id" gorm:"primaryKey"`
-	PropertyID      string         `json:"property_id" gorm:"not null"`
-	Property        Property       `json:"property" gorm:"foreignKey:PropertyID;references:PropertyID"`
-	Name            string         `json:"name" gorm:"not null"`
-	Location        string         `json:"location"`
-	AcceptsPackage  bool           `json:"accepts_breakfast_package" gorm:"default:true"`
-	OpenTime        string         `json:"open_time"` // Format: "06:30"
-	CloseTime       string         `json:"close_time"` // Format: "10:30"
-	Capacity        int            `json:"capacity"`
-	MenuType        string         `json:"menu_type"` // buffet, a_la_carte, continental
-	IsActive        bool           `json:"is_active" gorm:"default:true"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
-	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	PropertyID       string         `json:"property_id" gorm:"not null"`
+	Property         Property       `json:"property" gorm:"foreignKey:PropertyID;references:PropertyID"`
+	Name             string         `json:"name" gorm:"not null"`
+	Location         string         `json:"location"`
+	AcceptsPackage   bool           `json:"accepts_breakfast_package" gorm:"default:true"`
+	OpenTime         string         `json:"open_time"`  // Format: "06:30"
+	CloseTime        string         `json:"close_time"` // Format: "10:30"
+	Capacity         int            `json:"capacity"`
+	MenuType         string         `json:"menu_type"`                   // buffet, a_la_carte, continental
+	PackageAllowance *float64       `json:"package_allowance,omitempty"` // À-la-carte value covered per package cover; nil uses the adult cover price
+	ManagerID        *uint          `json:"manager_id,omitempty"`        // Staff member sent the next day's staffing and prep plan
+	DelayAlertedAt   *time.Time     `json:"delay_alerted_at,omitempty"`  // Set while a service delay alert is outstanding; cleared once service recovers
+	IsActive         bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// DiningTable is a table on an outlet's floor plan
+type DiningTable struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	OutletID      uint           `json:"outlet_id" gorm:"not null;index"`
+	PropertyID    string         `json:"property_id" gorm:"not null;index"`
+	Name          string         `json:"name" gorm:"not null"` // e.g. "T12"
+	Capacity      int            `json:"capacity" gorm:"not null"`
+	Attributes    string         `json:"attributes"` // Comma-separated seating attributes: window, booth, patio, quiet
+	Section       string         `json:"section"`
+	PosX          int            `json:"pos_x"` // Position on the outlet's table map
+	PosY          int            `json:"pos_y"`
+	Status        string         `json:"status" gorm:"default:'available'"` // available, occupied, out_of_service
+	CurrentTurnID *uint          `json:"current_turn_id,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableTurn is one party's use of a table, from seating to clearing
+type TableTurn struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	TableID      uint       `json:"table_id" gorm:"not null;index"`
+	OutletID     uint       `json:"outlet_id" gorm:"not null;index:idx_table_turn_day"`
+	PropertyID   string     `json:"property_id" gorm:"not null"`
+	BusinessDate time.Time  `json:"business_date" gorm:"index:idx_table_turn_day"`
+	GuestID      *uint      `json:"guest_id,omitempty"` // nil for walk-ins
+	RoomNumber   string     `json:"room_number,omitempty"`
+	GuestName    string     `json:"guest_name,omitempty"`
+	PartySize    int        `json:"party_size"`
+	SeatingPref  string     `json:"seating_preference,omitempty"`
+	PrefMatched  bool       `json:"preference_matched"`
+	Status       string     `json:"status" gorm:"default:'seated'"` // seated, cleared
+	SeatedAt     time.Time  `json:"seated_at"`
+	SeatedBy     uint       `json:"seated_by"`
+	ClearedAt    *time.Time `json:"cleared_at,omitempty"`
+	ClearedBy    *uint      `json:"cleared_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// WaitlistEntry is a party waiting for a table at an outlet
+type WaitlistEntry struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	OutletID          uint       `json:"outlet_id" gorm:"not null;index:idx_waitlist_day"`
+	PropertyID        string     `json:"property_id" gorm:"not null;index"`
+	BusinessDate      time.Time  `json:"business_date" gorm:"index:idx_waitlist_day"`
+	GuestID           *uint      `json:"guest_id,omitempty"` // nil for walk-ins
+	RoomNumber        string     `json:"room_number,omitempty"`
+	PartyName         string     `json:"party_name" gorm:"not null"`
+	PartySize         int        `json:"party_size" gorm:"not null"`
+	Phone             string     `json:"phone,omitempty"`
+	SeatingPref       string     `json:"seating_preference,omitempty"`
+	Notes             string     `json:"notes,omitempty"`
+	Status            string     `json:"status" gorm:"default:'waiting';index"` // waiting, notified, seated, cancelled, no_show
+	QuotedWaitMinutes int        `json:"quoted_wait_minutes"`                   // Estimate given when the party was added
+	AddedAt           time.Time  `json:"added_at"`
+	AddedBy           uint       `json:"added_by"`
+	NotifiedAt        *time.Time `json:"notified_at,omitempty"`
+	NotifiedTableID   *uint      `json:"notified_table_id,omitempty"`
+	WaitMinutes       *float64   `json:"wait_minutes,omitempty"` // From joining the list until a table was ready
+	SeatedAt          *time.Time `json:"seated_at,omitempty"`
+	TurnID            *uint      `json:"turn_id,omitempty"`
+	ClosedAt          *time.Time `json:"closed_at,omitempty"` // Cancelled or marked no-show
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// MenuItem is a dish on an à-la-carte outlet's menu
+type MenuItem struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	OutletID     uint           `json:"outlet_id" gorm:"not null;index"`
+	PropertyID   string         `json:"property_id" gorm:"not null"`
+	Name         string         `json:"name" gorm:"not null"`
+	Description  string         `json:"description"`
+	Category     string         `json:"category"` // e.g. eggs, pastries, beverages
+	Price        float64        `json:"price" gorm:"not null"`
+	Station      string         `json:"station" gorm:"default:'kitchen'"` // Kitchen station that prepares it, e.g. grill, cold, pastry, bar
+	Modifiers    string         `json:"modifiers"`                        // Comma-separated options, optionally priced: "no onions,extra bacon:3.50"
+	AllergenTags string         `json:"allergen_tags"`                    // Comma-separated allergen codes from the taxonomy, e.g. gluten,dairy,tree_nuts
+	DietaryTags  string         `json:"dietary_tags"`                     // Comma-separated dietary codes the item suits, e.g. vegetarian,halal
+	IsAvailable  bool           `json:"is_available" gorm:"default:true"`
+	SortOrder    int            `json:"sort_order"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Order is an à-la-carte order placed during a breakfast visit
+type Order struct {
+	ID               uint        `json:"id" gorm:"primaryKey"`
+	ConsumptionID    uint        `json:"consumption_id" gorm:"not null;index"` // Visit the order belongs to
+	OutletID         uint        `json:"outlet_id" gorm:"not null;index"`
+	PropertyID       string      `json:"property_id" gorm:"not null"`
+	GuestID          uint        `json:"guest_id"`
+	RoomNumber       string      `json:"room_number"`
+	TableTurnID      *uint       `json:"table_turn_id,omitempty"`
+	Status           string      `json:"status" gorm:"default:'open';index"` // open, fired, ready, served, cancelled
+	Items            []OrderItem `json:"items,omitempty" gorm:"foreignKey:OrderID"`
+	Subtotal         float64     `json:"subtotal"`          // Menu prices including priced modifiers
+	AllowanceApplied float64     `json:"allowance_applied"` // Part of the subtotal covered by the breakfast package
+	OverageAmount    float64     `json:"overage_amount"`    // Subtotal beyond the allowance, charged to the room
+	ServiceCharge    float64     `json:"service_charge"`
+	TaxAmount        float64     `json:"tax_amount"`
+	PMSPosted        bool        `json:"pms_posted" gorm:"default:false"`
+	PMSTransactionID string      `json:"pms_transaction_id"`
+	CreatedBy        uint        `json:"created_by"`
+	FiredAt          *time.Time  `json:"fired_at,omitempty"`
+	ReadyAt          *time.Time  `json:"ready_at,omitempty"`
+	ServedAt         *time.Time  `json:"served_at,omitempty"`
+	CancelledAt      *time.Time  `json:"cancelled_at,omitempty"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+}
+
+// OrderItem is one line of an order
+type OrderItem struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	OrderID      uint      `json:"order_id" gorm:"not null;index"`
+	MenuItemID   uint      `json:"menu_item_id" gorm:"not null"`
+	TicketID     *uint     `json:"ticket_id,omitempty" gorm:"index"` // Kitchen ticket once fired
+	Name         string    `json:"name"`
+	Station      string    `json:"station"`
+	Quantity     int       `json:"quantity" gorm:"not null"`
+	UnitPrice    float64   `json:"unit_price"` // Menu price plus priced modifiers
+	Modifiers    string    `json:"modifiers"`  // Comma-separated chosen modifiers
+	AllergenTags string    `json:"allergen_tags"`
+	DietaryTags  string    `json:"dietary_tags"`
+	Notes        string    `json:"notes"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// KitchenTicket is the part of an order sent to one kitchen station
+type KitchenTicket struct {
+	ID        uint        `json:"id" gorm:"primaryKey"`
+	OrderID   uint        `json:"order_id" gorm:"not null;index"`
+	OutletID  uint        `json:"outlet_id" gorm:"not null;index:idx_ticket_station"`
+	Station   string      `json:"station" gorm:"not null;index:idx_ticket_station"`
+	Status    string      `json:"status" gorm:"default:'fired'"` // fired, ready, served
+	Items     []OrderItem `json:"items,omitempty" gorm:"foreignKey:TicketID"`
+	FiredAt   time.Time   `json:"fired_at"`
+	FiredBy   uint        `json:"fired_by"`
+	ReadyAt   *time.Time  `json:"ready_at,omitempty"`
+	ServedAt  *time.Time  `json:"served_at,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// AllergyAcknowledgement records staff confirming they have seen a guest's
+// allergy warnings, either when opening the room or when placing an order
+type AllergyAcknowledgement struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	GuestID        uint      `json:"guest_id" gorm:"not null;index"`
+	PropertyID     string    `json:"property_id" gorm:"not null"`
+	BusinessDate   time.Time `json:"business_date" gorm:"index"`
+	OutletID       *uint     `json:"outlet_id,omitempty"`
+	OrderID        *uint     `json:"order_id,omitempty" gorm:"index"`
+	Context        string    `json:"context"`                    // room, order
+	Conflicts      string    `json:"conflicts" gorm:"type:text"` // JSON list of the conflicts staff were shown
+	AcknowledgedBy uint      `json:"acknowledged_by"`
+	AcknowledgedAt time.Time `json:"acknowledged_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// InventoryItem is a supply stocked at an outlet, such as eggs, coffee or napkins
+type InventoryItem struct {
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	OutletID          uint           `json:"outlet_id" gorm:"not null;index"`
+	PropertyID        string         `json:"property_id" gorm:"not null;index"`
+	Code              string         `json:"code" gorm:"not null"` // Matches usage ratios, e.g. eggs, coffee_beans
+	Name              string         `json:"name" gorm:"not null"`
+	Category          string         `json:"category"`             // e.g. dairy, bakery, beverages, disposables
+	Unit              string         `json:"unit" gorm:"not null"` // e.g. each, kg, litre
+	ParLevel          float64        `json:"par_level"`            // Stock below this raises a low_supplies alert
+	UnitCost          float64        `json:"unit_cost"`            // Purchase cost per unit, used for cost per breakfast
+	OnHand            float64        `json:"on_hand"`
+	LastCountedAt     *time.Time     `json:"last_counted_at,omitempty"`
+	LowStockAlertedAt *time.Time     `json:"low_stock_alerted_at,omitempty"` // Set while an alert is outstanding; cleared once back at par
+	IsActive          bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// InventoryUsage is how much of a supply one breakfast cover uses at a
+// property's outlets of a given menu type
+type InventoryUsage struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	PropertyID string    `json:"property_id" gorm:"not null;uniqueIndex:idx_usage_menu_code"`
+	MenuType   string    `json:"menu_type" gorm:"not null;uniqueIndex:idx_usage_menu_code"` // buffet, a_la_carte, continental
+	Code       string    `json:"code" gorm:"not null;uniqueIndex:idx_usage_menu_code"`      // Inventory item code
+	PerCover   float64   `json:"per_cover" gorm:"not null"`                                 // In the item's unit
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// InventoryMovement is a change to an item's stock
+type InventoryMovement struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	InventoryItemID uint      `json:"inventory_item_id" gorm:"not null;index"`
+	OutletID        uint      `json:"outlet_id" gorm:"not null"`
+	PropertyID      string    `json:"property_id" gorm:"not null"`
+	Type            string    `json:"type" gorm:"not null"`                  // count, receipt, adjustment, depletion, reversal
+	Quantity        float64   `json:"quantity"`                              // Change in stock; negative when stock goes down
+	OnHand          float64   `json:"on_hand"`                               // Stock after the movement
+	ConsumptionID   *uint     `json:"consumption_id,omitempty" gorm:"index"` // Visit that depleted the stock
+	Reason          string    `json:"reason"`
+	StaffID         *uint     `json:"staff_id,omitempty"` // nil for depletion by visits
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// StaffingRatio is how an outlet turns forecast covers into headcount and prep
+type StaffingRatio struct {
+	ID                   uint      `json:"id" gorm:"primaryKey"`
+	OutletID             uint      `json:"outlet_id" gorm:"not null;uniqueIndex"`
+	PropertyID           string    `json:"property_id" gorm:"not null;index"`
+	FOHCoversPerHour     float64   `json:"foh_covers_per_hour"`     // Covers one front-of-house staff member handles in an hour
+	KitchenCoversPerHour float64   `json:"kitchen_covers_per_hour"` // Covers one cook handles in an hour
+	MinFOH               int       `json:"min_foh"`                 // Floor on front-of-house headcount while open
+	MinKitchen           int       `json:"min_kitchen"`             // Floor on kitchen headcount while open
+	ShiftMinutes         int       `json:"shift_minutes"`           // Length of the shifts service hours are split into
+	PrepBuffer           float64   `json:"prep_buffer"`             // Extra prepared on top of forecast covers, e.g. 0.1 for 10%
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// PlanEmail records a staffing and prep plan emailed to an outlet manager
+type PlanEmail struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	OutletID   uint      `json:"outlet_id" gorm:"not null;uniqueIndex:idx_plan_email_day"`
+	PlanDate   time.Time `json:"plan_date" gorm:"not null;uniqueIndex:idx_plan_email_day"`
+	PropertyID string    `json:"property_id" gorm:"not null"`
+	SentTo     string    `json:"sent_to"`
+	SentAt     time.Time `json:"sent_at"`
+}
+
+// DailyRollup materialises a business date's breakfast aggregates for a
+// property (OutletID 0) or one of its outlets, so dashboards don't rescan visits
+type DailyRollup struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	PropertyID     string    `json:"property_id" gorm:"not null;uniqueIndex:idx_daily_rollup"`
+	OutletID       uint      `json:"outlet_id" gorm:"not null;uniqueIndex:idx_daily_rollup"` // 0 for the whole property
+	BusinessDate   time.Time `json:"business_date" gorm:"not null;uniqueIndex:idx_daily_rollup"`
+	Visits         int       `json:"visits"`
+	RoomsServed    int       `json:"rooms_served"`  // Rooms with at least one visit
+	GuestsServed   int       `json:"guests_served"` // Guests with at least one visit
+	Covers         int       `json:"covers"`
+	UpsellCovers   int       `json:"upsell_covers"`
+	EntitledRooms  int       `json:"entitled_rooms"`  // Rooms entitled to breakfast; property rows only
+	EntitledCovers int       `json:"entitled_covers"` // Covers included in packages; property rows only
+	TakenCovers    int       `json:"taken_covers"`    // Entitled covers that were served
+	Revenue        float64   `json:"revenue"`
+	NoShows        int       `json:"no_shows"` // Rooms recorded as no-shows by the close-out
+	VIPGuests      int       `json:"vip_guests"`
+	VIPCovers      int       `json:"vip_covers"`
+	OHIPVisits     int       `json:"ohip_visits"`
+	PMSPosted      int       `json:"pms_posted"`
+	ServiceMinutes float64   `json:"service_minutes"` // Total seated-to-served minutes
+	ServiceSamples int       `json:"service_samples"`
+	RefreshedAt    time.Time `json:"refreshed_at"`
+}
+
+// HourlyRollup splits a DailyRollup by the property's local hour of service
+type HourlyRollup struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	PropertyID     string    `json:"property_id" gorm:"not null;uniqueIndex:idx_hourly_rollup"`
+	OutletID       uint      `json:"outlet_id" gorm:"not null;uniqueIndex:idx_hourly_rollup"` // 0 for the whole property
+	BusinessDate   time.Time `json:"business_date" gorm:"not null;uniqueIndex:idx_hourly_rollup"`
+	Hour           int       `json:"hour" gorm:"not null;uniqueIndex:idx_hourly_rollup"` // 0-23, local time
+	Visits         int       `json:"visits"`
+	Covers         int       `json:"covers"`
+	Revenue        float64   `json:"revenue"`
+	VIPCovers      int       `json:"vip_covers"`
+	OHIPVisits     int       `json:"ohip_visits"`
+	ServiceMinutes float64   `json:"service_minutes"`
+	ServiceSamples int       `json:"service_samples"`
+}
+
+// PropertyGroup is a named set of properties compared as one portfolio
+type PropertyGroup struct {
+	ID          uint                  `json:"id" gorm:"primaryKey"`
+	Name        string                `json:"name" gorm:"uniqueIndex;not null"`
+	Description string                `json:"description"`
+	CreatedBy   uint                  `json:"created_by"`
+	Members     []PropertyGroupMember `json:"members" gorm:"foreignKey:GroupID"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+// PropertyGroupMember places a property in a PropertyGroup
+type PropertyGroupMember struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	GroupID    uint   `json:"group_id" gorm:"not null;uniqueIndex:idx_group_member"`
+	PropertyID string `json:"property_id" gorm:"not null;uniqueIndex:idx_group_member"`
+}
+
+// ReportSubscription emails a report to a staff member on a schedule, at a
+// property-local time, covering the business days before it is sent
+type ReportSubscription struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	StaffID    uint           `json:"staff_id" gorm:"not null;index"`
+	Staff      *Staff         `json:"staff,omitempty" gorm:"foreignKey:StaffID"`
+	PropertyID string         `json:"property_id" gorm:"not null;index"`
+	Report     string         `json:"report" gorm:"not null"`                    // daily_report, consumption_history
+	Format     string         `json:"format" gorm:"not null"`                    // csv, xlsx, pdf
+	Frequency  string         `json:"frequency" gorm:"not null;default:'daily'"` // daily, weekly
+	SendTime   string         `json:"send_time" gorm:"not null;default:'07:00'"` // Property-local HH:MM
+	Weekday    int            `json:"weekday"`                                   // 0 (Sunday) to 6; weekly subscriptions only
+	IsActive   bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// ReportDelivery records one attempt to email a subscribed report
+type ReportDelivery struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint      `json:"subscription_id" gorm:"not null;index"`
+	StaffID        uint      `json:"staff_id" gorm:"not null;index"`
+	PropertyID     string    `json:"property_id" gorm:"not null;index"`
+	Report         string    `json:"report"`
+	Format         string    `json:"format"`
+	PeriodStart    time.Time `json:"period_start"` // First business date covered
+	PeriodEnd      time.Time `json:"period_end"`   // Last business date covered
+	SentTo         string    `json:"sent_to"`
+	Status         string    `json:"status" gorm:"not null;index"` // sent, failed
+	Error          string    `json:"error,omitempty"`
+	Rows           int       `json:"rows"`
+	Bytes          int       `json:"bytes"`     // Size of the attachment
+	Scheduled      bool      `json:"scheduled"` // false when sent on demand
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ServiceCloseOut records the end of a breakfast service for a property or a
+// single outlet. While closed, the day's consumptions are locked.
+type ServiceCloseOut struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	PropertyID     string     `json:"property_id" gorm:"not null;index:idx_close_out_day"`
+	OutletID       *uint      `json:"outlet_id,omitempty"` // nil for a property-wide close-out
+	Outlet         *Outlet    `json:"outlet,omitempty" gorm:"foreignKey:OutletID"`
+	BusinessDate   time.Time  `json:"business_date" gorm:"not null;index:idx_close_out_day"`
+	Status         string     `json:"status" gorm:"not null;default:'closed'"` // closed, reopened
+	Trigger        string     `json:"trigger"`                                 // scheduled, manual
+	ClosedAt       time.Time  `json:"closed_at"`
+	ClosedBy       *uint      `json:"closed_by,omitempty"` // nil when closed by the scheduler
+	GuestsEligible int        `json:"guests_eligible"`
+	GuestsServed   int        `json:"guests_served"`
+	NoShows        int        `json:"no_shows"`
+	CoversEntitled int        `json:"covers_entitled"`
+	CoversServed   int        `json:"covers_served"`
+	UpsellCovers   int        `json:"upsell_covers"`
+	VoidedVisits   int        `json:"voided_visits"`
+	Revenue        float64    `json:"revenue"`
+	ReopenedAt     *time.Time `json:"reopened_at,omitempty"`
+	ReopenedBy     *uint      `json:"reopened_by,omitempty"`
+	ReopenReason   string     `json:"reopen_reason,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// BreakfastPrice is a price book entry for one breakfast cover. Empty or nil
+// match fields apply to any outlet, room type or day; the most specific
+// entry in effect on the day wins.
+type BreakfastPrice struct {
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	PropertyID        string         `json:"property_id" gorm:"not null;index"`
+	OutletID          *uint          `json:"outlet_id,omitempty"`
+	RoomType          string         `json:"room_type"`                  // standard, deluxe, suite; empty for any
+	GuestType         string         `json:"guest_type" gorm:"not null"` // adult, child
+	DayOfWeek         *int           `json:"day_of_week,omitempty"`      // 0 = Sunday; nil for any day
+	Price             float64        `json:"price" gorm:"not null"`
+	TaxRate           float64        `json:"tax_rate"`                   // Percent, e.g. 13 for 13%
+	ServiceChargeRate float64        `json:"service_charge_rate"`        // Percent of the price
+	EffectiveFrom     time.Time      `json:"effective_from" gorm:"not null"`
+	EffectiveTo       *time.Time     `json:"effective_to,omitempty"`     // Exclusive; nil while open-ended
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// EligibilityRule configures one breakfast eligibility rule for a property.
+// Properties without rules use the default departure-day cutoff.
+type EligibilityRule struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	PropertyID  string         `json:"property_id" gorm:"not null;index"`
+	RuleType    string         `json:"rule_type" gorm:"not null"` // departure_day_cutoff, no_arrival_day, child_age_free, loyalty_complimentary, rate_code_includes
+	Value       string         `json:"value"`                     // HH:MM, an age, or comma-separated tiers or rate codes
+	Description string         `json:"description"`
+	IsActive    bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 // StaffComment represents categorized comments on guests or consumption
@@ -175,15 +599,27 @@ type RoomBreakfastStatus struct {
 	GuestName        string    `json:"guest_name"`
 	BreakfastPackage bool      `json:"breakfast_package"`
 	BreakfastCount   int       `json:"breakfast_count"`
+	AdultCount       int       `json:"adult_count"`
+	ChildCount       int       `json:"child_count"`
+	CoversEntitled   int       `json:"covers_entitled"`
+	CoversConsumed   int       `json:"covers_consumed"`
+	CoversRemaining  int       `json:"covers_remaining"`
 	ConsumedToday    bool      `json:"consumed_today"`
 	ConsumedAt       *time.Time `json:"consumed_at,omitempty"`
 	ConsumedBy       string    `json:"consumed_by"`
 	CheckInDate      *time.Time `json:"check_in_date,omitempty"`
 	CheckOutDate     *time.Time `json:"check_out_date,omitempty"`
+	GuestID          uint      `json:"guest_id,omitempty"`
+	Eligible         bool      `json:"eligible"`
+	EligibilityReason string   `json:"eligibility_reason,omitempty"`
+	EntitledConsumed int       `json:"-"` // Covers served against the entitlement
 	// VIP Status Fields
 	IsVIP            bool      `json:"is_vip"`
 	IsUpset          bool      `json:"is_upset"`
 	SpecialRequests  string    `json:"special_requests"`
+	// Allergy Fields
+	CriticalAllergy   bool     `json:"critical_allergy"`
+	CriticalAllergies []string `json:"critical_allergies,omitempty" gorm:"-"`
 }
 
 // UserDevice represents a user's device for push notifications
@@ -200,6 +636,64 @@ type UserDevice struct {
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
+// BreakfastPass is a signed QR pass issued to a guest for the length of their
+// stay. A room pass admits the guest's whole daily entitlement; a cover pass
+// admits one cover a day, so each person in the room can carry their own.
+type BreakfastPass struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	GuestID        uint       `json:"guest_id" gorm:"not null;index"`
+	PropertyID     string     `json:"property_id" gorm:"not null;index"`
+	RoomNumber     string     `json:"room_number" gorm:"not null"`
+	CoverNumber    int        `json:"cover_number"` // 0 for a room pass, otherwise the cover this pass admits
+	ValidFrom      time.Time  `json:"valid_from"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	Status         string     `json:"status" gorm:"default:'active';index"` // active, revoked
+	IssuedBy       uint       `json:"issued_by"`
+	DeliveredVia   string     `json:"delivered_via,omitempty"` // email, sms
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	RedeemCount    int        `json:"redeem_count" gorm:"default:0"`
+	LastRedeemedAt *time.Time `json:"last_redeemed_at,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy      *uint      `json:"revoked_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// SyncEvent records a consumption event queued offline by a device and applied
+// through batch sync, so that events re-sent by the device are not applied twice
+type SyncEvent struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	DeviceID      string    `json:"device_id" gorm:"uniqueIndex:idx_sync_event_device;not null"`
+	ClientEventID string    `json:"client_event_id" gorm:"uniqueIndex:idx_sync_event_device;not null"`
+	PropertyID    string    `json:"property_id" gorm:"not null;index"`
+	RoomNumber    string    `json:"room_number" gorm:"not null"`
+	OccurredAt    time.Time `json:"occurred_at"` // Client timestamp of the event
+	BusinessDate  time.Time `json:"business_date"`
+	SyncedBy      uint      `json:"synced_by"`
+	Status        string    `json:"status"`                   // applied, merged
+	ConsumptionID *uint     `json:"consumption_id,omitempty"` // Visit recorded, or merged into, for this event
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// IdempotencyKey stores the response to a mutating request so that retries
+// carrying the same Idempotency-Key header replay it instead of re-executing
+type IdempotencyKey struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"uniqueIndex:idx_idempotency_user_key"`
+	Key          string    `json:"key" gorm:"column:idempotency_key;uniqueIndex:idx_idempotency_user_key;not null"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	RequestHash  string    `json:"request_hash"`
+	Status       string    `json:"status" gorm:"default:'processing'"` // processing, completed
+	StatusCode   int       `json:"status_code"`
+	ContentType  string    `json:"content_type"`
+	ResponseBody string    `json:"response_body" gorm:"type:text"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
 // AuditLog represents system audit logs for compliance and security
 type AuditLog struct {
 	ID         uint      `json:"id" gorm:"primaryKey"`
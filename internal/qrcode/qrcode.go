@@ -0,0 +1,492 @@
+// Package qrcode encodes short payloads, such as breakfast pass tokens, as QR
+// codes. It supports byte mode at error correction level M for versions 1-10,
+// which holds up to 213 bytes.
+package qrcode
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// ErrTooLong is returned when a payload does not fit in the largest supported version
+var ErrTooLong = errors.New("qrcode: payload too long")
+
+const maxVersion = 10
+
+// Error correction codewords per block and number of blocks at level M, by version
+var (
+	eccPerBlock = [maxVersion + 1]int{0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26}
+	numBlocks   = [maxVersion + 1]int{0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5}
+)
+
+// Code is an encoded QR symbol
+type Code struct {
+	Size    int
+	modules [][]bool
+}
+
+// Dark reports whether the module at a row and column is dark
+func (c *Code) Dark(row, col int) bool {
+	return c.modules[row][col]
+}
+
+// Encode encodes a payload in the smallest version that holds it
+func Encode(payload []byte) (*Code, error) {
+	for version := 1; version <= maxVersion; version++ {
+		countBits := 8
+		if version >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(payload) <= dataCodewords(version)*8 {
+			return encode(payload, version, countBits), nil
+		}
+	}
+	return nil, ErrTooLong
+}
+
+// WritePNG renders the code as a PNG with the given module size in pixels and a four-module quiet zone
+func (c *Code) WritePNG(w io.Writer, scale int) error {
+	if scale < 1 {
+		scale = 1
+	}
+	const quiet = 4
+	side := (c.Size + 2*quiet) * scale
+
+	img := image.NewGray(image.Rect(0, 0, side, side))
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			row, col := y/scale-quiet, x/scale-quiet
+			shade := color.Gray{Y: 255}
+			if row >= 0 && row < c.Size && col >= 0 && col < c.Size && c.modules[row][col] {
+				shade = color.Gray{Y: 0}
+			}
+			img.SetGray(x, y, shade)
+		}
+	}
+	return png.Encode(w, img)
+}
+
+func encode(payload []byte, version, countBits int) *Code {
+	// Byte mode segment, terminator and padding
+	var bits bitBuffer
+	bits.append(0x4, 4)
+	bits.append(len(payload), countBits)
+	for _, b := range payload {
+		bits.append(int(b), 8)
+	}
+	capacity := dataCodewords(version) * 8
+	terminator := capacity - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	codewords := interleave(bits.bytes(), version)
+
+	code := newCode(version)
+	code.placeData(codewords)
+
+	// Apply the mask with the lowest penalty
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		code.applyMask(mask)
+		code.drawFormat(mask)
+		if penalty := code.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		code.applyMask(mask)
+	}
+	code.applyMask(best)
+	code.drawFormat(best)
+
+	return &Code{Size: code.size, modules: code.modules}
+}
+
+// rawCodewords returns the number of codewords a version holds, data and error correction
+func rawCodewords(version int) int {
+	modules := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		modules -= (25*align-10)*align - 55
+		if version >= 7 {
+			modules -= 36
+		}
+	}
+	return modules / 8
+}
+
+func dataCodewords(version int) int {
+	return rawCodewords(version) - eccPerBlock[version]*numBlocks[version]
+}
+
+// interleave splits data into blocks, appends their error correction and interleaves them
+func interleave(data []byte, version int) []byte {
+	blocks := numBlocks[version]
+	ecc := eccPerBlock[version]
+	raw := rawCodewords(version)
+	shortBlocks := blocks - raw%blocks
+	shortLen := raw/blocks - ecc
+
+	divisor := rsDivisor(ecc)
+	dataBlocks := make([][]byte, blocks)
+	eccBlocks := make([][]byte, blocks)
+	offset := 0
+	for i := 0; i < blocks; i++ {
+		length := shortLen
+		if i >= shortBlocks {
+			length++
+		}
+		dataBlocks[i] = data[offset : offset+length]
+		eccBlocks[i] = rsRemainder(dataBlocks[i], divisor)
+		offset += length
+	}
+
+	result := make([]byte, 0, raw)
+	for i := 0; i <= shortLen; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < ecc; i++ {
+		for _, block := range eccBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, (value>>i)&1 == 1)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	result := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			result[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return result
+}
+
+// Reed-Solomon error correction over GF(256) with the QR polynomial 0x11D
+
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= gfMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+// matrix holds modules while a symbol is built; function modules are never masked
+type matrix struct {
+	size       int
+	version    int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+func newCode(version int) *matrix {
+	size := version*4 + 17
+	m := &matrix{size: size, version: version}
+	m.modules = make([][]bool, size)
+	m.isFunction = make([][]bool, size)
+	for i := range m.modules {
+		m.modules[i] = make([]bool, size)
+		m.isFunction[i] = make([]bool, size)
+	}
+
+	// Timing patterns
+	for i := 0; i < size; i++ {
+		m.set(6, i, i%2 == 0)
+		m.set(i, 6, i%2 == 0)
+	}
+
+	// Finder patterns with separators
+	m.drawFinder(3, 3)
+	m.drawFinder(3, size-4)
+	m.drawFinder(size-4, 3)
+
+	// Alignment patterns, except where they would overlap the finders
+	positions := alignmentPositions(version)
+	last := len(positions) - 1
+	for i, row := range positions {
+		for j, col := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			m.drawAlignment(row, col)
+		}
+	}
+
+	// Reserve the format areas and draw the version information
+	m.drawFormat(0)
+	m.drawVersion()
+	return m
+}
+
+func (m *matrix) set(row, col int, dark bool) {
+	m.modules[row][col] = dark
+	m.isFunction[row][col] = true
+}
+
+func (m *matrix) drawFinder(row, col int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			r, c := row+dy, col+dx
+			if r < 0 || r >= m.size || c < 0 || c >= m.size {
+				continue
+			}
+			dist := maxInt(absInt(dx), absInt(dy))
+			m.set(r, c, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (m *matrix) drawAlignment(row, col int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			m.set(row+dy, col+dx, maxInt(absInt(dx), absInt(dy)) != 1)
+		}
+	}
+}
+
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	count := version/7 + 2
+	step := (version*4 + count*2 + 1) / (count*2 - 2) * 2
+	positions := make([]int, count)
+	positions[0] = 6
+	for i, pos := count-1, version*4+10; i >= 1; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+	return positions
+}
+
+// drawFormat writes both copies of the format information for level M and a mask
+func (m *matrix) drawFormat(mask int) {
+	data := mask // Level M is 00
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return (bits>>i)&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		m.set(i, 8, bit(i))
+	}
+	m.set(7, 8, bit(6))
+	m.set(8, 8, bit(7))
+	m.set(8, 7, bit(8))
+	for i := 9; i < 15; i++ {
+		m.set(8, 14-i, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		m.set(8, m.size-1-i, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		m.set(m.size-15+i, 8, bit(i))
+	}
+	m.set(m.size-8, 8, true) // Dark module
+}
+
+func (m *matrix) drawVersion() {
+	if m.version < 7 {
+		return
+	}
+	rem := m.version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := m.version<<12 | rem
+	for i := 0; i < 18; i++ {
+		dark := (bits>>i)&1 == 1
+		a, b := m.size-11+i%3, i/3
+		m.set(b, a, dark)
+		m.set(a, b, dark)
+	}
+}
+
+// placeData fills the non-function modules in the zigzag order
+func (m *matrix) placeData(codewords []byte) {
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < m.size; vert++ {
+			for j := 0; j < 2; j++ {
+				col := right - j
+				row := vert
+				if (right+1)&2 == 0 {
+					row = m.size - 1 - vert
+				}
+				if !m.isFunction[row][col] && i < len(codewords)*8 {
+					m.modules[row][col] = (codewords[i>>3]>>(7-i&7))&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask flips the data modules selected by a mask; applying it twice undoes it
+func (m *matrix) applyMask(mask int) {
+	for row := 0; row < m.size; row++ {
+		for col := 0; col < m.size; col++ {
+			if m.isFunction[row][col] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (row+col)%2 == 0
+			case 1:
+				invert = row%2 == 0
+			case 2:
+				invert = col%3 == 0
+			case 3:
+				invert = (row+col)%3 == 0
+			case 4:
+				invert = (row/2+col/3)%2 == 0
+			case 5:
+				invert = row*col%2+row*col%3 == 0
+			case 6:
+				invert = (row*col%2+row*col%3)%2 == 0
+			case 7:
+				invert = ((row+col)%2+row*col%3)%2 == 0
+			}
+			if invert {
+				m.modules[row][col] = !m.modules[row][col]
+			}
+		}
+	}
+}
+
+// penalty scores a masked symbol; lower scores are easier to scan
+func (m *matrix) penalty() int {
+	score := 0
+	dark := 0
+	finderLike := [][]bool{
+		{true, false, true, true, true, false, true, false, false, false, false},
+		{false, false, false, false, true, false, true, true, true, false, true},
+	}
+
+	line := func(get func(int) bool) {
+		run := 1
+		for i := 1; i <= m.size; i++ {
+			if i < m.size && get(i) == get(i-1) {
+				run++
+				continue
+			}
+			if run >= 5 {
+				score += 3 + run - 5
+			}
+			run = 1
+		}
+		for i := 0; i+11 <= m.size; i++ {
+			for _, pattern := range finderLike {
+				match := true
+				for k, want := range pattern {
+					if get(i+k) != want {
+						match = false
+						break
+					}
+				}
+				if match {
+					score += 40
+				}
+			}
+		}
+	}
+
+	for row := 0; row < m.size; row++ {
+		r := row
+		line(func(i int) bool { return m.modules[r][i] })
+	}
+	for col := 0; col < m.size; col++ {
+		c := col
+		line(func(i int) bool { return m.modules[i][c] })
+	}
+
+	for row := 0; row < m.size; row++ {
+		for col := 0; col < m.size; col++ {
+			if m.modules[row][col] {
+				dark++
+			}
+			if row+1 < m.size && col+1 < m.size {
+				v := m.modules[row][col]
+				if m.modules[row][col+1] == v && m.modules[row+1][col] == v && m.modules[row+1][col+1] == v {
+					score += 3
+				}
+			}
+		}
+	}
+
+	total := m.size * m.size
+	deviation := absInt(dark*20-total*10) / total
+	score += deviation * 10
+	return score
+}
+
+func absInt(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,465 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// The tables below are copied from ISO/IEC 18004 for error correction level M
+// rather than derived from the encoder, so the decoder checks the encoder
+// against the standard and not against itself.
+
+// levelMBlocks lists each version's blocks as (count, total codewords, data codewords)
+var levelMBlocks = map[int][][3]int{
+	1:  {{1, 26, 16}},
+	2:  {{1, 44, 28}},
+	3:  {{1, 70, 44}},
+	4:  {{2, 50, 32}},
+	5:  {{2, 67, 43}},
+	6:  {{4, 43, 27}},
+	7:  {{4, 49, 31}},
+	8:  {{2, 60, 38}, {2, 61, 39}},
+	9:  {{3, 58, 36}, {2, 59, 37}},
+	10: {{4, 69, 43}, {1, 70, 44}},
+}
+
+// byteCapacity is the longest byte-mode payload each version holds at level M
+var byteCapacity = []int{0, 14, 26, 42, 62, 84, 106, 122, 152, 180, 213}
+
+var alignmentCenters = map[int][]int{
+	2: {6, 18}, 3: {6, 22}, 4: {6, 26}, 5: {6, 30}, 6: {6, 34},
+	7: {6, 22, 38}, 8: {6, 24, 42}, 9: {6, 26, 46}, 10: {6, 28, 50},
+}
+
+// formatM is the masked format information for level M, by mask
+var formatM = []string{
+	"101010000010010", "101000100100101", "101111001111100", "101101101001011",
+	"100010111111001", "100000011001110", "100111110010111", "100101010100000",
+}
+
+var versionInfo = map[int]string{
+	7:  "000111110010010100",
+	8:  "001000010110111100",
+	9:  "001001101010011001",
+	10: "001010010011010011",
+}
+
+func TestEncodeDecodes(t *testing.T) {
+	lengths := []int{0, 1, 14, 15, 26, 27, 42, 62, 63, 84, 106, 107, 122, 123, 152, 153, 180, 181, 213}
+	for _, length := range lengths {
+		payload := testPayload(length)
+		t.Run(fmt.Sprintf("%d bytes", length), func(t *testing.T) {
+			code, err := Encode(payload)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+
+			wantVersion := 1
+			for byteCapacity[wantVersion] < length {
+				wantVersion++
+			}
+			if want := 17 + 4*wantVersion; code.Size != want {
+				t.Fatalf("size = %d, want %d (version %d)", code.Size, want, wantVersion)
+			}
+
+			decoded, err := decode(code)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !bytes.Equal(decoded, payload) {
+				t.Fatalf("decoded %q, want %q", decoded, payload)
+			}
+		})
+	}
+}
+
+func TestEncodePassToken(t *testing.T) {
+	token := "MTI6MzQ6MjAyNi0xMC0xNjoyMDI2LTEwLTE5OjA.Q2hlY2tzdW0tb2YtdGhlLXBhc3MtdG9rZW4tMzItYnl0ZXM"
+	code, err := Encode([]byte(token))
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	decoded, err := decode(code)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if string(decoded) != token {
+		t.Fatalf("decoded %q, want %q", decoded, token)
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := Encode(testPayload(214)); !errors.Is(err, ErrTooLong) {
+		t.Fatalf("Encode(214 bytes) error = %v, want ErrTooLong", err)
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	code, err := Encode([]byte("https://example.com/api/passes/qr.png?token=abc"))
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	const scale, quiet = 3, 4
+	var buf bytes.Buffer
+	if err := code.WritePNG(&buf, scale); err != nil {
+		t.Fatalf("WritePNG: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode: %v", err)
+	}
+
+	if side := (code.Size + 2*quiet) * scale; img.Bounds().Dx() != side || img.Bounds().Dy() != side {
+		t.Fatalf("image is %v, want %dx%d", img.Bounds(), side, side)
+	}
+	for row := -quiet; row < code.Size+quiet; row++ {
+		for col := -quiet; col < code.Size+quiet; col++ {
+			r, _, _, _ := img.At((col+quiet)*scale+1, (row+quiet)*scale+1).RGBA()
+			dark := row >= 0 && row < code.Size && col >= 0 && col < code.Size && code.Dark(row, col)
+			if (r == 0) != dark {
+				t.Fatalf("pixel for module (%d, %d) dark = %v, want %v", row, col, r == 0, dark)
+			}
+		}
+	}
+}
+
+func testPayload(length int) []byte {
+	payload := make([]byte, length)
+	for i := range payload {
+		payload[i] = byte(i*37 + 11)
+	}
+	return payload
+}
+
+// decode reads a byte-mode, level M symbol, checking its function patterns,
+// format and version information and the error correction of every block
+func decode(code *Code) ([]byte, error) {
+	version := (code.Size - 17) / 4
+	blocks, ok := levelMBlocks[version]
+	if !ok || code.Size != 17+4*version {
+		return nil, fmt.Errorf("unsupported size %d", code.Size)
+	}
+
+	reserved := functionModules(version)
+	if err := checkPatterns(code, version); err != nil {
+		return nil, err
+	}
+
+	// Both copies of the format information must carry level M and the same mask
+	var first, second strings.Builder
+	for i := 14; i >= 0; i-- {
+		r, c := formatFirst(i)
+		first.WriteString(bit(code.Dark(r, c)))
+		r, c = formatSecond(i, code.Size)
+		second.WriteString(bit(code.Dark(r, c)))
+	}
+	if first.String() != second.String() {
+		return nil, fmt.Errorf("format copies differ: %s and %s", first.String(), second.String())
+	}
+	mask := -1
+	for m, format := range formatM {
+		if format == first.String() {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		return nil, fmt.Errorf("format %s is not level M", first.String())
+	}
+
+	if want, ok := versionInfo[version]; ok {
+		var a, b strings.Builder
+		for i := 17; i >= 0; i-- {
+			row, col := i/3, code.Size-11+i%3
+			a.WriteString(bit(code.Dark(row, col)))
+			b.WriteString(bit(code.Dark(col, row)))
+		}
+		if a.String() != want || b.String() != want {
+			return nil, fmt.Errorf("version information %s and %s, want %s", a.String(), b.String(), want)
+		}
+	}
+
+	// Read the codewords in the zigzag order, removing the mask
+	var stream []byte
+	var current byte
+	count := 0
+	upward := true
+	for right := code.Size - 1; right > 0; right -= 2 {
+		if right == 6 {
+			right--
+		}
+		for i := 0; i < code.Size; i++ {
+			row := i
+			if upward {
+				row = code.Size - 1 - i
+			}
+			for _, col := range []int{right, right - 1} {
+				if reserved[row][col] {
+					continue
+				}
+				dark := code.Dark(row, col) != masked(mask, row, col)
+				current = current<<1 | boolByte(dark)
+				if count++; count%8 == 0 {
+					stream = append(stream, current)
+					current = 0
+				}
+			}
+		}
+		upward = !upward
+	}
+
+	// De-interleave the blocks and check each one's error correction
+	var lengths, dataLengths []int
+	total := 0
+	for _, group := range blocks {
+		for i := 0; i < group[0]; i++ {
+			lengths = append(lengths, group[1])
+			dataLengths = append(dataLengths, group[2])
+			total += group[1]
+		}
+	}
+	if len(stream) < total {
+		return nil, fmt.Errorf("read %d codewords, want %d", len(stream), total)
+	}
+	ecc := lengths[0] - dataLengths[0]
+	split := make([][]byte, len(lengths))
+	next := 0
+	for i := 0; i < dataLengths[len(dataLengths)-1]; i++ {
+		for b := range split {
+			if i < dataLengths[b] {
+				split[b] = append(split[b], stream[next])
+				next++
+			}
+		}
+	}
+	for i := 0; i < ecc; i++ {
+		for b := range split {
+			split[b] = append(split[b], stream[next])
+			next++
+		}
+	}
+
+	var data []byte
+	for b, block := range split {
+		if err := checkSyndromes(block, ecc); err != nil {
+			return nil, fmt.Errorf("block %d: %w", b, err)
+		}
+		data = append(data, block[:dataLengths[b]]...)
+	}
+
+	// Parse the byte-mode segment
+	reader := bitReader{data: data}
+	if mode := reader.read(4); mode != 0x4 {
+		return nil, fmt.Errorf("mode %04b, want byte mode", mode)
+	}
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	length := reader.read(countBits)
+	payload := make([]byte, length)
+	for i := range payload {
+		payload[i] = byte(reader.read(8))
+	}
+	if reader.pos > len(data)*8 {
+		return nil, fmt.Errorf("segment of %d bytes overruns the data codewords", length)
+	}
+	return payload, nil
+}
+
+// functionModules marks the modules that do not carry data
+func functionModules(version int) [][]bool {
+	size := 17 + 4*version
+	reserved := make([][]bool, size)
+	for i := range reserved {
+		reserved[i] = make([]bool, size)
+	}
+	fill := func(row, col, height, width int) {
+		for r := row; r < row+height; r++ {
+			for c := col; c < col+width; c++ {
+				reserved[r][c] = true
+			}
+		}
+	}
+
+	// Finders with separators and format areas
+	fill(0, 0, 9, 9)
+	fill(0, size-8, 9, 8)
+	fill(size-8, 0, 8, 9)
+	// Timing patterns
+	fill(6, 0, 1, size)
+	fill(0, 6, size, 1)
+	// Alignment patterns
+	for _, center := range alignmentPatterns(version) {
+		fill(center[0]-2, center[1]-2, 5, 5)
+	}
+	// Version information
+	if version >= 7 {
+		fill(0, size-11, 6, 3)
+		fill(size-11, 0, 3, 6)
+	}
+	return reserved
+}
+
+// alignmentPatterns lists the centres of a version's alignment patterns,
+// leaving out those that would overlap a finder
+func alignmentPatterns(version int) [][2]int {
+	size := 17 + 4*version
+	centers := alignmentCenters[version]
+	var patterns [][2]int
+	for _, row := range centers {
+		for _, col := range centers {
+			if (row < 9 && col < 9) || (row < 9 && col > size-9) || (row > size-9 && col < 9) {
+				continue
+			}
+			patterns = append(patterns, [2]int{row, col})
+		}
+	}
+	return patterns
+}
+
+// checkPatterns checks the finder, timing and alignment patterns and the dark module
+func checkPatterns(code *Code, version int) error {
+	for _, corner := range [][2]int{{0, 0}, {0, code.Size - 7}, {code.Size - 7, 0}} {
+		for dy := 0; dy < 7; dy++ {
+			for dx := 0; dx < 7; dx++ {
+				ring := maxInt(absInt(dy-3), absInt(dx-3))
+				if code.Dark(corner[0]+dy, corner[1]+dx) != (ring != 2) {
+					return fmt.Errorf("finder at %v is malformed", corner)
+				}
+			}
+		}
+	}
+	for i := 8; i < code.Size-8; i++ {
+		if code.Dark(6, i) != (i%2 == 0) || code.Dark(i, 6) != (i%2 == 0) {
+			return fmt.Errorf("timing pattern is malformed at %d", i)
+		}
+	}
+	for _, center := range alignmentPatterns(version) {
+		for dy := -2; dy <= 2; dy++ {
+			for dx := -2; dx <= 2; dx++ {
+				if code.Dark(center[0]+dy, center[1]+dx) != (maxInt(absInt(dy), absInt(dx)) != 1) {
+					return fmt.Errorf("alignment pattern at %v is malformed", center)
+				}
+			}
+		}
+	}
+	if !code.Dark(code.Size-8, 8) {
+		return errors.New("dark module is missing")
+	}
+	return nil
+}
+
+// formatFirst and formatSecond locate format bit i, 14 being the most significant
+func formatFirst(i int) (int, int) {
+	switch {
+	case i <= 5:
+		return i, 8
+	case i == 6:
+		return 7, 8
+	case i == 7:
+		return 8, 8
+	case i == 8:
+		return 8, 7
+	default:
+		return 8, 14 - i
+	}
+}
+
+func formatSecond(i, size int) (int, int) {
+	if i < 8 {
+		return 8, size - 1 - i
+	}
+	return size - 15 + i, 8
+}
+
+func masked(mask, row, col int) bool {
+	switch mask {
+	case 0:
+		return (row+col)%2 == 0
+	case 1:
+		return row%2 == 0
+	case 2:
+		return col%3 == 0
+	case 3:
+		return (row+col)%3 == 0
+	case 4:
+		return (row/2+col/3)%2 == 0
+	case 5:
+		return (row*col)%2+(row*col)%3 == 0
+	case 6:
+		return ((row*col)%2+(row*col)%3)%2 == 0
+	default:
+		return ((row+col)%2+(row*col)%3)%2 == 0
+	}
+}
+
+// checkSyndromes evaluates a block at the generator's roots α^0..α^(ecc-1);
+// every syndrome of an intact block is zero
+func checkSyndromes(block []byte, ecc int) error {
+	var exp [512]byte
+	var log [256]int
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i], exp[i+255] = byte(x), byte(x)
+		log[x] = i
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11D
+		}
+	}
+	mul := func(a, b byte) byte {
+		if a == 0 || b == 0 {
+			return 0
+		}
+		return exp[log[a]+log[b]]
+	}
+
+	for j := 0; j < ecc; j++ {
+		var syndrome byte
+		root := exp[j]
+		for _, c := range block {
+			syndrome = mul(syndrome, root) ^ c
+		}
+		if syndrome != 0 {
+			return fmt.Errorf("syndrome %d is %#x", j, syndrome)
+		}
+	}
+	return nil
+}
+
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) read(n int) int {
+	value := 0
+	for i := 0; i < n; i++ {
+		bit := 0
+		if r.pos < len(r.data)*8 && r.data[r.pos/8]>>(7-r.pos%8)&1 == 1 {
+			bit = 1
+		}
+		value = value<<1 | bit
+		r.pos++
+	}
+	return value
+}
+
+func bit(dark bool) string {
+	if dark {
+		return "1"
+	}
+	return "0"
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,656 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Errors returned by allergen checks
+var (
+	ErrUnknownAllergen        = errors.New("unknown allergen")
+	ErrUnknownDietary         = errors.New("unknown dietary restriction")
+	ErrUnknownSeverity        = errors.New("allergy severity must be mild, severe or critical")
+	ErrAllergyNotAcknowledged = errors.New("allergen conflicts must be acknowledged by staff")
+	ErrDietaryGuestNotFound   = errors.New("guest not found")
+)
+
+// Allergy severities, from least to most serious
+const (
+	AllergySeverityMild     = "mild"
+	AllergySeveritySevere   = "severe"
+	AllergySeverityCritical = "critical" // Anaphylaxis risk; flagged on the room grid and kitchen tickets
+)
+
+// Allergen is an entry in the allergen taxonomy menu items are tagged against
+type Allergen struct {
+	Code    string   `json:"code"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"` // Free-text names recognised as this allergen
+}
+
+// DietaryRestriction is an entry in the dietary taxonomy. An item breaks the
+// restriction when it contains an excluded allergen or, for restrictions that
+// cannot be told from allergens, when it lacks one of the satisfying tags.
+type DietaryRestriction struct {
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	Excludes    []string `json:"excludes,omitempty"`
+	SatisfiedBy []string `json:"satisfied_by,omitempty"` // Menu item dietary tags that meet it
+}
+
+// AllergenTaxonomy lists the allergens menu items and guest allergies use
+var AllergenTaxonomy = []Allergen{
+	{Code: "gluten", Name: "Cereals containing gluten", Aliases: []string{"wheat", "barley", "rye", "oats", "spelt"}},
+	{Code: "crustaceans", Name: "Crustaceans", Aliases: []string{"shellfish", "shrimp", "prawn", "prawns", "crab", "lobster"}},
+	{Code: "eggs", Name: "Eggs", Aliases: []string{"egg"}},
+	{Code: "fish", Name: "Fish"},
+	{Code: "peanuts", Name: "Peanuts", Aliases: []string{"peanut", "groundnut"}},
+	{Code: "soy", Name: "Soybeans", Aliases: []string{"soya", "soybean"}},
+	{Code: "dairy", Name: "Milk", Aliases: []string{"milk", "lactose"}},
+	{Code: "tree_nuts", Name: "Tree nuts", Aliases: []string{"nuts", "almond", "hazelnut", "walnut", "cashew", "pecan", "pistachio", "macadamia"}},
+	{Code: "celery", Name: "Celery"},
+	{Code: "mustard", Name: "Mustard"},
+	{Code: "sesame", Name: "Sesame"},
+	{Code: "sulphites", Name: "Sulphites", Aliases: []string{"sulfites", "sulphur_dioxide", "sulfur_dioxide"}},
+	{Code: "lupin", Name: "Lupin"},
+	{Code: "molluscs", Name: "Molluscs", Aliases: []string{"mollusks", "mussels", "oysters", "clams", "squid"}},
+}
+
+// DietaryTaxonomy lists the dietary restrictions guests can have and menu items can be tagged with
+var DietaryTaxonomy = []DietaryRestriction{
+	{Code: "vegetarian", Name: "Vegetarian", SatisfiedBy: []string{"vegetarian", "vegan"}},
+	{Code: "vegan", Name: "Vegan", Excludes: []string{"eggs", "dairy", "fish", "crustaceans", "molluscs"}, SatisfiedBy: []string{"vegan"}},
+	{Code: "pescatarian", Name: "Pescatarian", SatisfiedBy: []string{"pescatarian", "vegetarian", "vegan"}},
+	{Code: "halal", Name: "Halal", SatisfiedBy: []string{"halal"}},
+	{Code: "kosher", Name: "Kosher", SatisfiedBy: []string{"kosher"}},
+	{Code: "gluten_free", Name: "Gluten free", Excludes: []string{"gluten"}},
+	{Code: "dairy_free", Name: "Dairy free", Excludes: []string{"dairy"}},
+	{Code: "nut_free", Name: "Nut free", Excludes: []string{"peanuts", "tree_nuts"}},
+}
+
+// GuestAllergy is one of a guest's allergies
+type GuestAllergy struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Notes    string `json:"notes,omitempty"`
+}
+
+// DietaryProfile is a guest's allergies and dietary restrictions
+type DietaryProfile struct {
+	GuestID             uint           `json:"guest_id"`
+	Allergies           []GuestAllergy `json:"allergies"`
+	DietaryRestrictions []string       `json:"dietary_restrictions"`
+}
+
+// AllergenConflict is a menu item that is unsafe or unsuitable for a guest
+type AllergenConflict struct {
+	MenuItemID uint   `json:"menu_item_id"`
+	ItemName   string `json:"item_name"`
+	Kind       string `json:"kind"` // allergen, dietary
+	Code       string `json:"code"`
+	Severity   string `json:"severity"` // The allergy's severity; "dietary" for dietary restrictions
+}
+
+// AllergenConflictError lists conflicts that staff have not acknowledged
+type AllergenConflictError struct {
+	Conflicts []AllergenConflict
+}
+
+func (e *AllergenConflictError) Error() string {
+	items := make([]string, 0, len(e.Conflicts))
+	for _, conflict := range e.Conflicts {
+		items = append(items, fmt.Sprintf("%s (%s)", conflict.ItemName, conflict.Code))
+	}
+	return fmt.Sprintf("%s: %s", ErrAllergyNotAcknowledged, strings.Join(items, ", "))
+}
+
+func (e *AllergenConflictError) Unwrap() error {
+	return ErrAllergyNotAcknowledged
+}
+
+// RoomAllergyCheck is what staff see about allergies when they open a room
+type RoomAllergyCheck struct {
+	GuestID                 uint               `json:"guest_id"`
+	GuestName               string             `json:"guest_name"`
+	RoomNumber              string             `json:"room_number"`
+	Profile                 DietaryProfile     `json:"profile"`
+	CriticalAllergies       []string           `json:"critical_allergies"`
+	Conflicts               []AllergenConflict `json:"conflicts"` // Against the outlet's menu
+	Acknowledged            bool               `json:"acknowledged"`
+	AcknowledgementRequired bool               `json:"acknowledgement_required"`
+}
+
+// DietaryProfileRequest replaces a guest's allergies and dietary restrictions
+type DietaryProfileRequest struct {
+	Allergies           []GuestAllergy `json:"allergies"`
+	DietaryRestrictions []string       `json:"dietary_restrictions"`
+}
+
+// AllergenService manages guest dietary profiles and allergen acknowledgements
+type AllergenService struct {
+	db *gorm.DB
+}
+
+// NewAllergenService creates a new allergen service
+func NewAllergenService(db *gorm.DB) *AllergenService {
+	return &AllergenService{
+		db: db,
+	}
+}
+
+// GetProfile returns a guest's allergies and dietary restrictions
+func (s *AllergenService) GetProfile(guestID uint) (*DietaryProfile, error) {
+	var guest models.Guest
+	if err := s.db.Select("id").First(&guest, guestID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDietaryGuestNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch guest: %w", err)
+	}
+	return loadDietaryProfile(s.db, guestID)
+}
+
+// UpdateProfile replaces a guest's allergies and dietary restrictions with taxonomy entries
+func (s *AllergenService) UpdateProfile(guestID uint, req DietaryProfileRequest) (*DietaryProfile, error) {
+	var guest models.Guest
+	if err := s.db.Select("id").First(&guest, guestID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDietaryGuestNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch guest: %w", err)
+	}
+
+	profile := DietaryProfile{GuestID: guestID, Allergies: []GuestAllergy{}, DietaryRestrictions: []string{}}
+	seen := make(map[string]bool)
+	for _, allergy := range req.Allergies {
+		code, ok := normalizeAllergen(allergy.Code)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownAllergen, allergy.Code)
+		}
+		severity := strings.ToLower(strings.TrimSpace(allergy.Severity))
+		if severity == "" {
+			severity = AllergySeveritySevere
+		}
+		if allergySeverityRank(severity) < 0 {
+			return nil, ErrUnknownSeverity
+		}
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		profile.Allergies = append(profile.Allergies, GuestAllergy{Code: code, Severity: severity, Notes: strings.TrimSpace(allergy.Notes)})
+	}
+	for _, value := range req.DietaryRestrictions {
+		code, ok := normalizeDietary(value)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownDietary, value)
+		}
+		if !containsString(profile.DietaryRestrictions, code) {
+			profile.DietaryRestrictions = append(profile.DietaryRestrictions, code)
+		}
+	}
+
+	allergies, err := json.Marshal(profile.Allergies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode allergies: %w", err)
+	}
+	dietary, err := json.Marshal(profile.DietaryRestrictions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode dietary restrictions: %w", err)
+	}
+
+	var preferences []models.GuestPreference
+	if err := s.db.Where("guest_id = ?", guestID).Limit(1).Find(&preferences).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch guest preferences: %w", err)
+	}
+	if len(preferences) == 0 {
+		err = s.db.Create(&models.GuestPreference{GuestID: guestID, Allergies: string(allergies), DietaryRestr: string(dietary)}).Error
+	} else {
+		err = s.db.Model(&preferences[0]).Updates(map[string]interface{}{
+			"allergies":     string(allergies),
+			"dietary_restr": string(dietary),
+		}).Error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save guest preferences: %w", err)
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":   "AllergenService",
+		"method":    "UpdateProfile",
+		"guest_id":  guestID,
+		"allergies": len(profile.Allergies),
+		"dietary":   len(profile.DietaryRestrictions),
+	}).Info("Guest dietary profile updated")
+
+	return &profile, nil
+}
+
+// CheckRoom returns the allergy warnings for the guest breakfast is for in a
+// room, checked against an outlet's menu when one is given
+func (s *AllergenService) CheckRoom(propertyID, roomNumber string, outletID uint) (*RoomAllergyCheck, error) {
+	engine, err := LoadEligibilityEngine(s.db, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	now := engine.Now()
+	businessDate := engine.clock.BusinessDate(now)
+
+	guest, err := guestForBreakfast(s.db, engine, propertyID, roomNumber, businessDate, &now, true)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := loadDietaryProfile(s.db, guest.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &RoomAllergyCheck{
+		GuestID:           guest.ID,
+		GuestName:         strings.TrimSpace(guest.FirstName + " " + guest.LastName),
+		RoomNumber:        guest.RoomNumber,
+		Profile:           *profile,
+		CriticalAllergies: profile.criticalAllergies(),
+		Conflicts:         []AllergenConflict{},
+	}
+
+	if outletID != 0 {
+		var items []models.MenuItem
+		if err := s.db.Where("outlet_id = ? AND is_available = ?", outletID, true).Find(&items).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch menu: %w", err)
+		}
+		for _, item := range items {
+			check.Conflicts = append(check.Conflicts, profile.conflicts(item.ID, item.Name, item.AllergenTags, item.DietaryTags)...)
+		}
+	}
+
+	// Staff acknowledge a guest's allergies once per business day and outlet
+	query := s.db.Model(&models.AllergyAcknowledgement{}).
+		Where("guest_id = ? AND DATE(business_date) = ? AND context = ?", guest.ID, businessDate.Format("2006-01-02"), AcknowledgementContextRoom)
+	if outletID != 0 {
+		query = query.Where("outlet_id = ?", outletID)
+	}
+	var acknowledged int64
+	if err := query.Count(&acknowledged).Error; err != nil {
+		return nil, fmt.Errorf("failed to check acknowledgements: %w", err)
+	}
+	check.Acknowledged = acknowledged > 0
+	check.AcknowledgementRequired = !check.Acknowledged && (len(check.CriticalAllergies) > 0 || len(check.Conflicts) > 0)
+
+	return check, nil
+}
+
+// Acknowledge records staff confirming they have seen a guest's allergy warnings
+// for the day, optionally at an outlet
+func (s *AllergenService) Acknowledge(guestID uint, outletID *uint, staffID uint) (*models.AllergyAcknowledgement, error) {
+	var guest models.Guest
+	if err := s.db.First(&guest, guestID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDietaryGuestNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch guest: %w", err)
+	}
+
+	profile, err := loadDietaryProfile(s.db, guest.ID)
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []AllergenConflict
+	if outletID != nil {
+		var items []models.MenuItem
+		if err := s.db.Where("outlet_id = ? AND is_available = ?", *outletID, true).Find(&items).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch menu: %w", err)
+		}
+		for _, item := range items {
+			conflicts = append(conflicts, profile.conflicts(item.ID, item.Name, item.AllergenTags, item.DietaryTags)...)
+		}
+	}
+
+	clock, err := LoadPropertyClock(s.db, guest.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+	return recordAcknowledgement(s.db, &guest, clock, AcknowledgementContextRoom, outletID, nil, conflicts, staffID)
+}
+
+// Acknowledgement contexts
+const (
+	AcknowledgementContextRoom  = "room"
+	AcknowledgementContextOrder = "order"
+)
+
+// recordAcknowledgement stores an allergy acknowledgement with the conflicts staff were shown
+func recordAcknowledgement(tx *gorm.DB, guest *models.Guest, clock *PropertyClock, context string, outletID, orderID *uint, conflicts []AllergenConflict, staffID uint) (*models.AllergyAcknowledgement, error) {
+	if conflicts == nil {
+		conflicts = []AllergenConflict{}
+	}
+	encoded, err := json.Marshal(conflicts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode conflicts: %w", err)
+	}
+
+	now := clock.Now()
+	acknowledgement := models.AllergyAcknowledgement{
+		GuestID:        guest.ID,
+		PropertyID:     guest.PropertyID,
+		BusinessDate:   clock.BusinessDate(now),
+		OutletID:       outletID,
+		OrderID:        orderID,
+		Context:        context,
+		Conflicts:      string(encoded),
+		AcknowledgedBy: staffID,
+		AcknowledgedAt: now,
+	}
+	if err := tx.Create(&acknowledgement).Error; err != nil {
+		return nil, fmt.Errorf("failed to record allergy acknowledgement: %w", err)
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":   "AllergenService",
+		"method":    "recordAcknowledgement",
+		"guest_id":  guest.ID,
+		"context":   context,
+		"conflicts": len(conflicts),
+		"staff_id":  staffID,
+	}).Info("Allergy warnings acknowledged")
+
+	return &acknowledgement, nil
+}
+
+// checkOrderAllergies checks items added to an order against the guest's
+// allergies. Conflicts block the items until staff acknowledge them, and the
+// acknowledgement is recorded against the order.
+func checkOrderAllergies(tx *gorm.DB, order *models.Order, items []models.OrderItem, acknowledged bool, staffID uint) error {
+	if order.GuestID == 0 || len(items) == 0 {
+		return nil
+	}
+	profile, err := loadDietaryProfile(tx, order.GuestID)
+	if err != nil {
+		return err
+	}
+
+	var conflicts []AllergenConflict
+	for _, item := range items {
+		conflicts = append(conflicts, profile.conflicts(item.MenuItemID, item.Name, item.AllergenTags, item.DietaryTags)...)
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	if !acknowledged {
+		return &AllergenConflictError{Conflicts: conflicts}
+	}
+
+	var guest models.Guest
+	if err := tx.First(&guest, order.GuestID).Error; err != nil {
+		return fmt.Errorf("failed to fetch guest: %w", err)
+	}
+	clock, err := LoadPropertyClock(tx, order.PropertyID)
+	if err != nil {
+		return err
+	}
+	outletID, orderID := order.OutletID, order.ID
+	_, err = recordAcknowledgement(tx, &guest, clock, AcknowledgementContextOrder, &outletID, &orderID, conflicts, staffID)
+	return err
+}
+
+// loadDietaryProfile reads a guest's stored preferences into a dietary profile.
+// Allergies stored before the taxonomy as plain names are matched against it
+// and treated as severe.
+func loadDietaryProfile(tx *gorm.DB, guestID uint) (*DietaryProfile, error) {
+	profile := &DietaryProfile{GuestID: guestID, Allergies: []GuestAllergy{}, DietaryRestrictions: []string{}}
+
+	var preferences []models.GuestPreference
+	if err := tx.Where("guest_id = ?", guestID).Limit(1).Find(&preferences).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch guest preferences: %w", err)
+	}
+	if len(preferences) == 0 {
+		return profile, nil
+	}
+
+	if value := strings.TrimSpace(preferences[0].Allergies); value != "" {
+		var allergies []GuestAllergy
+		if err := json.Unmarshal([]byte(value), &allergies); err != nil {
+			allergies = nil
+			for _, name := range parseLegacyList(value) {
+				allergy := GuestAllergy{Code: name, Severity: AllergySeveritySevere}
+				if code, ok := normalizeAllergen(name); ok {
+					allergy.Code = code
+				}
+				allergies = append(allergies, allergy)
+			}
+		}
+		profile.Allergies = append(profile.Allergies, allergies...)
+	}
+
+	if value := strings.TrimSpace(preferences[0].DietaryRestr); value != "" {
+		for _, name := range parseLegacyList(value) {
+			if code, ok := normalizeDietary(name); ok && !containsString(profile.DietaryRestrictions, code) {
+				profile.DietaryRestrictions = append(profile.DietaryRestrictions, code)
+			}
+		}
+	}
+	return profile, nil
+}
+
+// criticalAllergies returns the codes of the guest's critical allergies
+func (p *DietaryProfile) criticalAllergies() []string {
+	critical := []string{}
+	for _, allergy := range p.Allergies {
+		if allergy.Severity == AllergySeverityCritical {
+			critical = append(critical, allergy.Code)
+		}
+	}
+	return critical
+}
+
+// hasCritical reports whether any of the conflicts involve a critical allergy
+func hasCritical(conflicts []AllergenConflict) bool {
+	for _, conflict := range conflicts {
+		if conflict.Severity == AllergySeverityCritical {
+			return true
+		}
+	}
+	return false
+}
+
+// conflicts returns how an item with the given tags clashes with the profile
+func (p *DietaryProfile) conflicts(menuItemID uint, name, allergenTags, dietaryTags string) []AllergenConflict {
+	allergens := splitTags(allergenTags)
+	suitableFor := splitTags(dietaryTags)
+
+	var conflicts []AllergenConflict
+	for _, allergy := range p.Allergies {
+		if containsString(allergens, allergy.Code) {
+			conflicts = append(conflicts, AllergenConflict{
+				MenuItemID: menuItemID,
+				ItemName:   name,
+				Kind:       "allergen",
+				Code:       allergy.Code,
+				Severity:   allergy.Severity,
+			})
+		}
+	}
+
+	for _, code := range p.DietaryRestrictions {
+		restriction, ok := dietaryRestriction(code)
+		if !ok {
+			continue
+		}
+		broken := false
+		for _, excluded := range restriction.Excludes {
+			if containsString(allergens, excluded) {
+				broken = true
+				break
+			}
+		}
+		if !broken && len(restriction.SatisfiedBy) > 0 {
+			broken = true
+			for _, tag := range restriction.SatisfiedBy {
+				if containsString(suitableFor, tag) {
+					broken = false
+					break
+				}
+			}
+		}
+		if broken {
+			conflicts = append(conflicts, AllergenConflict{
+				MenuItemID: menuItemID,
+				ItemName:   name,
+				Kind:       "dietary",
+				Code:       code,
+				Severity:   "dietary",
+			})
+		}
+	}
+	return conflicts
+}
+
+// flagRoomAllergies marks the rooms whose guests have critical allergies
+func flagRoomAllergies(tx *gorm.DB, statuses []models.RoomBreakfastStatus) error {
+	var guestIDs []uint
+	for _, status := range statuses {
+		if status.GuestID != 0 {
+			guestIDs = append(guestIDs, status.GuestID)
+		}
+	}
+	if len(guestIDs) == 0 {
+		return nil
+	}
+
+	var preferences []models.GuestPreference
+	if err := tx.Where("guest_id IN ? AND allergies <> ''", guestIDs).Find(&preferences).Error; err != nil {
+		return fmt.Errorf("failed to fetch guest preferences: %w", err)
+	}
+	critical := make(map[uint][]string, len(preferences))
+	for _, preference := range preferences {
+		profile, err := loadDietaryProfile(tx, preference.GuestID)
+		if err != nil {
+			return err
+		}
+		if codes := profile.criticalAllergies(); len(codes) > 0 {
+			critical[preference.GuestID] = codes
+		}
+	}
+
+	for i := range statuses {
+		if codes, ok := critical[statuses[i].GuestID]; ok {
+			statuses[i].CriticalAllergy = true
+			statuses[i].CriticalAllergies = codes
+		}
+	}
+	return nil
+}
+
+// normalizeAllergen maps a code, name or alias to its taxonomy code
+func normalizeAllergen(value string) (string, bool) {
+	key := taxonomyKey(value)
+	for _, allergen := range AllergenTaxonomy {
+		if key == allergen.Code || key == taxonomyKey(allergen.Name) {
+			return allergen.Code, true
+		}
+		for _, alias := range allergen.Aliases {
+			if key == alias {
+				return allergen.Code, true
+			}
+		}
+	}
+	return "", false
+}
+
+// normalizeDietary maps a code or name to its dietary taxonomy code
+func normalizeDietary(value string) (string, bool) {
+	key := taxonomyKey(value)
+	for _, restriction := range DietaryTaxonomy {
+		if key == restriction.Code || key == taxonomyKey(restriction.Name) {
+			return restriction.Code, true
+		}
+	}
+	return "", false
+}
+
+func dietaryRestriction(code string) (DietaryRestriction, bool) {
+	for _, restriction := range DietaryTaxonomy {
+		if restriction.Code == code {
+			return restriction, true
+		}
+	}
+	return DietaryRestriction{}, false
+}
+
+// normalizeTagList validates comma-separated tags against a taxonomy and returns them as codes
+func normalizeTagList(value string, normalize func(string) (string, bool), unknown error) (string, error) {
+	var codes []string
+	for _, tag := range strings.Split(value, ",") {
+		if strings.TrimSpace(tag) == "" {
+			continue
+		}
+		code, ok := normalize(tag)
+		if !ok {
+			return "", fmt.Errorf("%w: %q", unknown, strings.TrimSpace(tag))
+		}
+		if !containsString(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	return strings.Join(codes, ","), nil
+}
+
+func allergySeverityRank(severity string) int {
+	switch severity {
+	case AllergySeverityMild:
+		return 0
+	case AllergySeveritySevere:
+		return 1
+	case AllergySeverityCritical:
+		return 2
+	}
+	return -1
+}
+
+// parseLegacyList reads a preference stored as a JSON array of names or a plain comma-separated list
+func parseLegacyList(value string) []string {
+	var values []string
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		values = strings.Split(value, ",")
+	}
+
+	list := make([]string, 0, len(values))
+	for _, entry := range values {
+		if entry = strings.ToLower(strings.TrimSpace(entry)); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+func taxonomyKey(value string) string {
+	key := strings.ToLower(strings.TrimSpace(value))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+}
+
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,690 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"hudini-breakfast-module/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// maxAnalyticsDays caps the business dates one analytics range may span
+const maxAnalyticsDays = 366
+
+// Errors returned by the analytics service
+var (
+	ErrInvalidAnalyticsRange = errors.New("analytics range must start on or before its end and span at most 366 days")
+)
+
+// AnalyticsService computes breakfast analytics from guests, visits, rooms,
+// staff comments and inventory over arbitrary ranges of business dates
+type AnalyticsService struct {
+	db *gorm.DB
+}
+
+// AnalyticsWindow is the range being analysed and the range it is compared with
+type AnalyticsWindow struct {
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	CompareFrom time.Time `json:"compare_from"`
+	CompareTo   time.Time `json:"compare_to"`
+	Comparison  string    `json:"comparison"` // previous, year
+}
+
+// AnalyticsSummary is a property's, or a segment's, analytics over a range of business dates
+type AnalyticsSummary struct {
+	From             string  `json:"from"`
+	To               string  `json:"to"`
+	Guests           int     `json:"guests"` // Guests in house during the range
+	Covers           int     `json:"covers"` // All covers served
+	Revenue          float64 `json:"revenue"`
+	EntitledCovers   int     `json:"entitled_covers"`    // Covers included in packages up to today
+	TakenCovers      int     `json:"taken_covers"`       // Entitled covers that were served
+	TakeUpRate       float64 `json:"take_up_rate"`       // Taken covers per entitled cover, 0-100
+	OccupancyRate    float64 `json:"occupancy_rate"`     // Average share of rooms occupied per night, 0-100; property only
+	RoomNights       int     `json:"room_nights"`        // Occupied room nights; property only
+	AverageStay      float64 `json:"average_stay"`       // Nights per stay arriving during the range
+	FoodCost         float64 `json:"food_cost"`          // Supplies depleted by the visits, at unit cost
+	CostPerBreakfast float64 `json:"cost_per_breakfast"` // Food cost per cover served
+	SatisfactionRate float64 `json:"satisfaction_rate"`  // Share of guests seen without a complaint, 0-100
+	LoyaltyShare     float64 `json:"loyalty_share"`      // Share of guests enrolled in a loyalty tier, 0-100
+}
+
+// AnalyticsSegment is the summary of the guests sharing a room type, VIP status or loyalty tier
+type AnalyticsSegment struct {
+	Dimension string `json:"dimension"` // room_type, vip, loyalty_tier
+	Name      string `json:"name"`
+	AnalyticsSummary
+}
+
+// AnalyticsPoint is one labelled value of an analytics chart
+type AnalyticsPoint struct {
+	Label string  `json:"label"`
+	Date  string  `json:"date,omitempty"`
+	Value float64 `json:"value"`
+}
+
+// AnalyticsSnapshot is where a property stands on today's business date
+type AnalyticsSnapshot struct {
+	TotalRooms    int `json:"total_rooms"`
+	OccupiedRooms int `json:"occupied_rooms"`
+	PackageGuests int `json:"package_guests"` // In-house guests with a breakfast package
+	ConsumedToday int `json:"consumed_today"` // Covers served today
+}
+
+// AnalyticsReport is everything the analytics dashboards show for one window
+type AnalyticsReport struct {
+	PropertyID     string             `json:"property_id"`
+	Window         AnalyticsWindow    `json:"window"`
+	Current        *AnalyticsSummary  `json:"current"`
+	Comparison     *AnalyticsSummary  `json:"comparison"`
+	Segments       []AnalyticsSegment `json:"segments"`
+	Granularity    string             `json:"granularity"`     // day, week or month, by the length of the range
+	RevenueByDate  []AnalyticsPoint   `json:"revenue_by_date"` // Revenue per bucket of the range
+	CoversByHour   []AnalyticsPoint   `json:"covers_by_hour"`  // Covers served per local hour of the day
+	PackageMix     []AnalyticsPoint   `json:"package_mix"`     // Guests in house by breakfast arrangement
+	MonthlyRevenue []AnalyticsPoint   `json:"monthly_revenue"` // Revenue for the six months ending with the range
+	Snapshot       AnalyticsSnapshot  `json:"snapshot"`
+}
+
+// AnalyticsBenchmark compares a property's metric with the other properties over the same range
+type AnalyticsBenchmark struct {
+	Metric        string  `json:"metric"`
+	Unit          string  `json:"unit"`
+	Value         float64 `json:"value"`
+	PeerAverage   float64 `json:"peer_average"`
+	PeerBest      float64 `json:"peer_best"`
+	Peers         int     `json:"peers"`
+	Rank          int     `json:"rank"` // 1 is best, out of Peers+1
+	LowerIsBetter bool    `json:"lower_is_better"`
+}
+
+// analyticsDataset is everything loaded for one property and range
+type analyticsDataset struct {
+	clock    *PropertyClock
+	from, to time.Time
+	rooms    map[string]string // Room number to room type
+	guests   []models.Guest
+	stats    map[uint]*guestAnalytics
+	unknown  guestAnalytics // Visits whose guest stayed outside the range
+	upset    map[uint]bool
+	occupied int // Room nights
+	nights   int // Business dates up to today
+}
+
+// guestAnalytics is one guest's share of a dataset
+type guestAnalytics struct {
+	covers   int
+	revenue  float64
+	cost     float64
+	entitled int
+	taken    int
+}
+
+// NewAnalyticsService creates a new analytics service
+func NewAnalyticsService(db *gorm.DB) *AnalyticsService {
+	return &AnalyticsService{
+		db: db,
+	}
+}
+
+// Window resolves the range to analyse and its comparison range. A zero from
+// or to falls back to the period (day, week, month, quarter or year) ending
+// today. The comparison is the same number of days immediately before, or the
+// same dates a year earlier when comparison is "year".
+func (s *AnalyticsService) Window(propertyID, period, comparison string, from, to time.Time) (AnalyticsWindow, error) {
+	clock, err := LoadPropertyClock(s.db, propertyID)
+	if err != nil {
+		return AnalyticsWindow{}, err
+	}
+
+	if from.IsZero() || to.IsZero() {
+		to = clock.Today()
+		days := map[string]int{"day": 1, "week": 7, "month": 30, "quarter": 91, "year": 365}[period]
+		if days == 0 {
+			days = 7
+		}
+		from = to.AddDate(0, 0, 1-days)
+	}
+	from, to = calendarDate(from), calendarDate(to)
+	if from.After(to) || to.Sub(from) >= maxAnalyticsDays*24*time.Hour {
+		return AnalyticsWindow{}, ErrInvalidAnalyticsRange
+	}
+
+	window := AnalyticsWindow{From: from, To: to, Comparison: "previous"}
+	if comparison == "year" {
+		window.Comparison = comparison
+		window.CompareFrom, window.CompareTo = from.AddDate(-1, 0, 0), to.AddDate(-1, 0, 0)
+	} else {
+		days := int(to.Sub(from).Hours()/24) + 1
+		window.CompareFrom, window.CompareTo = from.AddDate(0, 0, -days), from.AddDate(0, 0, -1)
+	}
+	return window, nil
+}
+
+// Report computes a property's analytics for a window
+func (s *AnalyticsService) Report(propertyID string, window AnalyticsWindow) (*AnalyticsReport, error) {
+	engine, err := LoadEligibilityEngine(s.db, propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.load(engine, propertyID, window.From, window.To)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.load(engine, propertyID, window.CompareFrom, window.CompareTo)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AnalyticsReport{
+		PropertyID: propertyID,
+		Window:     window,
+		Current:    current.summarize(nil),
+		Comparison: previous.summarize(nil),
+		Segments:   current.segments(),
+		PackageMix: current.packageMix(),
+	}
+
+	if report.Granularity, report.RevenueByDate, err = s.revenueByDate(propertyID, window.From, window.To); err != nil {
+		return nil, err
+	}
+	if report.CoversByHour, err = s.coversByHour(engine.clock, propertyID, window.From, window.To); err != nil {
+		return nil, err
+	}
+	first := time.Date(window.To.Year(), window.To.Month()-5, 1, 0, 0, 0, 0, time.UTC)
+	if _, report.MonthlyRevenue, err = s.revenueBuckets(propertyID, "month", first, window.To); err != nil {
+		return nil, err
+	}
+	if report.Snapshot, err = s.snapshot(engine.clock, propertyID); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Benchmarks ranks a property's take-up, cost per breakfast, revenue per
+// occupied room night, occupancy and satisfaction against every other property
+// over the same range. It returns nothing when there are no other properties.
+func (s *AnalyticsService) Benchmarks(propertyID string, from, to time.Time) ([]AnalyticsBenchmark, error) {
+	var propertyIDs []string
+	if err := s.db.Model(&models.Property{}).Order("property_id").Pluck("property_id", &propertyIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch properties: %w", err)
+	}
+
+	var own *AnalyticsSummary
+	var peers []*AnalyticsSummary
+	for _, id := range append([]string{propertyID}, propertyIDs...) {
+		if id == propertyID && own != nil {
+			continue
+		}
+		engine, err := LoadEligibilityEngine(s.db, id)
+		if err != nil {
+			return nil, err
+		}
+		dataset, err := s.load(engine, id, calendarDate(from), calendarDate(to))
+		if err != nil {
+			return nil, err
+		}
+		if summary := dataset.summarize(nil); own == nil {
+			own = summary
+		} else {
+			peers = append(peers, summary)
+		}
+	}
+	if len(peers) == 0 {
+		return []AnalyticsBenchmark{}, nil
+	}
+
+	revPAR := func(summary *AnalyticsSummary) float64 {
+		if summary.RoomNights == 0 {
+			return 0
+		}
+		return summary.Revenue / float64(summary.RoomNights)
+	}
+	metrics := []struct {
+		name, unit    string
+		lowerIsBetter bool
+		value         func(*AnalyticsSummary) float64
+	}{
+		{"Breakfast Take-up Rate", "percent", false, func(m *AnalyticsSummary) float64 { return m.TakeUpRate }},
+		{"Cost per Breakfast", "currency", true, func(m *AnalyticsSummary) float64 { return m.CostPerBreakfast }},
+		{"Breakfast Revenue per Occupied Room", "currency", false, revPAR},
+		{"Occupancy Rate", "percent", false, func(m *AnalyticsSummary) float64 { return m.OccupancyRate }},
+		{"Guest Satisfaction", "percent", false, func(m *AnalyticsSummary) float64 { return m.SatisfactionRate }},
+	}
+
+	benchmarks := make([]AnalyticsBenchmark, 0, len(metrics))
+	for _, metric := range metrics {
+		benchmark := AnalyticsBenchmark{
+			Metric:        metric.name,
+			Unit:          metric.unit,
+			Value:         roundTo(metric.value(own), 2),
+			Peers:         len(peers),
+			Rank:          1,
+			LowerIsBetter: metric.lowerIsBetter,
+		}
+		better := func(a, b float64) bool {
+			if metric.lowerIsBetter {
+				return a < b
+			}
+			return a > b
+		}
+
+		total := 0.0
+		for i, peer := range peers {
+			value := metric.value(peer)
+			total += value
+			if i == 0 || better(value, benchmark.PeerBest) {
+				benchmark.PeerBest = value
+			}
+			if better(value, metric.value(own)) {
+				benchmark.Rank++
+			}
+		}
+		benchmark.PeerAverage = roundTo(total/float64(len(peers)), 2)
+		benchmark.PeerBest = roundTo(benchmark.PeerBest, 2)
+		benchmarks = append(benchmarks, benchmark)
+	}
+	return benchmarks, nil
+}
+
+// load fetches a property's stays, visits, complaints and food costs for the
+// business dates from through to, inclusive, and works out each guest's
+// breakfast entitlement on the dates up to today
+func (s *AnalyticsService) load(engine *EligibilityEngine, propertyID string, from, to time.Time) (*analyticsDataset, error) {
+	fromStr, toStr := from.Format("2006-01-02"), to.Format("2006-01-02")
+	data := &analyticsDataset{
+		clock: engine.clock,
+		from:  from,
+		to:    to,
+		rooms: make(map[string]string),
+		stats: make(map[uint]*guestAnalytics),
+		upset: make(map[uint]bool),
+	}
+
+	var rooms []models.Room
+	if err := s.db.Select("room_number", "room_type").Where("property_id = ?", propertyID).Find(&rooms).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch rooms: %w", err)
+	}
+	for _, room := range rooms {
+		data.rooms[room.RoomNumber] = room.RoomType
+	}
+
+	// Checked-out guests are deactivated, so past stays include inactive guests
+	err := s.db.Where("property_id = ? AND DATE(check_in_date) <= ? AND DATE(check_out_date) >= ?", propertyID, toStr, fromStr).
+		Order("room_number ASC, check_in_date ASC, id ASC").
+		Find(&data.guests).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stays: %w", err)
+	}
+	for _, guest := range data.guests {
+		data.stats[guest.ID] = &guestAnalytics{}
+	}
+
+	// Entitlement, one guest per room and date, and occupied room nights
+	entitled := make(map[string]int)
+	for date := from; !date.After(to) && !date.After(data.clock.Today()); date = date.AddDate(0, 0, 1) {
+		data.nights++
+		for start := 0; start < len(data.guests); {
+			end := start + 1
+			for end < len(data.guests) && data.guests[end].RoomNumber == data.guests[start].RoomNumber {
+				end++
+			}
+
+			var inHouse []models.Guest
+			night := false
+			for _, guest := range data.guests[start:end] {
+				if !calendarDate(guest.CheckInDate).After(date) && !calendarDate(guest.CheckOutDate).Before(date) {
+					inHouse = append(inHouse, guest)
+					night = night || calendarDate(guest.CheckOutDate).After(date)
+				}
+			}
+			if night {
+				data.occupied++
+			}
+			if len(inHouse) > 0 {
+				if guest, decision := engine.pick(inHouse, date, nil); decision.Eligible {
+					data.stats[guest.ID].entitled += decision.Covers
+					entitled[visitKey(guest.ID, date)] = decision.Covers
+				}
+			}
+			start = end
+		}
+	}
+
+	var visits []models.DailyBreakfastConsumption
+	err = s.db.Select("id", "guest_id", "consumption_date", "adult_covers", "child_covers", "upsell_covers", "amount").
+		Where("property_id = ? AND status = ? AND DATE(consumption_date) >= ? AND DATE(consumption_date) <= ?",
+			propertyID, "consumed", fromStr, toStr).
+		Find(&visits).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch visits: %w", err)
+	}
+
+	costs, err := s.visitCosts(propertyID, fromStr, toStr)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, visit := range visits {
+		stats, ok := data.stats[visit.GuestID]
+		if !ok {
+			stats = &data.unknown
+		}
+		covers := visit.AdultCovers + visit.ChildCovers
+		stats.covers += covers
+		stats.revenue += visit.Amount
+		stats.cost += costs[visit.ID]
+
+		key := visitKey(visit.GuestID, calendarDate(visit.ConsumptionDate))
+		if taken := minInt(covers-visit.UpsellCovers, entitled[key]); taken > 0 {
+			stats.taken += taken
+			entitled[key] -= taken
+		}
+	}
+
+	var complainants []uint
+	err = s.db.Model(&models.StaffComment{}).
+		Joins("JOIN guests ON guests.id = staff_comments.guest_id").
+		Where("guests.property_id = ? AND staff_comments.category = ?", propertyID, "complaint").
+		Where("staff_comments.created_at >= ? AND staff_comments.created_at < ?", data.clock.StartOf(from), data.clock.StartOf(to.AddDate(0, 0, 1))).
+		Distinct().Pluck("staff_comments.guest_id", &complainants).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch complaints: %w", err)
+	}
+	for _, guestID := range complainants {
+		data.upset[guestID] = true
+	}
+
+	return data, nil
+}
+
+// visitCosts prices the supplies each consumed visit in the range depleted,
+// net of reversals, at the items' unit costs
+func (s *AnalyticsService) visitCosts(propertyID, fromStr, toStr string) (map[uint]float64, error) {
+	visits := s.db.Model(&models.DailyBreakfastConsumption{}).Select("id").
+		Where("property_id = ? AND status = ? AND DATE(consumption_date) >= ? AND DATE(consumption_date) <= ?",
+			propertyID, "consumed", fromStr, toStr)
+
+	var rows []struct {
+		ConsumptionID uint
+		Cost          float64
+	}
+	err := s.db.Model(&models.InventoryMovement{}).
+		Select("inventory_movements.consumption_id, SUM(-inventory_movements.quantity * inventory_items.unit_cost) AS cost").
+		Joins("JOIN inventory_items ON inventory_items.id = inventory_movements.inventory_item_id").
+		Where("inventory_movements.type IN ? AND inventory_movements.consumption_id IN (?)", []string{MovementDepletion, MovementReversal}, visits).
+		Group("inventory_movements.consumption_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch food costs: %w", err)
+	}
+
+	costs := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		costs[row.ConsumptionID] = row.Cost
+	}
+	return costs, nil
+}
+
+// revenueByDate buckets revenue by day for ranges up to a month, by week up
+// to six months and by month beyond that
+func (s *AnalyticsService) revenueByDate(propertyID string, from, to time.Time) (string, []AnalyticsPoint, error) {
+	granularity := "month"
+	switch days := int(to.Sub(from).Hours()/24) + 1; {
+	case days <= 31:
+		granularity = "day"
+	case days <= 183:
+		granularity = "week"
+	}
+	return s.revenueBuckets(propertyID, granularity, from, to)
+}
+
+// revenueBuckets sums consumed visits' revenue per day, week (from the start
+// of the range) or calendar month between from and to, inclusive
+func (s *AnalyticsService) revenueBuckets(propertyID, granularity string, from, to time.Time) (string, []AnalyticsPoint, error) {
+	var visits []models.DailyBreakfastConsumption
+	err := s.db.Select("consumption_date", "amount").
+		Where("property_id = ? AND status = ? AND DATE(consumption_date) >= ? AND DATE(consumption_date) <= ?",
+			propertyID, "consumed", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Find(&visits).Error
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to fetch revenue: %w", err)
+	}
+
+	var points []AnalyticsPoint
+	var starts []time.Time
+	for start := from; !start.After(to); {
+		next := start.AddDate(0, 0, 1)
+		label := start.Format("Jan 2")
+		switch granularity {
+		case "week":
+			next = start.AddDate(0, 0, 7)
+			label = "Week of " + label
+		case "month":
+			next = time.Date(start.Year(), start.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			label = start.Format("Jan 2006")
+		}
+		points = append(points, AnalyticsPoint{Label: label, Date: start.Format("2006-01-02")})
+		starts = append(starts, start)
+		start = next
+	}
+
+	for _, visit := range visits {
+		date := calendarDate(visit.ConsumptionDate)
+		index := sort.Search(len(starts), func(i int) bool { return starts[i].After(date) }) - 1
+		if index >= 0 {
+			points[index].Value += visit.Amount
+		}
+	}
+	for i := range points {
+		points[i].Value = roundTo(points[i].Value, 2)
+	}
+	return granularity, points, nil
+}
+
+// coversByHour counts covers served per local hour, from 6 AM to 11 AM and
+// any earlier or later hours that saw service
+func (s *AnalyticsService) coversByHour(clock *PropertyClock, propertyID string, from, to time.Time) ([]AnalyticsPoint, error) {
+	var visits []models.DailyBreakfastConsumption
+	err := s.db.Select("consumed_at", "adult_covers", "child_covers").
+		Where("property_id = ? AND status = ? AND consumed_at IS NOT NULL AND DATE(consumption_date) >= ? AND DATE(consumption_date) <= ?",
+			propertyID, "consumed", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Find(&visits).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch visit times: %w", err)
+	}
+
+	counts := make([]int, 24)
+	first, last := 6, 11
+	for _, visit := range visits {
+		hour := visit.ConsumedAt.In(clock.Location).Hour()
+		counts[hour] += visit.AdultCovers + visit.ChildCovers
+		first, last = min(first, hour), max(last, hour)
+	}
+
+	points := make([]AnalyticsPoint, 0, last-first+1)
+	for hour := first; hour <= last; hour++ {
+		label := time.Date(2000, 1, 1, hour, 0, 0, 0, time.UTC).Format("3 PM")
+		points = append(points, AnalyticsPoint{Label: label, Value: float64(counts[hour])})
+	}
+	return points, nil
+}
+
+// snapshot counts today's rooms, occupied rooms, package guests and covers served
+func (s *AnalyticsService) snapshot(clock *PropertyClock, propertyID string) (AnalyticsSnapshot, error) {
+	var snapshot AnalyticsSnapshot
+	today := clock.Today().Format("2006-01-02")
+
+	var rooms int64
+	if err := s.db.Model(&models.Room{}).Where("property_id = ?", propertyID).Count(&rooms).Error; err != nil {
+		return snapshot, fmt.Errorf("failed to count rooms: %w", err)
+	}
+	snapshot.TotalRooms = int(rooms)
+
+	var guests []models.Guest
+	err := s.db.Select("room_number", "breakfast_package").
+		Where("property_id = ? AND is_active = ? AND DATE(check_in_date) <= ? AND DATE(check_out_date) > ?", propertyID, true, today, today).
+		Find(&guests).Error
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to fetch in-house guests: %w", err)
+	}
+	occupied := make(map[string]bool)
+	for _, guest := range guests {
+		occupied[guest.RoomNumber] = true
+		if guest.BreakfastPackage {
+			snapshot.PackageGuests++
+		}
+	}
+	snapshot.OccupiedRooms = len(occupied)
+
+	var covers int64
+	err = s.db.Model(&models.DailyBreakfastConsumption{}).
+		Select("COALESCE(SUM(adult_covers + child_covers), 0)").
+		Where("property_id = ? AND status = ? AND DATE(consumption_date) = ?", propertyID, "consumed", today).
+		Scan(&covers).Error
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to count today's covers: %w", err)
+	}
+	snapshot.ConsumedToday = int(covers)
+	return snapshot, nil
+}
+
+// summarize aggregates the guests matching include, or the whole property when include is nil
+func (d *analyticsDataset) summarize(include func(*models.Guest) bool) *AnalyticsSummary {
+	summary := &AnalyticsSummary{From: d.from.Format("2006-01-02"), To: d.to.Format("2006-01-02")}
+
+	var totals guestAnalytics
+	add := func(stats *guestAnalytics) {
+		totals.covers += stats.covers
+		totals.revenue += stats.revenue
+		totals.cost += stats.cost
+		totals.entitled += stats.entitled
+		totals.taken += stats.taken
+	}
+
+	stays, nights, enrolled, seen, upset := 0, 0, 0, 0, 0
+	for i := range d.guests {
+		guest := &d.guests[i]
+		if include != nil && !include(guest) {
+			continue
+		}
+		stats := d.stats[guest.ID]
+		add(stats)
+		summary.Guests++
+		if guest.LoyaltyTier != "" {
+			enrolled++
+		}
+		if checkIn := calendarDate(guest.CheckInDate); !checkIn.Before(d.from) && !checkIn.After(d.to) {
+			stays++
+			nights += max(int(calendarDate(guest.CheckOutDate).Sub(checkIn).Hours()/24), 1)
+		}
+		if stats.covers > 0 || d.upset[guest.ID] {
+			seen++
+			if d.upset[guest.ID] {
+				upset++
+			}
+		}
+	}
+	if include == nil {
+		add(&d.unknown)
+		summary.RoomNights = d.occupied
+		if len(d.rooms) > 0 && d.nights > 0 {
+			summary.OccupancyRate = roundTo(float64(d.occupied)/float64(d.nights*len(d.rooms))*100, 1)
+		}
+	}
+
+	summary.Covers = totals.covers
+	summary.Revenue = roundTo(totals.revenue, 2)
+	summary.FoodCost = roundTo(totals.cost, 2)
+	summary.EntitledCovers = totals.entitled
+	summary.TakenCovers = totals.taken
+	if totals.entitled > 0 {
+		summary.TakeUpRate = roundTo(float64(totals.taken)/float64(totals.entitled)*100, 1)
+	}
+	if totals.covers > 0 {
+		summary.CostPerBreakfast = roundTo(totals.cost/float64(totals.covers), 2)
+	}
+	if stays > 0 {
+		summary.AverageStay = roundTo(float64(nights)/float64(stays), 1)
+	}
+	if seen > 0 {
+		summary.SatisfactionRate = roundTo(float64(seen-upset)/float64(seen)*100, 1)
+	}
+	if summary.Guests > 0 {
+		summary.LoyaltyShare = roundTo(float64(enrolled)/float64(summary.Guests)*100, 1)
+	}
+	return summary
+}
+
+// segments summarizes the guests by room type, VIP status and loyalty tier
+func (d *analyticsDataset) segments() []AnalyticsSegment {
+	dimensions := []struct {
+		name string
+		key  func(*models.Guest) string
+	}{
+		{"room_type", func(g *models.Guest) string {
+			if roomType := d.rooms[g.RoomNumber]; roomType != "" {
+				return roomType
+			}
+			return "unassigned"
+		}},
+		{"vip", func(g *models.Guest) string {
+			if g.IsVIP {
+				return "VIP"
+			}
+			return "Non-VIP"
+		}},
+		{"loyalty_tier", func(g *models.Guest) string {
+			if g.LoyaltyTier != "" {
+				return g.LoyaltyTier
+			}
+			return "none"
+		}},
+	}
+
+	segments := []AnalyticsSegment{}
+	for _, dimension := range dimensions {
+		var names []string
+		seen := make(map[string]bool)
+		for i := range d.guests {
+			if name := dimension.key(&d.guests[i]); !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			summary := d.summarize(func(g *models.Guest) bool { return dimension.key(g) == name })
+			segments = append(segments, AnalyticsSegment{Dimension: dimension.name, Name: name, AnalyticsSummary: *summary})
+		}
+	}
+	return segments
+}
+
+// packageMix counts the guests in house by whether breakfast was included,
+// bought without a package, or not taken at all
+func (d *analyticsDataset) packageMix() []AnalyticsPoint {
+	mix := []AnalyticsPoint{{Label: "Breakfast package"}, {Label: "Room only, bought breakfast"}, {Label: "Room only"}}
+	for _, guest := range d.guests {
+		switch {
+		case guest.BreakfastPackage:
+			mix[0].Value++
+		case d.stats[guest.ID].covers > 0:
+			mix[1].Value++
+		default:
+			mix[2].Value++
+		}
+	}
+	return mix
+}
+
+// visitKey identifies a guest's breakfast on a business date
+func visitKey(guestID uint, date time.Time) string {
+	return fmt.Sprintf("%d/%s", guestID, date.Format("2006-01-02"))
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"hudini-breakfast-module/internal/audit"
+	"hudini-breakfast-module/internal/export"
 	"hudini-breakfast-module/internal/models"
 
 	"gorm.io/gorm"
@@ -91,33 +92,7 @@ func (s *AuditService) LogFailure(ctx context.Context, userID *uint, action audi
 
 // GetAuditLogs retrieves audit logs with filters
 func (s *AuditService) GetAuditLogs(ctx context.Context, filters AuditFilters) ([]models.AuditLog, int64, error) {
-	query := s.db.WithContext(ctx).Model(&models.AuditLog{})
-
-	// Apply filters
-	if filters.UserID != nil {
-		query = query.Where("user_id = ?", *filters.UserID)
-	}
-	if filters.Action != "" {
-		query = query.Where("action = ?", filters.Action)
-	}
-	if filters.Resource != "" {
-		query = query.Where("resource = ?", filters.Resource)
-	}
-	if filters.ResourceID != "" {
-		query = query.Where("resource_id = ?", filters.ResourceID)
-	}
-	if filters.Status != "" {
-		query = query.Where("status = ?", filters.Status)
-	}
-	if filters.IPAddress != "" {
-		query = query.Where("ip_address = ?", filters.IPAddress)
-	}
-	if !filters.StartDate.IsZero() {
-		query = query.Where("created_at >= ?", filters.StartDate)
-	}
-	if !filters.EndDate.IsZero() {
-		query = query.Where("created_at <= ?", filters.EndDate)
-	}
+	query := s.filtered(ctx, filters)
 
 	// Count total records
 	var total int64
@@ -152,6 +127,76 @@ func (s *AuditService) GetAuditLogs(ctx context.Context, filters AuditFilters) (
 	return logs, total, nil
 }
 
+// ExportAuditLogs streams every audit log matching the filters, ignoring
+// pagination, in batches, and returns how many were written
+func (s *AuditService) ExportAuditLogs(ctx context.Context, filters AuditFilters, w export.Writer) (int, error) {
+	if err := w.Table("Audit log", auditLogColumns); err != nil {
+		return 0, err
+	}
+
+	// Entries are paged by ID, which follows creation order
+	ascending := filters.SortOrder == "asc"
+	order, after := "id DESC", "id < ?"
+	if ascending {
+		order, after = "id ASC", "id > ?"
+	}
+
+	rows := 0
+	var last uint
+	for {
+		query := s.filtered(ctx, filters).Preload("User").Order(order).Limit(exportBatchSize)
+		if rows > 0 {
+			query = query.Where(after, last)
+		}
+		var logs []models.AuditLog
+		if err := query.Find(&logs).Error; err != nil {
+			return rows, fmt.Errorf("failed to retrieve audit logs: %w", err)
+		}
+		for i := range logs {
+			if err := writeAuditLog(w, &logs[i]); err != nil {
+				return rows, err
+			}
+			rows++
+		}
+		if len(logs) < exportBatchSize {
+			return rows, nil
+		}
+		last = logs[len(logs)-1].ID
+	}
+}
+
+// filtered returns a query for the audit logs matching the filters
+func (s *AuditService) filtered(ctx context.Context, filters AuditFilters) *gorm.DB {
+	query := s.db.WithContext(ctx).Model(&models.AuditLog{})
+
+	// Apply filters
+	if filters.UserID != nil {
+		query = query.Where("user_id = ?", *filters.UserID)
+	}
+	if filters.Action != "" {
+		query = query.Where("action = ?", filters.Action)
+	}
+	if filters.Resource != "" {
+		query = query.Where("resource = ?", filters.Resource)
+	}
+	if filters.ResourceID != "" {
+		query = query.Where("resource_id = ?", filters.ResourceID)
+	}
+	if filters.Status != "" {
+		query = query.Where("status = ?", filters.Status)
+	}
+	if filters.IPAddress != "" {
+		query = query.Where("ip_address = ?", filters.IPAddress)
+	}
+	if !filters.StartDate.IsZero() {
+		query = query.Where("created_at >= ?", filters.StartDate)
+	}
+	if !filters.EndDate.IsZero() {
+		query = query.Where("created_at <= ?", filters.EndDate)
+	}
+	return query
+}
+
 // AuditFilters represents filters for querying audit logs
 type AuditFilters struct {
 	UserID     *uint
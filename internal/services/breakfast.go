@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"hudini-breakfast-module/internal/cache"
+	"hudini-breakfast-module/internal/config"
+	"hudini-breakfast-module/internal/export"
 	"hudini-breakfast-module/internal/logging"
 	"hudini-breakfast-module/internal/models"
 
@@ -15,15 +18,23 @@ import (
 )
 
 type BreakfastService struct {
-	db          *gorm.DB
-	ohipService *OHIPService
-	vipCache    *cache.VIPCache
+	db           *gorm.DB
+	ohipService  *OHIPService
+	vipCache     *cache.VIPCache
+	rollups      *RollupService
+	chargePoster VisitChargePoster
+}
+
+// VisitChargePoster posts a visit's breakfast charge to the guest's room folio
+type VisitChargePoster interface {
+	ChargeBreakfast(guestID, reservationID, roomNumber string, amount, taxAmount float64, propertyID string, businessDate time.Time) (*PMSChargeResponse, error)
 }
 
 func NewBreakfastService(db *gorm.DB, ohipService *OHIPService) *BreakfastService {
 	return &BreakfastService{
 		db:          db,
 		ohipService: ohipService,
+		rollups:     NewRollupService(db, config.RollupConfig{}),
 	}
 }
 
@@ -33,9 +44,25 @@ func NewBreakfastServiceWithCache(db *gorm.DB, ohipService *OHIPService, vipCach
 		db:          db,
 		ohipService: ohipService,
 		vipCache:    vipCache,
+		rollups:     NewRollupService(db, config.RollupConfig{}),
 	}
 }
 
+// SetRollups sets the rollups daily reports are read from and visits are reported to
+func (s *BreakfastService) SetRollups(rollups *RollupService) {
+	s.rollups = rollups
+}
+
+// SetChargePoster sets the PMS visits charged to the room are posted to
+func (s *BreakfastService) SetChargePoster(poster VisitChargePoster) {
+	s.chargePoster = poster
+}
+
+// BusinessDate returns the property's current business date
+func (s *BreakfastService) BusinessDate(propertyID string) time.Time {
+	return currentBusinessDate(s.db, propertyID)
+}
+
 // Room Grid Management
 func (s *BreakfastService) GetRoomBreakfastStatus(propertyID string) ([]models.RoomBreakfastStatus, error) {
 	logging.WithFields(logrus.Fields{
@@ -44,6 +71,7 @@ func (s *BreakfastService) GetRoomBreakfastStatus(propertyID string) ([]models.R
 		"property_id": propertyID,
 	}).Debug("Fetching room breakfast status")
 
+	today := currentBusinessDate(s.db, propertyID).Format("2006-01-02")
 	var roomStatuses []models.RoomBreakfastStatus
 
 	query := `
@@ -54,9 +82,18 @@ func (s *BreakfastService) GetRoomBreakfastStatus(propertyID string) ([]models.R
 			r.room_type,
 			r.status,
 			CASE WHEN g.id IS NOT NULL THEN true ELSE false END as has_guest,
+			COALESCE(g.id, 0) as guest_id,
 			COALESCE(g.first_name || ' ' || g.last_name, '') as guest_name,
 			COALESCE(g.breakfast_package, false) as breakfast_package,
 			COALESCE(g.breakfast_count, 0) as breakfast_count,
+			COALESCE(g.adult_count, 0) as adult_count,
+			COALESCE(g.child_count, 0) as child_count,
+			COALESCE(g.covers_entitled, 0) as covers_entitled,
+			COALESCE(dbc.covers_consumed, 0) as covers_consumed,
+			COALESCE(dbc.entitled_consumed, 0) as entitled_consumed,
+			CASE WHEN COALESCE(g.covers_entitled, 0) > COALESCE(dbc.entitled_consumed, 0)
+				THEN COALESCE(g.covers_entitled, 0) - COALESCE(dbc.entitled_consumed, 0)
+				ELSE 0 END as covers_remaining,
 			CASE WHEN dbc.id IS NOT NULL THEN true ELSE false END as consumed_today,
 			dbc.consumed_at,
 			COALESCE(s.first_name || ' ' || s.last_name, '') as consumed_by,
@@ -68,24 +105,32 @@ func (s *BreakfastService) GetRoomBreakfastStatus(propertyID string) ([]models.R
 		FROM rooms r
 		LEFT JOIN (
 			SELECT DISTINCT room_number, property_id, first_name, last_name, 
-				breakfast_package, breakfast_count, check_in_date, check_out_date, id,
+				breakfast_package, breakfast_count, adult_count, child_count,
+				CASE WHEN breakfast_package = false THEN 0
+					WHEN breakfast_count > 0 THEN breakfast_count
+					ELSE adult_count + child_count END as covers_entitled,
+				check_in_date, check_out_date, id,
 				is_vip, is_upset, pms_special_requests
 			FROM guests 
 			WHERE is_active = true
-				AND DATE(check_in_date) <= DATE('now') 
-				AND DATE(check_out_date) >= DATE('now')
+				AND DATE(check_in_date) <= ? 
+				AND DATE(check_out_date) >= ?
 		) g ON r.room_number = g.room_number AND r.property_id = g.property_id
 		LEFT JOIN (
-			SELECT DISTINCT room_number, property_id, consumed_at, consumed_by, id
+			SELECT room_number, property_id, MAX(consumed_at) as consumed_at, MAX(id) as id,
+				SUM(adult_covers + child_covers) as covers_consumed,
+				SUM(adult_covers + child_covers - upsell_covers) as entitled_consumed
 			FROM daily_breakfast_consumptions 
-			WHERE DATE(consumption_date) = DATE('now') AND status = 'consumed'
+			WHERE DATE(consumption_date) = ? AND status = 'consumed' AND deleted_at IS NULL
+			GROUP BY room_number, property_id
 		) dbc ON r.room_number = dbc.room_number AND r.property_id = dbc.property_id
-		LEFT JOIN staffs s ON dbc.consumed_by = s.id
+		LEFT JOIN daily_breakfast_consumptions last_visit ON last_visit.id = dbc.id
+		LEFT JOIN staffs s ON last_visit.consumed_by = s.id
 		WHERE r.property_id = ?
-		ORDER BY r.room_number
+		ORDER BY r.room_number, g.check_in_date
 	`
 
-	err := s.db.Raw(query, propertyID).Scan(&roomStatuses).Error
+	err := s.db.Raw(query, today, today, today, propertyID).Scan(&roomStatuses).Error
 	if err != nil {
 		logging.WithFields(logrus.Fields{
 			"service":     "BreakfastService",
@@ -96,6 +141,14 @@ func (s *BreakfastService) GetRoomBreakfastStatus(propertyID string) ([]models.R
 		return nil, fmt.Errorf("failed to fetch room breakfast status: %w", err)
 	}
 
+	roomStatuses, err = s.applyEligibility(propertyID, roomStatuses)
+	if err != nil {
+		return nil, err
+	}
+	if err := flagRoomAllergies(s.db, roomStatuses); err != nil {
+		return nil, err
+	}
+
 	logging.WithFields(logrus.Fields{
 		"service":     "BreakfastService",
 		"method":      "GetRoomBreakfastStatus",
@@ -106,51 +159,283 @@ func (s *BreakfastService) GetRoomBreakfastStatus(propertyID string) ([]models.R
 	return roomStatuses, nil
 }
 
-func (s *BreakfastService) MarkBreakfastConsumed(propertyID, roomNumber string, staffID uint) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		// Check if guest exists and has breakfast package
-		var guest models.Guest
-		err := tx.Where("property_id = ? AND room_number = ? AND is_active = true", propertyID, roomNumber).First(&guest).Error
-		if err != nil {
-			return errors.New("no active guest found in this room")
+// applyEligibility sets each room's entitlement from the property's eligibility
+// rules. On turnover days the status query returns both guests of a room, so
+// only the guest breakfast is for is kept.
+func (s *BreakfastService) applyEligibility(propertyID string, statuses []models.RoomBreakfastStatus) ([]models.RoomBreakfastStatus, error) {
+	engine, err := LoadEligibilityEngine(s.db, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	now := engine.Now()
+	today := engine.clock.BusinessDate(now)
+
+	var guestIDs []uint
+	for _, status := range statuses {
+		if status.GuestID != 0 {
+			guestIDs = append(guestIDs, status.GuestID)
+		}
+	}
+	guestsByID := make(map[uint]models.Guest, len(guestIDs))
+	if len(guestIDs) > 0 {
+		var guests []models.Guest
+		if err := s.db.Where("id IN ?", guestIDs).Find(&guests).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch guests: %w", err)
 		}
+		for _, guest := range guests {
+			guestsByID[guest.ID] = guest
+		}
+	}
 
-		if !guest.BreakfastPackage {
-			return errors.New("guest does not have breakfast package")
+	result := make([]models.RoomBreakfastStatus, 0, len(statuses))
+	for start := 0; start < len(statuses); {
+		end := start + 1
+		for end < len(statuses) && statuses[end].RoomNumber == statuses[start].RoomNumber {
+			end++
 		}
 
-		// Check if already consumed today
-		var existing models.DailyBreakfastConsumption
-		today := time.Now().Format("2006-01-02")
-		err = tx.Where("property_id = ? AND room_number = ? AND DATE(consumption_date) = ?",
-			propertyID, roomNumber, today).First(&existing).Error
+		// Rows of a room are ordered by check-in
+		var roomGuests []models.Guest
+		for _, status := range statuses[start:end] {
+			if guest, ok := guestsByID[status.GuestID]; ok {
+				roomGuests = append(roomGuests, guest)
+			}
+		}
+		if len(roomGuests) == 0 {
+			result = append(result, statuses[start])
+			start = end
+			continue
+		}
 
-		if err == nil && existing.Status == "consumed" {
-			return errors.New("breakfast already consumed today")
+		guest, decision := engine.pick(roomGuests, today, &now)
+		for _, status := range statuses[start:end] {
+			if status.GuestID != guest.ID {
+				continue
+			}
+			status.Eligible = decision.Eligible
+			status.EligibilityReason = decision.Reason
+			status.CoversEntitled = 0
+			if decision.Eligible {
+				status.CoversEntitled = decision.Covers
+			}
+			status.CoversRemaining = 0
+			if status.CoversEntitled > status.EntitledConsumed {
+				status.CoversRemaining = status.CoversEntitled - status.EntitledConsumed
+			}
+			result = append(result, status)
+			break
 		}
+		start = end
+	}
+
+	return result, nil
+}
 
-		// Create or update consumption record
-		now := time.Now()
-		consumption := models.DailyBreakfastConsumption{
-			PropertyID:      propertyID,
-			RoomNumber:      roomNumber,
-			GuestID:         guest.ID,
-			ConsumptionDate: now,
-			ConsumedAt:      &now,
-			ConsumedBy:      &staffID,
-			Status:          "consumed",
-			PaymentMethod:   "room_charge",
-			Amount:          25.00, // Default breakfast price
+// MarkBreakfastConsumed records a breakfast visit, optionally at a specific outlet (outletID 0 for none)
+func (s *BreakfastService) MarkBreakfastConsumed(propertyID, roomNumber string, staffID, outletID uint, covers CoverRequest) (*models.DailyBreakfastConsumption, error) {
+	var guest *models.Guest
+	var visit *recordedVisit
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Visits are recorded against the property's business date
+		engine, err := LoadEligibilityEngine(tx, propertyID)
+		if err != nil {
+			return err
 		}
+		now := engine.Now()
+		businessDate := engine.clock.BusinessDate(now)
 
-		if err == gorm.ErrRecordNotFound {
-			// Create new record
-			return tx.Create(&consumption).Error
-		} else {
-			// Update existing record
-			return tx.Model(&existing).Updates(consumption).Error
+		// Find the in-house guest breakfast is for
+		guest, err = guestForBreakfast(tx, engine, propertyID, roomNumber, businessDate, &now, true)
+		if err != nil {
+			if errors.Is(err, ErrNoGuestInRoom) {
+				return errors.New("no active guest found in this room")
+			}
+			return err
 		}
+
+		visit, err = recordVisit(tx, engine, guest, visitRequest{
+			StaffID:       staffID,
+			OutletID:      outletID,
+			PaymentMethod: "room_charge",
+			Covers:        covers,
+			At:            now,
+		})
+		return err
 	})
+	if err != nil {
+		return nil, err
+	}
+	consumption := visit.Consumption
+	s.rollups.ConsumptionChanged(propertyID, consumption.ConsumptionDate)
+
+	// Post the charge once the visit is committed, so a slow PMS does not hold
+	// the visit open
+	if s.chargePoster != nil {
+		if err := postVisitCharge(s.db, s.chargePoster, guest, visit); err != nil {
+			return nil, err
+		}
+	}
+	return consumption, nil
+}
+
+// visitRequest describes a breakfast visit to record
+type visitRequest struct {
+	StaffID       uint
+	OutletID      uint
+	PaymentMethod string // room_charge, ohip, comp, cash
+	Notes         string
+	Covers        CoverRequest
+	At            time.Time // Current time for live visits, the client time for synced ones
+	PassID        *uint     // Breakfast pass redeemed for the visit
+}
+
+// recordedVisit is a recorded visit with the price of its chargeable covers
+type recordedVisit struct {
+	Consumption *models.DailyBreakfastConsumption
+	Price       *VisitPrice
+	Decision    EligibilityDecision
+}
+
+// recordVisit checks a guest's eligibility at the visit time and records the visit
+func recordVisit(tx *gorm.DB, engine *EligibilityEngine, guest *models.Guest, visit visitRequest) (*recordedVisit, error) {
+	at := visit.At.In(engine.clock.Location)
+	businessDate := engine.clock.BusinessDate(at)
+
+	decision := engine.Evaluate(guest, at)
+	if !decision.Eligible {
+		return nil, fmt.Errorf("%w: %s", ErrNotEligible, decision.Reason)
+	}
+
+	// Refuse the visit if the outlet cannot serve a package breakfast at that time
+	var servingOutlet *uint
+	if visit.OutletID != 0 {
+		outlet, err := resolveOutlet(tx, guest.PropertyID, visit.OutletID, at)
+		if err != nil {
+			return nil, err
+		}
+		servingOutlet = &outlet.ID
+	}
+
+	// Refuse the visit once the day's service has been closed out
+	if err := ensureServiceOpen(tx, guest.PropertyID, servingOutlet, businessDate); err != nil {
+		return nil, err
+	}
+
+	// Count covers already served to this guest on the business date
+	var served int64
+	err := tx.Model(&models.DailyBreakfastConsumption{}).
+		Where("property_id = ? AND room_number = ? AND guest_id = ? AND DATE(consumption_date) = ? AND status = 'consumed'",
+			guest.PropertyID, guest.RoomNumber, guest.ID, businessDate.Format("2006-01-02")).
+		Select("COALESCE(SUM(adult_covers + child_covers - upsell_covers), 0)").
+		Row().Scan(&served)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count served covers: %w", err)
+	}
+
+	adults, children, upsell, err := allocateCovers(visit.Covers, guest, decision.Covers, int(served))
+	if err != nil {
+		return nil, err
+	}
+
+	// Price the covers that are charged from the property's price book
+	pricedAdults, pricedChildren := chargeableCovers(&decision, adults, children, upsell)
+	price, err := priceVisit(tx, guest.PropertyID, servingOutlet, roomTypeFor(tx, guest.PropertyID, guest.RoomNumber), pricedAdults, pricedChildren, businessDate)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentMethod := visit.PaymentMethod
+	if decision.Complimentary && upsell == 0 {
+		paymentMethod = "comp"
+	}
+
+	// Record this visit
+	consumption := models.DailyBreakfastConsumption{
+		PropertyID:      guest.PropertyID,
+		RoomNumber:      guest.RoomNumber,
+		GuestID:         guest.ID,
+		ConsumptionDate: businessDate,
+		ConsumedAt:      &at,
+		ConsumedBy:      &visit.StaffID,
+		ArrivedAt:       &at,
+		OutletID:        servingOutlet,
+		Status:          "consumed",
+		AdultCovers:     adults,
+		ChildCovers:     children,
+		UpsellCovers:    upsell,
+		Notes:           visit.Notes,
+		PaymentMethod:   paymentMethod,
+		OHIPCovered:     guest.OHIPNumber != "" && paymentMethod == "ohip",
+		Amount:          price.Subtotal,
+		PriceID:         price.Adult.PriceID,
+		AdultPrice:      price.Adult.Price,
+		ChildPrice:      price.Child.Price,
+		ServiceCharge:   price.ServiceCharge,
+		TaxAmount:       price.TaxAmount,
+		PassID:          visit.PassID,
+	}
+
+	if err := tx.Create(&consumption).Error; err != nil {
+		return nil, fmt.Errorf("failed to create consumption record: %w", err)
+	}
+
+	// A guest served at an outlet re-opened after the close-out that recorded
+	// them is no longer a no-show
+	err = tx.Where("property_id = ? AND guest_id = ? AND DATE(consumption_date) = ? AND status = 'no_show'",
+		guest.PropertyID, guest.ID, businessDate.Format("2006-01-02")).
+		Delete(&models.DailyBreakfastConsumption{}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear no-show: %w", err)
+	}
+
+	// Take the supplies the covers used from the outlet's stock
+	if err := depleteInventory(tx, &consumption); err != nil {
+		return nil, err
+	}
+	return &recordedVisit{Consumption: &consumption, Price: price, Decision: decision}, nil
+}
+
+// roomCharge returns the part of a recorded visit charged to the room: all
+// of it for a room charge, otherwise its upsold covers, adults first
+func (v *recordedVisit) roomCharge() *VisitPrice {
+	consumption := v.Consumption
+	if consumption.PaymentMethod == "room_charge" {
+		return v.Price
+	}
+	if consumption.UpsellCovers > 0 {
+		return v.Price.Portion(upsoldCovers(&v.Decision, consumption.AdultCovers, consumption.ChildCovers, consumption.UpsellCovers))
+	}
+	return nil
+}
+
+// postVisitCharge posts a recorded visit's room charge to the PMS and marks
+// the visit posted. A charge the PMS refuses is logged and left unposted, so
+// the visit still stands.
+func postVisitCharge(db *gorm.DB, poster VisitChargePoster, guest *models.Guest, visit *recordedVisit) error {
+	charge := visit.roomCharge()
+	if charge == nil || charge.Total <= 0 {
+		return nil
+	}
+
+	consumption := visit.Consumption
+	response, err := poster.ChargeBreakfast(guest.PMSGuestID, guest.ReservationID, consumption.RoomNumber,
+		charge.Subtotal+charge.ServiceCharge, charge.TaxAmount, consumption.PropertyID, consumption.ConsumptionDate)
+	if err != nil {
+		logging.WithError(err).WithFields(logrus.Fields{
+			"consumption_id": consumption.ID,
+			"property_id":    consumption.PropertyID,
+			"room_number":    consumption.RoomNumber,
+			"amount":         charge.Total,
+		}).Error("Failed to post breakfast charge to PMS")
+		return nil
+	}
+
+	consumption.PMSPosted = true
+	consumption.PMSTransactionID = response.TransactionID
+	if err := db.Save(consumption).Error; err != nil {
+		return fmt.Errorf("failed to record PMS charge %s: %w", response.TransactionID, err)
+	}
+	return nil
 }
 
 // Consumption History Management
@@ -165,57 +450,113 @@ func (s *BreakfastService) GetConsumptionHistory(propertyID string, startDate, e
 	return consumptions, err
 }
 
-func (s *BreakfastService) GetDailyReport(propertyID string, date time.Time) (*DailyBreakfastReport, error) {
-	var report DailyBreakfastReport
-	dateStr := date.Format("2006-01-02")
-
-	// Get total rooms with breakfast packages
-	var totalWithBreakfast int64
-	err := s.db.Model(&models.Guest{}).
-		Where("property_id = ? AND breakfast_package = true AND is_active = true", propertyID).
-		Where("DATE(check_in_date) <= ? AND DATE(check_out_date) >= ?", dateStr, dateStr).
-		Count(&totalWithBreakfast).Error
-	if err != nil {
-		return nil, err
+// ExportConsumptionHistory streams a property's visits between two business
+// dates, inclusive, a day at a time, and returns how many were written
+func (s *BreakfastService) ExportConsumptionHistory(ctx context.Context, propertyID string, startDate, endDate time.Time, w export.Writer) (int, error) {
+	if err := w.Table("Consumption history", consumptionColumns); err != nil {
+		return 0, err
 	}
 
-	// Get consumed count
-	var consumed int64
-	err = s.db.Model(&models.DailyBreakfastConsumption{}).
-		Where("property_id = ? AND DATE(consumption_date) = ? AND status = 'consumed'", propertyID, dateStr).
-		Count(&consumed).Error
-	if err != nil {
-		return nil, err
+	rows := 0
+	for date := calendarDate(startDate); !date.After(calendarDate(endDate)); date = date.AddDate(0, 0, 1) {
+		var consumptions []models.DailyBreakfastConsumption
+		err := s.db.WithContext(ctx).
+			Preload("Guest").
+			Preload("Staff").
+			Preload("Outlet").
+			Where("property_id = ? AND DATE(consumption_date) = ?", propertyID, date.Format("2006-01-02")).
+			Order("consumed_at ASC, id ASC").
+			Find(&consumptions).Error
+		if err != nil {
+			return rows, fmt.Errorf("failed to fetch consumption history: %w", err)
+		}
+		for i := range consumptions {
+			if err := writeConsumption(w, &consumptions[i]); err != nil {
+				return rows, err
+			}
+			rows++
+		}
 	}
+	return rows, nil
+}
 
-	// Get OHIP covered count
-	var ohipCovered int64
-	err = s.db.Model(&models.DailyBreakfastConsumption{}).
-		Where("property_id = ? AND DATE(consumption_date) = ? AND ohip_covered = true", propertyID, dateStr).
-		Count(&ohipCovered).Error
+// GetDailyReport reads a business date's report from its rollups
+func (s *BreakfastService) GetDailyReport(propertyID string, date time.Time) (*DailyBreakfastReport, error) {
+	days, err := s.rollups.Days(propertyID, date, date)
 	if err != nil {
 		return nil, err
 	}
-
-	// Get PMS posted count
-	var pmsPosted int64
-	err = s.db.Model(&models.DailyBreakfastConsumption{}).
-		Where("property_id = ? AND DATE(consumption_date) = ? AND pms_posted = true", propertyID, dateStr).
-		Count(&pmsPosted).Error
+	day := models.DailyRollup{}
+	if len(days) > 0 {
+		day = days[0]
+	}
+	outletDays, err := s.rollups.OutletDays(propertyID, date, date)
 	if err != nil {
 		return nil, err
 	}
 
-	report.Date = date
-	report.TotalRoomsWithBreakfast = int(totalWithBreakfast)
-	report.TotalConsumed = int(consumed)
-	report.TotalNotConsumed = int(totalWithBreakfast - consumed)
-	report.ConsumptionRate = 0
-	if totalWithBreakfast > 0 {
-		report.ConsumptionRate = float64(consumed) / float64(totalWithBreakfast) * 100
+	closedOut := errors.Is(ensureServiceOpen(s.db, propertyID, nil, date), ErrDayClosed)
+
+	// Per-outlet breakdown; visits without an outlet are grouped as unassigned
+	outlets := make([]OutletBreakdown, 0, len(outletDays)+1)
+	if len(outletDays) > 0 {
+		ids := make([]uint, 0, len(outletDays))
+		for _, row := range outletDays {
+			ids = append(ids, row.OutletID)
+		}
+		var named []models.Outlet
+		if err := s.db.Unscoped().Select("id", "name").Where("id IN ?", ids).Find(&named).Error; err != nil {
+			return nil, err
+		}
+		names := make(map[uint]string, len(named))
+		for _, outlet := range named {
+			names[outlet.ID] = outlet.Name
+		}
+
+		for _, row := range outletDays {
+			outletID := row.OutletID
+			outlets = append(outlets, OutletBreakdown{
+				OutletID:     &outletID,
+				OutletName:   names[row.OutletID],
+				Visits:       row.Visits,
+				CoversServed: row.Covers,
+				UpsellCovers: row.UpsellCovers,
+				Amount:       row.Revenue,
+			})
+		}
+	}
+	unassigned := OutletBreakdown{OutletName: "Unassigned", Visits: day.Visits, CoversServed: day.Covers, UpsellCovers: day.UpsellCovers, Amount: day.Revenue}
+	for _, outlet := range outlets {
+		unassigned.Visits -= outlet.Visits
+		unassigned.CoversServed -= outlet.CoversServed
+		unassigned.UpsellCovers -= outlet.UpsellCovers
+		unassigned.Amount -= outlet.Amount
+	}
+	if unassigned.Visits > 0 {
+		unassigned.Amount = roundTo(unassigned.Amount, 2)
+		outlets = append(outlets, unassigned)
+	}
+	sort.SliceStable(outlets, func(i, j int) bool { return outlets[i].OutletName < outlets[j].OutletName })
+
+	report := DailyBreakfastReport{
+		Date:                    date,
+		TotalRoomsWithBreakfast: day.EntitledRooms,
+		TotalConsumed:           day.RoomsServed,
+		TotalNotConsumed:        day.EntitledRooms - day.RoomsServed,
+		NoShows:                 day.NoShows,
+		ClosedOut:               closedOut,
+		TotalCoversServed:       day.Covers,
+		UpsellCovers:            day.UpsellCovers,
+		OHIPCoveredCount:        day.OHIPVisits,
+		PMSChargesPosted:        day.PMSPosted,
+		Outlets:                 outlets,
+	}
+	if closedOut {
+		report.TotalNotConsumed = day.NoShows
+	}
+	if day.EntitledRooms > 0 {
+		report.ConsumptionRate = float64(day.RoomsServed) / float64(day.EntitledRooms) * 100
 	}
-	report.OHIPCoveredCount = int(ohipCovered)
-	report.PMSChargesPosted = int(pmsPosted)
 
 	return &report, nil
 }
@@ -224,18 +565,18 @@ func (s *BreakfastService) GetAnalyticsData(propertyID string, period string) (*
 	var analytics BreakfastAnalytics
 
 	// Calculate date range based on period
-	now := time.Now()
+	today := currentBusinessDate(s.db, propertyID)
 	var startDate time.Time
 
 	switch period {
 	case "today":
-		startDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		startDate = today
 	case "week":
-		startDate = now.AddDate(0, 0, -7)
+		startDate = today.AddDate(0, 0, -7)
 	case "month":
-		startDate = now.AddDate(0, -1, 0)
+		startDate = today.AddDate(0, -1, 0)
 	default:
-		startDate = now.AddDate(0, 0, -7)
+		startDate = today.AddDate(0, 0, -7)
 	}
 
 	// Get daily breakdown
@@ -265,13 +606,28 @@ func (s *BreakfastService) GetAnalyticsData(propertyID string, period string) (*
 
 // Helper types for reports and analytics
 type DailyBreakfastReport struct {
-	Date                    time.Time `json:"date"`
-	TotalRoomsWithBreakfast int       `json:"total_rooms_with_breakfast"`
-	TotalConsumed           int       `json:"total_consumed"`
-	TotalNotConsumed        int       `json:"total_not_consumed"`
-	ConsumptionRate         float64   `json:"consumption_rate"`
-	OHIPCoveredCount        int       `json:"ohip_covered_count"`
-	PMSChargesPosted        int       `json:"pms_charges_posted"`
+	Date                    time.Time         `json:"date"`
+	TotalRoomsWithBreakfast int               `json:"total_rooms_with_breakfast"`
+	TotalConsumed           int               `json:"total_consumed"`
+	TotalNotConsumed        int               `json:"total_not_consumed"`
+	NoShows                 int               `json:"no_shows"`
+	ClosedOut               bool              `json:"closed_out"`
+	ConsumptionRate         float64           `json:"consumption_rate"`
+	TotalCoversServed       int               `json:"total_covers_served"`
+	UpsellCovers            int               `json:"upsell_covers"`
+	OHIPCoveredCount        int               `json:"ohip_covered_count"`
+	PMSChargesPosted        int               `json:"pms_charges_posted"`
+	Outlets                 []OutletBreakdown `json:"outlets"`
+}
+
+// OutletBreakdown summarizes a day's consumption at a single outlet
+type OutletBreakdown struct {
+	OutletID     *uint   `json:"outlet_id"`
+	OutletName   string  `json:"outlet_name"`
+	Visits       int     `json:"visits"`
+	CoversServed int     `json:"covers_served"`
+	UpsellCovers int     `json:"upsell_covers"`
+	Amount       float64 `json:"amount"`
 }
 
 type BreakfastAnalytics struct {
@@ -371,17 +727,18 @@ func (s *BreakfastService) GetVIPMetrics(ctx context.Context, propertyID string)
 	
 	// Calculate VIP breakfast consumption rate
 	var vipConsumed, vipTotal int64
+	today := currentBusinessDate(s.db, propertyID).Format("2006-01-02")
 	s.db.Model(&models.DailyBreakfastConsumption{}).
 		Joins("JOIN guests ON guests.id = daily_breakfast_consumptions.guest_id").
-		Where("guests.property_id = ? AND guests.is_vip = ? AND daily_breakfast_consumptions.consumption_date = ?", 
-			propertyID, true, time.Now().Format("2006-01-02")).
+		Where("guests.property_id = ? AND guests.is_vip = ? AND DATE(daily_breakfast_consumptions.consumption_date) = ?", 
+			propertyID, true, today).
 		Where("daily_breakfast_consumptions.status = ?", "consumed").
 		Count(&vipConsumed)
 	
 	s.db.Model(&models.DailyBreakfastConsumption{}).
 		Joins("JOIN guests ON guests.id = daily_breakfast_consumptions.guest_id").
-		Where("guests.property_id = ? AND guests.is_vip = ? AND daily_breakfast_consumptions.consumption_date = ?", 
-			propertyID, true, time.Now().Format("2006-01-02")).
+		Where("guests.property_id = ? AND guests.is_vip = ? AND DATE(daily_breakfast_consumptions.consumption_date) = ?", 
+			propertyID, true, today).
 		Count(&vipTotal)
 	
 	if vipTotal > 0 {
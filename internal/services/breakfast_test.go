@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"hudini-breakfast-module/internal/models"
+)
+
+// fakeChargePoster records the breakfast charges posted to it
+type fakeChargePoster struct {
+	amounts []float64
+	fail    bool
+}
+
+func (p *fakeChargePoster) ChargeBreakfast(guestID, reservationID, roomNumber string, amount, taxAmount float64, propertyID string, businessDate time.Time) (*PMSChargeResponse, error) {
+	if p.fail {
+		return nil, errors.New("PMS unavailable")
+	}
+	p.amounts = append(p.amounts, amount+taxAmount)
+	return &PMSChargeResponse{TransactionID: "T1"}, nil
+}
+
+func TestMarkBreakfastConsumedPostsCharge(t *testing.T) {
+	tests := []struct {
+		name    string
+		fail    bool
+		posted  bool
+		charged []float64
+	}{
+		{"posted", false, true, []float64{44}},
+		{"refused by the PMS", true, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			now := time.Now().UTC()
+			mustCreate(t, db, &models.Property{PropertyID: "P1", Name: "Harbour Hotel", TimeZone: "UTC"})
+			mustCreate(t, db, &models.BreakfastPrice{PropertyID: "P1", GuestType: "adult", Price: 20, TaxRate: 10, EffectiveFrom: now.AddDate(-1, 0, 0)})
+			mustCreate(t, db, &models.Guest{
+				PMSGuestID: "G1", ReservationID: "R1", RoomNumber: "101", FirstName: "Ada", LastName: "Guest",
+				PropertyID: "P1", IsActive: true, BreakfastPackage: true, AdultCount: 2,
+				CheckInDate: now.AddDate(0, 0, -1), CheckOutDate: now.AddDate(0, 0, 2),
+			})
+
+			poster := &fakeChargePoster{fail: tt.fail}
+			service := NewBreakfastService(db, nil)
+			service.SetChargePoster(poster)
+
+			// A failed posting is logged; the visit still stands
+			visit, err := service.MarkBreakfastConsumed("P1", "101", 1, 0, CoverRequest{})
+			if err != nil {
+				t.Fatalf("MarkBreakfastConsumed: %v", err)
+			}
+			if len(poster.amounts) != len(tt.charged) || (len(tt.charged) > 0 && poster.amounts[0] != tt.charged[0]) {
+				t.Errorf("charges posted = %v, want %v", poster.amounts, tt.charged)
+			}
+
+			var stored models.DailyBreakfastConsumption
+			if err := db.First(&stored, visit.ID).Error; err != nil {
+				t.Fatalf("reloading visit: %v", err)
+			}
+			if stored.PMSPosted != tt.posted || (tt.posted && stored.PMSTransactionID != "T1") {
+				t.Errorf("visit posted %v, transaction %q, want posted %v", stored.PMSPosted, stored.PMSTransactionID, tt.posted)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"hudini-breakfast-module/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrPropertyNotFound is returned when a property does not exist
+var ErrPropertyNotFound = errors.New("property not found")
+
+// PropertyClock resolves property-local time and business dates.
+//
+// A business date is the property-local calendar date, shifted back by the
+// cutover hour so that service after midnight but before the cutover still
+// belongs to the previous day. Business dates are represented as midnight UTC
+// so they compare consistently with DATE() in SQL regardless of server zone.
+type PropertyClock struct {
+	Location    *time.Location
+	CutoverHour int
+}
+
+// LoadPropertyClock loads the time zone and cutover hour configured for a property.
+// Unknown properties fall back to UTC with a midnight cutover.
+func LoadPropertyClock(db *gorm.DB, propertyID string) (*PropertyClock, error) {
+	var properties []models.Property
+	err := db.Select("time_zone", "cutover_hour").
+		Where("property_id = ?", propertyID).
+		Limit(1).
+		Find(&properties).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load property time zone: %w", err)
+	}
+
+	clock := &PropertyClock{Location: time.UTC}
+	if len(properties) == 0 {
+		return clock, nil
+	}
+
+	property := properties[0]
+	if property.TimeZone != "" {
+		location, err := time.LoadLocation(property.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q for property %s: %w", property.TimeZone, propertyID, err)
+		}
+		clock.Location = location
+	}
+	clock.CutoverHour = property.CutoverHour
+	return clock, nil
+}
+
+// Now returns the current property-local time
+func (c *PropertyClock) Now() time.Time {
+	return time.Now().In(c.Location)
+}
+
+// BusinessDate returns the business date that an instant belongs to
+func (c *PropertyClock) BusinessDate(t time.Time) time.Time {
+	local := t.In(c.Location).Add(-time.Duration(c.CutoverHour) * time.Hour)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// StartOf returns the instant a business date begins in property-local time
+func (c *PropertyClock) StartOf(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), c.CutoverHour, 0, 0, 0, c.Location)
+}
+
+// Today returns the property's current business date
+func (c *PropertyClock) Today() time.Time {
+	return c.BusinessDate(time.Now())
+}
+
+// currentBusinessDate returns a property's current business date, falling back to the UTC date
+func currentBusinessDate(db *gorm.DB, propertyID string) time.Time {
+	clock, err := LoadPropertyClock(db, propertyID)
+	if err != nil {
+		clock = &PropertyClock{Location: time.UTC}
+	}
+	return clock.Today()
+}
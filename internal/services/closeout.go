@@ -0,0 +1,551 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hudini-breakfast-module/internal/audit"
+	"hudini-breakfast-module/internal/config"
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Errors returned by close-out operations
+var (
+	ErrDayClosed            = errors.New("breakfast service is closed out for this day")
+	ErrCloseOutNotFound     = errors.New("close-out not found")
+	ErrAlreadyClosed        = errors.New("breakfast service is already closed out")
+	ErrNotReopenable        = errors.New("only closed services can be re-opened")
+	ErrReopenReasonRequired = errors.New("a reason is required to re-open a service")
+	ErrPropertyClosedOut    = errors.New("the property is closed out for this day; re-open it first")
+)
+
+// CloseOutService closes breakfast service for the day, recording no-shows
+type CloseOutService struct {
+	db           *gorm.DB
+	auditService *AuditService
+	listener     ConsumptionListener
+	config       config.CloseOutConfig
+}
+
+// CloseOutRequest describes the service being closed and who is closing it
+type CloseOutRequest struct {
+	PropertyID   string
+	OutletID     *uint // nil to close the whole property
+	BusinessDate time.Time
+	StaffID      *uint // nil when triggered by the scheduler
+	Trigger      string
+	IPAddress    string
+	UserAgent    string
+}
+
+// NewCloseOutService creates a new close-out service
+func NewCloseOutService(db *gorm.DB, auditService *AuditService, cfg config.CloseOutConfig) *CloseOutService {
+	return &CloseOutService{
+		db:           db,
+		auditService: auditService,
+		config:       cfg,
+	}
+}
+
+// SetConsumptionListener sets who is told when close-outs record or remove no-shows
+func (s *CloseOutService) SetConsumptionListener(listener ConsumptionListener) {
+	s.listener = listener
+}
+
+// CloseOut closes a day's service for a property or outlet. No-shows are recorded
+// once no package outlet remains open to serve the property's guests.
+func (s *CloseOutService) CloseOut(ctx context.Context, req CloseOutRequest) (*models.ServiceCloseOut, error) {
+	date := calendarDate(req.BusinessDate)
+	dateStr := date.Format("2006-01-02")
+
+	var closeOut models.ServiceCloseOut
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureServiceOpen(tx, req.PropertyID, req.OutletID, date); err != nil {
+			if errors.Is(err, ErrDayClosed) {
+				return ErrAlreadyClosed
+			}
+			return err
+		}
+
+		if req.OutletID != nil {
+			var outlet models.Outlet
+			if err := tx.First(&outlet, *req.OutletID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrOutletNotFound
+				}
+				return fmt.Errorf("failed to fetch outlet: %w", err)
+			}
+			if outlet.PropertyID != req.PropertyID {
+				return ErrOutletPropertyMismatch
+			}
+		}
+
+		recordNoShows, err := s.lastOpenOutlet(tx, req.PropertyID, req.OutletID, dateStr)
+		if err != nil {
+			return err
+		}
+
+		closeOut = models.ServiceCloseOut{
+			PropertyID:   req.PropertyID,
+			OutletID:     req.OutletID,
+			BusinessDate: date,
+			Status:       "closed",
+			Trigger:      req.Trigger,
+			ClosedAt:     time.Now(),
+			ClosedBy:     req.StaffID,
+		}
+		if err := tx.Create(&closeOut).Error; err != nil {
+			return fmt.Errorf("failed to create close-out: %w", err)
+		}
+
+		// Eligible in-house guests for the day
+		engine, err := LoadEligibilityEngine(tx, req.PropertyID)
+		if err != nil {
+			return err
+		}
+		guests, err := eligibleGuests(tx, engine, req.PropertyID, date)
+		if err != nil {
+			return err
+		}
+
+		// Guests who ate anywhere in the property, or already have a no-show
+		var accounted []uint
+		err = tx.Model(&models.DailyBreakfastConsumption{}).
+			Where("property_id = ? AND DATE(consumption_date) = ? AND status IN ?", req.PropertyID, dateStr, []string{"consumed", "no_show"}).
+			Distinct("guest_id").
+			Pluck("guest_id", &accounted).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch served guests: %w", err)
+		}
+		seen := make(map[uint]bool, len(accounted))
+		for _, guestID := range accounted {
+			seen[guestID] = true
+		}
+
+		for _, eligible := range guests {
+			guest := eligible.Guest
+			entitled := eligible.Decision.Covers
+			closeOut.GuestsEligible++
+			closeOut.CoversEntitled += entitled
+
+			if !recordNoShows || seen[guest.ID] || entitled == 0 {
+				continue
+			}
+
+			adults, children, _, err := allocateCovers(CoverRequest{}, &guest, entitled, 0)
+			if err != nil {
+				continue
+			}
+
+			noShow := models.DailyBreakfastConsumption{
+				PropertyID:      req.PropertyID,
+				RoomNumber:      guest.RoomNumber,
+				GuestID:         guest.ID,
+				ConsumptionDate: date,
+				Status:          "no_show",
+				AdultCovers:     adults,
+				ChildCovers:     children,
+				CloseOutID:      &closeOut.ID,
+			}
+			if err := tx.Create(&noShow).Error; err != nil {
+				return fmt.Errorf("failed to record no-show for room %s: %w", guest.RoomNumber, err)
+			}
+		}
+
+		if err := s.summarize(tx, &closeOut, dateStr); err != nil {
+			return err
+		}
+		return tx.Save(&closeOut).Error
+	})
+
+	resourceID := req.PropertyID + "/" + dateStr
+	if req.OutletID != nil {
+		resourceID += "/" + strconv.FormatUint(uint64(*req.OutletID), 10)
+	}
+	if err != nil {
+		if s.auditService != nil {
+			s.auditService.LogFailure(ctx, req.StaffID, audit.ActionCloseOut, audit.ResourceCloseOut, resourceID, req.IPAddress, req.UserAgent, err)
+		}
+		return nil, err
+	}
+
+	if s.auditService != nil {
+		if err := s.auditService.LogSuccess(ctx, req.StaffID, audit.ActionCloseOut, audit.ResourceCloseOut, strconv.FormatUint(uint64(closeOut.ID), 10), req.IPAddress, req.UserAgent, nil, closeOut); err != nil {
+			logging.WithError(err).Warn("Failed to write close-out audit entry")
+		}
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":       "CloseOutService",
+		"property_id":   req.PropertyID,
+		"outlet_id":     req.OutletID,
+		"business_date": dateStr,
+		"trigger":       req.Trigger,
+		"no_shows":      closeOut.NoShows,
+	}).Info("Breakfast service closed out")
+
+	if s.listener != nil {
+		s.listener.ConsumptionChanged(closeOut.PropertyID, closeOut.BusinessDate)
+	}
+	return &closeOut, nil
+}
+
+// lastOpenOutlet reports whether closing the given scope leaves no package outlet open
+func (s *CloseOutService) lastOpenOutlet(tx *gorm.DB, propertyID string, outletID *uint, dateStr string) (bool, error) {
+	if outletID == nil {
+		return true, nil
+	}
+
+	stillOpen, err := openPackageOutlets(tx, propertyID, dateStr, outletID)
+	if err != nil {
+		return false, err
+	}
+	return stillOpen == 0, nil
+}
+
+// openPackageOutlets counts the property's package outlets that are not closed
+// out on a business date, leaving out the given outlet
+func openPackageOutlets(tx *gorm.DB, propertyID, dateStr string, except *uint) (int64, error) {
+	query := tx.Model(&models.Outlet{}).
+		Where("property_id = ? AND is_active = true AND accepts_package = true", propertyID).
+		Where("id NOT IN (?)", tx.Model(&models.ServiceCloseOut{}).
+			Select("outlet_id").
+			Where("property_id = ? AND DATE(business_date) = ? AND status = 'closed' AND outlet_id IS NOT NULL", propertyID, dateStr))
+	if except != nil {
+		query = query.Where("id <> ?", *except)
+	}
+
+	var open int64
+	if err := query.Count(&open).Error; err != nil {
+		return 0, fmt.Errorf("failed to check open outlets: %w", err)
+	}
+	return open, nil
+}
+
+// summarize fills the close-out summary from the day's consumption records
+func (s *CloseOutService) summarize(tx *gorm.DB, closeOut *models.ServiceCloseOut, dateStr string) error {
+	scope := func() *gorm.DB {
+		query := tx.Model(&models.DailyBreakfastConsumption{}).
+			Where("property_id = ? AND DATE(consumption_date) = ?", closeOut.PropertyID, dateStr)
+		if closeOut.OutletID != nil {
+			query = query.Where("outlet_id = ?", *closeOut.OutletID)
+		}
+		return query
+	}
+
+	var served struct {
+		Guests  int64
+		Covers  int64
+		Upsell  int64
+		Revenue float64
+	}
+	err := scope().
+		Select("COUNT(DISTINCT guest_id) as guests, COALESCE(SUM(adult_covers + child_covers), 0) as covers, COALESCE(SUM(upsell_covers), 0) as upsell, COALESCE(SUM(amount), 0) as revenue").
+		Where("status = 'consumed'").
+		Scan(&served).Error
+	if err != nil {
+		return fmt.Errorf("failed to summarize close-out: %w", err)
+	}
+
+	var voided int64
+	if err := scope().Where("status = 'voided'").Count(&voided).Error; err != nil {
+		return fmt.Errorf("failed to summarize close-out: %w", err)
+	}
+
+	// No-shows belong to the property, not to a single outlet
+	var noShows int64
+	err = tx.Model(&models.DailyBreakfastConsumption{}).
+		Where("property_id = ? AND DATE(consumption_date) = ? AND status = 'no_show'", closeOut.PropertyID, dateStr).
+		Count(&noShows).Error
+	if err != nil {
+		return fmt.Errorf("failed to summarize close-out: %w", err)
+	}
+
+	closeOut.GuestsServed = int(served.Guests)
+	closeOut.CoversServed = int(served.Covers)
+	closeOut.UpsellCovers = int(served.Upsell)
+	closeOut.Revenue = served.Revenue
+	closeOut.VoidedVisits = int(voided)
+	closeOut.NoShows = int(noShows)
+	return nil
+}
+
+// Reopen re-opens a closed service, removing the no-shows its close-out
+// recorded. An outlet cannot be re-opened while the whole property is closed.
+func (s *CloseOutService) Reopen(ctx context.Context, closeOutID, staffID uint, reason, ipAddress, userAgent string) (*models.ServiceCloseOut, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, ErrReopenReasonRequired
+	}
+
+	var before, after models.ServiceCloseOut
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&before, closeOutID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCloseOutNotFound
+			}
+			return fmt.Errorf("failed to load close-out: %w", err)
+		}
+		if before.Status != "closed" {
+			return ErrNotReopenable
+		}
+
+		// A property close-out closes every outlet, so it has to be re-opened
+		// on its own before any outlet can serve again
+		if before.OutletID != nil {
+			var propertyClosed int64
+			err := tx.Model(&models.ServiceCloseOut{}).
+				Where("property_id = ? AND DATE(business_date) = ? AND status = 'closed' AND outlet_id IS NULL",
+					before.PropertyID, before.BusinessDate.Format("2006-01-02")).
+				Count(&propertyClosed).Error
+			if err != nil {
+				return fmt.Errorf("failed to check property close-out: %w", err)
+			}
+			if propertyClosed > 0 {
+				return ErrPropertyClosedOut
+			}
+		}
+
+		now := time.Now()
+		after = before
+		after.Status = "reopened"
+		after.ReopenedAt = &now
+		after.ReopenedBy = &staffID
+		after.ReopenReason = reason
+
+		err := tx.Model(&before).Updates(map[string]interface{}{
+			"status":        after.Status,
+			"reopened_at":   now,
+			"reopened_by":   staffID,
+			"reopen_reason": reason,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to re-open close-out: %w", err)
+		}
+
+		// Only this close-out's no-shows go; guests recorded by another
+		// close-out stop being no-shows if they are served after all
+		err = tx.Where("close_out_id = ? AND status = 'no_show'", before.ID).
+			Delete(&models.DailyBreakfastConsumption{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to remove no-shows: %w", err)
+		}
+		return nil
+	})
+
+	resourceID := strconv.FormatUint(uint64(closeOutID), 10)
+	if err != nil {
+		if s.auditService != nil {
+			s.auditService.LogFailure(ctx, &staffID, audit.ActionReopen, audit.ResourceCloseOut, resourceID, ipAddress, userAgent, err)
+		}
+		return nil, err
+	}
+
+	if s.auditService != nil {
+		if err := s.auditService.LogSuccess(ctx, &staffID, audit.ActionReopen, audit.ResourceCloseOut, resourceID, ipAddress, userAgent, before, after); err != nil {
+			logging.WithError(err).Warn("Failed to write re-open audit entry")
+		}
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":      "CloseOutService",
+		"close_out_id": closeOutID,
+		"staff_id":     staffID,
+		"reason":       reason,
+	}).Info("Breakfast service re-opened")
+
+	if s.listener != nil {
+		s.listener.ConsumptionChanged(after.PropertyID, after.BusinessDate)
+	}
+	return &after, nil
+}
+
+// BusinessDate returns the property's current business date
+func (s *CloseOutService) BusinessDate(propertyID string) time.Time {
+	return currentBusinessDate(s.db, propertyID)
+}
+
+// GetCloseOuts lists a property's close-outs, optionally for a single business date
+func (s *CloseOutService) GetCloseOuts(propertyID string, date *time.Time) ([]models.ServiceCloseOut, error) {
+	query := s.db.Preload("Outlet").Where("property_id = ?", propertyID)
+	if date != nil {
+		query = query.Where("DATE(business_date) = ?", date.Format("2006-01-02"))
+	}
+
+	var closeOuts []models.ServiceCloseOut
+	if err := query.Order("business_date DESC, closed_at DESC").Find(&closeOuts).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch close-outs: %w", err)
+	}
+	return closeOuts, nil
+}
+
+// GetCloseOut retrieves a close-out by ID
+func (s *CloseOutService) GetCloseOut(closeOutID uint) (*models.ServiceCloseOut, error) {
+	var closeOut models.ServiceCloseOut
+	if err := s.db.Preload("Outlet").First(&closeOut, closeOutID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCloseOutNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch close-out: %w", err)
+	}
+	return &closeOut, nil
+}
+
+// RunScheduledCloseOuts closes every package outlet whose service hours ended
+// more than the grace period ago. Properties without package outlet hours are
+// closed as a whole once their business date has ended.
+func (s *CloseOutService) RunScheduledCloseOuts(ctx context.Context) {
+	var outlets []models.Outlet
+	if err := s.db.Where("is_active = true AND accepts_package = true AND close_time <> ''").Find(&outlets).Error; err != nil {
+		logging.WithError(err).Error("Failed to fetch outlets for scheduled close-out")
+		return
+	}
+
+	for _, outlet := range outlets {
+		clock, err := LoadPropertyClock(s.db, outlet.PropertyID)
+		if err != nil {
+			logging.WithError(err).Warn("Skipping scheduled close-out for outlet")
+			continue
+		}
+		closeTime, err := time.Parse("15:04", outlet.CloseTime)
+		if err != nil {
+			continue
+		}
+
+		// Service hours are property-local
+		now := clock.Now()
+		today := clock.BusinessDate(now)
+		closeAt := time.Date(today.Year(), today.Month(), today.Day(), closeTime.Hour(), closeTime.Minute(), 0, 0, clock.Location)
+		if closeAt.Before(time.Date(today.Year(), today.Month(), today.Day(), clock.CutoverHour, 0, 0, 0, clock.Location)) {
+			// Closing before the cutover means service ends after midnight
+			closeAt = closeAt.AddDate(0, 0, 1)
+		}
+		if now.Before(closeAt.Add(s.config.Grace)) {
+			continue
+		}
+
+		outletID := outlet.ID
+		s.scheduledCloseOut(ctx, outlet.PropertyID, &outletID, today)
+	}
+
+	var properties []models.Property
+	err := s.db.Where("property_id NOT IN (?)", s.db.Model(&models.Outlet{}).
+		Select("property_id").
+		Where("is_active = true AND accepts_package = true AND close_time <> ''")).
+		Find(&properties).Error
+	if err != nil {
+		logging.WithError(err).Error("Failed to fetch properties for scheduled close-out")
+		return
+	}
+
+	for _, property := range properties {
+		clock, err := LoadPropertyClock(s.db, property.PropertyID)
+		if err != nil {
+			logging.WithError(err).Warn("Skipping scheduled close-out for property")
+			continue
+		}
+
+		// The business date that ended at the last cutover, once the grace has passed
+		yesterday := clock.BusinessDate(time.Now().Add(-s.config.Grace)).AddDate(0, 0, -1)
+		s.scheduledCloseOut(ctx, property.PropertyID, nil, yesterday)
+	}
+}
+
+// scheduledCloseOut closes a property or outlet for a business date unless it
+// is already closed
+func (s *CloseOutService) scheduledCloseOut(ctx context.Context, propertyID string, outletID *uint, date time.Time) {
+	if err := ensureServiceOpen(s.db.WithContext(ctx), propertyID, outletID, date); err != nil {
+		if !errors.Is(err, ErrDayClosed) {
+			logging.WithError(err).Warn("Skipping scheduled close-out")
+		}
+		return
+	}
+
+	_, err := s.CloseOut(ctx, CloseOutRequest{
+		PropertyID:   propertyID,
+		OutletID:     outletID,
+		BusinessDate: date,
+		Trigger:      "scheduled",
+	})
+	if err != nil && !errors.Is(err, ErrAlreadyClosed) {
+		logging.WithFields(logrus.Fields{
+			"service":       "CloseOutService",
+			"property_id":   propertyID,
+			"outlet_id":     outletID,
+			"business_date": date.Format("2006-01-02"),
+			"error":         err.Error(),
+		}).Error("Scheduled close-out failed")
+	}
+}
+
+// StartScheduler runs scheduled close-outs until the context is cancelled
+func (s *CloseOutService) StartScheduler(ctx context.Context) {
+	interval := s.config.Interval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logging.Info("Close-out scheduler stopped")
+			return
+		case <-ticker.C:
+			s.RunScheduledCloseOuts(ctx)
+		}
+	}
+}
+
+// ensureServiceOpen returns ErrDayClosed when the property, or the given outlet,
+// is closed out for the day. Visits without an outlet could be served by any
+// package outlet, so for them the day is also closed once every one of those
+// outlets is closed out.
+func ensureServiceOpen(tx *gorm.DB, propertyID string, outletID *uint, date time.Time) error {
+	dateStr := date.Format("2006-01-02")
+	query := tx.Model(&models.ServiceCloseOut{}).
+		Where("property_id = ? AND DATE(business_date) = ? AND status = 'closed'", propertyID, dateStr)
+	if outletID != nil {
+		query = query.Where("outlet_id IS NULL OR outlet_id = ?", *outletID)
+	} else {
+		query = query.Where("outlet_id IS NULL")
+	}
+
+	var closed int64
+	if err := query.Count(&closed).Error; err != nil {
+		return fmt.Errorf("failed to check close-out: %w", err)
+	}
+	if closed > 0 {
+		return ErrDayClosed
+	}
+	if outletID != nil {
+		return nil
+	}
+
+	var outlets int64
+	err := tx.Model(&models.Outlet{}).
+		Where("property_id = ? AND is_active = true AND accepts_package = true", propertyID).
+		Count(&outlets).Error
+	if err != nil {
+		return fmt.Errorf("failed to check open outlets: %w", err)
+	}
+	if outlets == 0 {
+		return nil
+	}
+	open, err := openPackageOutlets(tx, propertyID, dateStr, nil)
+	if err != nil {
+		return err
+	}
+	if open == 0 {
+		return ErrDayClosed
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"hudini-breakfast-module/internal/config"
+	"hudini-breakfast-module/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var serviceDate = time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+// closeOutFixture is a property with two package outlets and three rooms: one
+// package guest who has not eaten, one who ate at the first outlet and one
+// without a package
+type closeOutFixture struct {
+	db       *gorm.DB
+	service  *CloseOutService
+	terrace  uint
+	lounge   uint
+	waiting  models.Guest
+	eaten    models.Guest
+	roomOnly models.Guest
+}
+
+func newCloseOutFixture(t *testing.T) *closeOutFixture {
+	t.Helper()
+	db := newTestDB(t)
+	f := &closeOutFixture{db: db, service: NewCloseOutService(db, nil, config.CloseOutConfig{})}
+
+	mustCreate(t, db, &models.Property{PropertyID: "P1", Name: "Harbour Hotel"})
+	terrace := models.Outlet{PropertyID: "P1", Name: "Terrace", AcceptsPackage: true, IsActive: true}
+	lounge := models.Outlet{PropertyID: "P1", Name: "Lounge", AcceptsPackage: true, IsActive: true}
+	mustCreate(t, db, &terrace)
+	mustCreate(t, db, &lounge)
+	f.terrace, f.lounge = terrace.ID, lounge.ID
+
+	stay := func(pmsID, room string, breakfast bool) models.Guest {
+		return models.Guest{
+			PMSGuestID: pmsID, ReservationID: "R-" + pmsID, RoomNumber: room,
+			FirstName: "Guest", LastName: room, PropertyID: "P1", IsActive: true,
+			CheckInDate: serviceDate.AddDate(0, 0, -1), CheckOutDate: serviceDate.AddDate(0, 0, 2),
+			AdultCount: 2, BreakfastPackage: breakfast,
+		}
+	}
+	f.waiting = stay("G1", "101", true)
+	f.eaten = stay("G2", "102", true)
+	f.roomOnly = stay("G3", "103", false)
+	mustCreate(t, db, &f.waiting)
+	mustCreate(t, db, &f.eaten)
+	mustCreate(t, db, &f.roomOnly)
+
+	mustCreate(t, db, &models.DailyBreakfastConsumption{
+		PropertyID: "P1", RoomNumber: "102", GuestID: f.eaten.ID, ConsumptionDate: serviceDate,
+		OutletID: &f.terrace, Status: "consumed", AdultCovers: 2, Amount: 40,
+	})
+	return f
+}
+
+func (f *closeOutFixture) closeOut(t *testing.T, outletID *uint) (*models.ServiceCloseOut, error) {
+	t.Helper()
+	return f.service.CloseOut(context.Background(), CloseOutRequest{
+		PropertyID: "P1", OutletID: outletID, BusinessDate: serviceDate, Trigger: "manual",
+	})
+}
+
+func (f *closeOutFixture) noShows(t *testing.T) []models.DailyBreakfastConsumption {
+	t.Helper()
+	var noShows []models.DailyBreakfastConsumption
+	if err := f.db.Where("status = ?", "no_show").Find(&noShows).Error; err != nil {
+		t.Fatalf("fetching no-shows: %v", err)
+	}
+	return noShows
+}
+
+func TestCloseOutOutletsInTurn(t *testing.T) {
+	f := newCloseOutFixture(t)
+
+	// Closing one outlet leaves the other serving, so nobody is a no-show yet
+	terrace, err := f.closeOut(t, &f.terrace)
+	if err != nil {
+		t.Fatalf("closing the terrace: %v", err)
+	}
+	if terrace.Status != "closed" || terrace.NoShows != 0 || len(f.noShows(t)) != 0 {
+		t.Fatalf("closing the terrace: status %q, %d no-shows", terrace.Status, terrace.NoShows)
+	}
+	if terrace.GuestsEligible != 2 || terrace.GuestsServed != 1 || terrace.CoversServed != 2 || terrace.Revenue != 40 {
+		t.Errorf("terrace summary = %d eligible, %d served, %d covers, %v revenue, want 2, 1, 2, 40",
+			terrace.GuestsEligible, terrace.GuestsServed, terrace.CoversServed, terrace.Revenue)
+	}
+	if err := ensureServiceOpen(f.db, "P1", &f.terrace, serviceDate); !errors.Is(err, ErrDayClosed) {
+		t.Errorf("terrace after closing: error = %v, want ErrDayClosed", err)
+	}
+	if err := ensureServiceOpen(f.db, "P1", nil, serviceDate); err != nil {
+		t.Errorf("visits without an outlet while the lounge is open: %v", err)
+	}
+	if _, err := f.closeOut(t, &f.terrace); !errors.Is(err, ErrAlreadyClosed) {
+		t.Errorf("closing the terrace twice: error = %v, want ErrAlreadyClosed", err)
+	}
+
+	// Closing the last outlet records the package guest who never came
+	lounge, err := f.closeOut(t, &f.lounge)
+	if err != nil {
+		t.Fatalf("closing the lounge: %v", err)
+	}
+	noShows := f.noShows(t)
+	if lounge.NoShows != 1 || len(noShows) != 1 || noShows[0].GuestID != f.waiting.ID {
+		t.Fatalf("closing the lounge: %d no-shows recorded, %+v", lounge.NoShows, noShows)
+	}
+	if noShows[0].CloseOutID == nil || *noShows[0].CloseOutID != lounge.ID || noShows[0].AdultCovers != 2 {
+		t.Errorf("no-show = close-out %v, %d adults, want close-out %d, 2 adults", noShows[0].CloseOutID, noShows[0].AdultCovers, lounge.ID)
+	}
+	if err := ensureServiceOpen(f.db, "P1", nil, serviceDate); !errors.Is(err, ErrDayClosed) {
+		t.Errorf("visits without an outlet once every outlet is closed: error = %v, want ErrDayClosed", err)
+	}
+	if _, err := f.closeOut(t, nil); !errors.Is(err, ErrAlreadyClosed) {
+		t.Errorf("closing the property once every outlet is closed: error = %v, want ErrAlreadyClosed", err)
+	}
+
+	// Re-opening the terrace lets guests eat there again, but the no-show the
+	// lounge recorded stays until the lounge re-opens
+	if _, err := f.service.Reopen(context.Background(), terrace.ID, 1, " ", "", ""); !errors.Is(err, ErrReopenReasonRequired) {
+		t.Errorf("re-opening without a reason: error = %v, want ErrReopenReasonRequired", err)
+	}
+	reopened, err := f.service.Reopen(context.Background(), terrace.ID, 1, "Late group", "", "")
+	if err != nil {
+		t.Fatalf("re-opening the terrace: %v", err)
+	}
+	if reopened.Status != "reopened" || reopened.ReopenReason != "Late group" || reopened.ReopenedBy == nil {
+		t.Errorf("re-opened close-out = %+v", reopened)
+	}
+	if noShows := f.noShows(t); len(noShows) != 1 {
+		t.Errorf("re-opening the terrace: %d no-shows, want the lounge's 1 kept", len(noShows))
+	}
+	if err := ensureServiceOpen(f.db, "P1", &f.terrace, serviceDate); err != nil {
+		t.Errorf("terrace after re-opening: %v", err)
+	}
+	if err := ensureServiceOpen(f.db, "P1", &f.lounge, serviceDate); !errors.Is(err, ErrDayClosed) {
+		t.Errorf("lounge after re-opening the terrace: error = %v, want ErrDayClosed", err)
+	}
+	if _, err := f.service.Reopen(context.Background(), terrace.ID, 1, "Again", "", ""); !errors.Is(err, ErrNotReopenable) {
+		t.Errorf("re-opening twice: error = %v, want ErrNotReopenable", err)
+	}
+
+	// Closing the terrace again finds the guest already recorded
+	again, err := f.closeOut(t, &f.terrace)
+	if err != nil {
+		t.Fatalf("closing the terrace again: %v", err)
+	}
+	if noShows := f.noShows(t); len(noShows) != 1 || again.NoShows != 1 {
+		t.Errorf("closing the terrace again: %d no-shows, summary %d, want 1", len(noShows), again.NoShows)
+	}
+
+	// Re-opening the lounge removes the no-show it recorded
+	if _, err := f.service.Reopen(context.Background(), lounge.ID, 1, "Late group", "", ""); err != nil {
+		t.Fatalf("re-opening the lounge: %v", err)
+	}
+	if noShows := f.noShows(t); len(noShows) != 0 {
+		t.Errorf("no-shows left after re-opening the lounge: %+v", noShows)
+	}
+}
+
+func TestServedAfterReopenIsNoLongerNoShow(t *testing.T) {
+	f := newCloseOutFixture(t)
+	mustCreate(t, f.db, &models.BreakfastPrice{PropertyID: "P1", GuestType: "adult", Price: 20, EffectiveFrom: serviceDate.AddDate(-1, 0, 0)})
+
+	terrace, err := f.closeOut(t, &f.terrace)
+	if err != nil {
+		t.Fatalf("closing the terrace: %v", err)
+	}
+	if _, err := f.closeOut(t, &f.lounge); err != nil {
+		t.Fatalf("closing the lounge: %v", err)
+	}
+	if _, err := f.service.Reopen(context.Background(), terrace.ID, 1, "Late guest", "", ""); err != nil {
+		t.Fatalf("re-opening the terrace: %v", err)
+	}
+
+	engine, err := LoadEligibilityEngine(f.db, "P1")
+	if err != nil {
+		t.Fatalf("loading eligibility: %v", err)
+	}
+	_, err = recordVisit(f.db, engine, &f.waiting, visitRequest{
+		StaffID: 1, OutletID: f.terrace, PaymentMethod: "room_charge", At: serviceDate.Add(9 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("serving the late guest: %v", err)
+	}
+	if noShows := f.noShows(t); len(noShows) != 0 {
+		t.Errorf("late guest still a no-show after being served: %+v", noShows)
+	}
+}
+
+func TestCloseOutProperty(t *testing.T) {
+	f := newCloseOutFixture(t)
+
+	property, err := f.closeOut(t, nil)
+	if err != nil {
+		t.Fatalf("closing the property: %v", err)
+	}
+	if property.NoShows != 1 || len(f.noShows(t)) != 1 {
+		t.Fatalf("closing the property: %d no-shows, want 1", property.NoShows)
+	}
+	for _, outletID := range []uint{f.terrace, f.lounge} {
+		if err := ensureServiceOpen(f.db, "P1", &outletID, serviceDate); !errors.Is(err, ErrDayClosed) {
+			t.Errorf("outlet %d after closing the property: error = %v, want ErrDayClosed", outletID, err)
+		}
+		if _, err := f.closeOut(t, &outletID); !errors.Is(err, ErrAlreadyClosed) {
+			t.Errorf("closing outlet %d after the property: error = %v, want ErrAlreadyClosed", outletID, err)
+		}
+	}
+
+	if _, err := f.service.Reopen(context.Background(), property.ID, 1, "Closed by mistake", "", ""); err != nil {
+		t.Fatalf("re-opening the property: %v", err)
+	}
+	if noShows := f.noShows(t); len(noShows) != 0 {
+		t.Errorf("no-shows left after re-opening the property: %+v", noShows)
+	}
+	if err := ensureServiceOpen(f.db, "P1", nil, serviceDate); err != nil {
+		t.Errorf("property after re-opening: %v", err)
+	}
+}
+
+func TestReopenOutletWhilePropertyClosed(t *testing.T) {
+	f := newCloseOutFixture(t)
+
+	terrace, err := f.closeOut(t, &f.terrace)
+	if err != nil {
+		t.Fatalf("closing the terrace: %v", err)
+	}
+	// Close the property on top, as the scheduler does once service ends
+	property := models.ServiceCloseOut{PropertyID: "P1", BusinessDate: serviceDate, Status: "closed", Trigger: "scheduled", ClosedAt: time.Now()}
+	mustCreate(t, f.db, &property)
+	noShow := models.DailyBreakfastConsumption{PropertyID: "P1", RoomNumber: "101", GuestID: f.waiting.ID, ConsumptionDate: serviceDate, Status: "no_show", CloseOutID: &property.ID}
+	mustCreate(t, f.db, &noShow)
+
+	// The outlet stays closed, with the property, until the property re-opens
+	if _, err := f.service.Reopen(context.Background(), terrace.ID, 1, "Late group", "", ""); !errors.Is(err, ErrPropertyClosedOut) {
+		t.Fatalf("re-opening the terrace: error = %v, want ErrPropertyClosedOut", err)
+	}
+	if err := f.db.First(&property, property.ID).Error; err != nil {
+		t.Fatalf("reloading the property close-out: %v", err)
+	}
+	if property.Status != "closed" || len(f.noShows(t)) != 1 {
+		t.Errorf("property close-out = %q with %d no-shows, want closed with 1", property.Status, len(f.noShows(t)))
+	}
+
+	if _, err := f.service.Reopen(context.Background(), property.ID, 1, "Late group", "", ""); err != nil {
+		t.Fatalf("re-opening the property: %v", err)
+	}
+	if err := ensureServiceOpen(f.db, "P1", &f.terrace, serviceDate); !errors.Is(err, ErrDayClosed) {
+		t.Errorf("terrace after re-opening only the property: error = %v, want ErrDayClosed", err)
+	}
+	if _, err := f.service.Reopen(context.Background(), terrace.ID, 1, "Late group", "", ""); err != nil {
+		t.Fatalf("re-opening the terrace: %v", err)
+	}
+	if err := ensureServiceOpen(f.db, "P1", &f.terrace, serviceDate); err != nil {
+		t.Errorf("terrace after re-opening: %v", err)
+	}
+}
+
+func TestRunScheduledCloseOuts(t *testing.T) {
+	db := newTestDB(t)
+	service := NewCloseOutService(db, nil, config.CloseOutConfig{})
+	yesterday := calendarDate(time.Now().UTC()).AddDate(0, 0, -1)
+
+	mustCreate(t, db, &models.Property{PropertyID: "BARE", Name: "No Outlets", TimeZone: "UTC"})
+	mustCreate(t, db, &models.Property{PropertyID: "HOURS", Name: "Outlet Hours", TimeZone: "UTC"})
+	mustCreate(t, db, &models.Outlet{PropertyID: "HOURS", Name: "Terrace", AcceptsPackage: true, IsActive: true, OpenTime: "06:00", CloseTime: "23:59"})
+	for _, propertyID := range []string{"BARE", "HOURS"} {
+		mustCreate(t, db, &models.Guest{
+			PMSGuestID: propertyID, ReservationID: "R-" + propertyID, RoomNumber: "101", FirstName: "Guest", LastName: propertyID,
+			PropertyID: propertyID, IsActive: true, BreakfastPackage: true, AdultCount: 1,
+			CheckInDate: yesterday.AddDate(0, 0, -1), CheckOutDate: yesterday.AddDate(0, 0, 3),
+		})
+	}
+
+	// Running twice closes each day once
+	service.RunScheduledCloseOuts(context.Background())
+	service.RunScheduledCloseOuts(context.Background())
+
+	tests := []struct {
+		propertyID string
+		closed     bool
+	}{
+		{"BARE", true},
+		{"HOURS", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.propertyID, func(t *testing.T) {
+			var closeOuts []models.ServiceCloseOut
+			db.Where("property_id = ? AND outlet_id IS NULL", tt.propertyID).Find(&closeOuts)
+			if !tt.closed {
+				if len(closeOuts) != 0 {
+					t.Errorf("property with outlet hours closed as a whole: %+v", closeOuts)
+				}
+				return
+			}
+			if len(closeOuts) != 1 {
+				t.Fatalf("%d property close-outs, want 1", len(closeOuts))
+			}
+			closeOut := closeOuts[0]
+			if !closeOut.BusinessDate.Equal(yesterday) || closeOut.Trigger != "scheduled" || closeOut.NoShows != 1 {
+				t.Errorf("close-out = %s, trigger %q, %d no-shows, want %s, scheduled, 1",
+					closeOut.BusinessDate.Format("2006-01-02"), closeOut.Trigger, closeOut.NoShows, yesterday.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestCloseOutUnknownOutlet(t *testing.T) {
+	f := newCloseOutFixture(t)
+	missing := uint(999)
+	if _, err := f.closeOut(t, &missing); !errors.Is(err, ErrOutletNotFound) {
+		t.Errorf("closing an unknown outlet: error = %v, want ErrOutletNotFound", err)
+	}
+	if _, err := f.service.Reopen(context.Background(), 999, 1, "Reason", "", ""); !errors.Is(err, ErrCloseOutNotFound) {
+		t.Errorf("re-opening an unknown close-out: error = %v, want ErrCloseOutNotFound", err)
+	}
+}
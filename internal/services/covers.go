@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"hudini-breakfast-module/internal/models"
+)
+
+// Errors returned when a visit asks for more covers than remain in the day's entitlement
+var (
+	ErrBreakfastConsumed = errors.New("breakfast already consumed today")
+	ErrCoversExceeded    = errors.New("not enough breakfast covers remaining today")
+	ErrInvalidCovers     = errors.New("cover counts cannot be negative")
+)
+
+// CoverRequest describes the covers served in a single breakfast visit
+type CoverRequest struct {
+	Adults      int  `json:"adult_covers"`
+	Children    int  `json:"child_covers"`
+	AllowUpsell bool `json:"allow_upsell"` // Charge covers beyond the entitlement instead of refusing them
+}
+
+// CoverEntitlement returns the number of breakfast covers a guest's package includes per day
+func CoverEntitlement(guest *models.Guest) int {
+	if !guest.BreakfastPackage {
+		return 0
+	}
+	return packageCovers(guest)
+}
+
+// allocateCovers resolves the covers for a visit against what is left of the
+// day's entitlement. When no covers are requested, all remaining covers are served.
+func allocateCovers(req CoverRequest, guest *models.Guest, entitled, served int) (adults, children, upsell int, err error) {
+	if req.Adults < 0 || req.Children < 0 {
+		return 0, 0, 0, ErrInvalidCovers
+	}
+
+	remaining := entitled - served
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	adults, children = req.Adults, req.Children
+	if adults == 0 && children == 0 {
+		if remaining == 0 {
+			return 0, 0, 0, ErrBreakfastConsumed
+		}
+		children = guest.ChildCount
+		if children > remaining {
+			children = remaining
+		}
+		adults = remaining - children
+	}
+
+	over := adults + children - remaining
+	if over > 0 {
+		if !req.AllowUpsell {
+			return 0, 0, 0, fmt.Errorf("%w: only %d remaining", ErrCoversExceeded, remaining)
+		}
+		upsell = over
+	}
+
+	return adults, children, upsell, nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"os"
+	"testing"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	logging.InitLogger(logging.LoggingConfig{Level: "error"})
+	os.Exit(m.Run())
+}
+
+// newTestDB opens a private in-memory database with the schema the service
+// tests need. A single connection keeps every query on the same database.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	err = db.AutoMigrate(
+		&models.Property{},
+		&models.Room{},
+		&models.Guest{},
+		&models.DailyBreakfastConsumption{},
+		&models.OHIPTransaction{},
+		&models.Outlet{},
+		&models.Order{},
+		&models.InventoryItem{},
+		&models.InventoryUsage{},
+		&models.InventoryMovement{},
+		&models.BreakfastPrice{},
+		&models.EligibilityRule{},
+		&models.ServiceCloseOut{},
+		&models.SyncEvent{},
+		&models.DailyRollup{},
+		&models.HourlyRollup{},
+	)
+	if err != nil {
+		t.Fatalf("migrating database: %v", err)
+	}
+	return db
+}
+
+// mustCreate inserts a record, failing the test if it cannot
+func mustCreate(t *testing.T, db *gorm.DB, value interface{}) {
+	t.Helper()
+	if err := db.Create(value).Error; err != nil {
+		t.Fatalf("creating %T: %v", value, err)
+	}
+}
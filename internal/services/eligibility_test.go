@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"hudini-breakfast-module/internal/models"
+)
+
+func TestEligibilityRules(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("loading time zone: %v", err)
+	}
+	clock := &PropertyClock{Location: tokyo, CutoverHour: 4}
+
+	// A family staying 14 to 16 October with a breakfast package
+	arrival := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	departure := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	family := models.Guest{
+		CheckInDate: arrival, CheckOutDate: departure, BreakfastPackage: true,
+		AdultCount: 2, ChildCount: 2, ChildAges: "4,9",
+	}
+	roomOnly := family
+	roomOnly.BreakfastPackage = false
+	bedAndBreakfast := roomOnly
+	bedAndBreakfast.RateCode = "bb"
+	gold := roomOnly
+	gold.LoyaltyTier = "Gold"
+	silver := roomOnly
+	silver.LoyaltyTier = "Silver"
+
+	local := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, tokyo)
+	}
+	rule := func(ruleType, value string) models.EligibilityRule {
+		return models.EligibilityRule{RuleType: ruleType, Value: value, IsActive: true}
+	}
+
+	tests := []struct {
+		name          string
+		rules         []models.EligibilityRule
+		guest         models.Guest
+		at            time.Time
+		eligible      bool
+		complimentary bool
+		freeChildren  int
+		reason        string
+	}{
+		{"package guest mid-stay", nil, family, local(15, 8, 0), true, false, 0, "breakfast package"},
+		{"no package", nil, roomOnly, local(15, 8, 0), false, false, 0, "guest does not have breakfast package"},
+		{"not staying", nil, family, local(17, 8, 0), false, false, 0, "guest is not staying on 2026-10-17"},
+
+		// Departure cutoff, compared in property-local time
+		{"departure morning before the cutoff", []models.EligibilityRule{rule(RuleDepartureDayCutoff, "11:00")}, family, local(16, 10, 59), true, false, 0, "breakfast package"},
+		{"departure morning at the cutoff", []models.EligibilityRule{rule(RuleDepartureDayCutoff, "11:00")}, family, local(16, 11, 0), false, false, 0, "departure-day breakfast ends at 11:00"},
+		{"after midnight before the cutover is still the day before", []models.EligibilityRule{rule(RuleDepartureDayCutoff, "01:00")}, family, local(16, 2, 0), true, false, 0, "breakfast package"},
+
+		{"arrival day", []models.EligibilityRule{rule(RuleNoArrivalDay, "")}, family, local(14, 8, 0), false, false, 0, "no breakfast on arrival day"},
+		{"day after arrival", []models.EligibilityRule{rule(RuleNoArrivalDay, "")}, family, local(15, 8, 0), true, false, 0, "breakfast package"},
+
+		{"children under six", []models.EligibilityRule{rule(RuleChildAgeFree, "6")}, family, local(15, 8, 0), true, false, 1, "breakfast package"},
+		{"children under twelve", []models.EligibilityRule{rule(RuleChildAgeFree, "12")}, family, local(15, 8, 0), true, false, 2, "breakfast package"},
+
+		{"loyalty tier", []models.EligibilityRule{rule(RuleLoyaltyComplimentary, "gold, platinum")}, gold, local(15, 8, 0), true, true, 0, "complimentary breakfast for loyalty tier GOLD"},
+		{"other loyalty tier", []models.EligibilityRule{rule(RuleLoyaltyComplimentary, "gold")}, silver, local(15, 8, 0), false, false, 0, "guest does not have breakfast package"},
+
+		{"rate code", []models.EligibilityRule{rule(RuleRateCodeIncludes, "BB,HB")}, bedAndBreakfast, local(15, 8, 0), true, false, 0, "rate code BB includes breakfast"},
+		{"rate code, but not on arrival day", []models.EligibilityRule{rule(RuleRateCodeIncludes, "BB"), rule(RuleNoArrivalDay, "")}, bedAndBreakfast, local(14, 8, 0), false, false, 0, "no breakfast on arrival day"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewEligibilityEngine(clock, tt.rules)
+			if err != nil {
+				t.Fatalf("NewEligibilityEngine: %v", err)
+			}
+			guest := tt.guest
+			got := engine.Evaluate(&guest, tt.at)
+			if got.Eligible != tt.eligible || got.Complimentary != tt.complimentary || got.FreeChildCovers != tt.freeChildren || got.Reason != tt.reason {
+				t.Errorf("Evaluate = eligible %v, complimentary %v, %d free children, %q, want %v, %v, %d, %q",
+					got.Eligible, got.Complimentary, got.FreeChildCovers, got.Reason,
+					tt.eligible, tt.complimentary, tt.freeChildren, tt.reason)
+			}
+		})
+	}
+}
+
+func TestEligibilityRuleValues(t *testing.T) {
+	tests := []struct {
+		ruleType, value string
+		valid           bool
+	}{
+		{RuleDepartureDayCutoff, "11:00", true},
+		{RuleDepartureDayCutoff, "9:30", true},
+		{RuleDepartureDayCutoff, "noon", false},
+		{RuleChildAgeFree, "6", true},
+		{RuleChildAgeFree, "0", false},
+		{RuleLoyaltyComplimentary, " , ", false},
+		{RuleRateCodeIncludes, "BB", true},
+		{"free_for_all", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ruleType+" "+tt.value, func(t *testing.T) {
+			_, err := NewEligibilityEngine(&PropertyClock{Location: time.UTC}, []models.EligibilityRule{{RuleType: tt.ruleType, Value: tt.value}})
+			if tt.valid && err != nil {
+				t.Errorf("rejected: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidEligibilityConfig) && !errors.Is(err, ErrUnknownEligibilityRule) {
+				t.Errorf("error = %v, want an invalid rule", err)
+			}
+		})
+	}
+}
+
+func TestLoadEligibilityEngineDefaultCutoff(t *testing.T) {
+	db := newTestDB(t)
+	mustCreate(t, db, &models.Property{PropertyID: "P1", Name: "Harbour Hotel", TimeZone: "UTC"})
+	mustCreate(t, db, &models.Property{PropertyID: "P2", Name: "Late Hotel", TimeZone: "UTC"})
+	mustCreate(t, db, &models.Property{PropertyID: "P3", Name: "Open Hotel", TimeZone: "UTC"})
+	mustCreate(t, db, &models.EligibilityRule{PropertyID: "P2", RuleType: RuleDepartureDayCutoff, Value: "13:00", IsActive: true})
+	inactive := models.EligibilityRule{PropertyID: "P3", RuleType: RuleDepartureDayCutoff, Value: "11:00", IsActive: true}
+	mustCreate(t, db, &inactive)
+	db.Model(&inactive).Update("is_active", false)
+
+	guest := models.Guest{
+		CheckInDate: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC), CheckOutDate: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+		BreakfastPackage: true, AdultCount: 1,
+	}
+	noon := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		propertyID string
+		eligible   bool
+	}{
+		{"P1", false}, // the default 11:00 cutoff
+		{"P2", true},  // the property's own later cutoff
+		{"P3", true},  // the property turned the cutoff off
+	}
+	for _, tt := range tests {
+		t.Run(tt.propertyID, func(t *testing.T) {
+			engine, err := LoadEligibilityEngine(db, tt.propertyID)
+			if err != nil {
+				t.Fatalf("LoadEligibilityEngine: %v", err)
+			}
+			if got := engine.Evaluate(&guest, noon); got.Eligible != tt.eligible {
+				t.Errorf("departure day at noon: eligible %v (%s), want %v", got.Eligible, got.Reason, tt.eligible)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"hudini-breakfast-module/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ExecutiveService aggregates guests, visit rollups and staff comments for the executive dashboards
+type ExecutiveService struct {
+	db      *gorm.DB
+	rollups *RollupService
+}
+
+// ExecutiveMetrics are a property's aggregates over a range of business dates
+type ExecutiveMetrics struct {
+	From             string  `json:"from"`
+	To               string  `json:"to"`
+	VIPGuests        int     `json:"vip_guests"`        // VIP guests in house during the range
+	UpsetVIPs        int     `json:"upset_vips"`        // VIP guests with a complaint logged during the range
+	GuestsServed     int     `json:"guests_served"`     // Guests with at least one visit
+	Complainants     int     `json:"complainants"`      // Guests with a complaint logged
+	SatisfactionRate float64 `json:"satisfaction_rate"` // Share of guests served without a complaint, 0-100
+	AvgServiceTime   float64 `json:"avg_service_time"`  // Minutes from seated to first served
+	ServiceSamples   int     `json:"service_samples"`
+	Covers           int     `json:"covers"`
+	Revenue          float64 `json:"revenue"`
+	OccupancyRate    float64 `json:"occupancy_rate"` // Average share of rooms occupied per night, 0-100
+}
+
+// ExecutiveSeries is a chart of metrics over the buckets of a period
+type ExecutiveSeries struct {
+	Period  string             `json:"period"`
+	Labels  []string           `json:"labels"`
+	Metrics []ExecutiveMetrics `json:"metrics"`
+}
+
+// HourlyServiceTimes is today's average service time by hour of service
+type HourlyServiceTimes struct {
+	Labels       []string  `json:"labels"`
+	ServiceTimes []float64 `json:"service_times"`
+	AverageTime  float64   `json:"average_time"`
+}
+
+// PreferenceCount is how many in-house guests follow a dietary restriction
+type PreferenceCount struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// executiveBucket is one labelled, inclusive range of business dates
+type executiveBucket struct {
+	label    string
+	from, to time.Time
+}
+
+// NewExecutiveService creates a new executive service
+func NewExecutiveService(db *gorm.DB, rollups *RollupService) *ExecutiveService {
+	return &ExecutiveService{
+		db:      db,
+		rollups: rollups,
+	}
+}
+
+// Metrics aggregates a property's business dates from through to, inclusive
+func (s *ExecutiveService) Metrics(propertyID string, from, to time.Time) (*ExecutiveMetrics, error) {
+	clock, err := LoadPropertyClock(s.db, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	return s.metrics(clock, propertyID, calendarDate(from), calendarDate(to))
+}
+
+// Compare returns the metrics for the last days business dates, ending
+// today, and for the same number of days before them
+func (s *ExecutiveService) Compare(propertyID string, days int) (*ExecutiveMetrics, *ExecutiveMetrics, error) {
+	if days <= 0 {
+		days = 7
+	}
+	clock, err := LoadPropertyClock(s.db, propertyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	today := clock.Today()
+
+	current, err := s.metrics(clock, propertyID, today.AddDate(0, 0, 1-days), today)
+	if err != nil {
+		return nil, nil, err
+	}
+	previous, err := s.metrics(clock, propertyID, today.AddDate(0, 0, 1-2*days), today.AddDate(0, 0, -days))
+	if err != nil {
+		return nil, nil, err
+	}
+	return current, previous, nil
+}
+
+// Series aggregates a property's metrics for this week (by day), the last four
+// weeks (by week), this quarter (by month) or the last twelve months (by month)
+func (s *ExecutiveService) Series(propertyID, period string) (*ExecutiveSeries, error) {
+	clock, err := LoadPropertyClock(s.db, propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	period, buckets := executiveBuckets(clock.Today(), period)
+	series := &ExecutiveSeries{Period: period}
+	for _, bucket := range buckets {
+		metrics, err := s.metrics(clock, propertyID, bucket.from, bucket.to)
+		if err != nil {
+			return nil, err
+		}
+		series.Labels = append(series.Labels, bucket.label)
+		series.Metrics = append(series.Metrics, *metrics)
+	}
+	return series, nil
+}
+
+// ServiceTimesToday averages today's seated-to-served times by hour of service
+func (s *ExecutiveService) ServiceTimesToday(propertyID string) (*HourlyServiceTimes, error) {
+	clock, err := LoadPropertyClock(s.db, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	today := clock.Today()
+
+	hours, err := s.rollups.Hours(propertyID, today, today)
+	if err != nil {
+		return nil, err
+	}
+
+	hourly := &HourlyServiceTimes{
+		Labels:       []string{"6AM", "7AM", "8AM", "9AM", "10AM", "11AM"},
+		ServiceTimes: make([]float64, 6),
+	}
+	counts := make([]int, 6)
+	total, samples := 0.0, 0
+	for _, hour := range hours {
+		total += hour.ServiceMinutes
+		samples += hour.ServiceSamples
+		if index := hour.Hour - 6; index >= 0 && index < len(counts) {
+			hourly.ServiceTimes[index] += hour.ServiceMinutes
+			counts[index] += hour.ServiceSamples
+		}
+	}
+	for i := range counts {
+		if counts[i] > 0 {
+			hourly.ServiceTimes[i] = roundTo(hourly.ServiceTimes[i]/float64(counts[i]), 1)
+		}
+	}
+	if samples > 0 {
+		hourly.AverageTime = roundTo(total/float64(samples), 1)
+	}
+	return hourly, nil
+}
+
+// GuestPreferences counts the dietary restrictions of today's in-house guests
+func (s *ExecutiveService) GuestPreferences(propertyID string) ([]PreferenceCount, int, error) {
+	clock, err := LoadPropertyClock(s.db, propertyID)
+	if err != nil {
+		return nil, 0, err
+	}
+	today := clock.Today().Format("2006-01-02")
+
+	var guestIDs []uint
+	err = s.db.Model(&models.Guest{}).
+		Where("property_id = ? AND is_active = ? AND DATE(check_in_date) <= ? AND DATE(check_out_date) >= ?", propertyID, true, today, today).
+		Pluck("id", &guestIDs).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch in-house guests: %w", err)
+	}
+
+	var preferences []models.GuestPreference
+	if len(guestIDs) > 0 {
+		if err := s.db.Where("guest_id IN ?", guestIDs).Find(&preferences).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to fetch guest preferences: %w", err)
+		}
+	}
+
+	counts := make(map[string]int)
+	restricted := 0
+	for _, preference := range preferences {
+		seen := map[string]bool{}
+		for _, name := range parseLegacyList(strings.TrimSpace(preference.DietaryRestr)) {
+			if code, ok := normalizeDietary(name); ok && !seen[code] {
+				seen[code] = true
+				counts[code]++
+			}
+		}
+		if len(seen) > 0 {
+			restricted++
+		}
+	}
+
+	result := []PreferenceCount{}
+	for _, restriction := range DietaryTaxonomy {
+		if counts[restriction.Code] > 0 {
+			result = append(result, PreferenceCount{Label: restriction.Name, Count: counts[restriction.Code]})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Count > result[j].Count })
+	result = append(result, PreferenceCount{Label: "No restrictions", Count: len(guestIDs) - restricted})
+	return result, len(guestIDs), nil
+}
+
+// metrics aggregates the business dates from through to, inclusive
+func (s *ExecutiveService) metrics(clock *PropertyClock, propertyID string, from, to time.Time) (*ExecutiveMetrics, error) {
+	fromStr, toStr := from.Format("2006-01-02"), to.Format("2006-01-02")
+	metrics := &ExecutiveMetrics{From: fromStr, To: toStr}
+
+	days, err := s.rollups.Days(propertyID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	totals := sumRollups(days)
+	metrics.Covers = totals.Covers
+	metrics.Revenue = totals.Revenue
+	metrics.ServiceSamples = totals.ServiceSamples
+	if totals.ServiceSamples > 0 {
+		metrics.AvgServiceTime = roundTo(totals.ServiceMinutes/float64(totals.ServiceSamples), 1)
+	}
+
+	// Guests served on several days count once, which daily rollups can't tell
+	var served int64
+	err = s.db.Model(&models.DailyBreakfastConsumption{}).
+		Where("property_id = ? AND status = ? AND DATE(consumption_date) >= ? AND DATE(consumption_date) <= ?",
+			propertyID, "consumed", fromStr, toStr).
+		Distinct("guest_id").
+		Count(&served).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count guests served: %w", err)
+	}
+	metrics.GuestsServed = int(served)
+
+	// Checked-out guests are deactivated, so past stays include inactive guests
+	var guests []models.Guest
+	err = s.db.Where("property_id = ? AND DATE(check_in_date) <= ? AND DATE(check_out_date) >= ?", propertyID, toStr, fromStr).
+		Find(&guests).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stays: %w", err)
+	}
+	vips := make(map[uint]bool)
+	for _, guest := range guests {
+		if guest.IsVIP {
+			vips[guest.ID] = true
+		}
+	}
+	metrics.VIPGuests = len(vips)
+
+	var rooms int64
+	if err := s.db.Model(&models.Room{}).Where("property_id = ?", propertyID).Count(&rooms).Error; err != nil {
+		return nil, fmt.Errorf("failed to count rooms: %w", err)
+	}
+	if rooms > 0 {
+		nights, occupied := 0, 0
+		for date := from; !date.After(to) && !date.After(clock.Today()); date = date.AddDate(0, 0, 1) {
+			inHouse := make(map[string]bool)
+			for _, guest := range guests {
+				if !calendarDate(guest.CheckInDate).After(date) && calendarDate(guest.CheckOutDate).After(date) {
+					inHouse[guest.RoomNumber] = true
+				}
+			}
+			nights++
+			occupied += len(inHouse)
+		}
+		if nights > 0 {
+			metrics.OccupancyRate = roundTo(float64(occupied)/float64(nights*int(rooms))*100, 1)
+		}
+	}
+
+	var complainants []uint
+	err = s.db.Model(&models.StaffComment{}).
+		Joins("JOIN guests ON guests.id = staff_comments.guest_id").
+		Where("guests.property_id = ? AND staff_comments.category = ?", propertyID, "complaint").
+		Where("staff_comments.created_at >= ? AND staff_comments.created_at < ?", clock.StartOf(from), clock.StartOf(to.AddDate(0, 0, 1))).
+		Distinct().Pluck("staff_comments.guest_id", &complainants).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch complaints: %w", err)
+	}
+	metrics.Complainants = len(complainants)
+	if len(complainants) > 0 {
+		var upset int64
+		err := s.db.Model(&models.Guest{}).Where("id IN ?", complainants).Where(&models.Guest{IsVIP: true}).Count(&upset).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to count upset VIPs: %w", err)
+		}
+		metrics.UpsetVIPs = int(upset)
+	}
+	if guestsSeen := max(metrics.GuestsServed, metrics.Complainants); guestsSeen > 0 {
+		metrics.SatisfactionRate = roundTo(float64(guestsSeen-metrics.Complainants)/float64(guestsSeen)*100, 1)
+	}
+
+	return metrics, nil
+}
+
+// executiveBuckets splits a period ending today into labelled ranges of business dates
+func executiveBuckets(today time.Time, period string) (string, []executiveBucket) {
+	var buckets []executiveBucket
+	switch period {
+	case "month":
+		monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		for week := 0; week < 4; week++ {
+			from := monday.AddDate(0, 0, 7*(week-3))
+			buckets = append(buckets, executiveBucket{label: fmt.Sprintf("Week %d", week+1), from: from, to: from.AddDate(0, 0, 6)})
+		}
+	case "quarter":
+		first := time.Date(today.Year(), today.Month()-(today.Month()-1)%3, 1, 0, 0, 0, 0, time.UTC)
+		for month := 0; month < 3; month++ {
+			from := first.AddDate(0, month, 0)
+			buckets = append(buckets, executiveBucket{label: from.Format("Jan"), from: from, to: from.AddDate(0, 1, -1)})
+		}
+	case "year":
+		first := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+		for month := -11; month <= 0; month++ {
+			from := first.AddDate(0, month, 0)
+			buckets = append(buckets, executiveBucket{label: from.Format("Jan"), from: from, to: from.AddDate(0, 1, -1)})
+		}
+	default:
+		period = "week"
+		monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		for day := 0; day < 7; day++ {
+			date := monday.AddDate(0, 0, day)
+			buckets = append(buckets, executiveBucket{label: date.Format("Mon"), from: date, to: date})
+		}
+	}
+	return period, buckets
+}
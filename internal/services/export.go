@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"hudini-breakfast-module/internal/audit"
+	"hudini-breakfast-module/internal/export"
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize is how many records an export loads at a time
+const exportBatchSize = 500
+
+// ExportService brands report exports and records them in the audit log
+type ExportService struct {
+	db           *gorm.DB
+	auditService *AuditService
+}
+
+// ExportRecord is what the audit log keeps about an export
+type ExportRecord struct {
+	Report     string        `json:"report"` // daily_report, consumption_history, audit_logs
+	Format     export.Format `json:"format"`
+	PropertyID string        `json:"property_id,omitempty"`
+	From       string        `json:"from,omitempty"`
+	To         string        `json:"to,omitempty"`
+	Rows       int           `json:"rows"`
+}
+
+// NewExportService creates a new export service
+func NewExportService(db *gorm.DB, auditService *AuditService) *ExportService {
+	return &ExportService{
+		db:           db,
+		auditService: auditService,
+	}
+}
+
+// Branding returns the PDF page header for a property's report
+func (s *ExportService) Branding(propertyID, title, subtitle string) export.Branding {
+	branding := export.Branding{PropertyName: propertyID, Title: title, Subtitle: subtitle}
+
+	var properties []models.Property
+	if err := s.db.Where("property_id = ?", propertyID).Limit(1).Find(&properties).Error; err != nil {
+		logging.WithError(err).Warn("Failed to load property for export branding")
+		return branding
+	}
+	if len(properties) > 0 {
+		branding.PropertyName = properties[0].Name
+		branding.Address = properties[0].Address
+	}
+	return branding
+}
+
+// Record writes an export to the audit log, as failed when err is set
+func (s *ExportService) Record(ctx context.Context, staffID uint, resource audit.AuditResource, record ExportRecord, ipAddress, userAgent string, err error) {
+	fields := logrus.Fields{
+		"service":     "ExportService",
+		"report":      record.Report,
+		"format":      record.Format,
+		"property_id": record.PropertyID,
+		"staff_id":    staffID,
+		"rows":        record.Rows,
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		logging.WithFields(fields).Error("Report export failed")
+	} else {
+		logging.WithFields(fields).Info("Report exported")
+	}
+
+	if s.auditService == nil {
+		return
+	}
+	userID := &staffID
+	if staffID == 0 {
+		userID = nil
+	}
+	if err != nil {
+		err = s.auditService.LogFailure(ctx, userID, audit.ActionExport, resource, record.PropertyID, ipAddress, userAgent, err)
+	} else {
+		err = s.auditService.LogSuccess(ctx, userID, audit.ActionExport, resource, record.PropertyID, ipAddress, userAgent, nil, record)
+	}
+	if err != nil {
+		logging.WithError(err).Warn("Failed to write export audit entry")
+	}
+}
+
+// WriteDailyReport writes a daily report's summary and outlet breakdown
+func WriteDailyReport(w export.Writer, report *DailyBreakfastReport) (int, error) {
+	if err := w.Table("Summary", []string{"Metric", "Value"}); err != nil {
+		return 0, err
+	}
+	summary := []struct {
+		metric string
+		value  interface{}
+	}{
+		{"Business date", report.Date.Format("2006-01-02")},
+		{"Rooms with breakfast", report.TotalRoomsWithBreakfast},
+		{"Rooms served", report.TotalConsumed},
+		{"Rooms not served", report.TotalNotConsumed},
+		{"No-shows", report.NoShows},
+		{"Closed out", report.ClosedOut},
+		{"Take-up rate (%)", roundTo(report.ConsumptionRate, 1)},
+		{"Covers served", report.TotalCoversServed},
+		{"Upsell covers", report.UpsellCovers},
+		{"OHIP covered visits", report.OHIPCoveredCount},
+		{"PMS charges posted", report.PMSChargesPosted},
+	}
+	rows := 0
+	for _, line := range summary {
+		if err := w.Row(line.metric, line.value); err != nil {
+			return rows, err
+		}
+		rows++
+	}
+
+	if err := w.Table("Outlets", []string{"Outlet", "Visits", "Covers served", "Upsell covers", "Revenue"}); err != nil {
+		return rows, err
+	}
+	for _, outlet := range report.Outlets {
+		if err := w.Row(outlet.OutletName, outlet.Visits, outlet.CoversServed, outlet.UpsellCovers, roundTo(outlet.Amount, 2)); err != nil {
+			return rows, err
+		}
+		rows++
+	}
+	return rows, nil
+}
+
+// consumptionColumns are the columns of a consumption history export
+var consumptionColumns = []string{"Date", "Room", "Guest", "Outlet", "Status", "Adults", "Children", "Upsell",
+	"Payment", "Amount", "Consumed at", "Staff", "OHIP", "PMS posted"}
+
+// writeConsumption writes one visit of a consumption history export
+func writeConsumption(w export.Writer, visit *models.DailyBreakfastConsumption) error {
+	outlet := ""
+	if visit.Outlet != nil {
+		outlet = visit.Outlet.Name
+	}
+	staff := ""
+	if visit.Staff != nil {
+		staff = visit.Staff.FirstName + " " + visit.Staff.LastName
+	}
+	return w.Row(visit.ConsumptionDate.Format("2006-01-02"), visit.RoomNumber,
+		visit.Guest.FirstName+" "+visit.Guest.LastName, outlet, visit.Status,
+		visit.AdultCovers, visit.ChildCovers, visit.UpsellCovers, visit.PaymentMethod, visit.Amount,
+		visit.ConsumedAt, staff, visit.OHIPCovered, visit.PMSPosted)
+}
+
+// auditLogColumns are the columns of an audit log export
+var auditLogColumns = []string{"Time", "User", "Action", "Resource", "Resource ID", "Status", "IP address", "Error"}
+
+// WriteAuditLogs writes audit log entries that are already loaded
+func WriteAuditLogs(w export.Writer, title string, logs []models.AuditLog) (int, error) {
+	if err := w.Table(title, auditLogColumns); err != nil {
+		return 0, err
+	}
+	for i := range logs {
+		if err := writeAuditLog(w, &logs[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(logs), nil
+}
+
+// writeAuditLog writes one entry of an audit log export
+func writeAuditLog(w export.Writer, entry *models.AuditLog) error {
+	user := ""
+	switch {
+	case entry.User != nil:
+		user = entry.User.Email
+	case entry.UserID != nil:
+		user = fmt.Sprintf("#%d", *entry.UserID)
+	}
+	return w.Row(entry.CreatedAt, user, entry.Action, entry.Resource, entry.ResourceID, entry.Status, entry.IPAddress, entry.Error)
+}
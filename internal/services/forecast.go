@@ -0,0 +1,545 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/middleware"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	forecastLookbackDays = 28    // History used to learn take-up rates, outlet shares and arrival curves
+	forecastBacktestDays = 14    // Past days replayed to measure accuracy
+	forecastSlotMinutes  = 15    // Width of the intra-day slots
+	forecastConfidence   = 0.9   // Coverage of the prediction intervals
+	forecastZ            = 1.645 // Two-sided z-score for forecastConfidence
+	defaultTakeUpRate    = 0.7   // Used until the property has history
+	maxForecastHorizon   = 90
+)
+
+// ReservationSource lists PMS reservations for a date
+type ReservationSource interface {
+	GetReservationsByDate(ctx context.Context, date time.Time) ([]middleware.Reservation, error)
+}
+
+// ForecastService predicts breakfast covers from upcoming occupancy and past take-up
+type ForecastService struct {
+	db           *gorm.DB
+	reservations ReservationSource
+}
+
+// DemandForecast is a property's predicted breakfast covers for the coming days
+type DemandForecast struct {
+	PropertyID      string           `json:"property_id"`
+	GeneratedAt     time.Time        `json:"generated_at"`
+	HorizonDays     int              `json:"horizon_days"`
+	ConfidenceLevel float64          `json:"confidence_level"` // Coverage of the lower and upper bounds
+	TakeUpRate      float64          `json:"take_up_rate"`     // Share of entitled covers served over the lookback window
+	HistoryDays     int              `json:"history_days"`     // Days of history behind the rates
+	Days            []ForecastDay    `json:"days"`
+	Backtest        ForecastBacktest `json:"backtest"`
+}
+
+// ForecastDay is the predicted demand for one business date
+type ForecastDay struct {
+	Date           string           `json:"date"`
+	Weekday        string           `json:"weekday"`
+	InHouseCovers  int              `json:"in_house_covers"` // Entitled covers from guests on file
+	ArrivingCovers int              `json:"arriving_covers"` // Package covers from PMS reservations not yet on file
+	EntitledCovers int              `json:"entitled_covers"`
+	TakeUpRate     float64          `json:"take_up_rate"`
+	ExtraCovers    float64          `json:"extra_covers"` // Expected upsell and paying covers
+	Predicted      float64          `json:"predicted"`
+	Lower          float64          `json:"lower"`
+	Upper          float64          `json:"upper"`
+	Outlets        []OutletForecast `json:"outlets"`
+}
+
+// OutletForecast is one outlet's share of a day's predicted covers
+type OutletForecast struct {
+	OutletID   uint           `json:"outlet_id"`
+	OutletName string         `json:"outlet_name"`
+	Share      float64        `json:"share"`
+	Predicted  float64        `json:"predicted"`
+	Lower      float64        `json:"lower"`
+	Upper      float64        `json:"upper"`
+	Slots      []ForecastSlot `json:"slots"`
+}
+
+// ForecastSlot is the covers expected to arrive in a 15-minute slot
+type ForecastSlot struct {
+	Start     string  `json:"start"` // Local time, e.g. "07:15"
+	Predicted float64 `json:"predicted"`
+}
+
+// ForecastBacktest measures how the model would have done over recent days
+type ForecastBacktest struct {
+	Days              int     `json:"days"`
+	MeanAbsoluteError float64 `json:"mean_absolute_error"` // In covers
+	MAPE              float64 `json:"mape"`                // Mean absolute percentage error, 0-1
+	Accuracy          float64 `json:"accuracy"`            // 1 - MAPE, floored at 0
+	IntervalCoverage  float64 `json:"interval_coverage"`   // Share of actuals inside the predicted interval
+}
+
+// forecastHistory is what happened on one past business date
+type forecastHistory struct {
+	date     time.Time
+	entitled int
+	served   int // Entitled covers served
+	extra    int // Upsell covers served
+}
+
+// takeUpModel holds the rates learned from history, by weekday
+type takeUpModel struct {
+	rate, rateSD   [7]float64
+	extra, extraSD [7]float64
+	overall        float64
+	days           int
+}
+
+// NewForecastService creates a new forecast service
+func NewForecastService(db *gorm.DB) *ForecastService {
+	return &ForecastService{
+		db: db,
+	}
+}
+
+// SetReservationSource sets the PMS consulted for arrivals not yet on file
+func (s *ForecastService) SetReservationSource(source ReservationSource) {
+	s.reservations = source
+}
+
+// Forecast predicts covers per outlet per day, and per 15-minute slot, for the
+// next horizonDays business dates starting today
+func (s *ForecastService) Forecast(ctx context.Context, propertyID string, horizonDays int) (*DemandForecast, error) {
+	if horizonDays <= 0 {
+		horizonDays = 7
+	}
+	if horizonDays > maxForecastHorizon {
+		horizonDays = maxForecastHorizon
+	}
+
+	engine, err := LoadEligibilityEngine(s.db, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	clock := engine.clock
+	today := clock.Today()
+
+	var outlets []models.Outlet
+	err = s.db.Where("property_id = ? AND is_active = ? AND accepts_package = ?", propertyID, true, true).
+		Order("id").Find(&outlets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch outlets: %w", err)
+	}
+
+	from := today.AddDate(0, 0, -(forecastLookbackDays + forecastBacktestDays))
+	history, visits, err := s.history(engine, propertyID, from, today)
+	if err != nil {
+		return nil, err
+	}
+	recent := history
+	if len(recent) > forecastLookbackDays {
+		recent = recent[len(recent)-forecastLookbackDays:]
+	}
+	model := fitTakeUp(recent)
+	shares, curves := outletProfiles(outlets, visits, clock, today.AddDate(0, 0, -forecastLookbackDays))
+
+	arriving, err := s.arrivingCovers(ctx, propertyID, today, horizonDays)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast := &DemandForecast{
+		PropertyID:      propertyID,
+		GeneratedAt:     time.Now(),
+		HorizonDays:     horizonDays,
+		ConfidenceLevel: forecastConfidence,
+		TakeUpRate:      roundTo(model.overall, 3),
+		HistoryDays:     model.days,
+		Backtest:        backtestTakeUp(history, forecastLookbackDays, forecastBacktestDays),
+	}
+
+	for i := 0; i < horizonDays; i++ {
+		date := today.AddDate(0, 0, i)
+		eligible, err := eligibleGuests(s.db, engine, propertyID, date)
+		if err != nil {
+			return nil, err
+		}
+		inHouse := 0
+		for _, guest := range eligible {
+			inHouse += guest.Decision.Covers
+		}
+
+		day := ForecastDay{
+			Date:           date.Format("2006-01-02"),
+			Weekday:        date.Weekday().String(),
+			InHouseCovers:  inHouse,
+			ArrivingCovers: arriving[date.Format("2006-01-02")],
+		}
+		day.EntitledCovers = day.InHouseCovers + day.ArrivingCovers
+
+		predicted, lower, upper := model.predict(date.Weekday(), day.EntitledCovers)
+		weekday := int(date.Weekday())
+		day.TakeUpRate = roundTo(model.rate[weekday], 3)
+		day.ExtraCovers = roundTo(model.extra[weekday], 1)
+		day.Predicted = roundTo(predicted, 1)
+		day.Lower = roundTo(lower, 1)
+		day.Upper = roundTo(upper, 1)
+
+		for _, outlet := range outlets {
+			share := shares[outlet.ID]
+			outletForecast := OutletForecast{
+				OutletID:   outlet.ID,
+				OutletName: outlet.Name,
+				Share:      roundTo(share, 3),
+				Predicted:  roundTo(predicted*share, 1),
+				Lower:      roundTo(lower*share, 1),
+				Upper:      roundTo(upper*share, 1),
+				Slots:      []ForecastSlot{},
+			}
+			for _, slot := range curves[outlet.ID] {
+				outletForecast.Slots = append(outletForecast.Slots, ForecastSlot{
+					Start:     slot.Start,
+					Predicted: roundTo(predicted*share*slot.Predicted, 1),
+				})
+			}
+			day.Outlets = append(day.Outlets, outletForecast)
+		}
+		forecast.Days = append(forecast.Days, day)
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":      "ForecastService",
+		"method":       "Forecast",
+		"property_id":  propertyID,
+		"horizon_days": horizonDays,
+		"history_days": model.days,
+		"accuracy":     forecast.Backtest.Accuracy,
+	}).Debug("Demand forecast generated")
+
+	return forecast, nil
+}
+
+// history returns what was entitled and served on each business date in
+// [from, to), oldest first, together with the visits served in that range.
+// Entitlement comes from the day's close-out when there is one, otherwise it
+// is recomputed from the guests on file.
+func (s *ForecastService) history(engine *EligibilityEngine, propertyID string, from, to time.Time) ([]forecastHistory, []models.DailyBreakfastConsumption, error) {
+	var visits []models.DailyBreakfastConsumption
+	err := s.db.Select("consumption_date", "consumed_at", "outlet_id", "adult_covers", "child_covers", "upsell_covers").
+		Where("property_id = ? AND status = ? AND DATE(consumption_date) >= ? AND DATE(consumption_date) < ?",
+			propertyID, "consumed", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Find(&visits).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch visit history: %w", err)
+	}
+
+	var closeOuts []models.ServiceCloseOut
+	err = s.db.Select("business_date", "covers_entitled").
+		Where("property_id = ? AND DATE(business_date) >= ? AND DATE(business_date) < ?",
+			propertyID, from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Find(&closeOuts).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch close-outs: %w", err)
+	}
+	entitled := make(map[string]int)
+	for _, closeOut := range closeOuts {
+		key := calendarDate(closeOut.BusinessDate).Format("2006-01-02")
+		if closeOut.CoversEntitled > entitled[key] {
+			entitled[key] = closeOut.CoversEntitled
+		}
+	}
+
+	days := make(map[string]*forecastHistory)
+	for _, visit := range visits {
+		key := calendarDate(visit.ConsumptionDate).Format("2006-01-02")
+		day, ok := days[key]
+		if !ok {
+			day = &forecastHistory{date: calendarDate(visit.ConsumptionDate)}
+			days[key] = day
+		}
+		day.served += visit.AdultCovers + visit.ChildCovers - visit.UpsellCovers
+		day.extra += visit.UpsellCovers
+	}
+
+	var history []forecastHistory
+	for date := from; date.Before(to); date = date.AddDate(0, 0, 1) {
+		key := date.Format("2006-01-02")
+		day := forecastHistory{date: date}
+		if recorded, ok := days[key]; ok {
+			day = *recorded
+		}
+
+		if covers, ok := entitled[key]; ok {
+			day.entitled = covers
+		} else {
+			eligible, err := eligibleGuests(s.db, engine, propertyID, date)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, guest := range eligible {
+				day.entitled += guest.Decision.Covers
+			}
+		}
+
+		if day.entitled > 0 || day.served > 0 || day.extra > 0 {
+			history = append(history, day)
+		}
+	}
+	return history, visits, nil
+}
+
+// arrivingCovers returns, per business date, the package covers of PMS
+// reservations whose guests are not yet on file
+func (s *ForecastService) arrivingCovers(ctx context.Context, propertyID string, today time.Time, horizonDays int) (map[string]int, error) {
+	covers := make(map[string]int)
+	if s.reservations == nil {
+		return covers, nil
+	}
+
+	var known []string
+	if err := s.db.Model(&models.Guest{}).Where("property_id = ?", propertyID).Pluck("reservation_id", &known).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch reservations on file: %w", err)
+	}
+	onFile := make(map[string]bool, len(known))
+	for _, id := range known {
+		onFile[id] = true
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < horizonDays; i++ {
+		reservations, err := s.reservations.GetReservationsByDate(ctx, today.AddDate(0, 0, i))
+		if err != nil {
+			// Forecast from the guests on file rather than fail outright
+			logging.WithError(err).WithField("property_id", propertyID).Warn("Failed to fetch PMS reservations for forecast")
+			return covers, nil
+		}
+
+		for _, reservation := range reservations {
+			if seen[reservation.ReservationID] || onFile[reservation.ReservationID] {
+				continue
+			}
+			seen[reservation.ReservationID] = true
+			if !reservation.BreakfastPackage || (reservation.PropertyID != "" && reservation.PropertyID != propertyID) {
+				continue
+			}
+			if reservation.Status == "cancelled" || reservation.Status == "no_show" || reservation.Status == "checked_out" {
+				continue
+			}
+
+			checkIn := calendarDate(reservation.CheckInDate)
+			checkOut := calendarDate(reservation.CheckOutDate)
+			for j := 0; j < horizonDays; j++ {
+				date := today.AddDate(0, 0, j)
+				if !date.Before(checkIn) && !date.After(checkOut) {
+					covers[date.Format("2006-01-02")] += reservation.Adults + reservation.Children
+				}
+			}
+		}
+	}
+	return covers, nil
+}
+
+// fitTakeUp learns take-up rates and extra covers by weekday, falling back to
+// the overall figures for weekdays with fewer than two days of history
+func fitTakeUp(history []forecastHistory) takeUpModel {
+	var model takeUpModel
+	var rates, extras [7][]float64
+	var allRates, allExtras []float64
+	for _, day := range history {
+		weekday := int(day.date.Weekday())
+		if day.entitled > 0 {
+			rate := math.Min(float64(day.served)/float64(day.entitled), 1)
+			rates[weekday] = append(rates[weekday], rate)
+			allRates = append(allRates, rate)
+		}
+		extras[weekday] = append(extras[weekday], float64(day.extra))
+		allExtras = append(allExtras, float64(day.extra))
+	}
+	model.days = len(allRates)
+
+	overallRate, overallRateSD := meanStdDev(allRates)
+	if len(allRates) == 0 {
+		overallRate, overallRateSD = defaultTakeUpRate, 0.15
+	}
+	overallExtra, overallExtraSD := meanStdDev(allExtras)
+	model.overall = overallRate
+
+	for weekday := 0; weekday < 7; weekday++ {
+		model.rate[weekday], model.rateSD[weekday] = overallRate, overallRateSD
+		if len(rates[weekday]) >= 2 {
+			model.rate[weekday], model.rateSD[weekday] = meanStdDev(rates[weekday])
+		}
+		model.extra[weekday], model.extraSD[weekday] = overallExtra, overallExtraSD
+		if len(extras[weekday]) >= 2 {
+			model.extra[weekday], model.extraSD[weekday] = meanStdDev(extras[weekday])
+		}
+	}
+	return model
+}
+
+// predict returns the expected covers for a day with the given entitlement and its interval
+func (m *takeUpModel) predict(weekday time.Weekday, entitled int) (predicted, lower, upper float64) {
+	day := int(weekday)
+	predicted = float64(entitled)*m.rate[day] + m.extra[day]
+	spread := forecastZ * math.Sqrt(math.Pow(float64(entitled)*m.rateSD[day], 2)+math.Pow(m.extraSD[day], 2))
+	return predicted, math.Max(predicted-spread, 0), predicted + spread
+}
+
+// backtestTakeUp replays the last days of history, predicting each from the
+// lookback window before it and its actual entitlement
+func backtestTakeUp(history []forecastHistory, lookback, days int) ForecastBacktest {
+	var backtest ForecastBacktest
+	var absErrors, pctErrors []float64
+	inside := 0
+
+	for i := len(history) - days; i < len(history); i++ {
+		if i <= 0 {
+			continue
+		}
+		day := history[i]
+		actual := float64(day.served + day.extra)
+		if day.entitled == 0 || actual == 0 {
+			continue
+		}
+
+		var window []forecastHistory
+		for _, past := range history[:i] {
+			if past.date.After(day.date.AddDate(0, 0, -lookback-1)) {
+				window = append(window, past)
+			}
+		}
+		if len(window) == 0 {
+			continue
+		}
+
+		model := fitTakeUp(window)
+		predicted, lower, upper := model.predict(day.date.Weekday(), day.entitled)
+		absErrors = append(absErrors, math.Abs(predicted-actual))
+		pctErrors = append(pctErrors, math.Abs(predicted-actual)/actual)
+		if actual >= lower && actual <= upper {
+			inside++
+		}
+	}
+
+	backtest.Days = len(absErrors)
+	if backtest.Days == 0 {
+		return backtest
+	}
+	mae, _ := meanStdDev(absErrors)
+	mape, _ := meanStdDev(pctErrors)
+	backtest.MeanAbsoluteError = roundTo(mae, 2)
+	backtest.MAPE = roundTo(mape, 3)
+	backtest.Accuracy = roundTo(math.Max(1-mape, 0), 3)
+	backtest.IntervalCoverage = roundTo(float64(inside)/float64(backtest.Days), 3)
+	return backtest
+}
+
+// outletProfiles returns each outlet's share of covers and its arrival curve
+// over 15-minute slots, learned from visits since the given date. Outlets
+// without history split evenly and arrive evenly across their opening hours.
+func outletProfiles(outlets []models.Outlet, visits []models.DailyBreakfastConsumption, clock *PropertyClock, since time.Time) (map[uint]float64, map[uint][]ForecastSlot) {
+	shares := make(map[uint]float64, len(outlets))
+	curves := make(map[uint][]ForecastSlot, len(outlets))
+	if len(outlets) == 0 {
+		return shares, curves
+	}
+
+	covers := make(map[uint]float64)
+	slotCovers := make(map[uint]map[string]float64)
+	total := 0.0
+	for _, visit := range visits {
+		if visit.OutletID == nil || calendarDate(visit.ConsumptionDate).Before(since) {
+			continue
+		}
+		count := float64(visit.AdultCovers + visit.ChildCovers)
+		covers[*visit.OutletID] += count
+		total += count
+		if visit.ConsumedAt != nil {
+			local := visit.ConsumedAt.In(clock.Location)
+			slot := fmt.Sprintf("%02d:%02d", local.Hour(), local.Minute()/forecastSlotMinutes*forecastSlotMinutes)
+			if slotCovers[*visit.OutletID] == nil {
+				slotCovers[*visit.OutletID] = make(map[string]float64)
+			}
+			slotCovers[*visit.OutletID][slot] += count
+		}
+	}
+
+	for _, outlet := range outlets {
+		if total > 0 {
+			shares[outlet.ID] = covers[outlet.ID] / total
+		} else {
+			shares[outlet.ID] = 1 / float64(len(outlets))
+		}
+
+		counts := slotCovers[outlet.ID]
+		if len(counts) == 0 {
+			counts = evenSlots(outlet.OpenTime, outlet.CloseTime)
+		}
+		sum := 0.0
+		starts := make([]string, 0, len(counts))
+		for start, count := range counts {
+			sum += count
+			starts = append(starts, start)
+		}
+		sort.Strings(starts)
+
+		curve := make([]ForecastSlot, 0, len(starts))
+		for _, start := range starts {
+			curve = append(curve, ForecastSlot{Start: start, Predicted: counts[start] / sum})
+		}
+		curves[outlet.ID] = curve
+	}
+	return shares, curves
+}
+
+// evenSlots spreads arrivals evenly over an outlet's opening hours
+func evenSlots(openTime, closeTime string) map[string]float64 {
+	open, err := time.Parse("15:04", openTime)
+	if err != nil {
+		open, _ = time.Parse("15:04", "07:00")
+	}
+	closing, err := time.Parse("15:04", closeTime)
+	if err != nil || !closing.After(open) {
+		closing = open.Add(3 * time.Hour)
+	}
+
+	slots := make(map[string]float64)
+	for t := open; t.Before(closing); t = t.Add(forecastSlotMinutes * time.Minute) {
+		slots[t.Format("15:04")] = 1
+	}
+	return slots
+}
+
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)-1))
+}
+
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,560 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hudini-breakfast-module/internal/audit"
+	"hudini-breakfast-module/internal/config"
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Errors returned by inventory operations
+var (
+	ErrInventoryItemNotFound  = errors.New("inventory item not found")
+	ErrInventoryUsageNotFound = errors.New("inventory usage not found")
+	ErrInvalidStockQuantity   = errors.New("stock quantity is invalid")
+	ErrStockReasonRequired    = errors.New("a reason is required to adjust stock")
+	ErrDuplicateInventoryCode = errors.New("outlet already stocks an item with this code")
+)
+
+// Inventory movement types
+const (
+	MovementCount      = "count"
+	MovementReceipt    = "receipt"
+	MovementAdjustment = "adjustment"
+	MovementDepletion  = "depletion"
+	MovementReversal   = "reversal"
+)
+
+// InventoryService tracks outlet supplies, depletes them as covers are served
+// and raises low_supplies alerts when stock drops below par
+type InventoryService struct {
+	db            *gorm.DB
+	auditService  *AuditService
+	notifications *NotificationService
+	config        config.InventoryConfig
+}
+
+// StockRequest records a count, receipt or adjustment against an item
+type StockRequest struct {
+	ItemID    uint
+	Quantity  float64 // On hand for counts; the change for receipts and adjustments
+	Reason    string
+	StaffID   uint
+	IPAddress string
+	UserAgent string
+}
+
+// NewInventoryService creates a new inventory service
+func NewInventoryService(db *gorm.DB, auditService *AuditService, notifications *NotificationService, cfg config.InventoryConfig) *InventoryService {
+	return &InventoryService{
+		db:            db,
+		auditService:  auditService,
+		notifications: notifications,
+		config:        cfg,
+	}
+}
+
+// GetItems returns an outlet's inventory, optionally only the items below par
+func (s *InventoryService) GetItems(outletID uint, belowParOnly bool) ([]models.InventoryItem, error) {
+	query := s.db.Where("outlet_id = ?", outletID)
+	if belowParOnly {
+		query = query.Where("is_active = ? AND on_hand < par_level", true)
+	}
+
+	var items []models.InventoryItem
+	if err := query.Order("category, name").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch inventory: %w", err)
+	}
+	return items, nil
+}
+
+// CreateItem adds a supply to an outlet's inventory
+func (s *InventoryService) CreateItem(item *models.InventoryItem) error {
+	var outlet models.Outlet
+	if err := s.db.First(&outlet, item.OutletID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOutletNotFound
+		}
+		return fmt.Errorf("failed to fetch outlet: %w", err)
+	}
+	item.PropertyID = outlet.PropertyID
+	item.OnHand = 0 // Stock is set by an audited count
+
+	if err := normalizeInventoryItem(item); err != nil {
+		return err
+	}
+	if err := s.ensureUniqueCode(item.OutletID, item.Code, 0); err != nil {
+		return err
+	}
+	if err := s.db.Create(item).Error; err != nil {
+		return fmt.Errorf("failed to create inventory item: %w", err)
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":           "InventoryService",
+		"method":            "CreateItem",
+		"outlet_id":         item.OutletID,
+		"inventory_item_id": item.ID,
+		"code":              item.Code,
+	}).Info("Inventory item created")
+
+	return nil
+}
+
+// UpdateItem applies partial updates to an item's details. Stock levels only
+// change through counts, receipts and adjustments so that they are audited.
+func (s *InventoryService) UpdateItem(itemID uint, updates map[string]interface{}) (*models.InventoryItem, error) {
+	item, err := s.getItem(s.db, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the item as it will be after the update
+	updated := *item
+	if value, ok := updates["code"].(string); ok {
+		updated.Code = value
+	}
+	if value, ok := updates["name"].(string); ok {
+		updated.Name = value
+	}
+	if value, ok := updates["unit"].(string); ok {
+		updated.Unit = value
+	}
+	if value, ok := updates["par_level"].(float64); ok {
+		updated.ParLevel = value
+	}
+	if value, ok := updates["unit_cost"].(float64); ok {
+		updated.UnitCost = value
+	}
+	if err := normalizeInventoryItem(&updated); err != nil {
+		return nil, err
+	}
+	if _, ok := updates["code"]; ok {
+		if err := s.ensureUniqueCode(item.OutletID, updated.Code, item.ID); err != nil {
+			return nil, err
+		}
+		updates["code"] = updated.Code
+	}
+
+	if err := s.db.Model(item).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update inventory item: %w", err)
+	}
+	return s.getItem(s.db, itemID)
+}
+
+// DeleteItem removes an item from an outlet's inventory; its movements are kept
+func (s *InventoryService) DeleteItem(itemID uint) error {
+	result := s.db.Delete(&models.InventoryItem{}, itemID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete inventory item: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInventoryItemNotFound
+	}
+	return nil
+}
+
+// GetMovements returns an item's most recent stock movements
+func (s *InventoryService) GetMovements(itemID uint, limit int) ([]models.InventoryMovement, error) {
+	if _, err := s.getItem(s.db.Unscoped(), itemID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var movements []models.InventoryMovement
+	err := s.db.Where("inventory_item_id = ?", itemID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&movements).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stock movements: %w", err)
+	}
+	return movements, nil
+}
+
+// GetUsage returns a property's per-cover usage ratios
+func (s *InventoryService) GetUsage(propertyID string) ([]models.InventoryUsage, error) {
+	var usage []models.InventoryUsage
+	if err := s.db.Where("property_id = ?", propertyID).Order("menu_type, code").Find(&usage).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch inventory usage: %w", err)
+	}
+	return usage, nil
+}
+
+// SetUsage creates or replaces how much of an item one cover uses for a menu type
+func (s *InventoryService) SetUsage(usage *models.InventoryUsage) error {
+	usage.MenuType = strings.ToLower(strings.TrimSpace(usage.MenuType))
+	usage.Code = inventoryCode(usage.Code)
+	if usage.PropertyID == "" || usage.MenuType == "" || usage.Code == "" {
+		return fmt.Errorf("property_id, menu_type and code are required")
+	}
+	if usage.PerCover <= 0 {
+		return fmt.Errorf("per_cover must be greater than zero")
+	}
+
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "property_id"}, {Name: "menu_type"}, {Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"per_cover", "updated_at"}),
+	}).Create(usage).Error
+	if err != nil {
+		return fmt.Errorf("failed to save inventory usage: %w", err)
+	}
+
+	var saved models.InventoryUsage
+	if err := s.db.Where("property_id = ? AND menu_type = ? AND code = ?", usage.PropertyID, usage.MenuType, usage.Code).First(&saved).Error; err != nil {
+		return fmt.Errorf("failed to fetch inventory usage: %w", err)
+	}
+	*usage = saved
+	return nil
+}
+
+// DeleteUsage removes a usage ratio
+func (s *InventoryService) DeleteUsage(usageID uint) error {
+	result := s.db.Delete(&models.InventoryUsage{}, usageID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete inventory usage: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInventoryUsageNotFound
+	}
+	return nil
+}
+
+// RecordCount sets an item's stock to a physical count
+func (s *InventoryService) RecordCount(ctx context.Context, req StockRequest) (*models.InventoryItem, error) {
+	if req.Quantity < 0 {
+		return nil, ErrInvalidStockQuantity
+	}
+	return s.moveStock(ctx, req, MovementCount, audit.ActionCount)
+}
+
+// ReceiveStock adds a delivery to an item's stock
+func (s *InventoryService) ReceiveStock(ctx context.Context, req StockRequest) (*models.InventoryItem, error) {
+	if req.Quantity <= 0 {
+		return nil, ErrInvalidStockQuantity
+	}
+	return s.moveStock(ctx, req, MovementReceipt, audit.ActionAdjust)
+}
+
+// AdjustStock corrects an item's stock, e.g. for waste or breakage
+func (s *InventoryService) AdjustStock(ctx context.Context, req StockRequest) (*models.InventoryItem, error) {
+	if req.Quantity == 0 {
+		return nil, ErrInvalidStockQuantity
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, ErrStockReasonRequired
+	}
+	return s.moveStock(ctx, req, MovementAdjustment, audit.ActionAdjust)
+}
+
+// CheckLowStock raises a low_supplies alert for each item that has dropped
+// below par since it was last alerted, and re-arms alerts for items that are
+// back at par. An empty property ID checks every property.
+func (s *InventoryService) CheckLowStock(ctx context.Context, propertyID string) (int, error) {
+	scope := s.db.WithContext(ctx).Model(&models.InventoryItem{})
+	if propertyID != "" {
+		scope = scope.Where("property_id = ?", propertyID)
+	}
+
+	err := scope.Session(&gorm.Session{}).
+		Where("low_stock_alerted_at IS NOT NULL AND on_hand >= par_level").
+		Update("low_stock_alerted_at", nil).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to re-arm stock alerts: %w", err)
+	}
+
+	var items []models.InventoryItem
+	err = scope.Session(&gorm.Session{}).
+		Where("is_active = ? AND par_level > 0 AND on_hand < par_level AND low_stock_alerted_at IS NULL", true).
+		Find(&items).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch low stock: %w", err)
+	}
+
+	alerted := 0
+	for i := range items {
+		item := &items[i]
+
+		var outlet models.Outlet
+		outletName := fmt.Sprintf("outlet %d", item.OutletID)
+		if err := s.db.Unscoped().Select("name").First(&outlet, item.OutletID).Error; err == nil {
+			outletName = outlet.Name
+		}
+
+		if s.notifications != nil {
+			if err := s.notifications.NotifyLowSupplies(ctx, item, outletName); err != nil {
+				logging.WithError(err).WithField("inventory_item_id", item.ID).Warn("Failed to send low supplies alert")
+				continue
+			}
+		}
+		if err := s.db.Model(item).Update("low_stock_alerted_at", time.Now()).Error; err != nil {
+			return alerted, fmt.Errorf("failed to mark stock alert: %w", err)
+		}
+		alerted++
+
+		logging.WithFields(logrus.Fields{
+			"service":           "InventoryService",
+			"method":            "CheckLowStock",
+			"inventory_item_id": item.ID,
+			"outlet_id":         item.OutletID,
+			"on_hand":           item.OnHand,
+			"par_level":         item.ParLevel,
+		}).Info("Low supplies alert raised")
+	}
+	return alerted, nil
+}
+
+// StartAlertScheduler checks stock against par levels until the context is
+// cancelled, picking up depletion from recorded visits
+func (s *InventoryService) StartAlertScheduler(ctx context.Context) {
+	interval := s.config.AlertInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logging.Info("Inventory alert scheduler stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.CheckLowStock(ctx, ""); err != nil {
+				logging.WithError(err).Error("Scheduled low stock check failed")
+			}
+		}
+	}
+}
+
+// moveStock applies a count, receipt or adjustment, audits it and checks the
+// item against its par level
+func (s *InventoryService) moveStock(ctx context.Context, req StockRequest, movementType string, action audit.AuditAction) (*models.InventoryItem, error) {
+	var before, after models.InventoryItem
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		item, err := s.getItem(tx, req.ItemID)
+		if err != nil {
+			return err
+		}
+		before = *item
+
+		change := req.Quantity
+		if movementType == MovementCount {
+			change = req.Quantity - item.OnHand
+		}
+		if item.OnHand+change < 0 {
+			return ErrInvalidStockQuantity
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{"on_hand": gorm.Expr("on_hand + ?", change)}
+		if movementType == MovementCount {
+			updates = map[string]interface{}{"on_hand": req.Quantity, "last_counted_at": now}
+		}
+		if err := tx.Model(item).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update stock: %w", err)
+		}
+		if err := tx.First(&after, item.ID).Error; err != nil {
+			return fmt.Errorf("failed to fetch inventory item: %w", err)
+		}
+
+		staffID := req.StaffID
+		movement := models.InventoryMovement{
+			InventoryItemID: item.ID,
+			OutletID:        item.OutletID,
+			PropertyID:      item.PropertyID,
+			Type:            movementType,
+			Quantity:        change,
+			OnHand:          after.OnHand,
+			Reason:          strings.TrimSpace(req.Reason),
+			StaffID:         &staffID,
+		}
+		if err := tx.Create(&movement).Error; err != nil {
+			return fmt.Errorf("failed to record stock movement: %w", err)
+		}
+		return nil
+	})
+
+	resourceID := strconv.FormatUint(uint64(req.ItemID), 10)
+	if err != nil {
+		if s.auditService != nil {
+			s.auditService.LogFailure(ctx, &req.StaffID, action, audit.ResourceInventory, resourceID, req.IPAddress, req.UserAgent, err)
+		}
+		return nil, err
+	}
+
+	if s.auditService != nil {
+		if err := s.auditService.LogSuccess(ctx, &req.StaffID, action, audit.ResourceInventory, resourceID, req.IPAddress, req.UserAgent, before, after); err != nil {
+			logging.WithError(err).Warn("Failed to write inventory audit entry")
+		}
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":           "InventoryService",
+		"method":            "moveStock",
+		"inventory_item_id": after.ID,
+		"type":              movementType,
+		"on_hand":           after.OnHand,
+		"staff_id":          req.StaffID,
+	}).Info("Stock updated")
+
+	if _, err := s.CheckLowStock(ctx, after.PropertyID); err != nil {
+		logging.WithError(err).Warn("Failed to check stock levels")
+	}
+	return &after, nil
+}
+
+func (s *InventoryService) getItem(tx *gorm.DB, itemID uint) (*models.InventoryItem, error) {
+	var item models.InventoryItem
+	if err := tx.First(&item, itemID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInventoryItemNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch inventory item: %w", err)
+	}
+	return &item, nil
+}
+
+func (s *InventoryService) ensureUniqueCode(outletID uint, code string, exceptID uint) error {
+	var count int64
+	err := s.db.Model(&models.InventoryItem{}).
+		Where("outlet_id = ? AND code = ? AND id <> ?", outletID, code, exceptID).
+		Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("failed to check inventory code: %w", err)
+	}
+	if count > 0 {
+		return ErrDuplicateInventoryCode
+	}
+	return nil
+}
+
+// depleteInventory takes the supplies a recorded visit used from its outlet's
+// stock, using the property's per-cover usage for the outlet's menu type.
+// Visits not tied to an outlet have no stock to deplete.
+func depleteInventory(tx *gorm.DB, consumption *models.DailyBreakfastConsumption) error {
+	if consumption.OutletID == nil {
+		return nil
+	}
+	covers := consumption.AdultCovers + consumption.ChildCovers
+	if covers <= 0 {
+		return nil
+	}
+
+	var outlet models.Outlet
+	if err := tx.Select("id", "menu_type").First(&outlet, *consumption.OutletID).Error; err != nil {
+		return fmt.Errorf("failed to fetch outlet: %w", err)
+	}
+
+	var usage []models.InventoryUsage
+	if err := tx.Where("property_id = ? AND menu_type = ?", consumption.PropertyID, outlet.MenuType).Find(&usage).Error; err != nil {
+		return fmt.Errorf("failed to fetch inventory usage: %w", err)
+	}
+
+	for _, ratio := range usage {
+		var items []models.InventoryItem
+		if err := tx.Where("outlet_id = ? AND code = ? AND is_active = ?", outlet.ID, ratio.Code, true).Limit(1).Find(&items).Error; err != nil {
+			return fmt.Errorf("failed to fetch inventory item: %w", err)
+		}
+		if len(items) == 0 {
+			continue
+		}
+		if err := moveStockTx(tx, &items[0], MovementDepletion, -ratio.PerCover*float64(covers), consumption.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreInventory puts back the supplies a voided visit depleted
+func restoreInventory(tx *gorm.DB, consumptionID uint) error {
+	var movements []models.InventoryMovement
+	if err := tx.Where("consumption_id = ? AND type = ?", consumptionID, MovementDepletion).Find(&movements).Error; err != nil {
+		return fmt.Errorf("failed to fetch stock movements: %w", err)
+	}
+
+	for _, movement := range movements {
+		var item models.InventoryItem
+		if err := tx.Unscoped().First(&item, movement.InventoryItemID).Error; err != nil {
+			return fmt.Errorf("failed to fetch inventory item: %w", err)
+		}
+		if err := moveStockTx(tx, &item, MovementReversal, -movement.Quantity, consumptionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveStockTx changes an item's stock for a visit and records the movement.
+// Stock is not allowed to go below zero; the shortfall shows up at the next count.
+// The row is locked and changed in a single UPDATE so concurrent visits cannot
+// overwrite each other, and the movement records the change actually applied.
+func moveStockTx(tx *gorm.DB, item *models.InventoryItem, movementType string, change float64, consumptionID uint) error {
+	var before, after models.InventoryItem
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "on_hand").First(&before, item.ID).Error; err != nil {
+		return fmt.Errorf("failed to fetch inventory item: %w", err)
+	}
+	update := tx.Unscoped().Model(&models.InventoryItem{}).Where("id = ?", item.ID).
+		Update("on_hand", gorm.Expr("CASE WHEN on_hand + ? < 0 THEN 0 ELSE on_hand + ? END", change, change))
+	if update.Error != nil {
+		return fmt.Errorf("failed to update stock: %w", update.Error)
+	}
+	if err := tx.Unscoped().Select("id", "on_hand").First(&after, item.ID).Error; err != nil {
+		return fmt.Errorf("failed to fetch inventory item: %w", err)
+	}
+	item.OnHand = after.OnHand
+
+	movement := models.InventoryMovement{
+		InventoryItemID: item.ID,
+		OutletID:        item.OutletID,
+		PropertyID:      item.PropertyID,
+		Type:            movementType,
+		Quantity:        after.OnHand - before.OnHand,
+		OnHand:          after.OnHand,
+		ConsumptionID:   &consumptionID,
+	}
+	if err := tx.Create(&movement).Error; err != nil {
+		return fmt.Errorf("failed to record stock movement: %w", err)
+	}
+	return nil
+}
+
+// normalizeInventoryItem validates an item and tidies its code and unit
+func normalizeInventoryItem(item *models.InventoryItem) error {
+	item.Code = inventoryCode(item.Code)
+	if item.Code == "" {
+		item.Code = inventoryCode(item.Name)
+	}
+	if strings.TrimSpace(item.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	item.Unit = strings.ToLower(strings.TrimSpace(item.Unit))
+	if item.Unit == "" {
+		return fmt.Errorf("unit is required")
+	}
+	if item.ParLevel < 0 {
+		return fmt.Errorf("par_level cannot be negative")
+	}
+	if item.UnitCost < 0 {
+		return fmt.Errorf("unit_cost cannot be negative")
+	}
+	return nil
+}
+
+func inventoryCode(value string) string {
+	code := strings.ToLower(strings.TrimSpace(value))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(code)
+}
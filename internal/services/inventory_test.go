@@ -0,0 +1,61 @@
+package services
+
+import (
+	"testing"
+
+	"hudini-breakfast-module/internal/config"
+	"hudini-breakfast-module/internal/models"
+)
+
+func TestInventoryItemUnitCost(t *testing.T) {
+	tests := []struct {
+		name    string
+		created float64
+		updates map[string]interface{}
+		valid   bool
+		cost    float64
+	}{
+		{"no cost", 0, nil, true, 0},
+		{"cost on create", 0.35, nil, true, 0.35},
+		{"negative cost on create", -0.35, nil, false, 0},
+		{"cost changed", 0.35, map[string]interface{}{"unit_cost": 0.4}, true, 0.4},
+		{"cost cleared", 0.35, map[string]interface{}{"unit_cost": 0.0}, true, 0},
+		{"other details changed", 0.35, map[string]interface{}{"name": "Free-range eggs"}, true, 0.35},
+		{"negative cost on update", 0.35, map[string]interface{}{"unit_cost": -1.0}, false, 0.35},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			outlet := models.Outlet{PropertyID: "P1", Name: "Terrace", AcceptsPackage: true, IsActive: true}
+			mustCreate(t, db, &outlet)
+			service := NewInventoryService(db, nil, nil, config.InventoryConfig{})
+
+			item := models.InventoryItem{OutletID: outlet.ID, Name: "Eggs", Unit: "Each", UnitCost: tt.created}
+			err := service.CreateItem(&item)
+			if tt.updates == nil {
+				if tt.valid != (err == nil) {
+					t.Fatalf("CreateItem error = %v, want valid %v", err, tt.valid)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("CreateItem: %v", err)
+				}
+				if _, err := service.UpdateItem(item.ID, tt.updates); tt.valid != (err == nil) {
+					t.Fatalf("UpdateItem error = %v, want valid %v", err, tt.valid)
+				}
+			}
+
+			var stored []models.InventoryItem
+			db.Find(&stored)
+			if !tt.valid && tt.updates == nil {
+				if len(stored) != 0 {
+					t.Errorf("%d items stored, want none", len(stored))
+				}
+				return
+			}
+			if len(stored) != 1 || stored[0].UnitCost != tt.cost {
+				t.Errorf("stored items = %+v, want one costing %v", stored, tt.cost)
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// KitchenRoom returns the websocket room a kitchen display subscribes to. A
+// station's room carries its own tickets; the outlet room, with no station,
+// carries every station's tickets and guest seatings for the expo display.
+func KitchenRoom(outletID uint, station string) string {
+	if station == "" {
+		return fmt.Sprintf("kitchen:%d", outletID)
+	}
+	return fmt.Sprintf("kitchen:%d:%s", outletID, station)
+}
+
+// KitchenDisplayService streams prep tickets and notable seatings to kitchen displays
+type KitchenDisplayService struct {
+	db        *gorm.DB
+	orders    *OrderService
+	publisher BoardPublisher
+}
+
+// KitchenGuest is what the kitchen needs to know about the guest behind a ticket or seating
+type KitchenGuest struct {
+	GuestID              uint           `json:"guest_id"`
+	Name                 string         `json:"name"`
+	RoomNumber           string         `json:"room_number"`
+	IsVIP                bool           `json:"is_vip"`
+	SpecialRequests      string         `json:"pms_special_requests,omitempty"`
+	HandlingInstructions string         `json:"handling_instructions,omitempty"`
+	Allergies            []GuestAllergy `json:"allergies,omitempty"`
+	CriticalAllergies    []string       `json:"critical_allergies,omitempty"`
+	DietaryRestrictions  []string       `json:"dietary_restrictions,omitempty"`
+}
+
+// KitchenDisplayItem is an order line with any clash against the guest's allergies
+type KitchenDisplayItem struct {
+	models.OrderItem
+	AllergyConflicts []AllergenConflict `json:"allergy_conflicts,omitempty"`
+}
+
+// KitchenDisplayTicket is a prep ticket as shown on a kitchen display
+type KitchenDisplayTicket struct {
+	ID              uint                 `json:"id"`
+	OrderID         uint                 `json:"order_id"`
+	OutletID        uint                 `json:"outlet_id"`
+	Station         string               `json:"station"`
+	Status          string               `json:"status"`
+	FiredAt         time.Time            `json:"fired_at"`
+	ReadyAt         *time.Time           `json:"ready_at,omitempty"`
+	OpenSeconds     int64                `json:"open_seconds"` // Since firing; stops when the ticket is bumped
+	TableName       string               `json:"table_name,omitempty"`
+	Guest           *KitchenGuest        `json:"guest,omitempty"`
+	AllergyAlert    bool                 `json:"allergy_alert"`
+	CriticalAllergy bool                 `json:"critical_allergy"` // The guest has a critical allergy, whether or not an item conflicts
+	Items           []KitchenDisplayItem `json:"items"`
+}
+
+// KitchenSeating is a seated party the kitchen should know about: a VIP, or a
+// guest with special requests, handling instructions, allergies or dietary needs
+type KitchenSeating struct {
+	TurnID      uint         `json:"turn_id"`
+	TableID     uint         `json:"table_id"`
+	TableName   string       `json:"table_name"`
+	PartySize   int          `json:"party_size"`
+	SeatedAt    time.Time    `json:"seated_at"`
+	OpenSeconds int64        `json:"open_seconds"`
+	Guest       KitchenGuest `json:"guest"`
+}
+
+// KitchenDisplay is the current state of a kitchen display, for rebuilding it after a reconnect
+type KitchenDisplay struct {
+	OutletID      uint                   `json:"outlet_id"`
+	Station       string                 `json:"station,omitempty"`
+	WebsocketRoom string                 `json:"websocket_room"`
+	Tickets       []KitchenDisplayTicket `json:"tickets"`
+	Seatings      []KitchenSeating       `json:"seatings"`
+	GeneratedAt   time.Time              `json:"generated_at"`
+}
+
+// NewKitchenDisplayService creates a new kitchen display service
+func NewKitchenDisplayService(db *gorm.DB, orders *OrderService) *KitchenDisplayService {
+	return &KitchenDisplayService{
+		db:     db,
+		orders: orders,
+	}
+}
+
+// SetPublisher sets where kitchen display updates are pushed
+func (s *KitchenDisplayService) SetPublisher(publisher BoardPublisher) {
+	s.publisher = publisher
+}
+
+// GetDisplay returns the open tickets for an outlet, or one of its stations,
+// and the notable parties seated there. Tickets still cooking come first,
+// oldest first; bumped tickets follow so they can be recalled.
+func (s *KitchenDisplayService) GetDisplay(outletID uint, station string) (*KitchenDisplay, error) {
+	query := s.db.Preload("Items").
+		Joins("JOIN orders ON orders.id = kitchen_tickets.order_id").
+		Where("kitchen_tickets.outlet_id = ? AND kitchen_tickets.status IN ? AND orders.status <> ?",
+			outletID, []string{TicketStatusFired, TicketStatusReady}, OrderStatusCancelled)
+	if station != "" {
+		query = query.Where("kitchen_tickets.station = ?", station)
+	}
+
+	var tickets []models.KitchenTicket
+	err := query.Order("CASE WHEN kitchen_tickets.status = 'fired' THEN 0 ELSE 1 END, kitchen_tickets.fired_at, kitchen_tickets.id").
+		Find(&tickets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch kitchen tickets: %w", err)
+	}
+
+	now := time.Now()
+	display := &KitchenDisplay{
+		OutletID:      outletID,
+		Station:       station,
+		WebsocketRoom: KitchenRoom(outletID, station),
+		Tickets:       make([]KitchenDisplayTicket, 0, len(tickets)),
+		Seatings:      []KitchenSeating{},
+		GeneratedAt:   now,
+	}
+	for i := range tickets {
+		view, err := s.displayTicket(&tickets[i], now)
+		if err != nil {
+			return nil, err
+		}
+		display.Tickets = append(display.Tickets, *view)
+	}
+
+	var turns []models.TableTurn
+	err = s.db.Where("outlet_id = ? AND status = ? AND guest_id IS NOT NULL", outletID, TurnStatusSeated).
+		Order("seated_at").
+		Find(&turns).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch seated parties: %w", err)
+	}
+	for i := range turns {
+		seating, err := s.displaySeating(&turns[i], now)
+		if err != nil {
+			return nil, err
+		}
+		if seating != nil {
+			display.Seatings = append(display.Seatings, *seating)
+		}
+	}
+
+	return display, nil
+}
+
+// Bump marks a ticket ready and takes it off its station's display
+func (s *KitchenDisplayService) Bump(ticketID uint) (*KitchenDisplayTicket, error) {
+	ticket, err := s.orders.MarkTicketReady(ticketID)
+	if err != nil {
+		return nil, err
+	}
+	return s.displayTicket(ticket, time.Now())
+}
+
+// Recall puts a bumped ticket back on its station's display
+func (s *KitchenDisplayService) Recall(ticketID uint) (*KitchenDisplayTicket, error) {
+	ticket, err := s.orders.RecallTicket(ticketID)
+	if err != nil {
+		return nil, err
+	}
+	return s.displayTicket(ticket, time.Now())
+}
+
+// TicketChanged pushes a ticket to its station's display and the expo display
+func (s *KitchenDisplayService) TicketChanged(ticket *models.KitchenTicket, event string) {
+	if s.publisher == nil {
+		return
+	}
+
+	var loaded models.KitchenTicket
+	if err := s.db.Preload("Items").First(&loaded, ticket.ID).Error; err != nil {
+		logging.WithError(err).WithField("ticket_id", ticket.ID).Warn("Failed to load kitchen ticket for display")
+		return
+	}
+	view, err := s.displayTicket(&loaded, time.Now())
+	if err != nil {
+		logging.WithError(err).WithField("ticket_id", ticket.ID).Warn("Failed to build kitchen ticket for display")
+		return
+	}
+
+	message := map[string]interface{}{
+		"type":      "kitchen_ticket",
+		"event":     event,
+		"outlet_id": loaded.OutletID,
+		"station":   loaded.Station,
+		"data":      view,
+		"timestamp": time.Now().Unix(),
+	}
+	s.publisher.BroadcastToRoom(KitchenRoom(loaded.OutletID, loaded.Station), message)
+	s.publisher.BroadcastToRoom(KitchenRoom(loaded.OutletID, ""), message)
+}
+
+// PartySeated tells the kitchen when a VIP or a guest with notes or allergies sits down
+func (s *KitchenDisplayService) PartySeated(turn *models.TableTurn) {
+	s.publishSeating(turn, "seated")
+}
+
+// PartyLeft takes a party's seating card off the kitchen displays
+func (s *KitchenDisplayService) PartyLeft(turn *models.TableTurn) {
+	s.publishSeating(turn, "cleared")
+}
+
+func (s *KitchenDisplayService) publishSeating(turn *models.TableTurn, event string) {
+	if s.publisher == nil || turn.GuestID == nil {
+		return
+	}
+
+	seating, err := s.displaySeating(turn, time.Now())
+	if err != nil {
+		logging.WithError(err).WithField("turn_id", turn.ID).Warn("Failed to build kitchen seating")
+		return
+	}
+	if seating == nil {
+		return
+	}
+
+	s.publisher.BroadcastToRoom(KitchenRoom(turn.OutletID, ""), map[string]interface{}{
+		"type":      "kitchen_seating",
+		"event":     event,
+		"outlet_id": turn.OutletID,
+		"data":      seating,
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// displayTicket adds the guest, table, timer and allergy flags to a ticket
+func (s *KitchenDisplayService) displayTicket(ticket *models.KitchenTicket, now time.Time) (*KitchenDisplayTicket, error) {
+	var order models.Order
+	if err := s.db.First(&order, ticket.OrderID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch order: %w", err)
+	}
+
+	view := &KitchenDisplayTicket{
+		ID:       ticket.ID,
+		OrderID:  ticket.OrderID,
+		OutletID: ticket.OutletID,
+		Station:  ticket.Station,
+		Status:   ticket.Status,
+		FiredAt:  ticket.FiredAt,
+		ReadyAt:  ticket.ReadyAt,
+		Items:    make([]KitchenDisplayItem, 0, len(ticket.Items)),
+	}
+	until := now
+	if ticket.ReadyAt != nil {
+		until = *ticket.ReadyAt
+	}
+	view.OpenSeconds = int64(until.Sub(ticket.FiredAt).Seconds())
+
+	if order.TableTurnID != nil {
+		var turn models.TableTurn
+		if err := s.db.First(&turn, *order.TableTurnID).Error; err == nil {
+			var table models.DiningTable
+			if err := s.db.Unscoped().Select("name").First(&table, turn.TableID).Error; err == nil {
+				view.TableName = table.Name
+			}
+		}
+	}
+
+	guest, err := s.kitchenGuest(order.GuestID)
+	if err != nil {
+		return nil, err
+	}
+	view.Guest = guest
+
+	var profile DietaryProfile
+	if guest != nil {
+		profile = DietaryProfile{GuestID: guest.GuestID, Allergies: guest.Allergies, DietaryRestrictions: guest.DietaryRestrictions}
+		view.CriticalAllergy = len(guest.CriticalAllergies) > 0
+	}
+	for _, item := range ticket.Items {
+		line := KitchenDisplayItem{OrderItem: item}
+		line.AllergyConflicts = profile.conflicts(item.MenuItemID, item.Name, item.AllergenTags, item.DietaryTags)
+		if len(line.AllergyConflicts) > 0 {
+			view.AllergyAlert = true
+		}
+		view.Items = append(view.Items, line)
+	}
+	return view, nil
+}
+
+// displaySeating returns a seating card, or nil when there is nothing for the kitchen to know
+func (s *KitchenDisplayService) displaySeating(turn *models.TableTurn, now time.Time) (*KitchenSeating, error) {
+	if turn.GuestID == nil {
+		return nil, nil
+	}
+	guest, err := s.kitchenGuest(*turn.GuestID)
+	if err != nil || guest == nil {
+		return nil, err
+	}
+	if !guest.IsVIP && guest.SpecialRequests == "" && guest.HandlingInstructions == "" &&
+		len(guest.Allergies) == 0 && len(guest.DietaryRestrictions) == 0 {
+		return nil, nil
+	}
+
+	seating := &KitchenSeating{
+		TurnID:    turn.ID,
+		TableID:   turn.TableID,
+		PartySize: turn.PartySize,
+		SeatedAt:  turn.SeatedAt,
+		Guest:     *guest,
+	}
+	until := now
+	if turn.ClearedAt != nil {
+		until = *turn.ClearedAt
+	}
+	seating.OpenSeconds = int64(until.Sub(turn.SeatedAt).Seconds())
+
+	var table models.DiningTable
+	if err := s.db.Unscoped().Select("name").First(&table, turn.TableID).Error; err == nil {
+		seating.TableName = table.Name
+	}
+	return seating, nil
+}
+
+// kitchenGuest loads a guest's kitchen-relevant details and preferences
+func (s *KitchenDisplayService) kitchenGuest(guestID uint) (*KitchenGuest, error) {
+	if guestID == 0 {
+		return nil, nil
+	}
+
+	var guests []models.Guest
+	if err := s.db.Where("id = ?", guestID).Limit(1).Find(&guests).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch guest: %w", err)
+	}
+	if len(guests) == 0 {
+		return nil, nil
+	}
+	guest := guests[0]
+
+	kitchenGuest := &KitchenGuest{
+		GuestID:              guest.ID,
+		Name:                 strings.TrimSpace(guest.FirstName + " " + guest.LastName),
+		RoomNumber:           guest.RoomNumber,
+		IsVIP:                guest.IsVIP,
+		SpecialRequests:      guest.PMSSpecialReq,
+		HandlingInstructions: guest.HandlingInstr,
+	}
+
+	profile, err := loadDietaryProfile(s.db, guestID)
+	if err != nil {
+		return nil, err
+	}
+	if len(profile.Allergies) > 0 {
+		kitchenGuest.Allergies = profile.Allergies
+		kitchenGuest.CriticalAllergies = profile.criticalAllergies()
+	}
+	if len(profile.DietaryRestrictions) > 0 {
+		kitchenGuest.DietaryRestrictions = profile.DietaryRestrictions
+	}
+	return kitchenGuest, nil
+}
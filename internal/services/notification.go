@@ -24,6 +24,7 @@ const (
 	NotificationLowSupplies   NotificationType = "low_supplies"
 	NotificationStaffAlert    NotificationType = "staff_alert"
 	NotificationSystemAlert   NotificationType = "system_alert"
+	NotificationTableReady    NotificationType = "table_ready"
 )
 
 // NotificationPriority represents the priority level
@@ -68,6 +69,7 @@ type Notification struct {
 	PropertyID     string              `json:"property_id" gorm:"not null"`
 	RecipientID    uint                `json:"recipient_id,omitempty"`
 	RecipientRole  string              `json:"recipient_role,omitempty"`
+	RecipientPhone string              `json:"recipient_phone,omitempty"` // Guest phone for guest-facing SMS
 	Channels       string              `json:"channels" gorm:"type:text"` // JSON string
 	Sent           bool                 `json:"sent" gorm:"default:false"`
 	SentAt         *time.Time          `json:"sent_at,omitempty"`
@@ -101,6 +103,14 @@ type PushNotificationProvider interface {
 // EmailProvider interface for email services
 type EmailProvider interface {
 	Send(ctx context.Context, to string, subject string, body string) error
+	SendWithAttachments(ctx context.Context, to string, subject string, body string, attachments []EmailAttachment) error
+}
+
+// EmailAttachment is a file attached to an email
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
 }
 
 // SMSProvider interface for SMS services
@@ -153,6 +163,7 @@ func (s *NotificationService) CreateNotification(ctx context.Context, req *Creat
 		PropertyID:    req.PropertyID,
 		RecipientID:   req.RecipientID,
 		RecipientRole: req.RecipientRole,
+		RecipientPhone: req.RecipientPhone,
 		Channels:      string(channelsJSON),
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
@@ -338,6 +349,11 @@ func (s *NotificationService) sendSMSNotification(ctx context.Context, notificat
 		return fmt.Errorf("SMS provider not configured")
 	}
 
+	// Guest-facing messages go straight to the guest's phone
+	if notification.RecipientPhone != "" {
+		return s.smsProvider.Send(ctx, notification.RecipientPhone, notification.Message)
+	}
+
 	// Only send SMS for high priority notifications
 	if notification.Priority != PriorityHigh && notification.Priority != PriorityCritical {
 		return nil
@@ -426,20 +442,22 @@ func (s *NotificationService) NotifyUpsetGuest(ctx context.Context, guest *model
 	return err
 }
 
-// NotifyServiceDelay sends notifications for service delays
-func (s *NotificationService) NotifyServiceDelay(ctx context.Context, propertyID string, avgServiceTime float64) error {
+// NotifyServiceDelay alerts staff that an outlet's rolling average service time has breached the threshold
+func (s *NotificationService) NotifyServiceDelay(ctx context.Context, outlet *models.Outlet, avgServiceTime, threshold float64, samples int) error {
 	data := map[string]interface{}{
+		"outlet_id":        outlet.ID,
 		"avg_service_time": avgServiceTime,
-		"threshold":        15.0,
+		"threshold":        threshold,
+		"samples":          samples,
 	}
 
 	req := &CreateNotificationRequest{
 		Type:          NotificationServiceDelay,
 		Priority:      PriorityMedium,
 		Title:         "Service Delay Alert",
-		Message:       fmt.Sprintf("Average service time is %.1f minutes, exceeding 15-minute threshold", avgServiceTime),
+		Message:       fmt.Sprintf("Average service time at %s is %.1f minutes, exceeding %.0f-minute threshold", outlet.Name, avgServiceTime, threshold),
 		Data:          data,
-		PropertyID:    propertyID,
+		PropertyID:    outlet.PropertyID,
 		RecipientRole: "staff",
 		Channels:      []NotificationChannel{ChannelPush, ChannelWebSocket},
 	}
@@ -448,6 +466,63 @@ func (s *NotificationService) NotifyServiceDelay(ctx context.Context, propertyID
 	return err
 }
 
+// NotifyTableReady texts a waiting party that their table is ready
+func (s *NotificationService) NotifyTableReady(ctx context.Context, entry *models.WaitlistEntry, outletName, tableName string) error {
+	data := map[string]interface{}{
+		"waitlist_entry_id": entry.ID,
+		"outlet_id":         entry.OutletID,
+		"table":             tableName,
+		"party_size":        entry.PartySize,
+		"room_number":       entry.RoomNumber,
+	}
+
+	req := &CreateNotificationRequest{
+		Type:           NotificationTableReady,
+		Priority:       PriorityHigh,
+		Title:          "Table Ready",
+		Message:        fmt.Sprintf("%s, your table for %d at %s is ready. Please see the host.", entry.PartyName, entry.PartySize, outletName),
+		Data:           data,
+		PropertyID:     entry.PropertyID,
+		RecipientPhone: entry.Phone,
+		Channels:       []NotificationChannel{ChannelSMS},
+		ExpiresIn:      30 * time.Minute,
+	}
+
+	_, err := s.CreateNotification(ctx, req)
+	return err
+}
+
+// NotifyLowSupplies alerts managers that an outlet's stock of an item has dropped below par
+func (s *NotificationService) NotifyLowSupplies(ctx context.Context, item *models.InventoryItem, outletName string) error {
+	data := map[string]interface{}{
+		"inventory_item_id": item.ID,
+		"outlet_id":         item.OutletID,
+		"code":              item.Code,
+		"on_hand":           item.OnHand,
+		"par_level":         item.ParLevel,
+		"unit":              item.Unit,
+	}
+
+	priority := PriorityMedium
+	if item.OnHand <= 0 {
+		priority = PriorityHigh
+	}
+
+	req := &CreateNotificationRequest{
+		Type:          NotificationLowSupplies,
+		Priority:      priority,
+		Title:         "Low Supplies",
+		Message:       fmt.Sprintf("%s at %s is down to %.2f %s (par %.2f)", item.Name, outletName, item.OnHand, item.Unit, item.ParLevel),
+		Data:          data,
+		PropertyID:    item.PropertyID,
+		RecipientRole: "manager",
+		Channels:      []NotificationChannel{ChannelPush, ChannelWebSocket},
+	}
+
+	_, err := s.CreateNotification(ctx, req)
+	return err
+}
+
 // GetUnreadNotifications gets unread notifications for a user
 func (s *NotificationService) GetUnreadNotifications(userID uint) ([]*Notification, error) {
 	var notifications []*Notification
@@ -505,6 +580,7 @@ type CreateNotificationRequest struct {
 	PropertyID    string               `json:"property_id"`
 	RecipientID   uint                 `json:"recipient_id,omitempty"`
 	RecipientRole string               `json:"recipient_role,omitempty"`
+	RecipientPhone string              `json:"recipient_phone,omitempty"`
 	Channels      []NotificationChannel `json:"channels"`
 	ExpiresIn     time.Duration        `json:"expires_in,omitempty"`
 }
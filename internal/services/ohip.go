@@ -194,6 +194,35 @@ func (s *OHIPService) CheckClaimStatus(transactionID string) (*OHIPClaimResponse
 	return &statusResp, nil
 }
 
+// CancelClaim withdraws a claim that has not been processed yet
+func (s *OHIPService) CancelClaim(transactionID string) error {
+	auth, err := s.authenticate()
+	if err != nil {
+		return err
+	}
+
+	cancelURL := fmt.Sprintf("%s/%s/claims/%s/cancel", s.config.BaseURL, s.config.Version, transactionID)
+	req, err := http.NewRequest("POST", cancelURL, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", auth.AccessToken))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("OHIP claim cancellation failed with status: %d", resp.StatusCode)
+	}
+
+	s.logger.Infof("OHIP claim cancelled: %s", transactionID)
+	return nil
+}
+
 func (s *OHIPService) ValidateOHIPNumber(ohipNumber string) (bool, error) {
 	auth, err := s.authenticate()
 	if err != nil {
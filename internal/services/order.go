@@ -0,0 +1,902 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Errors returned by à-la-carte ordering
+var (
+	ErrMenuItemNotFound    = errors.New("menu item not found")
+	ErrMenuItemUnavailable = errors.New("menu item is not available")
+	ErrUnknownModifier     = errors.New("modifier is not offered for this item")
+	ErrOutletNotALaCarte   = errors.New("outlet does not serve à la carte")
+	ErrVisitNotActive      = errors.New("orders can only be placed against a consumed visit")
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrOrderClosed         = errors.New("order has been served or cancelled")
+	ErrOrderEmpty          = errors.New("order has no items")
+	ErrNothingToFire       = errors.New("order has no items waiting to be fired")
+	ErrOrderAlreadyFired   = errors.New("order has been sent to the kitchen")
+	ErrTicketNotFound      = errors.New("kitchen ticket not found")
+	ErrTicketState         = errors.New("kitchen ticket cannot move to that state")
+	ErrNoChargePoster      = errors.New("no PMS configured to post room charges")
+)
+
+// Order and kitchen ticket states
+const (
+	OrderStatusOpen      = "open"
+	OrderStatusFired     = "fired"
+	OrderStatusReady     = "ready"
+	OrderStatusServed    = "served"
+	OrderStatusCancelled = "cancelled"
+
+	TicketStatusFired  = "fired"
+	TicketStatusReady  = "ready"
+	TicketStatusServed = "served"
+)
+
+// defaultStation prepares menu items that do not name a station
+const defaultStation = "kitchen"
+
+// RoomCharge is a charge posted to a guest's room folio
+type RoomCharge struct {
+	GuestID       string // PMS guest ID
+	ReservationID string
+	RoomNumber    string
+	PropertyID    string
+	Amount        float64 // Net of tax
+	TaxAmount     float64
+	Description   string
+	Reference     string
+}
+
+// ChargePoster posts charges to a guest's room in the PMS
+type ChargePoster interface {
+	PostRoomCharge(ctx context.Context, charge RoomCharge) (string, error)
+}
+
+// TicketListener is told when a kitchen ticket is fired or changes state
+type TicketListener interface {
+	TicketChanged(ticket *models.KitchenTicket, event string)
+}
+
+// Kitchen ticket events passed to the TicketListener
+const (
+	TicketEventFired    = "fired"
+	TicketEventBumped   = "bumped"
+	TicketEventRecalled = "recalled"
+	TicketEventServed   = "served"
+)
+
+// OrderService manages à-la-carte menus, orders and kitchen tickets
+type OrderService struct {
+	db           *gorm.DB
+	chargePoster ChargePoster
+	listener     TicketListener
+}
+
+// OrderItemRequest is one line of an order request
+type OrderItemRequest struct {
+	MenuItemID uint     `json:"menu_item_id" binding:"required"`
+	Quantity   int      `json:"quantity"` // Defaults to 1
+	Modifiers  []string `json:"modifiers"`
+	Notes      string   `json:"notes"`
+}
+
+// OrderRequest places an order against a recorded breakfast visit
+type OrderRequest struct {
+	ConsumptionID        uint               `json:"consumption_id" binding:"required"`
+	TableTurnID          *uint              `json:"table_turn_id"`
+	Items                []OrderItemRequest `json:"items"`
+	Fire                 bool               `json:"fire"`                  // Send the items to the kitchen straight away
+	AcknowledgeAllergies bool               `json:"acknowledge_allergies"` // Staff have warned the guest about allergen conflicts
+}
+
+// NewOrderService creates a new order service
+func NewOrderService(db *gorm.DB) *OrderService {
+	return &OrderService{
+		db: db,
+	}
+}
+
+// SetChargePoster sets the PMS used to charge overages to the room
+func (s *OrderService) SetChargePoster(poster ChargePoster) {
+	s.chargePoster = poster
+}
+
+// SetTicketListener sets who is told about kitchen ticket changes
+func (s *OrderService) SetTicketListener(listener TicketListener) {
+	s.listener = listener
+}
+
+// GetMenu returns an outlet's menu, optionally only the items that can be ordered
+func (s *OrderService) GetMenu(outletID uint, availableOnly bool) ([]models.MenuItem, error) {
+	query := s.db.Where("outlet_id = ?", outletID)
+	if availableOnly {
+		query = query.Where("is_available = ?", true)
+	}
+
+	var items []models.MenuItem
+	if err := query.Order("category, sort_order, name").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch menu: %w", err)
+	}
+	return items, nil
+}
+
+// CreateMenuItem adds an item to an outlet's menu
+func (s *OrderService) CreateMenuItem(item *models.MenuItem) error {
+	var outlet models.Outlet
+	if err := s.db.First(&outlet, item.OutletID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOutletNotFound
+		}
+		return fmt.Errorf("failed to fetch outlet: %w", err)
+	}
+	item.PropertyID = outlet.PropertyID
+
+	if err := normalizeMenuItem(item); err != nil {
+		return err
+	}
+	if err := s.db.Create(item).Error; err != nil {
+		return fmt.Errorf("failed to create menu item: %w", err)
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":      "OrderService",
+		"method":       "CreateMenuItem",
+		"outlet_id":    item.OutletID,
+		"menu_item_id": item.ID,
+	}).Info("Menu item created")
+
+	return nil
+}
+
+// UpdateMenuItem applies partial updates to a menu item
+func (s *OrderService) UpdateMenuItem(itemID uint, updates map[string]interface{}) (*models.MenuItem, error) {
+	var item models.MenuItem
+	if err := s.db.First(&item, itemID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMenuItemNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch menu item: %w", err)
+	}
+
+	// Validate the item as it will be after the update
+	updated := item
+	if value, ok := updates["name"].(string); ok {
+		updated.Name = value
+	}
+	if value, ok := updates["price"].(float64); ok {
+		updated.Price = value
+	}
+	if value, ok := updates["station"].(string); ok {
+		updated.Station = value
+	}
+	if value, ok := updates["modifiers"].(string); ok {
+		updated.Modifiers = value
+	}
+	if value, ok := updates["allergen_tags"].(string); ok {
+		updated.AllergenTags = value
+	}
+	if value, ok := updates["dietary_tags"].(string); ok {
+		updated.DietaryTags = value
+	}
+	if err := normalizeMenuItem(&updated); err != nil {
+		return nil, err
+	}
+	for _, field := range []struct {
+		column string
+		value  string
+	}{{"station", updated.Station}, {"modifiers", updated.Modifiers}, {"allergen_tags", updated.AllergenTags}, {"dietary_tags", updated.DietaryTags}} {
+		if _, ok := updates[field.column]; ok {
+			updates[field.column] = field.value
+		}
+	}
+
+	if err := s.db.Model(&item).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update menu item: %w", err)
+	}
+	if err := s.db.First(&item, itemID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch menu item: %w", err)
+	}
+	return &item, nil
+}
+
+// DeleteMenuItem removes an item from the menu; past orders keep their copy of it
+func (s *OrderService) DeleteMenuItem(itemID uint) error {
+	result := s.db.Delete(&models.MenuItem{}, itemID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete menu item: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrMenuItemNotFound
+	}
+	return nil
+}
+
+// CreateOrder opens an order for a recorded visit at an à-la-carte outlet
+func (s *OrderService) CreateOrder(req OrderRequest, staffID uint) (*models.Order, error) {
+	var order models.Order
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var consumption models.DailyBreakfastConsumption
+		if err := tx.First(&consumption, req.ConsumptionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrConsumptionNotFound
+			}
+			return fmt.Errorf("failed to fetch visit: %w", err)
+		}
+		if consumption.Status != "consumed" {
+			return ErrVisitNotActive
+		}
+		if consumption.OutletID == nil {
+			return ErrOutletNotALaCarte
+		}
+
+		var outlet models.Outlet
+		if err := tx.First(&outlet, *consumption.OutletID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOutletNotFound
+			}
+			return fmt.Errorf("failed to fetch outlet: %w", err)
+		}
+		if outlet.MenuType != "a_la_carte" {
+			return ErrOutletNotALaCarte
+		}
+		if err := ensureServiceOpen(tx, consumption.PropertyID, consumption.OutletID, consumption.ConsumptionDate); err != nil {
+			return err
+		}
+
+		order = models.Order{
+			ConsumptionID: consumption.ID,
+			OutletID:      outlet.ID,
+			PropertyID:    consumption.PropertyID,
+			GuestID:       consumption.GuestID,
+			RoomNumber:    consumption.RoomNumber,
+			TableTurnID:   req.TableTurnID,
+			Status:        OrderStatusOpen,
+			CreatedBy:     staffID,
+		}
+		if err := tx.Create(&order).Error; err != nil {
+			return fmt.Errorf("failed to create order: %w", err)
+		}
+
+		if err := s.addItems(tx, &order, req.Items, req.AcknowledgeAllergies, staffID); err != nil {
+			return err
+		}
+		return s.priceOrder(tx, &order)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":        "OrderService",
+		"method":         "CreateOrder",
+		"order_id":       order.ID,
+		"consumption_id": order.ConsumptionID,
+		"outlet_id":      order.OutletID,
+		"items":          len(req.Items),
+	}).Info("Order created")
+
+	if req.Fire && len(req.Items) > 0 {
+		return s.FireOrder(order.ID, staffID)
+	}
+	return s.GetOrder(order.ID)
+}
+
+// AddItems adds lines to an order that has not been served or cancelled.
+// Items that conflict with the guest's allergies are only added once staff
+// acknowledge the conflicts.
+func (s *OrderService) AddItems(orderID uint, items []OrderItemRequest, acknowledgeAllergies bool, staffID uint) (*models.Order, error) {
+	if len(items) == 0 {
+		return nil, ErrOrderEmpty
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		order, err := s.getOrder(tx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.Status == OrderStatusServed || order.Status == OrderStatusCancelled {
+			return ErrOrderClosed
+		}
+
+		if err := s.addItems(tx, order, items, acknowledgeAllergies, staffID); err != nil {
+			return err
+		}
+		return s.priceOrder(tx, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOrder(orderID)
+}
+
+// GetOrder returns an order with its items
+func (s *OrderService) GetOrder(orderID uint) (*models.Order, error) {
+	return s.getOrder(s.db, orderID)
+}
+
+// GetOrders returns the orders placed against a visit
+func (s *OrderService) GetOrders(consumptionID uint) ([]models.Order, error) {
+	var orders []models.Order
+	err := s.db.Preload("Items").
+		Where("consumption_id = ?", consumptionID).
+		Order("created_at").
+		Find(&orders).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch orders: %w", err)
+	}
+	return orders, nil
+}
+
+// FireOrder sends an order's unfired items to the kitchen, one ticket per station
+func (s *OrderService) FireOrder(orderID, staffID uint) (*models.Order, error) {
+	var tickets []models.KitchenTicket
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		order, err := s.getOrder(tx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.Status == OrderStatusServed || order.Status == OrderStatusCancelled {
+			return ErrOrderClosed
+		}
+
+		// Group the items not yet on a ticket by station, keeping the order they were added in
+		byStation := make(map[string][]uint)
+		var stations []string
+		for _, item := range order.Items {
+			if item.TicketID != nil {
+				continue
+			}
+			if _, ok := byStation[item.Station]; !ok {
+				stations = append(stations, item.Station)
+			}
+			byStation[item.Station] = append(byStation[item.Station], item.ID)
+		}
+		if len(stations) == 0 {
+			return ErrNothingToFire
+		}
+
+		now := time.Now()
+		for _, station := range stations {
+			ticket := models.KitchenTicket{
+				OrderID:  order.ID,
+				OutletID: order.OutletID,
+				Station:  station,
+				Status:   TicketStatusFired,
+				FiredAt:  now,
+				FiredBy:  staffID,
+			}
+			if err := tx.Create(&ticket).Error; err != nil {
+				return fmt.Errorf("failed to create kitchen ticket: %w", err)
+			}
+			err := tx.Model(&models.OrderItem{}).
+				Where("id IN ?", byStation[station]).
+				Update("ticket_id", ticket.ID).Error
+			if err != nil {
+				return fmt.Errorf("failed to assign items to ticket: %w", err)
+			}
+			tickets = append(tickets, ticket)
+		}
+
+		updates := map[string]interface{}{"status": OrderStatusFired, "ready_at": nil}
+		if order.FiredAt == nil {
+			updates["fired_at"] = now
+		}
+		return tx.Model(order).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":  "OrderService",
+		"method":   "FireOrder",
+		"order_id": orderID,
+		"tickets":  len(tickets),
+	}).Info("Order fired to kitchen")
+
+	for i := range tickets {
+		s.ticketChanged(&tickets[i], TicketEventFired)
+	}
+
+	return s.GetOrder(orderID)
+}
+
+// CancelOrder cancels an order that has not been sent to the kitchen
+func (s *OrderService) CancelOrder(orderID uint) (*models.Order, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		order, err := s.getOrder(tx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.Status != OrderStatusOpen {
+			if order.Status == OrderStatusCancelled || order.Status == OrderStatusServed {
+				return ErrOrderClosed
+			}
+			return ErrOrderAlreadyFired
+		}
+
+		return tx.Model(order).Updates(map[string]interface{}{
+			"status":            OrderStatusCancelled,
+			"cancelled_at":      time.Now(),
+			"allowance_applied": 0,
+			"overage_amount":    0,
+			"service_charge":    0,
+			"tax_amount":        0,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOrder(orderID)
+}
+
+// GetTickets returns an outlet's kitchen tickets, optionally for one station and
+// in the given states, oldest first
+func (s *OrderService) GetTickets(outletID uint, station string, statuses []string) ([]models.KitchenTicket, error) {
+	query := s.db.Preload("Items").Where("outlet_id = ?", outletID)
+	if station != "" {
+		query = query.Where("station = ?", station)
+	}
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+
+	var tickets []models.KitchenTicket
+	if err := query.Order("fired_at, id").Find(&tickets).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch kitchen tickets: %w", err)
+	}
+	return tickets, nil
+}
+
+// MarkTicketReady marks a station's ticket as ready for the pass, bumping it off the station's display
+func (s *OrderService) MarkTicketReady(ticketID uint) (*models.KitchenTicket, error) {
+	return s.advanceTicket(context.Background(), ticketID, TicketStatusFired, TicketStatusReady)
+}
+
+// RecallTicket puts a bumped ticket back on its station's display
+func (s *OrderService) RecallTicket(ticketID uint) (*models.KitchenTicket, error) {
+	var ticket models.KitchenTicket
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&ticket, ticketID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTicketNotFound
+			}
+			return fmt.Errorf("failed to fetch kitchen ticket: %w", err)
+		}
+		if ticket.Status != TicketStatusReady {
+			return ErrTicketState
+		}
+
+		recall := tx.Model(&models.KitchenTicket{}).
+			Where("id = ? AND status = ?", ticket.ID, TicketStatusReady).
+			Updates(map[string]interface{}{"status": TicketStatusFired, "ready_at": nil})
+		if recall.Error != nil {
+			return fmt.Errorf("failed to recall kitchen ticket: %w", recall.Error)
+		}
+		if recall.RowsAffected == 0 {
+			return ErrTicketState
+		}
+
+		// The order is cooking again
+		return tx.Model(&models.Order{}).
+			Where("id = ? AND status = ?", ticket.OrderID, OrderStatusReady).
+			Updates(map[string]interface{}{"status": OrderStatusFired, "ready_at": nil}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Preload("Items").First(&ticket, ticket.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch kitchen ticket: %w", err)
+	}
+	s.ticketChanged(&ticket, TicketEventRecalled)
+	return &ticket, nil
+}
+
+// MarkTicketServed marks a ticket as served; once every ticket of an order is
+// served, the order is closed and any overage is charged to the room
+func (s *OrderService) MarkTicketServed(ctx context.Context, ticketID uint) (*models.KitchenTicket, error) {
+	return s.advanceTicket(ctx, ticketID, TicketStatusReady, TicketStatusServed)
+}
+
+// PostOverage charges an order's overage to the guest's room. Served orders
+// post automatically; this retries a charge the PMS did not accept.
+func (s *OrderService) PostOverage(ctx context.Context, orderID uint) (*models.Order, error) {
+	order, err := s.getOrder(s.db, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.postOverage(ctx, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// advanceTicket moves a ticket between states and rolls the change up to its order
+func (s *OrderService) advanceTicket(ctx context.Context, ticketID uint, from, to string) (*models.KitchenTicket, error) {
+	var ticket models.KitchenTicket
+	var served *models.Order
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&ticket, ticketID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTicketNotFound
+			}
+			return fmt.Errorf("failed to fetch kitchen ticket: %w", err)
+		}
+		// Tickets may be served straight from fired when the station runs its own food
+		if ticket.Status != from && !(to == TicketStatusServed && ticket.Status == TicketStatusFired) {
+			return ErrTicketState
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{"status": to}
+		if to == TicketStatusReady || ticket.ReadyAt == nil {
+			updates["ready_at"] = now
+		}
+		if to == TicketStatusServed {
+			updates["served_at"] = now
+		}
+		if err := tx.Model(&ticket).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update kitchen ticket: %w", err)
+		}
+
+		// The order is ready once nothing is left cooking, and served once
+		// every ticket has gone out and no items wait to be fired
+		var open struct {
+			Cooking int64
+			Pending int64
+		}
+		err := tx.Model(&models.KitchenTicket{}).
+			Select("COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS cooking, COALESCE(SUM(CASE WHEN status <> ? THEN 1 ELSE 0 END), 0) AS pending",
+				TicketStatusFired, TicketStatusServed).
+			Where("order_id = ?", ticket.OrderID).
+			Scan(&open).Error
+		if err != nil {
+			return fmt.Errorf("failed to check order tickets: %w", err)
+		}
+		var unfired int64
+		if err := tx.Model(&models.OrderItem{}).Where("order_id = ? AND ticket_id IS NULL", ticket.OrderID).Count(&unfired).Error; err != nil {
+			return fmt.Errorf("failed to check order items: %w", err)
+		}
+
+		order, err := s.getOrder(tx, ticket.OrderID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case open.Pending == 0 && unfired == 0:
+			if err := tx.Model(order).Updates(map[string]interface{}{"status": OrderStatusServed, "served_at": now}).Error; err != nil {
+				return fmt.Errorf("failed to update order: %w", err)
+			}
+			order.Status = OrderStatusServed
+			order.ServedAt = &now
+			served = order
+		case open.Cooking == 0 && order.Status == OrderStatusFired:
+			if err := tx.Model(order).Updates(map[string]interface{}{"status": OrderStatusReady, "ready_at": now}).Error; err != nil {
+				return fmt.Errorf("failed to update order: %w", err)
+			}
+		}
+
+		ticket.Status = to
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if served != nil && served.OverageAmount > 0 {
+		if err := s.postOverage(ctx, served); err != nil {
+			// Keep the order served; the charge can be retried
+			logging.WithError(err).WithField("order_id", served.ID).Error("Failed to post order overage to PMS")
+		}
+	}
+
+	if err := s.db.Preload("Items").First(&ticket, ticket.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch kitchen ticket: %w", err)
+	}
+
+	event := TicketEventBumped
+	if to == TicketStatusServed {
+		event = TicketEventServed
+	}
+	s.ticketChanged(&ticket, event)
+	return &ticket, nil
+}
+
+// ticketChanged tells the listener about a ticket change
+func (s *OrderService) ticketChanged(ticket *models.KitchenTicket, event string) {
+	if s.listener != nil {
+		s.listener.TicketChanged(ticket, event)
+	}
+}
+
+// postOverage posts a served order's overage to the guest's room
+func (s *OrderService) postOverage(ctx context.Context, order *models.Order) error {
+	if order.Status != OrderStatusServed || order.OverageAmount <= 0 || order.PMSPosted {
+		return nil
+	}
+	if s.chargePoster == nil {
+		return ErrNoChargePoster
+	}
+
+	var guest models.Guest
+	if err := s.db.First(&guest, order.GuestID).Error; err != nil {
+		return fmt.Errorf("failed to fetch guest: %w", err)
+	}
+
+	transactionID, err := s.chargePoster.PostRoomCharge(ctx, RoomCharge{
+		GuestID:       guest.PMSGuestID,
+		ReservationID: guest.ReservationID,
+		RoomNumber:    order.RoomNumber,
+		PropertyID:    order.PropertyID,
+		Amount:        order.OverageAmount + order.ServiceCharge,
+		TaxAmount:     order.TaxAmount,
+		Description:   "Breakfast à la carte beyond package",
+		Reference:     fmt.Sprintf("ORDER-%d", order.ID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to post overage charge: %w", err)
+	}
+
+	order.PMSPosted = true
+	order.PMSTransactionID = transactionID
+	err = s.db.Model(order).Updates(map[string]interface{}{
+		"pms_posted":         true,
+		"pms_transaction_id": transactionID,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record overage charge: %w", err)
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":        "OrderService",
+		"method":         "postOverage",
+		"order_id":       order.ID,
+		"room_number":    order.RoomNumber,
+		"overage":        order.OverageAmount,
+		"transaction_id": transactionID,
+	}).Info("Order overage charged to room")
+
+	return nil
+}
+
+// addItems adds menu items to an order, copying their price, station and
+// allergens, and checks them against the guest's allergies
+func (s *OrderService) addItems(tx *gorm.DB, order *models.Order, requests []OrderItemRequest, acknowledgeAllergies bool, staffID uint) error {
+	added := make([]models.OrderItem, 0, len(requests))
+	for _, req := range requests {
+		var menuItem models.MenuItem
+		if err := tx.First(&menuItem, req.MenuItemID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %d", ErrMenuItemNotFound, req.MenuItemID)
+			}
+			return fmt.Errorf("failed to fetch menu item: %w", err)
+		}
+		if menuItem.OutletID != order.OutletID {
+			return fmt.Errorf("%w: %d", ErrMenuItemNotFound, req.MenuItemID)
+		}
+		if !menuItem.IsAvailable {
+			return fmt.Errorf("%w: %s", ErrMenuItemUnavailable, menuItem.Name)
+		}
+
+		quantity := req.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+		if quantity < 0 {
+			return fmt.Errorf("quantity cannot be negative")
+		}
+
+		modifiers, extra, err := chooseModifiers(&menuItem, req.Modifiers)
+		if err != nil {
+			return err
+		}
+
+		item := models.OrderItem{
+			OrderID:      order.ID,
+			MenuItemID:   menuItem.ID,
+			Name:         menuItem.Name,
+			Station:      menuItem.Station,
+			Quantity:     quantity,
+			UnitPrice:    roundCents(menuItem.Price + extra),
+			Modifiers:    strings.Join(modifiers, ","),
+			AllergenTags: menuItem.AllergenTags,
+			DietaryTags:  menuItem.DietaryTags,
+			Notes:        strings.TrimSpace(req.Notes),
+		}
+		if item.Station == "" {
+			item.Station = defaultStation
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return fmt.Errorf("failed to add order item: %w", err)
+		}
+		order.Items = append(order.Items, item)
+		added = append(added, item)
+	}
+	return checkOrderAllergies(tx, order, added, acknowledgeAllergies, staffID)
+}
+
+// priceOrder totals an order and splits it between the visit's package
+// allowance and the overage charged to the room. The allowance is per
+// entitled cover and is shared by every order placed during the visit.
+func (s *OrderService) priceOrder(tx *gorm.DB, order *models.Order) error {
+	var consumption models.DailyBreakfastConsumption
+	if err := tx.First(&consumption, order.ConsumptionID).Error; err != nil {
+		return fmt.Errorf("failed to fetch visit: %w", err)
+	}
+	var outlet models.Outlet
+	if err := tx.First(&outlet, order.OutletID).Error; err != nil {
+		return fmt.Errorf("failed to fetch outlet: %w", err)
+	}
+
+	var subtotal float64
+	for _, item := range order.Items {
+		subtotal += item.UnitPrice * float64(item.Quantity)
+	}
+
+	perCover := consumption.AdultPrice
+	if outlet.PackageAllowance != nil {
+		perCover = *outlet.PackageAllowance
+	}
+	entitled := consumption.AdultCovers + consumption.ChildCovers - consumption.UpsellCovers
+	allowance := perCover * float64(entitled)
+
+	var used float64
+	err := tx.Model(&models.Order{}).
+		Where("consumption_id = ? AND id <> ? AND status <> ?", order.ConsumptionID, order.ID, OrderStatusCancelled).
+		Select("COALESCE(SUM(allowance_applied), 0)").
+		Row().Scan(&used)
+	if err != nil {
+		return fmt.Errorf("failed to total used allowance: %w", err)
+	}
+
+	order.Subtotal = roundCents(subtotal)
+	order.AllowanceApplied = roundCents(math.Min(order.Subtotal, math.Max(allowance-used, 0)))
+	order.OverageAmount = roundCents(order.Subtotal - order.AllowanceApplied)
+	order.ServiceCharge, order.TaxAmount = 0, 0
+	if order.OverageAmount > 0 {
+		// Menu items carry the outlet's adult price book rates for service
+		// charge and tax on the visit's business date
+		rates, err := resolveCoverPrice(tx, order.PropertyID, &order.OutletID, roomTypeFor(tx, order.PropertyID, order.RoomNumber), "adult", consumption.ConsumptionDate)
+		if err != nil {
+			return err
+		}
+		order.ServiceCharge = roundCents(order.OverageAmount * rates.ServiceChargeRate / 100)
+		// Tax applies to the service charge as well as the overage
+		order.TaxAmount = roundCents((order.OverageAmount + order.ServiceCharge) * rates.TaxRate / 100)
+	}
+
+	return tx.Model(order).Updates(map[string]interface{}{
+		"subtotal":          order.Subtotal,
+		"allowance_applied": order.AllowanceApplied,
+		"overage_amount":    order.OverageAmount,
+		"service_charge":    order.ServiceCharge,
+		"tax_amount":        order.TaxAmount,
+	}).Error
+}
+
+func (s *OrderService) getOrder(tx *gorm.DB, orderID uint) (*models.Order, error) {
+	var order models.Order
+	if err := tx.Preload("Items").First(&order, orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch order: %w", err)
+	}
+	return &order, nil
+}
+
+// menuModifier is one option offered on a menu item
+type menuModifier struct {
+	Name  string
+	Price float64
+}
+
+// parseModifiers reads a menu item's "name" or "name:price" options
+func parseModifiers(value string) ([]menuModifier, error) {
+	var modifiers []menuModifier
+	for _, option := range strings.Split(value, ",") {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+
+		modifier := menuModifier{Name: option}
+		if index := strings.LastIndex(option, ":"); index >= 0 {
+			price, err := strconv.ParseFloat(strings.TrimSpace(option[index+1:]), 64)
+			if err != nil || price < 0 {
+				return nil, fmt.Errorf("invalid modifier price in %q", option)
+			}
+			modifier.Name = strings.TrimSpace(option[:index])
+			modifier.Price = price
+		}
+		if modifier.Name == "" {
+			return nil, fmt.Errorf("modifier name cannot be empty")
+		}
+		modifiers = append(modifiers, modifier)
+	}
+	return modifiers, nil
+}
+
+// chooseModifiers matches requested modifiers against a menu item's options
+// and returns their names and the total they add to the item's price
+func chooseModifiers(item *models.MenuItem, requested []string) ([]string, float64, error) {
+	if len(requested) == 0 {
+		return nil, 0, nil
+	}
+
+	offered, err := parseModifiers(item.Modifiers)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var names []string
+	var extra float64
+	for _, name := range requested {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, option := range offered {
+			if strings.EqualFold(option.Name, name) {
+				names = append(names, option.Name)
+				extra += option.Price
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, 0, fmt.Errorf("%w: %q on %s", ErrUnknownModifier, name, item.Name)
+		}
+	}
+	return names, extra, nil
+}
+
+// normalizeMenuItem validates a menu item and tidies its list fields
+func normalizeMenuItem(item *models.MenuItem) error {
+	if strings.TrimSpace(item.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if item.Price < 0 {
+		return fmt.Errorf("price cannot be negative")
+	}
+
+	item.Station = strings.ToLower(strings.TrimSpace(item.Station))
+	if item.Station == "" {
+		item.Station = defaultStation
+	}
+
+	modifiers, err := parseModifiers(item.Modifiers)
+	if err != nil {
+		return err
+	}
+	options := make([]string, 0, len(modifiers))
+	for _, modifier := range modifiers {
+		if modifier.Price > 0 {
+			options = append(options, fmt.Sprintf("%s:%.2f", modifier.Name, modifier.Price))
+		} else {
+			options = append(options, modifier.Name)
+		}
+	}
+	item.Modifiers = strings.Join(options, ",")
+
+	if item.AllergenTags, err = normalizeTagList(item.AllergenTags, normalizeAllergen, ErrUnknownAllergen); err != nil {
+		return err
+	}
+	if item.DietaryTags, err = normalizeTagList(item.DietaryTags, normalizeDietary, ErrUnknownDietary); err != nil {
+		return err
+	}
+	return nil
+}
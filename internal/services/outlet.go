@@ -0,0 +1,202 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Errors returned by outlet lookups and checks
+var (
+	ErrOutletNotFound         = errors.New("outlet not found")
+	ErrOutletClosed           = errors.New("outlet is closed")
+	ErrOutletRejectsPackage   = errors.New("outlet does not accept breakfast packages")
+	ErrOutletPropertyMismatch = errors.New("outlet belongs to a different property")
+)
+
+type OutletService struct {
+	db *gorm.DB
+}
+
+func NewOutletService(db *gorm.DB) *OutletService {
+	return &OutletService{
+		db: db,
+	}
+}
+
+// GetOutlets retrieves all outlets for a property
+func (s *OutletService) GetOutlets(propertyID string) ([]models.Outlet, error) {
+	var outlets []models.Outlet
+	err := s.db.Where("property_id = ?", propertyID).
+		Order("name ASC").
+		Find(&outlets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch outlets: %w", err)
+	}
+	return outlets, nil
+}
+
+// GetOutlet retrieves an outlet by ID
+func (s *OutletService) GetOutlet(outletID uint) (*models.Outlet, error) {
+	var outlet models.Outlet
+	err := s.db.First(&outlet, outletID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOutletNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch outlet: %w", err)
+	}
+	return &outlet, nil
+}
+
+// CreateOutlet creates a new outlet
+func (s *OutletService) CreateOutlet(outlet *models.Outlet) error {
+	if outlet.PropertyID == "" {
+		return fmt.Errorf("property_id is required")
+	}
+	if outlet.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	openTime, closeTime, err := normalizeServiceHours(outlet.OpenTime, outlet.CloseTime)
+	if err != nil {
+		return err
+	}
+	outlet.OpenTime, outlet.CloseTime = openTime, closeTime
+
+	acceptsPackage, isActive := outlet.AcceptsPackage, outlet.IsActive
+	if err := s.db.Create(outlet).Error; err != nil {
+		return fmt.Errorf("failed to create outlet: %w", err)
+	}
+
+	// Zero-valued flags are replaced by the column defaults on insert
+	if !acceptsPackage || !isActive {
+		err := s.db.Model(outlet).Updates(map[string]interface{}{
+			"accepts_package": acceptsPackage,
+			"is_active":       isActive,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to create outlet: %w", err)
+		}
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":     "OutletService",
+		"method":      "CreateOutlet",
+		"property_id": outlet.PropertyID,
+		"outlet_id":   outlet.ID,
+	}).Info("Successfully created outlet")
+
+	return nil
+}
+
+// UpdateOutlet applies column updates to an existing outlet
+func (s *OutletService) UpdateOutlet(outletID uint, updates map[string]interface{}) (*models.Outlet, error) {
+	outlet, err := s.GetOutlet(outletID)
+	if err != nil {
+		return nil, err
+	}
+
+	openTime, closeTime := outlet.OpenTime, outlet.CloseTime
+	if value, ok := updates["open_time"].(string); ok {
+		openTime = value
+	}
+	if value, ok := updates["close_time"].(string); ok {
+		closeTime = value
+	}
+	openTime, closeTime, err = normalizeServiceHours(openTime, closeTime)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := updates["open_time"]; ok {
+		updates["open_time"] = openTime
+	}
+	if _, ok := updates["close_time"]; ok {
+		updates["close_time"] = closeTime
+	}
+
+	// Outlets cannot move between properties
+	delete(updates, "id")
+	delete(updates, "property_id")
+
+	if len(updates) > 0 {
+		if err := s.db.Model(outlet).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update outlet: %w", err)
+		}
+	}
+
+	return s.GetOutlet(outletID)
+}
+
+// DeleteOutlet soft-deletes an outlet
+func (s *OutletService) DeleteOutlet(outletID uint) error {
+	result := s.db.Delete(&models.Outlet{}, outletID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete outlet: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrOutletNotFound
+	}
+	return nil
+}
+
+// resolveOutlet loads an outlet and checks it can serve a package breakfast at the given time
+func resolveOutlet(tx *gorm.DB, propertyID string, outletID uint, at time.Time) (*models.Outlet, error) {
+	var outlet models.Outlet
+	if err := tx.First(&outlet, outletID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOutletNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch outlet: %w", err)
+	}
+
+	if outlet.PropertyID != propertyID {
+		return nil, ErrOutletPropertyMismatch
+	}
+	if !outlet.AcceptsPackage {
+		return nil, ErrOutletRejectsPackage
+	}
+	if !outlet.IsActive || !outletOpenAt(&outlet, at) {
+		return nil, fmt.Errorf("%w: %s serves %s-%s", ErrOutletClosed, outlet.Name, outlet.OpenTime, outlet.CloseTime)
+	}
+
+	return &outlet, nil
+}
+
+// outletOpenAt reports whether the outlet's service hours include the given time.
+// Outlets without configured hours are always open.
+func outletOpenAt(outlet *models.Outlet, at time.Time) bool {
+	openMinutes, closeMinutes := clockMinutes(outlet.OpenTime), clockMinutes(outlet.CloseTime)
+	if openMinutes < 0 || closeMinutes < 0 {
+		return true
+	}
+
+	current := at.Hour()*60 + at.Minute()
+	if openMinutes <= closeMinutes {
+		return current >= openMinutes && current < closeMinutes
+	}
+	// Service hours wrap past midnight
+	return current >= openMinutes || current < closeMinutes
+}
+
+// normalizeServiceHours checks that open and close times use the HH:MM format
+// and returns them zero-padded, so "7:00" is stored as "07:00"
+func normalizeServiceHours(openTime, closeTime string) (string, string, error) {
+	values := []string{openTime, closeTime}
+	for i, value := range values {
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse("15:04", value)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid service time %q, use HH:MM", value)
+		}
+		values[i] = t.Format("15:04")
+	}
+	return values[0], values[1], nil
+}
@@ -0,0 +1,550 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"hudini-breakfast-module/internal/config"
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+	"hudini-breakfast-module/internal/qrcode"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Errors returned when a breakfast pass cannot be issued, delivered or redeemed
+var (
+	ErrPassNotFound        = errors.New("breakfast pass not found")
+	ErrPassGuestNotFound   = errors.New("guest not found")
+	ErrPassInvalid         = errors.New("breakfast pass is not valid")
+	ErrPassRevoked         = errors.New("breakfast pass has been revoked")
+	ErrPassNotYetValid     = errors.New("breakfast pass is not valid yet")
+	ErrPassExpired         = errors.New("breakfast pass has expired")
+	ErrPassUsedToday       = errors.New("breakfast pass has already been used today")
+	ErrPassNoneActive      = errors.New("guest has no active breakfast passes")
+	ErrPassChannel         = errors.New("delivery channel must be email or sms")
+	ErrPassNoContact       = errors.New("guest has no contact details for this channel")
+	ErrPassNoProvider      = errors.New("no provider configured for this channel")
+	ErrPassSigningDisabled = errors.New("breakfast pass signing secret is not configured")
+)
+
+// Breakfast pass states
+const (
+	PassStatusActive  = "active"
+	PassStatusRevoked = "revoked"
+)
+
+// passImageScale is the size of one QR module in pixels in pass images
+const passImageScale = 8
+
+// PassService issues signed QR breakfast passes and redeems them at the outlet
+type PassService struct {
+	db       *gorm.DB
+	email    EmailProvider
+	sms      SMSProvider
+	listener ConsumptionListener
+	config   config.PassConfig
+}
+
+// IssuedPass is a pass with the token encoded in its QR code
+type IssuedPass struct {
+	models.BreakfastPass
+	Token    string `json:"token"`
+	ImageURL string `json:"image_url"`
+}
+
+// RedeemPassRequest describes a pass scanned at an outlet
+type RedeemPassRequest struct {
+	Token    string
+	StaffID  uint
+	OutletID uint
+	Covers   CoverRequest
+}
+
+// PassRedemption is the visit recorded for a redeemed pass
+type PassRedemption struct {
+	Pass        models.BreakfastPass              `json:"pass"`
+	Guest       models.Guest                      `json:"guest"`
+	Consumption *models.DailyBreakfastConsumption `json:"consumption"`
+}
+
+// passClaims are the fields signed into a pass token
+type passClaims struct {
+	PassID   uint
+	GuestID  uint
+	CheckIn  string
+	CheckOut string
+	Cover    int
+}
+
+// NewPassService creates a new breakfast pass service
+func NewPassService(db *gorm.DB, cfg config.PassConfig) *PassService {
+	return &PassService{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// SetProviders sets the providers passes are delivered through
+func (s *PassService) SetProviders(email EmailProvider, sms SMSProvider) {
+	s.email = email
+	s.sms = sms
+}
+
+// SetConsumptionListener sets who is told when passes are redeemed
+func (s *PassService) SetConsumptionListener(listener ConsumptionListener) {
+	s.listener = listener
+}
+
+// IssuePasses issues passes for a guest's stay: one for the room, or one per
+// cover when perCover is set. Passes issued to the guest earlier are revoked,
+// so re-issuing after a stay change or a lost phone leaves one valid set.
+func (s *PassService) IssuePasses(guestID uint, perCover bool, issuedBy uint) ([]IssuedPass, error) {
+	if s.config.SigningSecret == "" {
+		return nil, ErrPassSigningDisabled
+	}
+
+	guest, err := s.passGuest(guestID)
+	if err != nil {
+		return nil, err
+	}
+
+	clock, err := LoadPropertyClock(s.db, guest.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Passes are valid from the start of the arrival business date to the end of the departure one
+	validFrom := clock.StartOf(calendarDate(guest.CheckInDate))
+	expiresAt := clock.StartOf(calendarDate(guest.CheckOutDate).AddDate(0, 0, 1))
+	if !clock.Now().Before(expiresAt) {
+		return nil, ErrPassExpired
+	}
+
+	coverNumbers := []int{0}
+	if perCover {
+		coverNumbers = coverNumbers[:0]
+		for i := 1; i <= packageCovers(guest); i++ {
+			coverNumbers = append(coverNumbers, i)
+		}
+	}
+
+	now := time.Now()
+	passes := make([]models.BreakfastPass, 0, len(coverNumbers))
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.BreakfastPass{}).
+			Where("guest_id = ? AND status = ?", guest.ID, PassStatusActive).
+			Updates(map[string]interface{}{
+				"status":     PassStatusRevoked,
+				"revoked_at": now,
+				"revoked_by": issuedBy,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to revoke earlier passes: %w", err)
+		}
+
+		for _, cover := range coverNumbers {
+			pass := models.BreakfastPass{
+				GuestID:     guest.ID,
+				PropertyID:  guest.PropertyID,
+				RoomNumber:  guest.RoomNumber,
+				CoverNumber: cover,
+				ValidFrom:   validFrom,
+				ExpiresAt:   expiresAt,
+				Status:      PassStatusActive,
+				IssuedBy:    issuedBy,
+			}
+			if err := tx.Create(&pass).Error; err != nil {
+				return fmt.Errorf("failed to create breakfast pass: %w", err)
+			}
+			passes = append(passes, pass)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":     "PassService",
+		"method":      "IssuePasses",
+		"guest_id":    guest.ID,
+		"property_id": guest.PropertyID,
+		"room_number": guest.RoomNumber,
+		"passes":      len(passes),
+		"issued_by":   issuedBy,
+	}).Info("Issued breakfast passes")
+
+	return s.withTokens(passes, guest), nil
+}
+
+// GetPasses returns a guest's passes, newest first
+func (s *PassService) GetPasses(guestID uint, activeOnly bool) ([]IssuedPass, error) {
+	guest, err := s.passGuest(guestID)
+	if err != nil {
+		return nil, err
+	}
+	return s.passesFor(guest, activeOnly)
+}
+
+func (s *PassService) passesFor(guest *models.Guest, activeOnly bool) ([]IssuedPass, error) {
+	query := s.db.Where("guest_id = ?", guest.ID)
+	if activeOnly {
+		query = query.Where("status = ?", PassStatusActive)
+	}
+
+	var passes []models.BreakfastPass
+	if err := query.Order("created_at DESC, cover_number ASC").Find(&passes).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch breakfast passes: %w", err)
+	}
+	return s.withTokens(passes, guest), nil
+}
+
+// RevokePass revokes a pass so that it can no longer be redeemed
+func (s *PassService) RevokePass(passID, staffID uint) (*models.BreakfastPass, error) {
+	var pass models.BreakfastPass
+	if err := s.db.First(&pass, passID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPassNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch breakfast pass: %w", err)
+	}
+	if pass.Status == PassStatusRevoked {
+		return &pass, nil
+	}
+
+	now := time.Now()
+	pass.Status = PassStatusRevoked
+	pass.RevokedAt = &now
+	pass.RevokedBy = &staffID
+	if err := s.db.Save(&pass).Error; err != nil {
+		return nil, fmt.Errorf("failed to revoke breakfast pass: %w", err)
+	}
+	return &pass, nil
+}
+
+// SendPasses delivers a guest's active passes by email or SMS
+func (s *PassService) SendPasses(ctx context.Context, guestID uint, channel string) ([]IssuedPass, error) {
+	guest, err := s.passGuest(guestID)
+	if err != nil {
+		return nil, err
+	}
+	passes, err := s.passesFor(guest, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(passes) == 0 {
+		return nil, ErrPassNoneActive
+	}
+
+	var lines []string
+	for _, pass := range passes {
+		label := "Room pass"
+		if pass.CoverNumber > 0 {
+			label = fmt.Sprintf("Guest %d", pass.CoverNumber)
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", label, pass.ImageURL))
+	}
+
+	switch channel {
+	case "email":
+		if s.email == nil {
+			return nil, ErrPassNoProvider
+		}
+		if guest.Email == "" {
+			return nil, ErrPassNoContact
+		}
+		body := fmt.Sprintf("Dear %s,\n\nYour breakfast passes for room %s are valid from %s to %s. Show the QR code at the restaurant:\n\n%s\n",
+			guest.FirstName, guest.RoomNumber,
+			guest.CheckInDate.Format("2 Jan 2006"), guest.CheckOutDate.Format("2 Jan 2006"),
+			strings.Join(lines, "\n"))
+		if err := s.email.Send(ctx, guest.Email, "Your breakfast pass", body); err != nil {
+			return nil, fmt.Errorf("failed to email breakfast passes: %w", err)
+		}
+	case "sms":
+		if s.sms == nil {
+			return nil, ErrPassNoProvider
+		}
+		if guest.Phone == "" {
+			return nil, ErrPassNoContact
+		}
+		message := fmt.Sprintf("Your breakfast pass for room %s: %s", guest.RoomNumber, strings.Join(lines, " "))
+		if err := s.sms.Send(ctx, guest.Phone, message); err != nil {
+			return nil, fmt.Errorf("failed to text breakfast passes: %w", err)
+		}
+	default:
+		return nil, ErrPassChannel
+	}
+
+	now := time.Now()
+	ids := make([]uint, len(passes))
+	for i := range passes {
+		ids[i] = passes[i].ID
+		passes[i].DeliveredVia = channel
+		passes[i].DeliveredAt = &now
+	}
+	err = s.db.Model(&models.BreakfastPass{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{"delivered_via": channel, "delivered_at": now}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to record pass delivery: %w", err)
+	}
+
+	return passes, nil
+}
+
+// PassImage renders an active pass token as a PNG QR code
+func (s *PassService) PassImage(token string) ([]byte, error) {
+	claims, err := s.verifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.activePass(s.db, claims); err != nil {
+		return nil, err
+	}
+
+	code, err := qrcode.Encode([]byte(token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode breakfast pass: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := code.WritePNG(&buf, passImageScale); err != nil {
+		return nil, fmt.Errorf("failed to render breakfast pass: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Redeem verifies a scanned pass and records the visit.
+//
+// The signature, the stay the pass was issued for and its validity window are
+// checked first. The pass is then claimed with a conditional update in the same
+// transaction as the visit, so concurrent scans of one pass are serialized and
+// a pass revoked mid-scan is refused. Room passes serve what is left of the
+// day's entitlement; cover passes admit one cover a day.
+func (s *PassService) Redeem(ctx context.Context, req RedeemPassRequest) (*PassRedemption, error) {
+	claims, err := s.verifyToken(req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.db.WithContext(ctx)
+	pass, err := s.activePass(db, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, err := LoadEligibilityEngine(db, pass.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+	now := engine.Now()
+	if now.Before(pass.ValidFrom) {
+		return nil, ErrPassNotYetValid
+	}
+	if !now.Before(pass.ExpiresAt) {
+		return nil, ErrPassExpired
+	}
+
+	result := &PassRedemption{}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var guest models.Guest
+		if err := tx.First(&guest, pass.GuestID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPassInvalid
+			}
+			return fmt.Errorf("failed to fetch guest: %w", err)
+		}
+
+		// A pass is scoped to the stay it was issued for; changed stays need new passes
+		if guest.CheckInDate.Format("20060102") != claims.CheckIn || guest.CheckOutDate.Format("20060102") != claims.CheckOut {
+			return fmt.Errorf("%w: stay dates have changed since it was issued", ErrPassInvalid)
+		}
+		if !guest.IsActive {
+			return fmt.Errorf("%w: guest is no longer in house", ErrNotEligible)
+		}
+
+		claim := tx.Model(&models.BreakfastPass{}).
+			Where("id = ? AND status = ?", pass.ID, PassStatusActive).
+			Updates(map[string]interface{}{
+				"redeem_count":     gorm.Expr("redeem_count + 1"),
+				"last_redeemed_at": now,
+			})
+		if claim.Error != nil {
+			return fmt.Errorf("failed to claim breakfast pass: %w", claim.Error)
+		}
+		if claim.RowsAffected == 0 {
+			return ErrPassRevoked
+		}
+
+		covers := req.Covers
+		if pass.CoverNumber > 0 {
+			var used int64
+			err := tx.Model(&models.DailyBreakfastConsumption{}).
+				Where("pass_id = ? AND DATE(consumption_date) = ? AND status = 'consumed'",
+					pass.ID, engine.clock.BusinessDate(now).Format("2006-01-02")).
+				Count(&used).Error
+			if err != nil {
+				return fmt.Errorf("failed to check pass use: %w", err)
+			}
+			if used > 0 {
+				return ErrPassUsedToday
+			}
+
+			switch {
+			case covers.Adults+covers.Children > 1:
+				return fmt.Errorf("%w: a cover pass admits one cover", ErrCoversExceeded)
+			case covers.Adults+covers.Children == 0 && pass.CoverNumber > guest.AdultCount && guest.ChildCount > 0:
+				covers.Children = 1
+			case covers.Adults+covers.Children == 0:
+				covers.Adults = 1
+			}
+		}
+
+		visit, err := recordVisit(tx, engine, &guest, visitRequest{
+			StaffID:       req.StaffID,
+			OutletID:      req.OutletID,
+			PaymentMethod: "room_charge",
+			Covers:        covers,
+			At:            now,
+			PassID:        &pass.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		pass.RedeemCount++
+		pass.LastRedeemedAt = &now
+		result.Pass = *pass
+		result.Guest = guest
+		result.Consumption = visit.Consumption
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":        "PassService",
+		"method":         "Redeem",
+		"pass_id":        pass.ID,
+		"guest_id":       pass.GuestID,
+		"property_id":    pass.PropertyID,
+		"staff_id":       req.StaffID,
+		"consumption_id": result.Consumption.ID,
+	}).Info("Redeemed breakfast pass")
+
+	if s.listener != nil {
+		s.listener.ConsumptionChanged(pass.PropertyID, result.Consumption.ConsumptionDate)
+	}
+	return result, nil
+}
+
+func (s *PassService) passGuest(guestID uint) (*models.Guest, error) {
+	var guest models.Guest
+	if err := s.db.First(&guest, guestID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPassGuestNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch guest: %w", err)
+	}
+	return &guest, nil
+}
+
+// activePass loads the pass a verified token was issued for and checks it is still active
+func (s *PassService) activePass(db *gorm.DB, claims *passClaims) (*models.BreakfastPass, error) {
+	var pass models.BreakfastPass
+	if err := db.First(&pass, claims.PassID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPassInvalid
+		}
+		return nil, fmt.Errorf("failed to fetch breakfast pass: %w", err)
+	}
+	if pass.GuestID != claims.GuestID || pass.CoverNumber != claims.Cover {
+		return nil, ErrPassInvalid
+	}
+	if pass.Status != PassStatusActive {
+		return nil, ErrPassRevoked
+	}
+	return &pass, nil
+}
+
+func (s *PassService) withTokens(passes []models.BreakfastPass, guest *models.Guest) []IssuedPass {
+	issued := make([]IssuedPass, len(passes))
+	for i, pass := range passes {
+		token := s.signToken(passClaims{
+			PassID:   pass.ID,
+			GuestID:  guest.ID,
+			CheckIn:  guest.CheckInDate.Format("20060102"),
+			CheckOut: guest.CheckOutDate.Format("20060102"),
+			Cover:    pass.CoverNumber,
+		})
+		issued[i] = IssuedPass{
+			BreakfastPass: pass,
+			Token:         token,
+			ImageURL:      s.config.PublicURL + "/api/passes/qr.png?token=" + url.QueryEscape(token),
+		}
+	}
+	return issued
+}
+
+// Pass tokens are the base64url-encoded claims and their HMAC-SHA256, joined by a dot
+
+func (s *PassService) signToken(claims passClaims) string {
+	payload := fmt.Sprintf("%d:%d:%s:%s:%d", claims.PassID, claims.GuestID, claims.CheckIn, claims.CheckOut, claims.Cover)
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.mac(encoded))
+}
+
+func (s *PassService) verifyToken(token string) (*passClaims, error) {
+	if s.config.SigningSecret == "" {
+		return nil, ErrPassSigningDisabled
+	}
+
+	encoded, signature, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok {
+		return nil, ErrPassInvalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.mac(encoded)) {
+		return nil, ErrPassInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrPassInvalid
+	}
+
+	fields := strings.Split(string(payload), ":")
+	if len(fields) != 5 {
+		return nil, ErrPassInvalid
+	}
+	passID, err1 := strconv.ParseUint(fields[0], 10, 64)
+	guestID, err2 := strconv.ParseUint(fields[1], 10, 64)
+	cover, err3 := strconv.Atoi(fields[4])
+	if err1 != nil || err2 != nil || err3 != nil {
+		return nil, ErrPassInvalid
+	}
+
+	return &passClaims{
+		PassID:   uint(passID),
+		GuestID:  uint(guestID),
+		CheckIn:  fields[2],
+		CheckOut: fields[3],
+		Cover:    cover,
+	}, nil
+}
+
+func (s *PassService) mac(payload string) []byte {
+	h := hmac.New(sha256.New, []byte(s.config.SigningSecret))
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
@@ -0,0 +1,533 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"hudini-breakfast-module/internal/config"
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Errors returned by the planning service
+var (
+	ErrPlanDateOutOfRange = errors.New("plans can only be made for today and the forecast horizon")
+	ErrOutletNotForecast  = errors.New("only active outlets that accept the breakfast package are forecast")
+	ErrNoOutletManager    = errors.New("outlet has no manager with an email address")
+	ErrEmailNotConfigured = errors.New("no email provider configured")
+	ErrInvalidRatio       = errors.New("covers per hour must be positive and minimums, shift length and prep buffer cannot be negative")
+)
+
+// Ratios used for outlets that have not configured their own
+const (
+	defaultFOHCoversPerHour     = 20
+	defaultKitchenCoversPerHour = 30
+	defaultMinFOH               = 1
+	defaultMinKitchen           = 1
+	defaultShiftMinutes         = 120
+	defaultPrepBuffer           = 0.1
+)
+
+// PlanningService turns cover forecasts into staffing and prep plans
+type PlanningService struct {
+	db        *gorm.DB
+	forecasts *ForecastService
+	email     EmailProvider
+	config    config.PlanningConfig
+}
+
+// StaffingPlan is the recommended headcount and prep for one outlet and business date
+type StaffingPlan struct {
+	OutletID    uint                 `json:"outlet_id"`
+	OutletName  string               `json:"outlet_name"`
+	PropertyID  string               `json:"property_id"`
+	Date        string               `json:"date"`
+	Covers      float64              `json:"covers"`       // Forecast covers
+	CoversUpper float64              `json:"covers_upper"` // Upper end of the forecast, which headcount is planned for
+	Ratios      models.StaffingRatio `json:"ratios"`
+	Shifts      []ShiftPlan          `json:"shifts"`
+	Prep        []PrepItem           `json:"prep"`
+	GeneratedAt time.Time            `json:"generated_at"`
+}
+
+// ShiftPlan is the recommended headcount for one shift
+type ShiftPlan struct {
+	Start          string  `json:"start"`
+	End            string  `json:"end"`
+	Covers         float64 `json:"covers"`
+	PeakHourCovers float64 `json:"peak_hour_covers"` // Busiest hour starting in the shift, at the upper end of the forecast
+	FOH            int     `json:"foh"`
+	Kitchen        int     `json:"kitchen"`
+}
+
+// PrepItem is how much of a buffet item to prepare
+type PrepItem struct {
+	Code      string   `json:"code"`
+	Name      string   `json:"name"`
+	Unit      string   `json:"unit"`
+	PerCover  float64  `json:"per_cover"`
+	Quantity  float64  `json:"quantity"`
+	OnHand    *float64 `json:"on_hand,omitempty"` // nil when the outlet does not stock the item
+	Shortfall float64  `json:"shortfall"`
+}
+
+// NewPlanningService creates a new planning service
+func NewPlanningService(db *gorm.DB, forecasts *ForecastService, cfg config.PlanningConfig) *PlanningService {
+	return &PlanningService{
+		db:        db,
+		forecasts: forecasts,
+		config:    cfg,
+	}
+}
+
+// SetEmailProvider sets the provider plans are emailed through
+func (s *PlanningService) SetEmailProvider(email EmailProvider) {
+	s.email = email
+}
+
+// GetRatios returns an outlet's staffing ratios, or the defaults when it has none
+func (s *PlanningService) GetRatios(outletID uint) (*models.StaffingRatio, error) {
+	var outlet models.Outlet
+	if err := s.db.First(&outlet, outletID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOutletNotFound
+		}
+		return nil, fmt.Errorf("failed to get outlet: %w", err)
+	}
+	return s.ratios(&outlet)
+}
+
+// SetRatios creates or replaces an outlet's staffing ratios
+func (s *PlanningService) SetRatios(outletID uint, ratio *models.StaffingRatio) (*models.StaffingRatio, error) {
+	var outlet models.Outlet
+	if err := s.db.First(&outlet, outletID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOutletNotFound
+		}
+		return nil, fmt.Errorf("failed to get outlet: %w", err)
+	}
+	if ratio.FOHCoversPerHour <= 0 || ratio.KitchenCoversPerHour <= 0 ||
+		ratio.MinFOH < 0 || ratio.MinKitchen < 0 || ratio.ShiftMinutes < 0 || ratio.PrepBuffer < 0 {
+		return nil, ErrInvalidRatio
+	}
+	if ratio.ShiftMinutes == 0 {
+		ratio.ShiftMinutes = defaultShiftMinutes
+	}
+
+	ratio.ID = 0
+	ratio.OutletID = outlet.ID
+	ratio.PropertyID = outlet.PropertyID
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "outlet_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"foh_covers_per_hour", "kitchen_covers_per_hour", "min_foh", "min_kitchen", "shift_minutes", "prep_buffer", "updated_at"}),
+	}).Create(ratio).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save staffing ratios: %w", err)
+	}
+
+	return s.ratios(&outlet)
+}
+
+// Plan recommends headcount per shift and a prep list for an outlet on a business date
+func (s *PlanningService) Plan(ctx context.Context, outletID uint, date time.Time) (*StaffingPlan, error) {
+	var outlet models.Outlet
+	if err := s.db.First(&outlet, outletID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOutletNotFound
+		}
+		return nil, fmt.Errorf("failed to get outlet: %w", err)
+	}
+
+	clock, err := LoadPropertyClock(s.db, outlet.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+	date = calendarDate(date)
+	days := int(date.Sub(clock.Today()).Hours()/24) + 1
+	if days < 1 || days > maxForecastHorizon {
+		return nil, ErrPlanDateOutOfRange
+	}
+
+	forecast, err := s.forecasts.Forecast(ctx, outlet.PropertyID, days)
+	if err != nil {
+		return nil, err
+	}
+	var outletForecast *OutletForecast
+	day := forecast.Days[len(forecast.Days)-1]
+	for i := range day.Outlets {
+		if day.Outlets[i].OutletID == outlet.ID {
+			outletForecast = &day.Outlets[i]
+		}
+	}
+	if outletForecast == nil {
+		return nil, ErrOutletNotForecast
+	}
+
+	ratio, err := s.ratios(&outlet)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &StaffingPlan{
+		OutletID:    outlet.ID,
+		OutletName:  outlet.Name,
+		PropertyID:  outlet.PropertyID,
+		Date:        day.Date,
+		Covers:      outletForecast.Predicted,
+		CoversUpper: outletForecast.Upper,
+		Ratios:      *ratio,
+		Shifts:      planShifts(&outlet, outletForecast, ratio),
+		GeneratedAt: time.Now(),
+	}
+
+	plan.Prep, err = s.prepList(&outlet, outletForecast.Predicted*(1+ratio.PrepBuffer))
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// EmailPlan emails an outlet's plan for a business date to its manager
+func (s *PlanningService) EmailPlan(ctx context.Context, outletID uint, date time.Time) (*models.PlanEmail, error) {
+	if s.email == nil {
+		return nil, ErrEmailNotConfigured
+	}
+
+	var outlet models.Outlet
+	if err := s.db.First(&outlet, outletID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOutletNotFound
+		}
+		return nil, fmt.Errorf("failed to get outlet: %w", err)
+	}
+	if outlet.ManagerID == nil {
+		return nil, ErrNoOutletManager
+	}
+	var manager models.Staff
+	err := s.db.Where("id = ? AND is_active = ?", *outlet.ManagerID, true).First(&manager).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && manager.Email == "") {
+		return nil, ErrNoOutletManager
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outlet manager: %w", err)
+	}
+
+	plan, err := s.Plan(ctx, outletID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := fmt.Sprintf("Breakfast plan for %s, %s", outlet.Name, plan.Date)
+	if err := s.email.Send(ctx, manager.Email, subject, FormatPlanText(plan)); err != nil {
+		return nil, fmt.Errorf("failed to email plan: %w", err)
+	}
+
+	record := models.PlanEmail{
+		OutletID:   outlet.ID,
+		PlanDate:   calendarDate(date),
+		PropertyID: outlet.PropertyID,
+		SentTo:     manager.Email,
+		SentAt:     time.Now(),
+	}
+	err = s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "outlet_id"}, {Name: "plan_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"sent_to", "sent_at"}),
+	}).Create(&record).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to record plan email: %w", err)
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":   "PlanningService",
+		"method":    "EmailPlan",
+		"outlet_id": outlet.ID,
+		"plan_date": plan.Date,
+		"sent_to":   manager.Email,
+	}).Info("Staffing plan emailed")
+
+	return &record, nil
+}
+
+// SendDuePlans emails the next day's plan to the manager of every active
+// outlet once the property's local time passes the configured email time
+func (s *PlanningService) SendDuePlans(ctx context.Context) {
+	var outlets []models.Outlet
+	err := s.db.Where("is_active = ? AND accepts_package = ? AND manager_id IS NOT NULL", true, true).Find(&outlets).Error
+	if err != nil {
+		logging.WithError(err).Error("Failed to fetch outlets for plan emails")
+		return
+	}
+
+	emailTime := s.config.EmailTime
+	if _, err := time.Parse("15:04", emailTime); err != nil {
+		emailTime = "16:00"
+	}
+
+	clocks := make(map[string]*PropertyClock)
+	for _, outlet := range outlets {
+		clock, ok := clocks[outlet.PropertyID]
+		if !ok {
+			clock, err = LoadPropertyClock(s.db, outlet.PropertyID)
+			if err != nil {
+				logging.WithError(err).WithField("property_id", outlet.PropertyID).Error("Failed to load property clock for plan emails")
+				continue
+			}
+			clocks[outlet.PropertyID] = clock
+		}
+		if clock.Now().Format("15:04") < emailTime {
+			continue
+		}
+
+		tomorrow := clock.Today().AddDate(0, 0, 1)
+		var sent int64
+		err := s.db.Model(&models.PlanEmail{}).
+			Where("outlet_id = ? AND DATE(plan_date) = ?", outlet.ID, tomorrow.Format("2006-01-02")).
+			Count(&sent).Error
+		if err != nil || sent > 0 {
+			continue
+		}
+
+		if _, err := s.EmailPlan(ctx, outlet.ID, tomorrow); err != nil {
+			logging.WithFields(logrus.Fields{
+				"service":   "PlanningService",
+				"method":    "SendDuePlans",
+				"outlet_id": outlet.ID,
+				"error":     err.Error(),
+			}).Warn("Failed to email staffing plan")
+		}
+	}
+}
+
+// StartEmailScheduler periodically emails the next day's plans
+func (s *PlanningService) StartEmailScheduler(ctx context.Context) {
+	interval := s.config.EmailInterval
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logging.Info("Plan email scheduler stopped")
+			return
+		case <-ticker.C:
+			s.SendDuePlans(ctx)
+		}
+	}
+}
+
+// ratios returns an outlet's saved ratios, or the defaults
+func (s *PlanningService) ratios(outlet *models.Outlet) (*models.StaffingRatio, error) {
+	var ratio models.StaffingRatio
+	err := s.db.Where("outlet_id = ?", outlet.ID).First(&ratio).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.StaffingRatio{
+			OutletID:             outlet.ID,
+			PropertyID:           outlet.PropertyID,
+			FOHCoversPerHour:     defaultFOHCoversPerHour,
+			KitchenCoversPerHour: defaultKitchenCoversPerHour,
+			MinFOH:               defaultMinFOH,
+			MinKitchen:           defaultMinKitchen,
+			ShiftMinutes:         defaultShiftMinutes,
+			PrepBuffer:           defaultPrepBuffer,
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staffing ratios: %w", err)
+	}
+	return &ratio, nil
+}
+
+// prepList scales the outlet's per-cover usage to the given covers
+func (s *PlanningService) prepList(outlet *models.Outlet, covers float64) ([]PrepItem, error) {
+	var usage []models.InventoryUsage
+	err := s.db.Where("property_id = ? AND menu_type = ?", outlet.PropertyID, outlet.MenuType).
+		Order("code").Find(&usage).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory usage: %w", err)
+	}
+
+	var items []models.InventoryItem
+	if err := s.db.Where("outlet_id = ? AND is_active = ?", outlet.ID, true).Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to get inventory items: %w", err)
+	}
+	stocked := make(map[string]models.InventoryItem, len(items))
+	for _, item := range items {
+		stocked[item.Code] = item
+	}
+
+	prep := []PrepItem{}
+	for _, use := range usage {
+		entry := PrepItem{
+			Code:     use.Code,
+			Name:     use.Code,
+			PerCover: use.PerCover,
+			Quantity: math.Ceil(covers*use.PerCover*100) / 100,
+		}
+		if item, ok := stocked[use.Code]; ok {
+			onHand := item.OnHand
+			entry.Name = item.Name
+			entry.Unit = item.Unit
+			entry.OnHand = &onHand
+			entry.Shortfall = roundTo(math.Max(entry.Quantity-onHand, 0), 2)
+		}
+		prep = append(prep, entry)
+	}
+	return prep, nil
+}
+
+// planShifts splits the outlet's service into shifts and staffs each for its
+// busiest hour at the upper end of the forecast
+func planShifts(outlet *models.Outlet, forecast *OutletForecast, ratio *models.StaffingRatio) []ShiftPlan {
+	open, closing := serviceWindow(outlet, forecast.Slots)
+	shiftMinutes := ratio.ShiftMinutes
+	if shiftMinutes <= 0 {
+		shiftMinutes = defaultShiftMinutes
+	}
+
+	upperScale := 1.0
+	if forecast.Predicted > 0 {
+		upperScale = forecast.Upper / forecast.Predicted
+	}
+	covers := make(map[int]float64, len(forecast.Slots))
+	for _, slot := range forecast.Slots {
+		covers[clockMinutes(slot.Start)] = slot.Predicted
+	}
+
+	var shifts []ShiftPlan
+	for start := open; start < closing; start += shiftMinutes {
+		end := start + shiftMinutes
+		if end > closing {
+			end = closing
+		}
+
+		shift := ShiftPlan{Start: minutesClock(start), End: minutesClock(end)}
+		for minute := start; minute < end; minute += forecastSlotMinutes {
+			shift.Covers += covers[minute]
+
+			hour := 0.0
+			for offset := 0; offset < 60; offset += forecastSlotMinutes {
+				hour += covers[minute+offset]
+			}
+			shift.PeakHourCovers = math.Max(shift.PeakHourCovers, hour*upperScale)
+		}
+		shift.Covers = roundTo(shift.Covers, 1)
+		shift.PeakHourCovers = roundTo(shift.PeakHourCovers, 1)
+		shift.FOH = headcount(shift.PeakHourCovers, ratio.FOHCoversPerHour, ratio.MinFOH)
+		shift.Kitchen = headcount(shift.PeakHourCovers, ratio.KitchenCoversPerHour, ratio.MinKitchen)
+		shifts = append(shifts, shift)
+	}
+	return shifts
+}
+
+// serviceWindow returns the minutes after midnight an outlet's service spans,
+// widened to any slot the forecast expects covers in
+func serviceWindow(outlet *models.Outlet, slots []ForecastSlot) (int, int) {
+	open, closing := clockMinutes(outlet.OpenTime), clockMinutes(outlet.CloseTime)
+	if open < 0 || closing <= open {
+		open, closing = -1, -1
+	}
+	for _, slot := range slots {
+		start := clockMinutes(slot.Start)
+		if open < 0 || start < open {
+			open = start
+		}
+		if start+forecastSlotMinutes > closing {
+			closing = start + forecastSlotMinutes
+		}
+	}
+	if open < 0 {
+		return 7 * 60, 10 * 60
+	}
+	return open, closing
+}
+
+func headcount(covers, perStaff float64, minimum int) int {
+	needed := 0
+	if perStaff > 0 {
+		needed = int(math.Ceil(covers / perStaff))
+	}
+	if needed < minimum {
+		needed = minimum
+	}
+	return needed
+}
+
+// clockMinutes parses "HH:MM" into minutes after midnight, or -1
+func clockMinutes(value string) int {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return -1
+	}
+	return t.Hour()*60 + t.Minute()
+}
+
+func minutesClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// WritePlanCSV writes a plan as two CSV tables, shifts then prep, separated by a blank line
+func WritePlanCSV(w io.Writer, plan *StaffingPlan) error {
+	writer := csv.NewWriter(w)
+	format := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	writer.Write([]string{"outlet", "date", "shift_start", "shift_end", "covers", "peak_hour_covers", "foh", "kitchen"})
+	for _, shift := range plan.Shifts {
+		writer.Write([]string{plan.OutletName, plan.Date, shift.Start, shift.End, format(shift.Covers),
+			format(shift.PeakHourCovers), strconv.Itoa(shift.FOH), strconv.Itoa(shift.Kitchen)})
+	}
+	writer.Write(nil)
+
+	writer.Write([]string{"code", "item", "unit", "per_cover", "quantity", "on_hand", "shortfall"})
+	for _, item := range plan.Prep {
+		onHand := ""
+		if item.OnHand != nil {
+			onHand = format(*item.OnHand)
+		}
+		writer.Write([]string{item.Code, item.Name, item.Unit, format(item.PerCover), format(item.Quantity),
+			onHand, format(item.Shortfall)})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// FormatPlanText renders a plan as the plain-text body of the manager email
+func FormatPlanText(plan *StaffingPlan) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s breakfast plan for %s\n\n", plan.OutletName, plan.Date)
+	fmt.Fprintf(&b, "Forecast covers: %.0f (plan for up to %.0f)\n\n", plan.Covers, plan.CoversUpper)
+
+	b.WriteString("Staffing\n")
+	for _, shift := range plan.Shifts {
+		fmt.Fprintf(&b, "  %s-%s  %3.0f covers  FOH %d  Kitchen %d\n", shift.Start, shift.End, shift.Covers, shift.FOH, shift.Kitchen)
+	}
+
+	if len(plan.Prep) > 0 {
+		fmt.Fprintf(&b, "\nPrep (includes %.0f%% buffer)\n", plan.Ratios.PrepBuffer*100)
+		for _, item := range plan.Prep {
+			fmt.Fprintf(&b, "  %-24s %8.2f %s", item.Name, item.Quantity, item.Unit)
+			if item.Shortfall > 0 {
+				fmt.Fprintf(&b, "  (short %.2f)", item.Shortfall)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
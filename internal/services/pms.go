@@ -2,10 +2,12 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 
 	"hudini-breakfast-module/internal/config"
@@ -28,6 +30,8 @@ type PMSGuestProfile struct {
 	CheckInDate     time.Time `json:"check_in_date"`
 	CheckOutDate    time.Time `json:"check_out_date"`
 	BreakfastPackage bool     `json:"breakfast_package"`
+	RateCode        string    `json:"rate_code"`
+	LoyaltyTier     string    `json:"loyalty_tier"`
 	PropertyID      string    `json:"property_id"`
 	Status          string    `json:"status"` // checked_in, checked_out, no_show
 }
@@ -42,6 +46,7 @@ type PMSChargeRequest struct {
 	TransactionDate string  `json:"transaction_date"`
 	DepartmentCode  string  `json:"department_code"`
 	PropertyID      string  `json:"property_id"`
+	TaxAmount       float64 `json:"tax_amount"`
 }
 
 type PMSChargeResponse struct {
@@ -188,6 +193,35 @@ func (s *PMSService) PostCharge(charge PMSChargeRequest) (*PMSChargeResponse, er
 	return &response, nil
 }
 
+// VoidCharge reverses a charge posted with PostCharge. The transaction ID is
+// sent as the idempotency key, so the PMS reverses a charge only once however
+// often the reversal is retried.
+func (s *PMSService) VoidCharge(ctx context.Context, transactionID string) error {
+	endpoint := fmt.Sprintf("%s/charges/%s/void", s.config.PMSIntegration.BaseURL, url.PathEscape(transactionID))
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.config.PMSIntegration.APIKey))
+	req.Header.Set("X-Property-ID", s.config.PMSIntegration.PropertyID)
+	req.Header.Set("Idempotency-Key", "VOID-"+transactionID)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("PMS void API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("PMS void API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
 // SyncGuest synchronizes guest data from PMS to local database
 func (s *PMSService) SyncGuest(pmsGuest PMSGuestProfile) (*models.Guest, error) {
 	guest := &models.Guest{
@@ -201,6 +235,8 @@ func (s *PMSService) SyncGuest(pmsGuest PMSGuestProfile) (*models.Guest, error)
 		CheckInDate:      pmsGuest.CheckInDate,
 		CheckOutDate:     pmsGuest.CheckOutDate,
 		BreakfastPackage: pmsGuest.BreakfastPackage,
+		RateCode:         pmsGuest.RateCode,
+		LoyaltyTier:      pmsGuest.LoyaltyTier,
 		PropertyID:       pmsGuest.PropertyID,
 		IsActive:         pmsGuest.Status == "checked_in",
 	}
@@ -209,7 +245,8 @@ func (s *PMSService) SyncGuest(pmsGuest PMSGuestProfile) (*models.Guest, error)
 }
 
 // ValidateGuestEligibility checks if guest is eligible for breakfast consumption
-func (s *PMSService) ValidateGuestEligibility(guestID string) (bool, string, error) {
+// now, using the eligibility rules of the guest's property
+func (s *PMSService) ValidateGuestEligibility(guestID string, engine *EligibilityEngine) (bool, string, error) {
 	guest, err := s.GetGuestByID(guestID)
 	if err != nil {
 		return false, "Failed to retrieve guest information", err
@@ -220,22 +257,18 @@ func (s *PMSService) ValidateGuestEligibility(guestID string) (bool, string, err
 		return false, "Guest is not currently checked in", nil
 	}
 
-	// Check if guest has breakfast package
-	if !guest.BreakfastPackage {
-		return false, "Guest does not have breakfast package", nil
-	}
-
-	// Check if it's within breakfast hours (business logic)
-	now := time.Now()
-	if now.Hour() < 6 || now.Hour() > 11 {
-		return false, "Breakfast service is not available at this time", nil
+	// Service hours are enforced per outlet; the rules decide entitlement
+	profile, err := s.SyncGuest(*guest)
+	if err != nil {
+		return false, "Failed to retrieve guest information", err
 	}
-
-	return true, "Guest is eligible for breakfast", nil
+	decision := engine.Evaluate(profile, engine.Now())
+	return decision.Eligible, decision.Reason, nil
 }
 
-// ChargeBreakfast is a convenience method to charge breakfast to a guest's room
-func (s *PMSService) ChargeBreakfast(guestID, reservationID, roomNumber string, amount float64, propertyID string) error {
+// ChargeBreakfast is a convenience method to charge breakfast to a guest's room,
+// dated on the business date the breakfast was served
+func (s *PMSService) ChargeBreakfast(guestID, reservationID, roomNumber string, amount, taxAmount float64, propertyID string, businessDate time.Time) (*PMSChargeResponse, error) {
 	charge := PMSChargeRequest{
 		GuestID:         guestID,
 		ReservationID:   reservationID,
@@ -243,11 +276,11 @@ func (s *PMSService) ChargeBreakfast(guestID, reservationID, roomNumber string,
 		ChargeCode:      "BRKFST",
 		Amount:          amount,
 		Description:     "Breakfast Service",
-		TransactionDate: time.Now().Format("2006-01-02"),
+		TransactionDate: businessDate.Format("2006-01-02"),
 		DepartmentCode:  "F&B",
 		PropertyID:      propertyID,
+		TaxAmount:       taxAmount,
 	}
 
-	_, err := s.PostCharge(charge)
-	return err
+	return s.PostCharge(charge)
 }
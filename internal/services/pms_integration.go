@@ -263,8 +263,22 @@ func (s *PMSIntegrationService) GetAllRooms(ctx context.Context, propertyID stri
 	return rooms, nil
 }
 
+// GetReservationsByDate retrieves the reservations in house or arriving on a date from PMS
+func (s *PMSIntegrationService) GetReservationsByDate(ctx context.Context, date time.Time) ([]middleware.Reservation, error) {
+	if s.defaultProvider == nil {
+		return nil, fmt.Errorf("no default PMS provider configured")
+	}
+	
+	reservations, err := s.defaultProvider.GetReservationsByDate(ctx, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservations: %w", err)
+	}
+	
+	return reservations, nil
+}
+
 // PostBreakfastCharge posts a breakfast charge to PMS
-func (s *PMSIntegrationService) PostBreakfastCharge(ctx context.Context, guestID, roomNumber string, amount float64) error {
+func (s *PMSIntegrationService) PostBreakfastCharge(ctx context.Context, guestID, roomNumber string, amount, taxAmount float64) error {
 	if s.defaultProvider == nil {
 		return fmt.Errorf("no default PMS provider configured")
 	}
@@ -279,6 +293,7 @@ func (s *PMSIntegrationService) PostBreakfastCharge(ctx context.Context, guestID
 		DepartmentCode:  "F&B",
 		PropertyID:      s.config.PMSIntegration.PropertyID,
 		Reference:       fmt.Sprintf("BREAKFAST-%s-%s", roomNumber, time.Now().Format("20060102")),
+		TaxAmount:       taxAmount,
 	}
 	
 	response, err := s.defaultProvider.PostCharge(ctx, charge)
@@ -294,6 +309,53 @@ func (s *PMSIntegrationService) PostBreakfastCharge(ctx context.Context, guestID
 	return nil
 }
 
+// PostRoomCharge posts an outlet charge to a guest's room and returns the PMS transaction ID
+func (s *PMSIntegrationService) PostRoomCharge(ctx context.Context, charge RoomCharge) (string, error) {
+	if s.defaultProvider == nil {
+		return "", fmt.Errorf("no default PMS provider configured")
+	}
+
+	request := &middleware.ChargeRequest{
+		GuestID:         charge.GuestID,
+		ReservationID:   charge.ReservationID,
+		RoomNumber:      charge.RoomNumber,
+		ChargeCode:      "BREAKFAST",
+		Amount:          charge.Amount,
+		Description:     charge.Description,
+		TransactionDate: time.Now(),
+		DepartmentCode:  "F&B",
+		PropertyID:      charge.PropertyID,
+		Reference:       charge.Reference,
+		TaxAmount:       charge.TaxAmount,
+	}
+
+	response, err := s.defaultProvider.PostCharge(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("failed to post room charge: %w", err)
+	}
+
+	if !response.Success {
+		return "", fmt.Errorf("charge posting failed: %s", response.Message)
+	}
+
+	s.logger.Info(fmt.Sprintf("Successfully posted %s for room %s: %s", charge.Reference, charge.RoomNumber, response.TransactionID))
+	return response.TransactionID, nil
+}
+
+// VoidCharge reverses a previously posted charge on the default provider
+func (s *PMSIntegrationService) VoidCharge(ctx context.Context, transactionID string) error {
+	if s.defaultProvider == nil {
+		return fmt.Errorf("no default PMS provider configured")
+	}
+	
+	if err := s.defaultProvider.VoidCharge(ctx, transactionID); err != nil {
+		return fmt.Errorf("failed to void charge %s: %w", transactionID, err)
+	}
+	
+	s.logger.Info(fmt.Sprintf("Successfully voided PMS charge: %s", transactionID))
+	return nil
+}
+
 // SyncRoomData synchronizes room data with PMS
 func (s *PMSIntegrationService) SyncRoomData(ctx context.Context, propertyID string) error {
 	if s.defaultProvider == nil {
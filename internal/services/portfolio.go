@@ -0,0 +1,673 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Errors returned by the portfolio service
+var (
+	ErrPropertyGroupNotFound  = errors.New("property group not found")
+	ErrGroupNameRequired      = errors.New("a property group needs a name")
+	ErrDuplicateGroupName     = errors.New("a property group with this name already exists")
+	ErrEmptyPortfolio         = errors.New("no properties match the portfolio selection")
+	ErrNotInPortfolio         = errors.New("property is not in the selected portfolio")
+	ErrUnknownPortfolioMetric = errors.New("metric must be one of covers, upsell_covers, revenue, revenue_per_cover, take_up_rate, avg_service_time, satisfaction_rate, occupancy_rate or no_show_rate")
+	ErrInvalidPortfolioRange  = errors.New("portfolio range must end on or after its start and span at most 366 days")
+)
+
+// defaultPortfolioMetric ranks properties when no metric is asked for
+const defaultPortfolioMetric = "revenue"
+
+// PortfolioService compares breakfast KPIs across a set of properties
+type PortfolioService struct {
+	db        *gorm.DB
+	executive *ExecutiveService
+	rollups   *RollupService
+}
+
+// PortfolioSelection picks the properties of a portfolio. An empty selection
+// is every property; otherwise a property must match every criterion given.
+type PortfolioSelection struct {
+	Region      string
+	Brand       string
+	GroupID     uint
+	PropertyIDs []string // A custom, unsaved group
+}
+
+// PortfolioKPIs are the breakfast KPIs of a property, or of a portfolio taken as one
+type PortfolioKPIs struct {
+	Covers           int     `json:"covers"`
+	UpsellCovers     int     `json:"upsell_covers"`
+	Revenue          float64 `json:"revenue"`
+	RevenuePerCover  float64 `json:"revenue_per_cover"`
+	TakeUpRate       float64 `json:"take_up_rate"`      // Share of covers included in packages that were served, 0-100
+	AvgServiceTime   float64 `json:"avg_service_time"`  // Minutes from seated to first served
+	SatisfactionRate float64 `json:"satisfaction_rate"` // Share of guests served without a complaint, 0-100
+	OccupancyRate    float64 `json:"occupancy_rate"`    // Average share of rooms occupied per night, 0-100
+	NoShowRate       float64 `json:"no_show_rate"`      // No-shows as a share of rooms entitled to breakfast, 0-100
+}
+
+// Benchmark compares one of a property's KPIs with the portfolio median
+type Benchmark struct {
+	Value      float64 `json:"value"`
+	Median     float64 `json:"median"`
+	Difference float64 `json:"difference"` // Value less the median
+	Percent    float64 `json:"percent"`    // Difference as a share of the median; 0 when the median is 0
+	Better     bool    `json:"better"`     // Better than the median, allowing for metrics where lower is better
+}
+
+// PropertyKPIs are one property's KPIs, rank and benchmarks within a portfolio
+type PropertyKPIs struct {
+	PropertyID string               `json:"property_id"`
+	Name       string               `json:"name"`
+	Region     string               `json:"region"`
+	Brand      string               `json:"brand"`
+	Rank       int                  `json:"rank"` // 1 is best on the ranking metric
+	KPIs       PortfolioKPIs        `json:"kpis"`
+	Benchmarks map[string]Benchmark `json:"benchmarks"` // By metric; metrics a property has no data for are left out
+
+	tally portfolioTally
+}
+
+// PortfolioReport ranks the properties of a portfolio over a range of business dates
+type PortfolioReport struct {
+	From       string             `json:"from"`
+	To         string             `json:"to"`
+	RankedBy   string             `json:"ranked_by"`
+	Totals     PortfolioKPIs      `json:"totals"`     // The portfolio taken as one
+	Median     map[string]float64 `json:"median"`     // Median of each metric across the properties with data for it
+	Properties []PropertyKPIs     `json:"properties"` // Best first
+}
+
+// PropertyDrilldown is one property of a portfolio, day by day and outlet by outlet
+type PropertyDrilldown struct {
+	From          string             `json:"from"`
+	To            string             `json:"to"`
+	PortfolioSize int                `json:"portfolio_size"`
+	Property      PropertyKPIs       `json:"property"`
+	Median        map[string]float64 `json:"median"`
+	Days          []PortfolioDay     `json:"days"`
+	Outlets       []PortfolioOutlet  `json:"outlets"`
+}
+
+// PortfolioDay is a property's KPIs on one business date
+type PortfolioDay struct {
+	Date           string  `json:"date"`
+	Covers         int     `json:"covers"`
+	Revenue        float64 `json:"revenue"`
+	TakeUpRate     float64 `json:"take_up_rate"`
+	AvgServiceTime float64 `json:"avg_service_time"`
+	NoShows        int     `json:"no_shows"`
+}
+
+// PortfolioOutlet is one of a property's outlets over the range
+type PortfolioOutlet struct {
+	OutletID       uint    `json:"outlet_id"`
+	Name           string  `json:"name"`
+	Visits         int     `json:"visits"`
+	Covers         int     `json:"covers"`
+	Revenue        float64 `json:"revenue"`
+	AvgServiceTime float64 `json:"avg_service_time"`
+}
+
+// portfolioTally keeps the counts a property's rates are worked out from, so a
+// portfolio's totals weigh each property by its volume
+type portfolioTally struct {
+	entitledCovers int
+	takenCovers    int
+	entitledRooms  int
+	noShows        int
+	serviceMinutes float64
+	serviceSamples int
+	guestsSeen     int
+	complainants   int
+	rooms          int
+}
+
+// portfolioMetric reads one KPI of a property; ok is false when the property
+// has no data for it, such as a service time with nothing timed
+type portfolioMetric struct {
+	value         func(p *PropertyKPIs) (value float64, ok bool)
+	lowerIsBetter bool
+}
+
+var portfolioMetrics = map[string]portfolioMetric{
+	"covers": {value: func(p *PropertyKPIs) (float64, bool) {
+		return float64(p.KPIs.Covers), true
+	}},
+	"upsell_covers": {value: func(p *PropertyKPIs) (float64, bool) {
+		return float64(p.KPIs.UpsellCovers), true
+	}},
+	"revenue": {value: func(p *PropertyKPIs) (float64, bool) {
+		return p.KPIs.Revenue, true
+	}},
+	"revenue_per_cover": {value: func(p *PropertyKPIs) (float64, bool) {
+		return p.KPIs.RevenuePerCover, p.KPIs.Covers > 0
+	}},
+	"take_up_rate": {value: func(p *PropertyKPIs) (float64, bool) {
+		return p.KPIs.TakeUpRate, p.tally.entitledCovers > 0
+	}},
+	"avg_service_time": {lowerIsBetter: true, value: func(p *PropertyKPIs) (float64, bool) {
+		return p.KPIs.AvgServiceTime, p.tally.serviceSamples > 0
+	}},
+	"satisfaction_rate": {value: func(p *PropertyKPIs) (float64, bool) {
+		return p.KPIs.SatisfactionRate, p.tally.guestsSeen > 0
+	}},
+	"occupancy_rate": {value: func(p *PropertyKPIs) (float64, bool) {
+		return p.KPIs.OccupancyRate, p.tally.rooms > 0
+	}},
+	"no_show_rate": {lowerIsBetter: true, value: func(p *PropertyKPIs) (float64, bool) {
+		return p.KPIs.NoShowRate, p.tally.entitledRooms > 0
+	}},
+}
+
+// NewPortfolioService creates a new portfolio service
+func NewPortfolioService(db *gorm.DB, executive *ExecutiveService, rollups *RollupService) *PortfolioService {
+	return &PortfolioService{
+		db:        db,
+		executive: executive,
+		rollups:   rollups,
+	}
+}
+
+// Properties returns the properties a selection picks, by property ID
+func (s *PortfolioService) Properties(selection PortfolioSelection) ([]models.Property, error) {
+	query := s.db.Model(&models.Property{})
+	if region := strings.TrimSpace(selection.Region); region != "" {
+		query = query.Where("LOWER(region) = ?", strings.ToLower(region))
+	}
+	if brand := strings.TrimSpace(selection.Brand); brand != "" {
+		query = query.Where("LOWER(brand) = ?", strings.ToLower(brand))
+	}
+	if selection.GroupID != 0 {
+		if _, err := s.GetGroup(selection.GroupID); err != nil {
+			return nil, err
+		}
+		members := s.db.Model(&models.PropertyGroupMember{}).Select("property_id").Where("group_id = ?", selection.GroupID)
+		query = query.Where("property_id IN (?)", members)
+	}
+	if len(selection.PropertyIDs) > 0 {
+		query = query.Where("property_id IN ?", selection.PropertyIDs)
+	}
+
+	var properties []models.Property
+	if err := query.Order("property_id ASC").Find(&properties).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch portfolio properties: %w", err)
+	}
+	if len(properties) == 0 {
+		return nil, ErrEmptyPortfolio
+	}
+	return properties, nil
+}
+
+// Report aggregates, ranks and benchmarks the selected properties over the
+// business dates from through to, inclusive
+func (s *PortfolioService) Report(selection PortfolioSelection, from, to time.Time, rankBy string) (*PortfolioReport, error) {
+	from, to = calendarDate(from), calendarDate(to)
+	if to.Before(from) || to.Sub(from) > 365*24*time.Hour {
+		return nil, ErrInvalidPortfolioRange
+	}
+	if rankBy == "" {
+		rankBy = defaultPortfolioMetric
+	}
+	metric, ok := portfolioMetrics[rankBy]
+	if !ok {
+		return nil, ErrUnknownPortfolioMetric
+	}
+
+	properties, err := s.Properties(selection)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PortfolioReport{
+		From:       from.Format("2006-01-02"),
+		To:         to.Format("2006-01-02"),
+		RankedBy:   rankBy,
+		Properties: make([]PropertyKPIs, 0, len(properties)),
+	}
+	var total portfolioTally
+	for i := range properties {
+		kpis, err := s.propertyKPIs(&properties[i], from, to)
+		if err != nil {
+			return nil, err
+		}
+		report.Properties = append(report.Properties, *kpis)
+
+		report.Totals.Covers += kpis.KPIs.Covers
+		report.Totals.UpsellCovers += kpis.KPIs.UpsellCovers
+		report.Totals.Revenue += kpis.KPIs.Revenue
+		total.add(kpis.tally)
+		if kpis.tally.rooms > 0 {
+			report.Totals.OccupancyRate += kpis.KPIs.OccupancyRate * float64(kpis.tally.rooms)
+		}
+	}
+	report.Totals.Revenue = roundTo(report.Totals.Revenue, 2)
+	if total.rooms > 0 {
+		report.Totals.OccupancyRate = roundTo(report.Totals.OccupancyRate/float64(total.rooms), 1)
+	}
+	total.rates(&report.Totals)
+
+	report.Median = portfolioMedians(report.Properties)
+	for i := range report.Properties {
+		report.Properties[i].Benchmarks = portfolioBenchmarks(&report.Properties[i], report.Median)
+	}
+	rankProperties(report.Properties, metric)
+
+	logging.WithFields(logrus.Fields{
+		"service":    "PortfolioService",
+		"method":     "Report",
+		"properties": len(report.Properties),
+		"from":       report.From,
+		"to":         report.To,
+		"ranked_by":  rankBy,
+	}).Debug("Portfolio report built")
+
+	return report, nil
+}
+
+// Drilldown returns one property of a portfolio with its rank and benchmarks,
+// its KPIs for each business date and its outlets
+func (s *PortfolioService) Drilldown(selection PortfolioSelection, propertyID string, from, to time.Time, rankBy string) (*PropertyDrilldown, error) {
+	report, err := s.Report(selection, from, to, rankBy)
+	if err != nil {
+		return nil, err
+	}
+
+	drilldown := &PropertyDrilldown{
+		From:          report.From,
+		To:            report.To,
+		PortfolioSize: len(report.Properties),
+		Median:        report.Median,
+		Days:          []PortfolioDay{},
+		Outlets:       []PortfolioOutlet{},
+	}
+	found := false
+	for _, property := range report.Properties {
+		if property.PropertyID == propertyID {
+			drilldown.Property, found = property, true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrNotInPortfolio
+	}
+
+	days, err := s.rollups.Days(propertyID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, day := range days {
+		point := PortfolioDay{
+			Date:    day.BusinessDate.Format("2006-01-02"),
+			Covers:  day.Covers,
+			Revenue: roundTo(day.Revenue, 2),
+			NoShows: day.NoShows,
+		}
+		if day.EntitledCovers > 0 {
+			point.TakeUpRate = roundTo(float64(day.TakenCovers)/float64(day.EntitledCovers)*100, 1)
+		}
+		if day.ServiceSamples > 0 {
+			point.AvgServiceTime = roundTo(day.ServiceMinutes/float64(day.ServiceSamples), 1)
+		}
+		drilldown.Days = append(drilldown.Days, point)
+	}
+
+	outletDays, err := s.rollups.OutletDays(propertyID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	byOutlet := make(map[uint][]models.DailyRollup)
+	var outletIDs []uint
+	for _, row := range outletDays {
+		if _, ok := byOutlet[row.OutletID]; !ok {
+			outletIDs = append(outletIDs, row.OutletID)
+		}
+		byOutlet[row.OutletID] = append(byOutlet[row.OutletID], row)
+	}
+	names := make(map[uint]string)
+	if len(outletIDs) > 0 {
+		var outlets []models.Outlet
+		if err := s.db.Unscoped().Where("id IN ?", outletIDs).Find(&outlets).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch outlets: %w", err)
+		}
+		for _, outlet := range outlets {
+			names[outlet.ID] = outlet.Name
+		}
+	}
+	for _, outletID := range outletIDs {
+		sum := sumRollups(byOutlet[outletID])
+		outlet := PortfolioOutlet{
+			OutletID: outletID,
+			Name:     names[outletID],
+			Visits:   sum.Visits,
+			Covers:   sum.Covers,
+			Revenue:  sum.Revenue,
+		}
+		if sum.ServiceSamples > 0 {
+			outlet.AvgServiceTime = roundTo(sum.ServiceMinutes/float64(sum.ServiceSamples), 1)
+		}
+		drilldown.Outlets = append(drilldown.Outlets, outlet)
+	}
+	sort.Slice(drilldown.Outlets, func(i, j int) bool {
+		return drilldown.Outlets[i].Revenue > drilldown.Outlets[j].Revenue
+	})
+
+	return drilldown, nil
+}
+
+// GetGroups lists the saved property groups by name
+func (s *PortfolioService) GetGroups() ([]models.PropertyGroup, error) {
+	var groups []models.PropertyGroup
+	if err := s.db.Preload("Members").Order("name ASC").Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("failed to get property groups: %w", err)
+	}
+	return groups, nil
+}
+
+// GetGroup returns a saved property group with its members
+func (s *PortfolioService) GetGroup(id uint) (*models.PropertyGroup, error) {
+	var group models.PropertyGroup
+	if err := s.db.Preload("Members").First(&group, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPropertyGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to get property group: %w", err)
+	}
+	return &group, nil
+}
+
+// CreateGroup saves a named group of properties
+func (s *PortfolioService) CreateGroup(group *models.PropertyGroup, propertyIDs []string) error {
+	group.Name = strings.TrimSpace(group.Name)
+	if err := s.checkGroup(group.Name, 0, propertyIDs); err != nil {
+		return err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Members").Create(group).Error; err != nil {
+			return fmt.Errorf("failed to create property group: %w", err)
+		}
+		return setGroupMembers(tx, group.ID, propertyIDs)
+	})
+	if err != nil {
+		return err
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":    "PortfolioService",
+		"group_id":   group.ID,
+		"name":       group.Name,
+		"properties": len(propertyIDs),
+	}).Info("Property group created")
+
+	created, err := s.GetGroup(group.ID)
+	if err != nil {
+		return err
+	}
+	*group = *created
+	return nil
+}
+
+// UpdateGroup renames, redescribes or re-members a group; nil leaves a field unchanged
+func (s *PortfolioService) UpdateGroup(id uint, name, description *string, propertyIDs []string) (*models.PropertyGroup, error) {
+	group, err := s.GetGroup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	newName := group.Name
+	if name != nil {
+		newName = strings.TrimSpace(*name)
+		updates["name"] = newName
+	}
+	if description != nil {
+		updates["description"] = *description
+	}
+	if err := s.checkGroup(newName, id, propertyIDs); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(group).Omit("Members").Updates(updates).Error; err != nil {
+				return fmt.Errorf("failed to update property group: %w", err)
+			}
+		}
+		if propertyIDs == nil {
+			return nil
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&models.PropertyGroupMember{}).Error; err != nil {
+			return fmt.Errorf("failed to update property group members: %w", err)
+		}
+		return setGroupMembers(tx, id, propertyIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetGroup(id)
+}
+
+// DeleteGroup removes a group; its properties are unaffected
+func (s *PortfolioService) DeleteGroup(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.PropertyGroup{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete property group: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrPropertyGroupNotFound
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&models.PropertyGroupMember{}).Error; err != nil {
+			return fmt.Errorf("failed to delete property group members: %w", err)
+		}
+		return nil
+	})
+}
+
+// propertyKPIs works out one property's KPIs from its rollups and executive metrics
+func (s *PortfolioService) propertyKPIs(property *models.Property, from, to time.Time) (*PropertyKPIs, error) {
+	metrics, err := s.executive.Metrics(property.PropertyID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.rollups.Days(property.PropertyID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	sum := sumRollups(days)
+
+	var rooms int64
+	if err := s.db.Model(&models.Room{}).Where("property_id = ?", property.PropertyID).Count(&rooms).Error; err != nil {
+		return nil, fmt.Errorf("failed to count rooms: %w", err)
+	}
+
+	kpis := &PropertyKPIs{
+		PropertyID: property.PropertyID,
+		Name:       property.Name,
+		Region:     property.Region,
+		Brand:      property.Brand,
+		KPIs: PortfolioKPIs{
+			Covers:        sum.Covers,
+			UpsellCovers:  sum.UpsellCovers,
+			Revenue:       sum.Revenue,
+			OccupancyRate: metrics.OccupancyRate,
+		},
+		tally: portfolioTally{
+			entitledCovers: sum.EntitledCovers,
+			takenCovers:    sum.TakenCovers,
+			entitledRooms:  sum.EntitledRooms,
+			noShows:        sum.NoShows,
+			serviceMinutes: sum.ServiceMinutes,
+			serviceSamples: sum.ServiceSamples,
+			guestsSeen:     max(metrics.GuestsServed, metrics.Complainants),
+			complainants:   metrics.Complainants,
+			rooms:          int(rooms),
+		},
+	}
+	kpis.tally.rates(&kpis.KPIs)
+	return kpis, nil
+}
+
+// checkGroup validates a group's name, unique among other groups, and that
+// its properties exist
+func (s *PortfolioService) checkGroup(name string, exceptID uint, propertyIDs []string) error {
+	if name == "" {
+		return ErrGroupNameRequired
+	}
+	var clashes int64
+	if err := s.db.Model(&models.PropertyGroup{}).Where("name = ? AND id <> ?", name, exceptID).Count(&clashes).Error; err != nil {
+		return fmt.Errorf("failed to check property group name: %w", err)
+	}
+	if clashes > 0 {
+		return ErrDuplicateGroupName
+	}
+
+	if len(propertyIDs) == 0 {
+		return nil
+	}
+	var known []string
+	if err := s.db.Model(&models.Property{}).Where("property_id IN ?", propertyIDs).Pluck("property_id", &known).Error; err != nil {
+		return fmt.Errorf("failed to check group properties: %w", err)
+	}
+	found := make(map[string]bool, len(known))
+	for _, id := range known {
+		found[id] = true
+	}
+	for _, id := range propertyIDs {
+		if !found[id] {
+			return fmt.Errorf("%w: %s", ErrPropertyNotFound, id)
+		}
+	}
+	return nil
+}
+
+// setGroupMembers adds properties to a group, once each
+func setGroupMembers(tx *gorm.DB, groupID uint, propertyIDs []string) error {
+	seen := make(map[string]bool)
+	for _, propertyID := range propertyIDs {
+		if seen[propertyID] {
+			continue
+		}
+		seen[propertyID] = true
+		if err := tx.Create(&models.PropertyGroupMember{GroupID: groupID, PropertyID: propertyID}).Error; err != nil {
+			return fmt.Errorf("failed to add property to group: %w", err)
+		}
+	}
+	return nil
+}
+
+func (t *portfolioTally) add(other portfolioTally) {
+	t.entitledCovers += other.entitledCovers
+	t.takenCovers += other.takenCovers
+	t.entitledRooms += other.entitledRooms
+	t.noShows += other.noShows
+	t.serviceMinutes += other.serviceMinutes
+	t.serviceSamples += other.serviceSamples
+	t.guestsSeen += other.guestsSeen
+	t.complainants += other.complainants
+	t.rooms += other.rooms
+}
+
+// rates fills in the KPIs that are ratios of the tally's counts
+func (t *portfolioTally) rates(kpis *PortfolioKPIs) {
+	if kpis.Covers > 0 {
+		kpis.RevenuePerCover = roundTo(kpis.Revenue/float64(kpis.Covers), 2)
+	}
+	if t.entitledCovers > 0 {
+		kpis.TakeUpRate = roundTo(float64(t.takenCovers)/float64(t.entitledCovers)*100, 1)
+	}
+	if t.serviceSamples > 0 {
+		kpis.AvgServiceTime = roundTo(t.serviceMinutes/float64(t.serviceSamples), 1)
+	}
+	if t.guestsSeen > 0 {
+		kpis.SatisfactionRate = roundTo(float64(t.guestsSeen-t.complainants)/float64(t.guestsSeen)*100, 1)
+	}
+	if t.entitledRooms > 0 {
+		kpis.NoShowRate = roundTo(float64(t.noShows)/float64(t.entitledRooms)*100, 1)
+	}
+}
+
+// portfolioMedians returns the median of each metric across the properties
+// that have data for it
+func portfolioMedians(properties []PropertyKPIs) map[string]float64 {
+	medians := make(map[string]float64, len(portfolioMetrics))
+	for name, metric := range portfolioMetrics {
+		var values []float64
+		for i := range properties {
+			if value, ok := metric.value(&properties[i]); ok {
+				values = append(values, value)
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		sort.Float64s(values)
+		middle := len(values) / 2
+		if len(values)%2 == 0 {
+			medians[name] = roundTo((values[middle-1]+values[middle])/2, 2)
+		} else {
+			medians[name] = values[middle]
+		}
+	}
+	return medians
+}
+
+// portfolioBenchmarks compares a property's metrics with the portfolio medians
+func portfolioBenchmarks(property *PropertyKPIs, medians map[string]float64) map[string]Benchmark {
+	benchmarks := make(map[string]Benchmark, len(portfolioMetrics))
+	for name, metric := range portfolioMetrics {
+		value, ok := metric.value(property)
+		median, hasMedian := medians[name]
+		if !ok || !hasMedian {
+			continue
+		}
+		benchmark := Benchmark{
+			Value:      value,
+			Median:     median,
+			Difference: roundTo(value-median, 2),
+			Better:     value > median,
+		}
+		if metric.lowerIsBetter {
+			benchmark.Better = value < median
+		}
+		if median != 0 {
+			benchmark.Percent = roundTo((value-median)/median*100, 1)
+		}
+		benchmarks[name] = benchmark
+	}
+	return benchmarks
+}
+
+// rankProperties orders properties best first on a metric and numbers them.
+// Properties without data for the metric come last.
+func rankProperties(properties []PropertyKPIs, metric portfolioMetric) {
+	sort.SliceStable(properties, func(i, j int) bool {
+		a, aok := metric.value(&properties[i])
+		b, bok := metric.value(&properties[j])
+		switch {
+		case aok != bok:
+			return aok
+		case a == b:
+			return properties[i].PropertyID < properties[j].PropertyID
+		case metric.lowerIsBetter:
+			return a < b
+		default:
+			return a > b
+		}
+	})
+	for i := range properties {
+		properties[i].Rank = i + 1
+	}
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Errors returned by the price book
+var (
+	ErrPriceNotFound     = errors.New("price not found")
+	ErrNoPriceConfigured = errors.New("no breakfast price is configured")
+)
+
+// PriceBookService manages per-property breakfast prices
+type PriceBookService struct {
+	db *gorm.DB
+}
+
+// NewPriceBookService creates a new price book service
+func NewPriceBookService(db *gorm.DB) *PriceBookService {
+	return &PriceBookService{
+		db: db,
+	}
+}
+
+// GetPrices retrieves all price book entries for a property
+func (s *PriceBookService) GetPrices(propertyID string) ([]models.BreakfastPrice, error) {
+	var prices []models.BreakfastPrice
+	err := s.db.Where("property_id = ?", propertyID).
+		Order("guest_type, effective_from DESC").
+		Find(&prices).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch prices: %w", err)
+	}
+	return prices, nil
+}
+
+// CreatePrice adds an entry to a property's price book
+func (s *PriceBookService) CreatePrice(price *models.BreakfastPrice) error {
+	if err := validatePrice(price); err != nil {
+		return err
+	}
+
+	if err := s.db.Create(price).Error; err != nil {
+		return fmt.Errorf("failed to create price: %w", err)
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":     "PriceBookService",
+		"method":      "CreatePrice",
+		"property_id": price.PropertyID,
+		"price_id":    price.ID,
+	}).Info("Successfully created price book entry")
+
+	return nil
+}
+
+// UpdatePrice replaces a price book entry
+func (s *PriceBookService) UpdatePrice(priceID uint, price *models.BreakfastPrice) (*models.BreakfastPrice, error) {
+	var existing models.BreakfastPrice
+	if err := s.db.First(&existing, priceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPriceNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch price: %w", err)
+	}
+
+	price.ID = existing.ID
+	price.PropertyID = existing.PropertyID
+	price.CreatedAt = existing.CreatedAt
+	if err := validatePrice(price); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Save(price).Error; err != nil {
+		return nil, fmt.Errorf("failed to update price: %w", err)
+	}
+	return price, nil
+}
+
+// DeletePrice removes a price book entry
+func (s *PriceBookService) DeletePrice(priceID uint) error {
+	result := s.db.Delete(&models.BreakfastPrice{}, priceID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete price: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrPriceNotFound
+	}
+	return nil
+}
+
+// QuoteVisit prices a visit at the given time without recording it. Prices
+// are those in effect on the property's business date at that time.
+func (s *PriceBookService) QuoteVisit(propertyID string, outletID *uint, roomType string, adults, children int, at time.Time) (*VisitPrice, error) {
+	clock, err := LoadPropertyClock(s.db, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	return priceVisit(s.db, propertyID, outletID, roomType, adults, children, clock.BusinessDate(at))
+}
+
+func validatePrice(price *models.BreakfastPrice) error {
+	if price.PropertyID == "" {
+		return errors.New("property_id is required")
+	}
+	if price.GuestType != "adult" && price.GuestType != "child" {
+		return errors.New("guest_type must be adult or child")
+	}
+	if price.Price < 0 || price.TaxRate < 0 || price.ServiceChargeRate < 0 {
+		return errors.New("price, tax_rate and service_charge_rate cannot be negative")
+	}
+	if price.DayOfWeek != nil && (*price.DayOfWeek < 0 || *price.DayOfWeek > 6) {
+		return errors.New("day_of_week must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	if price.EffectiveFrom.IsZero() {
+		return errors.New("effective_from is required")
+	}
+	if price.EffectiveTo != nil && !price.EffectiveTo.After(price.EffectiveFrom) {
+		return errors.New("effective_to must be after effective_from")
+	}
+	return nil
+}
+
+// CoverPrice is the resolved price of a single adult or child cover
+type CoverPrice struct {
+	PriceID           *uint   `json:"price_id,omitempty"`
+	Price             float64 `json:"price"`
+	TaxRate           float64 `json:"tax_rate"`
+	ServiceChargeRate float64 `json:"service_charge_rate"`
+}
+
+// VisitPrice is the resolved price of the covers served in a visit
+type VisitPrice struct {
+	Adult         CoverPrice `json:"adult"`
+	Child         CoverPrice `json:"child"`
+	Subtotal      float64    `json:"subtotal"`
+	ServiceCharge float64    `json:"service_charge"`
+	TaxAmount     float64    `json:"tax_amount"`
+	Total         float64    `json:"total"`
+}
+
+// Portion prices a subset of the visit's covers with the same resolved prices
+func (p *VisitPrice) Portion(adults, children int) *VisitPrice {
+	portion := &VisitPrice{Adult: p.Adult, Child: p.Child}
+	for _, line := range []struct {
+		price  CoverPrice
+		covers int
+	}{{p.Adult, adults}, {p.Child, children}} {
+		subtotal := line.price.Price * float64(line.covers)
+		service := subtotal * line.price.ServiceChargeRate / 100
+		portion.Subtotal += subtotal
+		portion.ServiceCharge += service
+		// Tax applies to the service charge as well as the cover price
+		portion.TaxAmount += (subtotal + service) * line.price.TaxRate / 100
+	}
+
+	portion.Subtotal = roundCents(portion.Subtotal)
+	portion.ServiceCharge = roundCents(portion.ServiceCharge)
+	portion.TaxAmount = roundCents(portion.TaxAmount)
+	portion.Total = roundCents(portion.Subtotal + portion.ServiceCharge + portion.TaxAmount)
+	return portion
+}
+
+// priceVisit resolves adult and child cover prices from the price book and
+// prices the visit. A guest type without an entry is only an error when the
+// visit has covers of that type to price; the adult price is still kept when
+// nothing is priced, as it sets the package allowance for à-la-carte orders.
+func priceVisit(tx *gorm.DB, propertyID string, outletID *uint, roomType string, adults, children int, businessDate time.Time) (*VisitPrice, error) {
+	price := &VisitPrice{}
+	for _, line := range []struct {
+		guestType string
+		covers    int
+		price     *CoverPrice
+	}{{"adult", adults, &price.Adult}, {"child", children, &price.Child}} {
+		resolved, err := resolveCoverPrice(tx, propertyID, outletID, roomType, line.guestType, businessDate)
+		if errors.Is(err, ErrNoPriceConfigured) && line.covers <= 0 {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		*line.price = *resolved
+	}
+
+	return price.Portion(adults, children), nil
+}
+
+// resolveCoverPrice picks the most specific price book entry in effect on a
+// business date. Effective dates and the day of week are those of the business
+// date, not of the wall clock, so service after midnight keeps the day's prices.
+func resolveCoverPrice(tx *gorm.DB, propertyID string, outletID *uint, roomType, guestType string, businessDate time.Time) (*CoverPrice, error) {
+	date := businessDate.Format("2006-01-02")
+	query := tx.Where("property_id = ? AND guest_type = ?", propertyID, guestType).
+		Where("DATE(effective_from) <= ? AND (effective_to IS NULL OR DATE(effective_to) > ?)", date, date).
+		Where("room_type = '' OR room_type = ?", roomType).
+		Where("day_of_week IS NULL OR day_of_week = ?", int(businessDate.Weekday()))
+	if outletID != nil {
+		query = query.Where("outlet_id IS NULL OR outlet_id = ?", *outletID)
+	} else {
+		query = query.Where("outlet_id IS NULL")
+	}
+
+	var entries []models.BreakfastPrice
+	err := query.
+		Order("(outlet_id IS NOT NULL) DESC, (room_type <> '') DESC, (day_of_week IS NOT NULL) DESC, effective_from DESC").
+		Limit(1).
+		Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s breakfast price: %w", guestType, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w for %s covers at property %s on %s", ErrNoPriceConfigured, guestType, propertyID, date)
+	}
+
+	entry := entries[0]
+
+	return &CoverPrice{
+		PriceID:           &entry.ID,
+		Price:             entry.Price,
+		TaxRate:           entry.TaxRate,
+		ServiceChargeRate: entry.ServiceChargeRate,
+	}, nil
+}
+
+// roomTypeFor returns the room type of a room, or empty when the room is unknown
+func roomTypeFor(tx *gorm.DB, propertyID, roomNumber string) string {
+	var roomTypes []string
+	tx.Model(&models.Room{}).
+		Where("property_id = ? AND room_number = ?", propertyID, roomNumber).
+		Limit(1).
+		Pluck("room_type", &roomTypes)
+	if len(roomTypes) == 0 {
+		return ""
+	}
+	return roomTypes[0]
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
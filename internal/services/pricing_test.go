@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"hudini-breakfast-module/internal/models"
+)
+
+func TestPortion(t *testing.T) {
+	price := &VisitPrice{
+		Adult: CoverPrice{Price: 20, TaxRate: 13, ServiceChargeRate: 10},
+		Child: CoverPrice{Price: 10, TaxRate: 13},
+	}
+
+	tests := []struct {
+		name             string
+		adults, children int
+		want             VisitPrice
+	}{
+		// Tax applies to the service charge as well as the cover price:
+		// (40 + 4) * 13% + 10 * 13% = 7.02
+		{"adults and children", 2, 1, VisitPrice{Subtotal: 50, ServiceCharge: 4, TaxAmount: 7.02, Total: 61.02}},
+		{"adult only", 1, 0, VisitPrice{Subtotal: 20, ServiceCharge: 2, TaxAmount: 2.86, Total: 24.86}},
+		{"child only", 0, 2, VisitPrice{Subtotal: 20, ServiceCharge: 0, TaxAmount: 2.6, Total: 22.6}},
+		{"nothing", 0, 0, VisitPrice{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := price.Portion(tt.adults, tt.children)
+			if got.Adult != price.Adult || got.Child != price.Child {
+				t.Errorf("Portion did not keep the resolved cover prices: %+v", got)
+			}
+			if got.Subtotal != tt.want.Subtotal || got.ServiceCharge != tt.want.ServiceCharge ||
+				got.TaxAmount != tt.want.TaxAmount || got.Total != tt.want.Total {
+				t.Errorf("Portion(%d, %d) = subtotal %v, service %v, tax %v, total %v, want %v, %v, %v, %v",
+					tt.adults, tt.children, got.Subtotal, got.ServiceCharge, got.TaxAmount, got.Total,
+					tt.want.Subtotal, tt.want.ServiceCharge, tt.want.TaxAmount, tt.want.Total)
+			}
+		})
+	}
+}
+
+func TestPortionRoundsEachLineToCents(t *testing.T) {
+	price := &VisitPrice{Adult: CoverPrice{Price: 9.99, TaxRate: 8.875, ServiceChargeRate: 12.5}}
+
+	// 29.97 subtotal, 3.74625 service and (29.97 + 3.74625) * 8.875% = 2.99232...
+	got := price.Portion(3, 0)
+	if got.Subtotal != 29.97 || got.ServiceCharge != 3.75 || got.TaxAmount != 2.99 {
+		t.Fatalf("Portion(3, 0) = subtotal %v, service %v, tax %v, want 29.97, 3.75, 2.99", got.Subtotal, got.ServiceCharge, got.TaxAmount)
+	}
+	if got.Total != 36.71 {
+		t.Errorf("total %v, want the sum of the rounded lines 36.71", got.Total)
+	}
+}
+
+func TestPriceVisitByBusinessDate(t *testing.T) {
+	// More specific entries override the property-wide adult price
+	db := newTestDB(t)
+	outletID := uint(7)
+	saturday := int(time.Saturday)
+	seasonStart := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+	prices := []models.BreakfastPrice{
+		{PropertyID: "P1", GuestType: "adult", Price: 20, TaxRate: 10, EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), EffectiveTo: &seasonStart},
+		{PropertyID: "P1", GuestType: "adult", Price: 22, TaxRate: 10, EffectiveFrom: seasonStart},
+		{PropertyID: "P1", GuestType: "adult", Price: 25, TaxRate: 10, DayOfWeek: &saturday, EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{PropertyID: "P1", GuestType: "adult", Price: 40, TaxRate: 10, RoomType: "suite", EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{PropertyID: "P1", GuestType: "adult", Price: 30, TaxRate: 10, OutletID: &outletID, EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{PropertyID: "P1", GuestType: "child", Price: 10, EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{PropertyID: "P2", GuestType: "adult", Price: 99, EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for i := range prices {
+		mustCreate(t, db, &prices[i])
+	}
+
+	tests := []struct {
+		name     string
+		outletID *uint
+		roomType string
+		date     time.Time
+		adult    float64
+	}{
+		{"weekday before the new season", nil, "standard", time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), 20},
+		{"last day before the new season", nil, "", time.Date(2026, 10, 9, 0, 0, 0, 0, time.UTC), 20},
+		{"first day of the new season", nil, "", time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), 22},
+		{"saturday rate", nil, "", time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC), 25},
+		{"suite rate beats the saturday rate", nil, "suite", time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC), 40},
+		{"outlet rate beats the suite rate", &outletID, "suite", time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC), 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := priceVisit(db, "P1", tt.outletID, tt.roomType, 2, 1, tt.date)
+			if err != nil {
+				t.Fatalf("priceVisit: %v", err)
+			}
+			if got.Adult.Price != tt.adult || got.Child.Price != 10 {
+				t.Errorf("adult %v, child %v, want %v, 10", got.Adult.Price, got.Child.Price, tt.adult)
+			}
+			if want := 2*tt.adult + 10; got.Subtotal != want {
+				t.Errorf("subtotal %v, want %v", got.Subtotal, want)
+			}
+			if got.Adult.PriceID == nil {
+				t.Error("adult price does not record the price book entry used")
+			}
+		})
+	}
+
+	if _, err := priceVisit(db, "P1", nil, "", 1, 0, time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrNoPriceConfigured) {
+		t.Errorf("pricing before any entry is effective: error = %v, want ErrNoPriceConfigured", err)
+	}
+}
+
+func TestPriceVisitWithoutPrice(t *testing.T) {
+	db := newTestDB(t)
+	mustCreate(t, db, &models.BreakfastPrice{PropertyID: "P1", GuestType: "adult", Price: 20, EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)})
+	date := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+
+	// A missing child price only matters when children are served
+	got, err := priceVisit(db, "P1", nil, "", 2, 0, date)
+	if err != nil {
+		t.Fatalf("adults only: %v", err)
+	}
+	if got.Total != 40 || got.Child.PriceID != nil {
+		t.Errorf("adults only: total %v, child price %+v", got.Total, got.Child)
+	}
+	if _, err := priceVisit(db, "P1", nil, "", 2, 1, date); !errors.Is(err, ErrNoPriceConfigured) {
+		t.Errorf("with a child: error = %v, want ErrNoPriceConfigured", err)
+	}
+
+	// The adult price is kept when nothing is priced, for the order allowance
+	got, err = priceVisit(db, "P1", nil, "", 0, 0, date)
+	if err != nil {
+		t.Fatalf("no covers: %v", err)
+	}
+	if got.Adult.Price != 20 || got.Total != 0 {
+		t.Errorf("no covers: adult %v, total %v, want 20, 0", got.Adult.Price, got.Total)
+	}
+
+	if _, err := priceVisit(db, "P9", nil, "", 1, 0, date); !errors.Is(err, ErrNoPriceConfigured) {
+		t.Errorf("unpriced property: error = %v, want ErrNoPriceConfigured", err)
+	}
+}
+
+func TestQuoteVisitUsesBusinessDate(t *testing.T) {
+	db := newTestDB(t)
+	mustCreate(t, db, &models.Property{PropertyID: "P1", Name: "Harbour Hotel", TimeZone: "America/New_York", CutoverHour: 3})
+	saturday := int(time.Saturday)
+	mustCreate(t, db, &models.BreakfastPrice{PropertyID: "P1", GuestType: "adult", Price: 20, EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)})
+	mustCreate(t, db, &models.BreakfastPrice{PropertyID: "P1", GuestType: "adult", Price: 25, DayOfWeek: &saturday, EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)})
+
+	service := NewPriceBookService(db)
+
+	// 02:30 on Sunday in New York is before the 03:00 cutover, so it is still
+	// Saturday's service
+	quote, err := service.QuoteVisit("P1", nil, "", 1, 0, time.Date(2026, 10, 4, 6, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("QuoteVisit: %v", err)
+	}
+	if quote.Adult.Price != 25 {
+		t.Errorf("after midnight before the cutover: adult %v, want the saturday price 25", quote.Adult.Price)
+	}
+
+	quote, err = service.QuoteVisit("P1", nil, "", 1, 0, time.Date(2026, 10, 4, 7, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("QuoteVisit: %v", err)
+	}
+	if quote.Adult.Price != 20 {
+		t.Errorf("after the cutover: adult %v, want the sunday price 20", quote.Adult.Price)
+	}
+}
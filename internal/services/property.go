@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type PropertyService struct {
+	db *gorm.DB
+}
+
+func NewPropertyService(db *gorm.DB) *PropertyService {
+	return &PropertyService{
+		db: db,
+	}
+}
+
+// GetProperty retrieves a property by its property ID
+func (s *PropertyService) GetProperty(propertyID string) (*models.Property, error) {
+	var property models.Property
+	err := s.db.Where("property_id = ?", propertyID).First(&property).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPropertyNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch property: %w", err)
+	}
+	return &property, nil
+}
+
+// UpdateBusinessDay sets the time zone and business date cutover hour of a property
+func (s *PropertyService) UpdateBusinessDay(propertyID, timeZone string, cutoverHour int) (*models.Property, error) {
+	if _, err := time.LoadLocation(timeZone); err != nil || timeZone == "" {
+		return nil, fmt.Errorf("invalid time zone %q", timeZone)
+	}
+	if cutoverHour < 0 || cutoverHour > 23 {
+		return nil, errors.New("cutover_hour must be between 0 and 23")
+	}
+
+	property, err := s.GetProperty(propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Model(property).Updates(map[string]interface{}{
+		"time_zone":    timeZone,
+		"cutover_hour": cutoverHour,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update property: %w", err)
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":      "PropertyService",
+		"method":       "UpdateBusinessDay",
+		"property_id":  propertyID,
+		"time_zone":    timeZone,
+		"cutover_hour": cutoverHour,
+	}).Info("Updated property business day settings")
+
+	return s.GetProperty(propertyID)
+}
+
+// UpdateTags sets the region and brand a property is grouped by in portfolio
+// reports; nil leaves a tag unchanged
+func (s *PropertyService) UpdateTags(propertyID string, region, brand *string) (*models.Property, error) {
+	property, err := s.GetProperty(propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if region != nil {
+		updates["region"] = strings.TrimSpace(*region)
+	}
+	if brand != nil {
+		updates["brand"] = strings.TrimSpace(*brand)
+	}
+	if len(updates) > 0 {
+		if err := s.db.Model(property).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update property: %w", err)
+		}
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":     "PropertyService",
+		"method":      "UpdateTags",
+		"property_id": propertyID,
+	}).Info("Updated property portfolio tags")
+
+	return s.GetProperty(propertyID)
+}
+
+// BusinessDate returns the property's current business date
+func (s *PropertyService) BusinessDate(propertyID string) (time.Time, error) {
+	clock, err := LoadPropertyClock(s.db, propertyID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return clock.Today(), nil
+}
@@ -170,9 +170,10 @@ type MockEmailProvider struct {
 }
 
 type MockEmail struct {
-	To      string
-	Subject string
-	Body    string
+	To          string
+	Subject     string
+	Body        string
+	Attachments []EmailAttachment
 }
 
 // NewMockEmailProvider creates a new mock email provider
@@ -194,6 +195,19 @@ func (m *MockEmailProvider) Send(ctx context.Context, to string, subject string,
 	return nil
 }
 
+// SendWithAttachments sends a mock email with files attached
+func (m *MockEmailProvider) SendWithAttachments(ctx context.Context, to string, subject string, body string, attachments []EmailAttachment) error {
+	m.sentEmails = append(m.sentEmails, MockEmail{
+		To:          to,
+		Subject:     subject,
+		Body:        body,
+		Attachments: attachments,
+	})
+
+	logging.WithField("to", to).WithField("subject", subject).WithField("attachments", len(attachments)).Info("Mock email sent")
+	return nil
+}
+
 // GetSentEmails returns all sent emails for testing
 func (m *MockEmailProvider) GetSentEmails() []MockEmail {
 	return m.sentEmails
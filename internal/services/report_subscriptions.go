@@ -0,0 +1,535 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"hudini-breakfast-module/internal/audit"
+	"hudini-breakfast-module/internal/config"
+	"hudini-breakfast-module/internal/export"
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Errors returned by the report subscription service
+var (
+	ErrSubscriptionNotFound = errors.New("report subscription not found")
+	ErrUnknownReport        = errors.New("report must be daily_report or consumption_history")
+	ErrInvalidSchedule      = errors.New("frequency must be daily or weekly, send time HH:MM and weekday 0 (Sunday) to 6")
+	ErrNoSubscriber         = errors.New("subscriber must be an active staff member with an email address")
+)
+
+// Reports that can be subscribed to
+const (
+	ReportDaily              = "daily_report"
+	ReportConsumptionHistory = "consumption_history"
+)
+
+// Subscription frequencies
+const (
+	FrequencyDaily  = "daily"
+	FrequencyWeekly = "weekly"
+)
+
+// Delivery statuses
+const (
+	DeliverySent   = "sent"
+	DeliveryFailed = "failed"
+)
+
+const (
+	defaultSubscriptionInterval    = 5 * time.Minute
+	defaultSubscriptionMaxAttempts = 3
+	defaultSubscriptionSendTime    = "07:00"
+)
+
+// ReportSubscriptionService emails reports to the staff who subscribe to them
+type ReportSubscriptionService struct {
+	db        *gorm.DB
+	breakfast *BreakfastService
+	exports   *ExportService
+	email     EmailProvider
+	config    config.SubscriptionConfig
+}
+
+// SubscriptionFilter narrows a subscription listing; zero values match everything
+type SubscriptionFilter struct {
+	StaffID    uint
+	PropertyID string
+}
+
+// DeliveryFilter narrows a delivery history; zero values match everything
+type DeliveryFilter struct {
+	SubscriptionID uint
+	StaffID        uint
+	PropertyID     string
+	Status         string
+	Limit          int
+}
+
+// NewReportSubscriptionService creates a new report subscription service
+func NewReportSubscriptionService(db *gorm.DB, breakfast *BreakfastService, exports *ExportService, cfg config.SubscriptionConfig) *ReportSubscriptionService {
+	return &ReportSubscriptionService{
+		db:        db,
+		breakfast: breakfast,
+		exports:   exports,
+		config:    cfg,
+	}
+}
+
+// SetEmailProvider sets the provider reports are emailed through
+func (s *ReportSubscriptionService) SetEmailProvider(email EmailProvider) {
+	s.email = email
+}
+
+// GetSubscriptions lists subscriptions, newest first
+func (s *ReportSubscriptionService) GetSubscriptions(filter SubscriptionFilter) ([]models.ReportSubscription, error) {
+	query := s.db.Preload("Staff")
+	if filter.StaffID != 0 {
+		query = query.Where("staff_id = ?", filter.StaffID)
+	}
+	if filter.PropertyID != "" {
+		query = query.Where("property_id = ?", filter.PropertyID)
+	}
+
+	var subscriptions []models.ReportSubscription
+	if err := query.Order("id DESC").Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get report subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// GetSubscription returns a subscription by ID
+func (s *ReportSubscriptionService) GetSubscription(id uint) (*models.ReportSubscription, error) {
+	var subscription models.ReportSubscription
+	if err := s.db.Preload("Staff").First(&subscription, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get report subscription: %w", err)
+	}
+	return &subscription, nil
+}
+
+// CreateSubscription validates and saves a new subscription
+func (s *ReportSubscriptionService) CreateSubscription(subscription *models.ReportSubscription) error {
+	if err := s.validate(subscription); err != nil {
+		return err
+	}
+	if err := s.db.Create(subscription).Error; err != nil {
+		return fmt.Errorf("failed to create report subscription: %w", err)
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":         "ReportSubscriptionService",
+		"subscription_id": subscription.ID,
+		"staff_id":        subscription.StaffID,
+		"property_id":     subscription.PropertyID,
+		"report":          subscription.Report,
+		"frequency":       subscription.Frequency,
+	}).Info("Report subscription created")
+	return nil
+}
+
+// UpdateSubscription applies changes to a subscription, validating the result
+func (s *ReportSubscriptionService) UpdateSubscription(id uint, updates map[string]interface{}) (*models.ReportSubscription, error) {
+	subscription, err := s.GetSubscription(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the subscription as it will be after the update
+	updated := *subscription
+	if value, ok := updates["staff_id"].(uint); ok {
+		updated.StaffID = value
+	}
+	if value, ok := updates["property_id"].(string); ok {
+		updated.PropertyID = value
+	}
+	if value, ok := updates["report"].(string); ok {
+		updated.Report = value
+	}
+	if value, ok := updates["format"].(string); ok {
+		updated.Format = value
+	}
+	if value, ok := updates["frequency"].(string); ok {
+		updated.Frequency = value
+	}
+	if value, ok := updates["send_time"].(string); ok {
+		updated.SendTime = value
+	}
+	if value, ok := updates["weekday"].(int); ok {
+		updated.Weekday = value
+	}
+	if err := s.validate(&updated); err != nil {
+		return nil, err
+	}
+	normalized := map[string]interface{}{
+		"report":    updated.Report,
+		"format":    updated.Format,
+		"frequency": updated.Frequency,
+		"send_time": updated.SendTime,
+	}
+	for column, value := range normalized {
+		if _, ok := updates[column]; ok {
+			updates[column] = value
+		}
+	}
+
+	if err := s.db.Model(subscription).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update report subscription: %w", err)
+	}
+	return s.GetSubscription(id)
+}
+
+// DeleteSubscription stops a subscription; its delivery history is kept
+func (s *ReportSubscriptionService) DeleteSubscription(id uint) error {
+	result := s.db.Delete(&models.ReportSubscription{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete report subscription: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// GetDeliveries returns a delivery history, newest first
+func (s *ReportSubscriptionService) GetDeliveries(filter DeliveryFilter) ([]models.ReportDelivery, error) {
+	query := s.db.Model(&models.ReportDelivery{})
+	if filter.SubscriptionID != 0 {
+		query = query.Where("subscription_id = ?", filter.SubscriptionID)
+	}
+	if filter.StaffID != 0 {
+		query = query.Where("staff_id = ?", filter.StaffID)
+	}
+	if filter.PropertyID != "" {
+		query = query.Where("property_id = ?", filter.PropertyID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Limit <= 0 || filter.Limit > 500 {
+		filter.Limit = 100
+	}
+
+	var deliveries []models.ReportDelivery
+	if err := query.Order("id DESC").Limit(filter.Limit).Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get report deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// SendNow emails a subscription's report for its most recent period straight
+// away, whatever its schedule
+func (s *ReportSubscriptionService) SendNow(ctx context.Context, id uint) (*models.ReportDelivery, error) {
+	if s.email == nil {
+		return nil, ErrEmailNotConfigured
+	}
+	subscription, err := s.GetSubscription(id)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := LoadPropertyClock(s.db, subscription.PropertyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load property clock: %w", err)
+	}
+
+	start, end := subscriptionPeriod(subscription, clock.Today())
+	delivery := s.deliver(ctx, subscription, start, end, false)
+	if delivery.Status == DeliveryFailed {
+		return delivery, errors.New(delivery.Error)
+	}
+	return delivery, nil
+}
+
+// SendDue emails every active subscription whose send time has passed today,
+// in the property's time zone, and whose current period has not been sent.
+// Failed periods are retried until the configured number of attempts is used.
+func (s *ReportSubscriptionService) SendDue(ctx context.Context) int {
+	if s.email == nil {
+		return 0
+	}
+
+	var subscriptions []models.ReportSubscription
+	if err := s.db.Preload("Staff").Where("is_active = ?", true).Find(&subscriptions).Error; err != nil {
+		logging.WithError(err).Error("Failed to fetch report subscriptions")
+		return 0
+	}
+
+	maxAttempts := s.config.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultSubscriptionMaxAttempts
+	}
+
+	sent := 0
+	clocks := make(map[string]*PropertyClock)
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		clock, ok := clocks[subscription.PropertyID]
+		if !ok {
+			var err error
+			clock, err = LoadPropertyClock(s.db, subscription.PropertyID)
+			if err != nil {
+				logging.WithError(err).WithField("property_id", subscription.PropertyID).Error("Failed to load property clock for report subscriptions")
+				continue
+			}
+			clocks[subscription.PropertyID] = clock
+		}
+		if !subscriptionDue(subscription, clock.Now()) {
+			continue
+		}
+
+		start, end := subscriptionPeriod(subscription, clock.Today())
+		var delivered, failed int64
+		err := s.db.Model(&models.ReportDelivery{}).
+			Where("subscription_id = ? AND scheduled = ? AND status = ? AND DATE(period_end) = ?", subscription.ID, true, DeliverySent, end.Format("2006-01-02")).
+			Count(&delivered).Error
+		if err == nil && delivered == 0 {
+			err = s.db.Model(&models.ReportDelivery{}).
+				Where("subscription_id = ? AND scheduled = ? AND status = ? AND DATE(period_end) = ?", subscription.ID, true, DeliveryFailed, end.Format("2006-01-02")).
+				Count(&failed).Error
+		}
+		if err != nil || delivered > 0 || failed >= int64(maxAttempts) {
+			continue
+		}
+
+		if delivery := s.deliver(ctx, subscription, start, end, true); delivery.Status == DeliverySent {
+			sent++
+		}
+	}
+	return sent
+}
+
+// StartScheduler periodically emails subscribed reports that are due
+func (s *ReportSubscriptionService) StartScheduler(ctx context.Context) {
+	interval := s.config.Interval
+	if interval <= 0 {
+		interval = defaultSubscriptionInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logging.Info("Report subscription scheduler stopped")
+			return
+		case <-ticker.C:
+			s.SendDue(ctx)
+		}
+	}
+}
+
+// deliver renders a subscription's report for a period, emails it and records
+// the attempt in the delivery history and the audit log
+func (s *ReportSubscriptionService) deliver(ctx context.Context, subscription *models.ReportSubscription, start, end time.Time, scheduled bool) *models.ReportDelivery {
+	delivery := &models.ReportDelivery{
+		SubscriptionID: subscription.ID,
+		StaffID:        subscription.StaffID,
+		PropertyID:     subscription.PropertyID,
+		Report:         subscription.Report,
+		Format:         subscription.Format,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		Scheduled:      scheduled,
+	}
+
+	err := s.send(ctx, subscription, delivery)
+	if err != nil {
+		delivery.Status = DeliveryFailed
+		delivery.Error = err.Error()
+	} else {
+		delivery.Status = DeliverySent
+	}
+	if dbErr := s.db.Create(delivery).Error; dbErr != nil {
+		logging.WithError(dbErr).WithField("subscription_id", subscription.ID).Error("Failed to record report delivery")
+	}
+
+	if s.exports != nil {
+		s.exports.Record(ctx, subscription.StaffID, subscriptionResource(subscription.Report), ExportRecord{
+			Report:     subscription.Report,
+			Format:     export.Format(subscription.Format),
+			PropertyID: subscription.PropertyID,
+			From:       start.Format("2006-01-02"),
+			To:         end.Format("2006-01-02"),
+			Rows:       delivery.Rows,
+		}, "", "report-subscription", err)
+	}
+
+	fields := logrus.Fields{
+		"service":         "ReportSubscriptionService",
+		"subscription_id": subscription.ID,
+		"staff_id":        subscription.StaffID,
+		"property_id":     subscription.PropertyID,
+		"report":          subscription.Report,
+		"period_end":      end.Format("2006-01-02"),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		logging.WithFields(fields).Warn("Failed to deliver subscribed report")
+	} else {
+		fields["sent_to"] = delivery.SentTo
+		logging.WithFields(fields).Info("Subscribed report delivered")
+	}
+	return delivery
+}
+
+// send renders the report into an attachment and emails it to the subscriber
+func (s *ReportSubscriptionService) send(ctx context.Context, subscription *models.ReportSubscription, delivery *models.ReportDelivery) error {
+	if subscription.Staff == nil || !subscription.Staff.IsActive || subscription.Staff.Email == "" {
+		return ErrNoSubscriber
+	}
+	delivery.SentTo = subscription.Staff.Email
+
+	format, err := export.ParseFormat(subscription.Format)
+	if err != nil {
+		return err
+	}
+	title, name := subscriptionReportTitle(subscription.Report), reportFilename(subscription, delivery.PeriodStart, delivery.PeriodEnd)
+	period := formatPeriod(delivery.PeriodStart, delivery.PeriodEnd)
+	branding := export.Branding{PropertyName: subscription.PropertyID, Title: title, Subtitle: period}
+	if s.exports != nil {
+		branding = s.exports.Branding(subscription.PropertyID, title, period)
+	}
+
+	var buf bytes.Buffer
+	writer, err := export.NewWriter(&buf, format, branding)
+	if err != nil {
+		return err
+	}
+	switch subscription.Report {
+	case ReportDaily:
+		report, err := s.breakfast.GetDailyReport(subscription.PropertyID, delivery.PeriodEnd)
+		if err != nil {
+			return fmt.Errorf("failed to build daily report: %w", err)
+		}
+		delivery.Rows, err = WriteDailyReport(writer, report)
+		if err != nil {
+			return fmt.Errorf("failed to render daily report: %w", err)
+		}
+	case ReportConsumptionHistory:
+		delivery.Rows, err = s.breakfast.ExportConsumptionHistory(ctx, subscription.PropertyID, delivery.PeriodStart, delivery.PeriodEnd, writer)
+		if err != nil {
+			return fmt.Errorf("failed to render consumption history: %w", err)
+		}
+	default:
+		return ErrUnknownReport
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	delivery.Bytes = buf.Len()
+
+	subject := fmt.Sprintf("%s for %s, %s", title, branding.PropertyName, period)
+	body := fmt.Sprintf("Hello %s,\n\nAttached is the %s for %s covering %s.\n\nYou receive this email because you subscribed to it. Manage your subscriptions in the breakfast module.\n",
+		subscription.Staff.FirstName, strings.ToLower(title), branding.PropertyName, period)
+	attachment := EmailAttachment{Filename: format.Filename(name), ContentType: format.ContentType(), Data: buf.Bytes()}
+	if err := s.email.SendWithAttachments(ctx, delivery.SentTo, subject, body, []EmailAttachment{attachment}); err != nil {
+		return fmt.Errorf("failed to email report: %w", err)
+	}
+	return nil
+}
+
+// validate normalizes a subscription and checks its report, format, schedule,
+// subscriber and property
+func (s *ReportSubscriptionService) validate(subscription *models.ReportSubscription) error {
+	subscription.Report = strings.ToLower(strings.TrimSpace(subscription.Report))
+	if subscription.Report != ReportDaily && subscription.Report != ReportConsumptionHistory {
+		return ErrUnknownReport
+	}
+	format, err := export.ParseFormat(subscription.Format)
+	if err != nil {
+		return err
+	}
+	subscription.Format = string(format)
+
+	subscription.Frequency = strings.ToLower(strings.TrimSpace(subscription.Frequency))
+	if subscription.Frequency == "" {
+		subscription.Frequency = FrequencyDaily
+	}
+	subscription.SendTime = strings.TrimSpace(subscription.SendTime)
+	if subscription.SendTime == "" {
+		subscription.SendTime = defaultSubscriptionSendTime
+	}
+	sendTime, err := time.Parse("15:04", subscription.SendTime)
+	if err != nil || (subscription.Frequency != FrequencyDaily && subscription.Frequency != FrequencyWeekly) ||
+		subscription.Weekday < 0 || subscription.Weekday > 6 {
+		return ErrInvalidSchedule
+	}
+	subscription.SendTime = sendTime.Format("15:04")
+
+	var staff models.Staff
+	err = s.db.Where("id = ? AND is_active = ?", subscription.StaffID, true).First(&staff).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && staff.Email == "") {
+		return ErrNoSubscriber
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get subscriber: %w", err)
+	}
+
+	var properties int64
+	if err := s.db.Model(&models.Property{}).Where("property_id = ?", subscription.PropertyID).Count(&properties).Error; err != nil {
+		return fmt.Errorf("failed to get property: %w", err)
+	}
+	if properties == 0 {
+		return ErrPropertyNotFound
+	}
+	return nil
+}
+
+// subscriptionDue reports whether a subscription's send time has passed on a
+// day it is sent, given the property's local time
+func subscriptionDue(subscription *models.ReportSubscription, now time.Time) bool {
+	if subscription.Frequency == FrequencyWeekly && int(now.Weekday()) != subscription.Weekday {
+		return false
+	}
+	return now.Format("15:04") >= subscription.SendTime
+}
+
+// subscriptionPeriod returns the business dates a report sent on a business
+// date covers: the day before for daily subscriptions, the seven days before
+// for weekly ones. A daily report only ever covers the last of them.
+func subscriptionPeriod(subscription *models.ReportSubscription, today time.Time) (time.Time, time.Time) {
+	end := calendarDate(today).AddDate(0, 0, -1)
+	if subscription.Frequency == FrequencyWeekly && subscription.Report != ReportDaily {
+		return end.AddDate(0, 0, -6), end
+	}
+	return end, end
+}
+
+func subscriptionReportTitle(report string) string {
+	if report == ReportConsumptionHistory {
+		return "Consumption history"
+	}
+	return "Daily breakfast report"
+}
+
+func subscriptionResource(report string) audit.AuditResource {
+	if report == ReportConsumptionHistory {
+		return audit.ResourceConsumption
+	}
+	return audit.ResourceReport
+}
+
+// reportFilename matches the names of the same reports downloaded from the API
+func reportFilename(subscription *models.ReportSubscription, start, end time.Time) string {
+	if subscription.Report == ReportConsumptionHistory {
+		return fmt.Sprintf("consumption-%s-%s-%s", subscription.PropertyID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("daily-report-%s-%s", subscription.PropertyID, end.Format("2006-01-02"))
+}
+
+func formatPeriod(start, end time.Time) string {
+	if start.Equal(end) {
+		return end.Format("2006-01-02")
+	}
+	return start.Format("2006-01-02") + " to " + end.Format("2006-01-02")
+}
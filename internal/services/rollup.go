@@ -0,0 +1,643 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"hudini-breakfast-module/internal/cache"
+	"hudini-breakfast-module/internal/config"
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Errors returned by the rollup service
+var (
+	ErrInvalidRollupRange = errors.New("rollup range must start on or before its end")
+)
+
+// defaultRollupInterval is how often rollups are refreshed in the background,
+// and how old today's rollup may get before a read refreshes it
+const defaultRollupInterval = 15 * time.Minute
+
+// defaultRollupDebounce is how long visit changes are gathered before a
+// business date's rollups are refreshed in the background
+const defaultRollupDebounce = 5 * time.Second
+
+// ConsumptionListener is told when the visits of a property's business date change
+type ConsumptionListener interface {
+	ConsumptionChanged(propertyID string, businessDate time.Time)
+}
+
+// RollupService materialises per-property, per-outlet daily and hourly
+// breakfast aggregates, which reports and dashboards read instead of
+// scanning visits and guests. A business date's rollups are recomputed
+// in the background shortly after its visits change and can be rebuilt for
+// any range.
+type RollupService struct {
+	db     *gorm.DB
+	cache  *cache.VIPCache
+	config config.RollupConfig
+
+	// Business dates whose visits changed, waiting for their background refresh
+	mutex   sync.Mutex
+	pending map[rollupDate]*pendingRefresh
+}
+
+// pendingRefresh is a scheduled background refresh of a business date
+type pendingRefresh struct {
+	timer *time.Timer
+}
+
+// rollupDate is a property's business date
+type rollupDate struct {
+	propertyID string
+	date       string
+}
+
+// NewRollupService creates a new rollup service
+func NewRollupService(db *gorm.DB, cfg config.RollupConfig) *RollupService {
+	return &RollupService{
+		db:      db,
+		config:  cfg,
+		pending: make(map[rollupDate]*pendingRefresh),
+	}
+}
+
+// SetCache sets the cache refreshed daily stats are published to
+func (s *RollupService) SetCache(vipCache *cache.VIPCache) {
+	s.cache = vipCache
+}
+
+// ConsumptionChanged schedules a background refresh of the rollups of a
+// business date whose visits changed. Changes made while a refresh is waiting
+// are picked up by it, so a busy service refreshes at most once per debounce.
+func (s *RollupService) ConsumptionChanged(propertyID string, businessDate time.Time) {
+	key := rollupDate{propertyID: propertyID, date: calendarDate(businessDate).Format("2006-01-02")}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.pending[key]; ok {
+		return
+	}
+	refresh := &pendingRefresh{}
+	refresh.timer = time.AfterFunc(s.debounce(), func() {
+		s.refreshPending(key, refresh, businessDate)
+	})
+	s.pending[key] = refresh
+}
+
+// refreshPending runs a scheduled refresh unless a read has already taken it
+func (s *RollupService) refreshPending(key rollupDate, refresh *pendingRefresh, businessDate time.Time) {
+	s.mutex.Lock()
+	if s.pending[key] != refresh {
+		s.mutex.Unlock()
+		return
+	}
+	delete(s.pending, key)
+	s.mutex.Unlock()
+
+	if _, err := s.Refresh(context.Background(), key.propertyID, businessDate); err != nil {
+		logging.WithFields(logrus.Fields{
+			"service":       "RollupService",
+			"method":        "ConsumptionChanged",
+			"property_id":   key.propertyID,
+			"business_date": key.date,
+			"error":         err.Error(),
+		}).Warn("Failed to refresh rollups")
+	}
+}
+
+// takePending cancels a business date's scheduled refresh, reporting whether
+// its rollups are stale
+func (s *RollupService) takePending(propertyID string, date time.Time) bool {
+	key := rollupDate{propertyID: propertyID, date: date.Format("2006-01-02")}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	refresh, ok := s.pending[key]
+	if !ok {
+		return false
+	}
+	refresh.timer.Stop()
+	delete(s.pending, key)
+	return true
+}
+
+// Refresh recomputes a property's daily and hourly rollups for a business
+// date and returns the property-wide row
+func (s *RollupService) Refresh(ctx context.Context, propertyID string, date time.Time) (*models.DailyRollup, error) {
+	db := s.db.WithContext(ctx)
+	date = calendarDate(date)
+	dateStr := date.Format("2006-01-02")
+
+	engine, err := LoadEligibilityEngine(db, propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var visits []models.DailyBreakfastConsumption
+	err = db.Where("property_id = ? AND DATE(consumption_date) = ? AND status IN ?", propertyID, dateStr, []string{"consumed", "no_show"}).
+		Order("id ASC").
+		Find(&visits).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch visits: %w", err)
+	}
+
+	// Checked-out guests are deactivated, so past dates include inactive guests
+	var guests []models.Guest
+	err = db.Where("property_id = ? AND DATE(check_in_date) <= ? AND DATE(check_out_date) >= ?", propertyID, dateStr, dateStr).
+		Order("room_number ASC, check_in_date ASC, id ASC").
+		Find(&guests).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch in-house guests: %w", err)
+	}
+
+	// Entitlement, one guest per room
+	total := newRollupTally(propertyID, 0, date)
+	entitled := make(map[uint]int)
+	for start := 0; start < len(guests); {
+		end := start + 1
+		for end < len(guests) && guests[end].RoomNumber == guests[start].RoomNumber {
+			end++
+		}
+		if guest, decision := engine.pick(guests[start:end], date, nil); decision.Eligible {
+			total.row.EntitledRooms++
+			total.row.EntitledCovers += decision.Covers
+			entitled[guest.ID] = decision.Covers
+		}
+		start = end
+	}
+
+	vips, err := s.vipGuests(db, visits)
+	if err != nil {
+		return nil, err
+	}
+
+	outlets := make(map[uint]*rollupTally)
+	hours := make(map[rollupHour]*models.HourlyRollup)
+	hour := func(outletID uint, at time.Time) *models.HourlyRollup {
+		key := rollupHour{outletID: outletID, hour: at.In(engine.clock.Location).Hour()}
+		if hours[key] == nil {
+			hours[key] = &models.HourlyRollup{PropertyID: propertyID, OutletID: outletID, BusinessDate: date, Hour: key.hour}
+		}
+		return hours[key]
+	}
+
+	for i := range visits {
+		visit := &visits[i]
+		tallies := []*rollupTally{total}
+		if visit.OutletID != nil {
+			if outlets[*visit.OutletID] == nil {
+				outlets[*visit.OutletID] = newRollupTally(propertyID, *visit.OutletID, date)
+			}
+			tallies = append(tallies, outlets[*visit.OutletID])
+		}
+
+		if visit.Status == "no_show" {
+			for _, tally := range tallies {
+				tally.noShows[visit.RoomNumber] = true
+			}
+			continue
+		}
+
+		covers := visit.AdultCovers + visit.ChildCovers
+		taken := minInt(covers-visit.UpsellCovers, entitled[visit.GuestID])
+		if taken > 0 {
+			entitled[visit.GuestID] -= taken
+		}
+		sample, timed := visitServiceTime(visit)
+		for _, tally := range tallies {
+			tally.add(visit, covers, max(taken, 0), vips[visit.GuestID])
+			if timed {
+				tally.row.ServiceMinutes += sample.minutes
+				tally.row.ServiceSamples++
+			}
+		}
+
+		at := visit.CreatedAt
+		if visit.ConsumedAt != nil {
+			at = *visit.ConsumedAt
+		}
+		outletIDs := []uint{0}
+		if visit.OutletID != nil {
+			outletIDs = append(outletIDs, *visit.OutletID)
+		}
+		for _, outletID := range outletIDs {
+			bucket := hour(outletID, at)
+			bucket.Visits++
+			bucket.Covers += covers
+			bucket.Revenue += visit.Amount
+			if vips[visit.GuestID] {
+				bucket.VIPCovers += covers
+			}
+			if visit.OHIPCovered {
+				bucket.OHIPVisits++
+			}
+			// Service times fall in the hour the party was seated
+			if timed {
+				served := hour(outletID, sample.at)
+				served.ServiceMinutes += sample.minutes
+				served.ServiceSamples++
+			}
+		}
+	}
+
+	now := time.Now()
+	rows := []models.DailyRollup{total.finish(now)}
+	for _, tally := range outlets {
+		rows = append(rows, tally.finish(now))
+	}
+	hourly := make([]models.HourlyRollup, 0, len(hours))
+	for _, bucket := range hours {
+		bucket.Revenue = roundTo(bucket.Revenue, 2)
+		hourly = append(hourly, *bucket)
+	}
+	sort.Slice(hourly, func(i, j int) bool {
+		if hourly[i].OutletID != hourly[j].OutletID {
+			return hourly[i].OutletID < hourly[j].OutletID
+		}
+		return hourly[i].Hour < hourly[j].Hour
+	})
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("property_id = ? AND DATE(business_date) = ?", propertyID, dateStr).Delete(&models.DailyRollup{}).Error; err != nil {
+			return fmt.Errorf("failed to clear daily rollups: %w", err)
+		}
+		if err := tx.Where("property_id = ? AND DATE(business_date) = ?", propertyID, dateStr).Delete(&models.HourlyRollup{}).Error; err != nil {
+			return fmt.Errorf("failed to clear hourly rollups: %w", err)
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return fmt.Errorf("failed to save daily rollups: %w", err)
+		}
+		if len(hourly) > 0 {
+			if err := tx.Create(&hourly).Error; err != nil {
+				return fmt.Errorf("failed to save hourly rollups: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishStats(ctx, &rows[0], hourly, len(guests))
+	return &rows[0], nil
+}
+
+// Rebuild recomputes the rollups of every business date from through to,
+// inclusive, for one property or, with an empty property ID, all of them. A
+// zero from starts at a property's first visit and a zero to ends today.
+// It returns the number of property days rebuilt.
+func (s *RollupService) Rebuild(ctx context.Context, propertyID string, from, to time.Time) (int, error) {
+	if !from.IsZero() && !to.IsZero() && calendarDate(from).After(calendarDate(to)) {
+		return 0, ErrInvalidRollupRange
+	}
+
+	properties := []string{propertyID}
+	if propertyID == "" {
+		var err error
+		if properties, err = s.properties(); err != nil {
+			return 0, err
+		}
+	}
+
+	rebuilt := 0
+	for _, property := range properties {
+		start, end := calendarDate(from), calendarDate(to)
+		if from.IsZero() {
+			var first []models.DailyBreakfastConsumption
+			err := s.db.WithContext(ctx).Select("consumption_date").
+				Where("property_id = ?", property).
+				Order("consumption_date ASC").
+				Limit(1).
+				Find(&first).Error
+			if err != nil {
+				return rebuilt, fmt.Errorf("failed to find first visit: %w", err)
+			}
+			if len(first) == 0 {
+				continue
+			}
+			start = calendarDate(first[0].ConsumptionDate)
+		}
+		if to.IsZero() {
+			end = currentBusinessDate(s.db, property)
+		}
+
+		for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+			if err := ctx.Err(); err != nil {
+				return rebuilt, err
+			}
+			if _, err := s.Refresh(ctx, property, date); err != nil {
+				return rebuilt, fmt.Errorf("failed to rebuild %s on %s: %w", property, date.Format("2006-01-02"), err)
+			}
+			rebuilt++
+		}
+	}
+	return rebuilt, nil
+}
+
+// Days returns a property's rollups for the business dates from through to,
+// inclusive, one per date in order
+func (s *RollupService) Days(propertyID string, from, to time.Time) ([]models.DailyRollup, error) {
+	if err := s.ensure(propertyID, from, to); err != nil {
+		return nil, err
+	}
+	var rows []models.DailyRollup
+	err := s.db.Where("property_id = ? AND outlet_id = ? AND DATE(business_date) >= ? AND DATE(business_date) <= ?",
+		propertyID, 0, calendarDate(from).Format("2006-01-02"), calendarDate(to).Format("2006-01-02")).
+		Order("business_date ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch daily rollups: %w", err)
+	}
+	return rows, nil
+}
+
+// OutletDays returns the per-outlet rollups of a property for the business
+// dates from through to, inclusive
+func (s *RollupService) OutletDays(propertyID string, from, to time.Time) ([]models.DailyRollup, error) {
+	if err := s.ensure(propertyID, from, to); err != nil {
+		return nil, err
+	}
+	var rows []models.DailyRollup
+	err := s.db.Where("property_id = ? AND outlet_id <> ? AND DATE(business_date) >= ? AND DATE(business_date) <= ?",
+		propertyID, 0, calendarDate(from).Format("2006-01-02"), calendarDate(to).Format("2006-01-02")).
+		Order("business_date ASC, outlet_id ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch outlet rollups: %w", err)
+	}
+	return rows, nil
+}
+
+// Hours returns a property's hourly rollups for the business dates from
+// through to, inclusive
+func (s *RollupService) Hours(propertyID string, from, to time.Time) ([]models.HourlyRollup, error) {
+	if err := s.ensure(propertyID, from, to); err != nil {
+		return nil, err
+	}
+	var rows []models.HourlyRollup
+	err := s.db.Where("property_id = ? AND outlet_id = ? AND DATE(business_date) >= ? AND DATE(business_date) <= ?",
+		propertyID, 0, calendarDate(from).Format("2006-01-02"), calendarDate(to).Format("2006-01-02")).
+		Order("business_date ASC, hour ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch hourly rollups: %w", err)
+	}
+	return rows, nil
+}
+
+// RefreshRecent refreshes every property's rollups for today and yesterday,
+// picking up check-ins and late close-outs that no visit write reported
+func (s *RollupService) RefreshRecent(ctx context.Context) {
+	properties, err := s.properties()
+	if err != nil {
+		logging.WithError(err).Error("Failed to list properties for rollups")
+		return
+	}
+	for _, propertyID := range properties {
+		today := currentBusinessDate(s.db, propertyID)
+		if _, err := s.Rebuild(ctx, propertyID, today.AddDate(0, 0, -1), today); err != nil {
+			logging.WithFields(logrus.Fields{
+				"service":     "RollupService",
+				"method":      "RefreshRecent",
+				"property_id": propertyID,
+				"error":       err.Error(),
+			}).Error("Failed to refresh rollups")
+		}
+	}
+}
+
+// StartScheduler keeps recent rollups fresh until ctx is cancelled
+func (s *RollupService) StartScheduler(ctx context.Context) {
+	ticker := time.NewTicker(s.interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logging.Info("Rollup scheduler stopped")
+			return
+		case <-ticker.C:
+			s.RefreshRecent(ctx)
+		}
+	}
+}
+
+// ensure rolls up the business dates in a range, up to today, that have never
+// been rolled up, whose visits changed since their last refresh, and today's
+// when its rollup has gone stale
+func (s *RollupService) ensure(propertyID string, from, to time.Time) error {
+	from, to = calendarDate(from), calendarDate(to)
+	today := currentBusinessDate(s.db, propertyID)
+	if to.After(today) {
+		to = today
+	}
+	if from.After(to) {
+		return nil
+	}
+
+	var rows []models.DailyRollup
+	err := s.db.Select("business_date", "refreshed_at").
+		Where("property_id = ? AND outlet_id = ? AND DATE(business_date) >= ? AND DATE(business_date) <= ?",
+			propertyID, 0, from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Find(&rows).Error
+	if err != nil {
+		return fmt.Errorf("failed to fetch daily rollups: %w", err)
+	}
+	fresh := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		date := calendarDate(row.BusinessDate)
+		fresh[date.Format("2006-01-02")] = !date.Equal(today) || time.Since(row.RefreshedAt) < s.interval()
+	}
+
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		if s.takePending(propertyID, date) || !fresh[date.Format("2006-01-02")] {
+			if _, err := s.Refresh(context.Background(), propertyID, date); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// vipGuests returns which of the visiting guests are VIPs
+func (s *RollupService) vipGuests(db *gorm.DB, visits []models.DailyBreakfastConsumption) (map[uint]bool, error) {
+	vips := make(map[uint]bool)
+	if len(visits) == 0 {
+		return vips, nil
+	}
+	guestIDs := make([]uint, 0, len(visits))
+	for _, visit := range visits {
+		guestIDs = append(guestIDs, visit.GuestID)
+	}
+
+	var ids []uint
+	err := db.Model(&models.Guest{}).Where("id IN ?", guestIDs).Where(&models.Guest{IsVIP: true}).Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch VIP guests: %w", err)
+	}
+	for _, id := range ids {
+		vips[id] = true
+	}
+	return vips, nil
+}
+
+// properties returns every property with a configuration or recorded visits
+func (s *RollupService) properties() ([]string, error) {
+	var configured, visited []string
+	if err := s.db.Model(&models.Property{}).Pluck("property_id", &configured).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch properties: %w", err)
+	}
+	if err := s.db.Model(&models.DailyBreakfastConsumption{}).Distinct().Pluck("property_id", &visited).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch visited properties: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var properties []string
+	for _, propertyID := range append(configured, visited...) {
+		if propertyID != "" && !seen[propertyID] {
+			seen[propertyID] = true
+			properties = append(properties, propertyID)
+		}
+	}
+	sort.Strings(properties)
+	return properties, nil
+}
+
+// publishStats caches a refreshed business date's headline stats
+func (s *RollupService) publishStats(ctx context.Context, day *models.DailyRollup, hours []models.HourlyRollup, inHouse int) {
+	if s.cache == nil {
+		return
+	}
+
+	stats := &cache.DailyStats{
+		Date:            day.BusinessDate.Format("2006-01-02"),
+		TotalGuests:     inHouse,
+		TotalBreakfasts: day.Covers,
+		VIPBreakfasts:   day.VIPCovers,
+		LastUpdated:     day.RefreshedAt,
+	}
+	if day.EntitledRooms > 0 {
+		stats.ConsumptionRate = roundTo(float64(day.RoomsServed)/float64(day.EntitledRooms)*100, 1)
+	}
+	if day.ServiceSamples > 0 {
+		stats.AverageServiceTime = roundTo(day.ServiceMinutes/float64(day.ServiceSamples), 1)
+	}
+	peak := -1
+	for i, hour := range hours {
+		if hour.OutletID == 0 && hour.Covers > 0 && (peak < 0 || hour.Covers > hours[peak].Covers) {
+			peak = i
+		}
+	}
+	if peak >= 0 {
+		stats.PeakHour = fmt.Sprintf("%02d:00", hours[peak].Hour)
+	}
+
+	if err := s.cache.SetDailyStats(ctx, day.PropertyID, stats.Date, stats); err != nil {
+		logging.WithError(err).Warn("Failed to cache daily stats")
+	}
+}
+
+func (s *RollupService) debounce() time.Duration {
+	if s.config.Debounce <= 0 {
+		return defaultRollupDebounce
+	}
+	return s.config.Debounce
+}
+
+func (s *RollupService) interval() time.Duration {
+	if s.config.Interval <= 0 {
+		return defaultRollupInterval
+	}
+	return s.config.Interval
+}
+
+// sumRollups adds up daily rollups; distinct counts are summed per day
+func sumRollups(rows []models.DailyRollup) models.DailyRollup {
+	var total models.DailyRollup
+	for _, row := range rows {
+		total.Visits += row.Visits
+		total.RoomsServed += row.RoomsServed
+		total.GuestsServed += row.GuestsServed
+		total.Covers += row.Covers
+		total.UpsellCovers += row.UpsellCovers
+		total.EntitledRooms += row.EntitledRooms
+		total.EntitledCovers += row.EntitledCovers
+		total.TakenCovers += row.TakenCovers
+		total.Revenue += row.Revenue
+		total.NoShows += row.NoShows
+		total.VIPGuests += row.VIPGuests
+		total.VIPCovers += row.VIPCovers
+		total.OHIPVisits += row.OHIPVisits
+		total.PMSPosted += row.PMSPosted
+		total.ServiceMinutes += row.ServiceMinutes
+		total.ServiceSamples += row.ServiceSamples
+	}
+	total.Revenue = roundTo(total.Revenue, 2)
+	return total
+}
+
+// rollupHour keys an hourly rollup
+type rollupHour struct {
+	outletID uint
+	hour     int
+}
+
+// rollupTally accumulates one daily rollup row and its distinct counts
+type rollupTally struct {
+	row     models.DailyRollup
+	rooms   map[string]bool
+	guests  map[uint]bool
+	vips    map[uint]bool
+	noShows map[string]bool
+}
+
+func newRollupTally(propertyID string, outletID uint, date time.Time) *rollupTally {
+	return &rollupTally{
+		row:     models.DailyRollup{PropertyID: propertyID, OutletID: outletID, BusinessDate: date},
+		rooms:   make(map[string]bool),
+		guests:  make(map[uint]bool),
+		vips:    make(map[uint]bool),
+		noShows: make(map[string]bool),
+	}
+}
+
+// add counts a consumed visit
+func (t *rollupTally) add(visit *models.DailyBreakfastConsumption, covers, taken int, vip bool) {
+	t.row.Visits++
+	t.row.Covers += covers
+	t.row.UpsellCovers += visit.UpsellCovers
+	t.row.TakenCovers += taken
+	t.row.Revenue += visit.Amount
+	t.rooms[visit.RoomNumber] = true
+	t.guests[visit.GuestID] = true
+	if vip {
+		t.vips[visit.GuestID] = true
+		t.row.VIPCovers += covers
+	}
+	if visit.OHIPCovered {
+		t.row.OHIPVisits++
+	}
+	if visit.PMSPosted {
+		t.row.PMSPosted++
+	}
+}
+
+// finish fills in the distinct counts and returns the row
+func (t *rollupTally) finish(now time.Time) models.DailyRollup {
+	t.row.RoomsServed = len(t.rooms)
+	t.row.GuestsServed = len(t.guests)
+	t.row.VIPGuests = len(t.vips)
+	t.row.NoShows = len(t.noShows)
+	t.row.Revenue = roundTo(t.row.Revenue, 2)
+	t.row.RefreshedAt = now
+	return t.row
+}
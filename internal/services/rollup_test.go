@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hudini-breakfast-module/internal/config"
+	"hudini-breakfast-module/internal/models"
+)
+
+// pendingRefreshes returns how many business dates wait for a background refresh
+func (s *RollupService) pendingRefreshes() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.pending)
+}
+
+func TestRollupRefreshAfterVisitChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		debounce time.Duration
+		changes  int
+		wait     bool // wait for the background refresh instead of reading at once
+		covers   int
+	}{
+		// Yesterday's rollup is only recomputed once a change is reported
+		{"no change reported", time.Hour, 0, false, 0},
+		{"read before the debounced refresh", time.Hour, 1, false, 2},
+		{"read picks up changes gathered for the refresh", time.Hour, 3, false, 2},
+		{"debounced refresh in the background", 20 * time.Millisecond, 3, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			yesterday := currentBusinessDate(db, "P1").AddDate(0, 0, -1)
+			mustCreate(t, db, &models.Property{PropertyID: "P1", Name: "Harbour Hotel", TimeZone: "UTC"})
+			guest := models.Guest{
+				PMSGuestID: "G1", ReservationID: "R1", RoomNumber: "101", FirstName: "Ada", LastName: "Guest",
+				PropertyID: "P1", IsActive: true, BreakfastPackage: true, AdultCount: 2,
+				CheckInDate: yesterday.AddDate(0, 0, -1), CheckOutDate: yesterday.AddDate(0, 0, 2),
+			}
+			mustCreate(t, db, &guest)
+
+			service := NewRollupService(db, config.RollupConfig{Debounce: tt.debounce})
+			if _, err := service.Refresh(context.Background(), "P1", yesterday); err != nil {
+				t.Fatalf("Refresh: %v", err)
+			}
+
+			// A visit recorded after the rollup
+			consumedAt := yesterday.Add(8 * time.Hour)
+			mustCreate(t, db, &models.DailyBreakfastConsumption{
+				PropertyID: "P1", RoomNumber: "101", GuestID: guest.ID, ConsumptionDate: yesterday,
+				ConsumedAt: &consumedAt, Status: "consumed", AdultCovers: 2, Amount: 40,
+			})
+			for i := 0; i < tt.changes; i++ {
+				service.ConsumptionChanged("P1", yesterday)
+			}
+			if tt.changes > 0 && service.pendingRefreshes() != 1 {
+				t.Fatalf("%d refreshes scheduled, want 1", service.pendingRefreshes())
+			}
+
+			if tt.wait {
+				deadline := time.Now().Add(2 * time.Second)
+				for service.pendingRefreshes() > 0 && time.Now().Before(deadline) {
+					time.Sleep(5 * time.Millisecond)
+				}
+				// The refresh runs after leaving the pending set; it is done
+				// once the stored rollup counts the visit
+				for time.Now().Before(deadline) {
+					var row models.DailyRollup
+					if db.Where("property_id = ? AND outlet_id = 0", "P1").First(&row).Error == nil && row.Covers == tt.covers {
+						break
+					}
+					time.Sleep(5 * time.Millisecond)
+				}
+			}
+
+			days, err := service.Days("P1", yesterday, yesterday)
+			if err != nil {
+				t.Fatalf("Days: %v", err)
+			}
+			if len(days) != 1 || days[0].Covers != tt.covers {
+				t.Fatalf("rollups = %+v, want one day with %d covers", days, tt.covers)
+			}
+			if service.pendingRefreshes() != 0 {
+				t.Errorf("%d refreshes still scheduled after the read", service.pendingRefreshes())
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ type RoomGridService struct {
 	db          *gorm.DB
 	pmsService  *PMSService
 	ohipService *OHIPService
+	listener    ConsumptionListener
 }
 
 func NewRoomGridService(db *gorm.DB, pmsService *PMSService, ohipService *OHIPService) *RoomGridService {
@@ -23,6 +24,16 @@ func NewRoomGridService(db *gorm.DB, pmsService *PMSService, ohipService *OHIPSe
 	}
 }
 
+// SetConsumptionListener sets who is told when visits are recorded
+func (s *RoomGridService) SetConsumptionListener(listener ConsumptionListener) {
+	s.listener = listener
+}
+
+// BusinessDate returns the property's current business date
+func (s *RoomGridService) BusinessDate(propertyID string) time.Time {
+	return currentBusinessDate(s.db, propertyID)
+}
+
 // GetRoomGrid returns the breakfast status for all rooms in a property
 func (s *RoomGridService) GetRoomGrid(propertyID string, date time.Time) ([]models.RoomBreakfastStatus, error) {
 	// Get all rooms for the property
@@ -31,8 +42,19 @@ func (s *RoomGridService) GetRoomGrid(propertyID string, date time.Time) ([]mode
 		return nil, fmt.Errorf("failed to get rooms: %w", err)
 	}
 
+	engine, err := LoadEligibilityEngine(s.db, propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Departure-day cutoffs only apply when looking at the current business day
+	var at *time.Time
+	dateOnly := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	if now := engine.Now(); engine.clock.BusinessDate(now).Equal(dateOnly) {
+		at = &now
+	}
+
 	var roomStatuses []models.RoomBreakfastStatus
-	dateOnly := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 
 	for _, room := range rooms {
 		status := models.RoomBreakfastStatus{
@@ -43,111 +65,110 @@ func (s *RoomGridService) GetRoomGrid(propertyID string, date time.Time) ([]mode
 			Status:     room.Status,
 		}
 
-		// Get current guest for this room
-		var guest models.Guest
-		err := s.db.Where("room_number = ? AND property_id = ? AND is_active = ? AND check_in_date <= ? AND check_out_date > ?",
-			room.RoomNumber, propertyID, true, dateOnly, dateOnly).
-			First(&guest).Error
+		// Get the guest breakfast is for in this room
+		guest, err := guestForBreakfast(s.db, engine, propertyID, room.RoomNumber, dateOnly, at, true)
 
 		if err == nil {
 			// Room has an active guest
+			decision := engine.evaluate(guest, dateOnly, at)
 			status.HasGuest = true
+			status.GuestID = guest.ID
+			status.Eligible = decision.Eligible
+			status.EligibilityReason = decision.Reason
 			status.GuestName = fmt.Sprintf("%s %s", guest.FirstName, guest.LastName)
 			status.BreakfastPackage = guest.BreakfastPackage
 			status.BreakfastCount = guest.BreakfastCount
 			status.CheckInDate = &guest.CheckInDate
 			status.CheckOutDate = &guest.CheckOutDate
+			status.AdultCount = guest.AdultCount
+			status.ChildCount = guest.ChildCount
+			if decision.Eligible {
+				status.CoversEntitled = decision.Covers
+			}
 
-			// Check if breakfast was consumed today
-			var consumption models.DailyBreakfastConsumption
+			// Collect today's visits for this room
+			var consumptions []models.DailyBreakfastConsumption
 			err = s.db.Preload("Staff").
-				Where("room_number = ? AND property_id = ? AND consumption_date = ?",
-					room.RoomNumber, propertyID, dateOnly).
-				First(&consumption).Error
-
-			if err == nil {
-				status.ConsumedToday = consumption.Status == "consumed"
-				status.ConsumedAt = consumption.ConsumedAt
-				if consumption.Staff != nil {
-					status.ConsumedBy = fmt.Sprintf("%s %s", consumption.Staff.FirstName, consumption.Staff.LastName)
+				Where("room_number = ? AND property_id = ? AND DATE(consumption_date) = ? AND status = ?",
+					room.RoomNumber, propertyID, dateOnly.Format("2006-01-02"), "consumed").
+				Order("consumed_at ASC").
+				Find(&consumptions).Error
+
+			if err == nil && len(consumptions) > 0 {
+				entitledServed := 0
+				for _, consumption := range consumptions {
+					status.CoversConsumed += consumption.AdultCovers + consumption.ChildCovers
+					entitledServed += consumption.AdultCovers + consumption.ChildCovers - consumption.UpsellCovers
+				}
+
+				last := consumptions[len(consumptions)-1]
+				status.ConsumedToday = true
+				status.ConsumedAt = last.ConsumedAt
+				if last.Staff != nil {
+					status.ConsumedBy = fmt.Sprintf("%s %s", last.Staff.FirstName, last.Staff.LastName)
 				}
+
+				if entitledServed < status.CoversEntitled {
+					status.CoversRemaining = status.CoversEntitled - entitledServed
+				}
+			} else {
+				status.CoversRemaining = status.CoversEntitled
 			}
 		}
 
 		roomStatuses = append(roomStatuses, status)
 	}
 
+	if err := flagRoomAllergies(s.db, roomStatuses); err != nil {
+		return nil, err
+	}
+
 	return roomStatuses, nil
 }
 
-// MarkBreakfastConsumed marks breakfast as consumed for a specific room
-func (s *RoomGridService) MarkBreakfastConsumed(propertyID, roomNumber string, staffID uint, paymentMethod string, notes string) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		// Get the guest for this room
-		var guest models.Guest
-		today := time.Now().Truncate(24 * time.Hour)
-		
-		err := tx.Where("room_number = ? AND property_id = ? AND is_active = ? AND check_in_date <= ? AND check_out_date > ?",
-			roomNumber, propertyID, true, today, today).
-			First(&guest).Error
+// MarkBreakfastConsumed records a breakfast visit for a specific room, optionally at an outlet (outletID 0 for none)
+func (s *RoomGridService) MarkBreakfastConsumed(propertyID, roomNumber string, staffID, outletID uint, paymentMethod string, notes string, covers CoverRequest) (*models.DailyBreakfastConsumption, error) {
+	var consumption *models.DailyBreakfastConsumption
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Visits are recorded against the property's business date
+		engine, err := LoadEligibilityEngine(tx, propertyID)
 		if err != nil {
-			return fmt.Errorf("no active guest found for room %s: %w", roomNumber, err)
-		}
-
-		if !guest.BreakfastPackage {
-			return fmt.Errorf("guest in room %s does not have breakfast package", roomNumber)
-		}
-
-		// Check if already consumed today
-		var existingConsumption models.DailyBreakfastConsumption
-		err = tx.Where("room_number = ? AND property_id = ? AND consumption_date = ?",
-			roomNumber, propertyID, today).First(&existingConsumption).Error
-		
-		if err == nil && existingConsumption.Status == "consumed" {
-			return fmt.Errorf("breakfast already consumed today for room %s", roomNumber)
+			return err
 		}
+		now := engine.Now()
+		today := engine.clock.BusinessDate(now)
 
-		now := time.Now()
-		consumption := models.DailyBreakfastConsumption{
-			PropertyID:      propertyID,
-			RoomNumber:      roomNumber,
-			GuestID:         guest.ID,
-			ConsumptionDate: today,
-			ConsumedAt:      &now,
-			ConsumedBy:      &staffID,
-			Status:          "consumed",
-			Notes:           notes,
-			PaymentMethod:   paymentMethod,
-			Amount:          25.00, // Default breakfast charge
-		}
-
-		// Handle OHIP coverage if applicable
-		if guest.OHIPNumber != "" && paymentMethod == "ohip" {
-			consumption.OHIPCovered = true
-			// Submit OHIP claim (simplified)
-			// In production, this would be more complex
-		}
-
-		if err := tx.Create(&consumption).Error; err != nil {
-			return fmt.Errorf("failed to create consumption record: %w", err)
+		// Get the guest for this room
+		guest, err := guestForBreakfast(tx, engine, propertyID, roomNumber, today, &now, true)
+		if err != nil {
+			return fmt.Errorf("no active guest found for room %s: %w", roomNumber, err)
 		}
 
-		// Post charge to PMS if payment method is room_charge
-		if paymentMethod == "room_charge" {
-			// Create charge request using the PMS service method
-			err := s.pmsService.ChargeBreakfast(guest.PMSGuestID, guest.ReservationID, roomNumber, consumption.Amount, propertyID)
-			if err != nil {
-				// Log error but don't fail the consumption tracking
-				fmt.Printf("Failed to post charge to PMS: %v\n", err)
-			} else {
-				consumption.PMSPosted = true
-				consumption.PMSTransactionID = "PMS_SUCCESS" // Simplified for now
-				tx.Save(&consumption)
-			}
+		visit, err := recordVisit(tx, engine, guest, visitRequest{
+			StaffID:       staffID,
+			OutletID:      outletID,
+			PaymentMethod: paymentMethod,
+			Notes:         notes,
+			Covers:        covers,
+			At:            now,
+		})
+		if err != nil {
+			return fmt.Errorf("room %s: %w", roomNumber, err)
 		}
+		consumption = visit.Consumption
 
-		return nil
+		// Post charge to PMS if payment method is room_charge; upsold covers
+		// are always charged to the room
+		return postVisitCharge(tx, s.pmsService, guest, visit)
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.listener != nil {
+		s.listener.ConsumptionChanged(propertyID, consumption.ConsumptionDate)
+	}
+	return consumption, nil
 }
 
 // GetRoomDetails returns detailed information for a specific room
@@ -199,6 +220,8 @@ func (s *RoomGridService) SyncGuestsFromPMS(propertyID string) error {
 					CheckOutDate:     pmsGuest.CheckOutDate,
 					BreakfastPackage: pmsGuest.BreakfastPackage,
 					BreakfastCount:   2, // Default: 2 breakfasts per day for double occupancy
+					RateCode:         pmsGuest.RateCode,
+					LoyaltyTier:      pmsGuest.LoyaltyTier,
 					PropertyID:       pmsGuest.PropertyID,
 					IsActive:         true,
 				}
@@ -212,6 +235,8 @@ func (s *RoomGridService) SyncGuestsFromPMS(propertyID string) error {
 				existingGuest.CheckInDate = pmsGuest.CheckInDate
 				existingGuest.CheckOutDate = pmsGuest.CheckOutDate
 				existingGuest.BreakfastPackage = pmsGuest.BreakfastPackage
+				existingGuest.RateCode = pmsGuest.RateCode
+				existingGuest.LoyaltyTier = pmsGuest.LoyaltyTier
 				existingGuest.IsActive = pmsGuest.Status == "checked_in"
 
 				if err := tx.Save(&existingGuest).Error; err != nil {
@@ -259,18 +284,32 @@ func (s *RoomGridService) GenerateDailyReport(propertyID string, date time.Time)
 			propertyID, true, dateOnly, dateOnly).
 		Count(&occupiedRooms)
 
-	// Count rooms with breakfast packages
-	s.db.Model(&models.Guest{}).
-		Where("property_id = ? AND is_active = ? AND breakfast_package = ? AND check_in_date <= ? AND check_out_date > ?",
-			propertyID, true, true, dateOnly, dateOnly).
-		Count(&roomsWithBreakfast)
+	// Count rooms entitled to breakfast
+	engine, err := LoadEligibilityEngine(s.db, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	eligible, err := eligibleGuests(s.db, engine, propertyID, dateOnly)
+	if err != nil {
+		return nil, err
+	}
+	roomsWithBreakfast = int64(len(eligible))
 
-	// Count consumed breakfasts today
+	// Count rooms that had breakfast today
 	s.db.Model(&models.DailyBreakfastConsumption{}).
 		Where("property_id = ? AND consumption_date = ? AND status = ?",
 			propertyID, dateOnly, "consumed").
+		Distinct("room_number").
 		Count(&consumedBreakfasts)
 
+	// Count covers served today
+	var coversServed int64
+	s.db.Model(&models.DailyBreakfastConsumption{}).
+		Where("property_id = ? AND consumption_date = ? AND status = ?",
+			propertyID, dateOnly, "consumed").
+		Select("COALESCE(SUM(adult_covers + child_covers), 0)").
+		Row().Scan(&coversServed)
+
 	// Count OHIP covered breakfasts
 	s.db.Model(&models.DailyBreakfastConsumption{}).
 		Where("property_id = ? AND consumption_date = ? AND status = ? AND ohip_covered = ?",
@@ -291,6 +330,7 @@ func (s *RoomGridService) GenerateDailyReport(propertyID string, date time.Time)
 		"occupied_rooms":       occupiedRooms,
 		"rooms_with_breakfast": roomsWithBreakfast,
 		"consumed_breakfasts":  consumedBreakfasts,
+		"covers_served":        coversServed,
 		"ohip_covered":         ohipCovered,
 		"total_revenue":        totalRevenue,
 		"consumption_rate":     float64(consumedBreakfasts) / float64(roomsWithBreakfast) * 100,
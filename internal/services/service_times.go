@@ -0,0 +1,465 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"hudini-breakfast-module/internal/config"
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Visit lifecycle events recorded from the staff app, in the order they happen
+const (
+	VisitArrived = "arrived"
+	VisitSeated  = "seated"
+	VisitServed  = "served"
+	VisitLeft    = "left"
+)
+
+// Errors returned by service-time tracking
+var (
+	ErrUnknownVisitEvent = errors.New("event must be arrived, seated, served or left")
+	ErrVisitEventOrder   = errors.New("visit events must happen in order: arrived, seated, served, left")
+	ErrVisitNotConsumed  = errors.New("only consumed visits have a service lifecycle")
+	ErrUnknownGrouping   = errors.New("service times can be grouped by staff or hour")
+)
+
+// serviceTimeBuckets are the upper bounds, in minutes, of the distribution histogram
+var serviceTimeBuckets = []float64{5, 10, 15, 20, 30}
+
+// ServiceTimeService records visit lifecycle timestamps, watches each outlet's
+// rolling service time and reports service-time distributions
+type ServiceTimeService struct {
+	db            *gorm.DB
+	notifications *NotificationService
+	listener      ConsumptionListener
+	config        config.ServiceTimeConfig
+}
+
+// OutletServiceTime is an outlet's rolling average service time
+type OutletServiceTime struct {
+	OutletID         uint       `json:"outlet_id"`
+	OutletName       string     `json:"outlet_name"`
+	WindowMinutes    float64    `json:"window_minutes"`
+	Samples          int        `json:"samples"`
+	AverageMinutes   float64    `json:"average_minutes"` // Seated, or arrived when never seated, to first served
+	ThresholdMinutes float64    `json:"threshold_minutes"`
+	Delayed          bool       `json:"delayed"`
+	AlertedAt        *time.Time `json:"alerted_at,omitempty"`
+}
+
+// ServiceTimeBucket counts the visits served within a range of minutes
+type ServiceTimeBucket struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// ServiceTimeGroup is the distribution of service times for one staff member or hour
+type ServiceTimeGroup struct {
+	Key       string              `json:"key"`
+	Label     string              `json:"label"`
+	Samples   int                 `json:"samples"`
+	Average   float64             `json:"average"`
+	Median    float64             `json:"median"`
+	P90       float64             `json:"p90"`
+	Max       float64             `json:"max"`
+	Histogram []ServiceTimeBucket `json:"histogram"`
+}
+
+// ServiceTimeDistribution breaks a range's service times down by staff member or hour
+type ServiceTimeDistribution struct {
+	PropertyID string             `json:"property_id"`
+	OutletID   *uint              `json:"outlet_id,omitempty"`
+	From       string             `json:"from"`
+	To         string             `json:"to"`
+	GroupBy    string             `json:"group_by"` // staff, hour
+	Overall    ServiceTimeGroup   `json:"overall"`
+	Groups     []ServiceTimeGroup `json:"groups"`
+}
+
+// serviceTime is one visit's wait from being seated to first being served
+type serviceTime struct {
+	at      time.Time // When the wait started
+	minutes float64
+	staffID *uint
+}
+
+// NewServiceTimeService creates a new service-time service
+func NewServiceTimeService(db *gorm.DB, notifications *NotificationService, cfg config.ServiceTimeConfig) *ServiceTimeService {
+	return &ServiceTimeService{
+		db:            db,
+		notifications: notifications,
+		config:        cfg,
+	}
+}
+
+// SetConsumptionListener sets who is told when visit service times change
+func (s *ServiceTimeService) SetConsumptionListener(listener ConsumptionListener) {
+	s.listener = listener
+}
+
+// RecordEvent stamps a lifecycle event on a visit. A zero time records now.
+// Only the first served event is kept; the others may be corrected as long as
+// the lifecycle stays in order. Serving a party re-checks the outlet's rolling
+// service time.
+func (s *ServiceTimeService) RecordEvent(ctx context.Context, consumptionID uint, event string, at time.Time, staffID uint) (*models.DailyBreakfastConsumption, error) {
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	var visit models.DailyBreakfastConsumption
+	if err := s.db.WithContext(ctx).First(&visit, consumptionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConsumptionNotFound
+		}
+		return nil, fmt.Errorf("failed to load consumption: %w", err)
+	}
+	if visit.Status != "consumed" {
+		return nil, ErrVisitNotConsumed
+	}
+
+	updates := map[string]interface{}{}
+	switch event {
+	case VisitArrived:
+		visit.ArrivedAt = &at
+		updates["arrived_at"] = at
+	case VisitSeated:
+		visit.SeatedAt = &at
+		updates["seated_at"] = at
+	case VisitServed:
+		if visit.FirstServedAt != nil {
+			return &visit, nil
+		}
+		visit.FirstServedAt = &at
+		visit.ServedBy = &staffID
+		updates["first_served_at"] = at
+		updates["served_by"] = staffID
+	case VisitLeft:
+		visit.LeftAt = &at
+		updates["left_at"] = at
+	default:
+		return nil, ErrUnknownVisitEvent
+	}
+
+	var last *time.Time
+	for _, stamp := range []*time.Time{visit.ArrivedAt, visit.SeatedAt, visit.FirstServedAt, visit.LeftAt} {
+		if stamp == nil {
+			continue
+		}
+		if last != nil && stamp.Before(*last) {
+			return nil, ErrVisitEventOrder
+		}
+		last = stamp
+	}
+
+	if err := s.db.WithContext(ctx).Model(&visit).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to record visit event: %w", err)
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":        "ServiceTimeService",
+		"method":         "RecordEvent",
+		"consumption_id": visit.ID,
+		"event":          event,
+		"staff_id":       staffID,
+	}).Info("Recorded visit event")
+
+	if s.listener != nil && event != VisitLeft {
+		s.listener.ConsumptionChanged(visit.PropertyID, visit.ConsumptionDate)
+	}
+	if event == VisitServed && visit.OutletID != nil {
+		if _, err := s.CheckOutlet(ctx, *visit.OutletID); err != nil {
+			logging.WithError(err).WithField("outlet_id", *visit.OutletID).Warn("Failed to check outlet service time")
+		}
+	}
+	return &visit, nil
+}
+
+// OutletServiceTime returns an outlet's average service time over the rolling window
+func (s *ServiceTimeService) OutletServiceTime(outletID uint) (*OutletServiceTime, error) {
+	var outlet models.Outlet
+	if err := s.db.First(&outlet, outletID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOutletNotFound
+		}
+		return nil, fmt.Errorf("failed to load outlet: %w", err)
+	}
+	return s.rolling(&outlet)
+}
+
+// CheckOutlet fires a service delay alert when an outlet's rolling average
+// service time is over the threshold, and re-arms the alert once it recovers
+func (s *ServiceTimeService) CheckOutlet(ctx context.Context, outletID uint) (*OutletServiceTime, error) {
+	var outlet models.Outlet
+	if err := s.db.WithContext(ctx).First(&outlet, outletID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOutletNotFound
+		}
+		return nil, fmt.Errorf("failed to load outlet: %w", err)
+	}
+
+	metric, err := s.rolling(&outlet)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case metric.Delayed && outlet.DelayAlertedAt == nil:
+		if s.notifications != nil {
+			if err := s.notifications.NotifyServiceDelay(ctx, &outlet, metric.AverageMinutes, metric.ThresholdMinutes, metric.Samples); err != nil {
+				return metric, fmt.Errorf("failed to send service delay alert: %w", err)
+			}
+		}
+		now := time.Now()
+		if err := s.db.Model(&outlet).Update("delay_alerted_at", now).Error; err != nil {
+			return metric, fmt.Errorf("failed to mark service delay alert: %w", err)
+		}
+		metric.AlertedAt = &now
+
+		logging.WithFields(logrus.Fields{
+			"service":          "ServiceTimeService",
+			"method":           "CheckOutlet",
+			"outlet_id":        outlet.ID,
+			"avg_service_time": metric.AverageMinutes,
+			"samples":          metric.Samples,
+		}).Info("Service delay alert raised")
+	case !metric.Delayed && outlet.DelayAlertedAt != nil && metric.AverageMinutes <= metric.ThresholdMinutes:
+		if err := s.db.Model(&outlet).Update("delay_alerted_at", nil).Error; err != nil {
+			return metric, fmt.Errorf("failed to re-arm service delay alert: %w", err)
+		}
+		metric.AlertedAt = nil
+	}
+	return metric, nil
+}
+
+// Distribution breaks the service times of visits seated on the business dates
+// from through to down by the staff member who first served them, or by hour
+func (s *ServiceTimeService) Distribution(propertyID string, outletID *uint, from, to time.Time, groupBy string) (*ServiceTimeDistribution, error) {
+	if groupBy != "staff" && groupBy != "hour" {
+		return nil, ErrUnknownGrouping
+	}
+	clock, err := LoadPropertyClock(s.db, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	from, to = calendarDate(from), calendarDate(to)
+
+	scope := s.db.Where("property_id = ?", propertyID)
+	if outletID != nil {
+		scope = scope.Where("outlet_id = ?", *outletID)
+	}
+	times, err := visitServiceTimes(scope, clock.StartOf(from), clock.StartOf(to.AddDate(0, 0, 1)))
+	if err != nil {
+		return nil, err
+	}
+
+	distribution := &ServiceTimeDistribution{
+		PropertyID: propertyID,
+		OutletID:   outletID,
+		From:       from.Format("2006-01-02"),
+		To:         to.Format("2006-01-02"),
+		GroupBy:    groupBy,
+		Groups:     []ServiceTimeGroup{},
+	}
+
+	all := make([]float64, 0, len(times))
+	grouped := make(map[string][]float64)
+	for _, sample := range times {
+		all = append(all, sample.minutes)
+		key := "unknown"
+		switch {
+		case groupBy == "hour":
+			key = fmt.Sprintf("%02d", sample.at.In(clock.Location).Hour())
+		case sample.staffID != nil:
+			key = fmt.Sprint(*sample.staffID)
+		}
+		grouped[key] = append(grouped[key], sample.minutes)
+	}
+	distribution.Overall = serviceTimeGroup("all", "All visits", all)
+
+	labels, err := s.groupLabels(groupBy, grouped)
+	if err != nil {
+		return nil, err
+	}
+	for key, minutes := range grouped {
+		distribution.Groups = append(distribution.Groups, serviceTimeGroup(key, labels[key], minutes))
+	}
+	sort.Slice(distribution.Groups, func(i, j int) bool {
+		if groupBy == "hour" {
+			return distribution.Groups[i].Key < distribution.Groups[j].Key
+		}
+		return distribution.Groups[i].Average < distribution.Groups[j].Average
+	})
+	return distribution, nil
+}
+
+// rolling averages the service times of an outlet's visits first served within the window
+func (s *ServiceTimeService) rolling(outlet *models.Outlet) (*OutletServiceTime, error) {
+	window := s.config.Window
+	if window <= 0 {
+		window = 30 * time.Minute
+	}
+	threshold := s.config.DelayThreshold
+	if threshold <= 0 {
+		threshold = 15 * time.Minute
+	}
+
+	var visits []models.DailyBreakfastConsumption
+	err := s.db.Select("arrived_at", "seated_at", "first_served_at").
+		Where("outlet_id = ? AND status = ? AND first_served_at >= ?", outlet.ID, "consumed", time.Now().Add(-window)).
+		Find(&visits).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch served visits: %w", err)
+	}
+
+	metric := &OutletServiceTime{
+		OutletID:         outlet.ID,
+		OutletName:       outlet.Name,
+		WindowMinutes:    window.Minutes(),
+		ThresholdMinutes: threshold.Minutes(),
+		AlertedAt:        outlet.DelayAlertedAt,
+	}
+	total := 0.0
+	for i := range visits {
+		if sample, ok := visitServiceTime(&visits[i]); ok {
+			total += sample.minutes
+			metric.Samples++
+		}
+	}
+	if metric.Samples > 0 {
+		metric.AverageMinutes = roundTo(total/float64(metric.Samples), 1)
+	}
+	metric.Delayed = metric.Samples >= max(s.config.MinSamples, 1) && metric.AverageMinutes > metric.ThresholdMinutes
+	return metric, nil
+}
+
+// groupLabels names each group: the staff member's name, or the hour of the day
+func (s *ServiceTimeService) groupLabels(groupBy string, grouped map[string][]float64) (map[string]string, error) {
+	labels := map[string]string{"unknown": "Unknown"}
+	if groupBy == "hour" {
+		for key := range grouped {
+			var hour int
+			fmt.Sscanf(key, "%d", &hour)
+			labels[key] = time.Date(2000, 1, 1, hour, 0, 0, 0, time.UTC).Format("3 PM")
+		}
+		return labels, nil
+	}
+
+	var staffIDs []string
+	for key := range grouped {
+		if key != "unknown" {
+			staffIDs = append(staffIDs, key)
+		}
+	}
+	if len(staffIDs) == 0 {
+		return labels, nil
+	}
+	var staff []models.Staff
+	if err := s.db.Select("id", "first_name", "last_name").Where("id IN ?", staffIDs).Find(&staff).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch staff: %w", err)
+	}
+	for _, member := range staff {
+		labels[fmt.Sprint(member.ID)] = member.FirstName + " " + member.LastName
+	}
+	for _, key := range staffIDs {
+		if labels[key] == "" {
+			labels[key] = "Staff " + key
+		}
+	}
+	return labels, nil
+}
+
+// visitServiceTimes returns the service times of the consumed visits in scope
+// whose wait started in [start, end)
+func visitServiceTimes(scope *gorm.DB, start, end time.Time) ([]serviceTime, error) {
+	var visits []models.DailyBreakfastConsumption
+	err := scope.Select("arrived_at", "seated_at", "first_served_at", "served_by", "consumed_by").
+		Where("status = ? AND first_served_at IS NOT NULL", "consumed").
+		Where("COALESCE(seated_at, arrived_at) >= ? AND COALESCE(seated_at, arrived_at) < ?", start, end).
+		Find(&visits).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch visit service times: %w", err)
+	}
+
+	times := make([]serviceTime, 0, len(visits))
+	for i := range visits {
+		if sample, ok := visitServiceTime(&visits[i]); ok {
+			times = append(times, sample)
+		}
+	}
+	return times, nil
+}
+
+// visitServiceTime measures from being seated, or arriving when never seated,
+// to first being served
+func visitServiceTime(visit *models.DailyBreakfastConsumption) (serviceTime, bool) {
+	start := visit.SeatedAt
+	if start == nil {
+		start = visit.ArrivedAt
+	}
+	if start == nil || visit.FirstServedAt == nil {
+		return serviceTime{}, false
+	}
+	minutes := visit.FirstServedAt.Sub(*start).Minutes()
+	if minutes < 0 {
+		return serviceTime{}, false
+	}
+
+	staffID := visit.ServedBy
+	if staffID == nil {
+		staffID = visit.ConsumedBy
+	}
+	return serviceTime{at: *start, minutes: minutes, staffID: staffID}, true
+}
+
+// serviceTimeGroup summarizes a set of service times
+func serviceTimeGroup(key, label string, minutes []float64) ServiceTimeGroup {
+	group := ServiceTimeGroup{Key: key, Label: label, Samples: len(minutes)}
+	lower := 0.0
+	for _, upper := range serviceTimeBuckets {
+		group.Histogram = append(group.Histogram, ServiceTimeBucket{Label: fmt.Sprintf("%.0f-%.0f min", lower, upper)})
+		lower = upper
+	}
+	group.Histogram = append(group.Histogram, ServiceTimeBucket{Label: fmt.Sprintf("%.0f+ min", lower)})
+	if len(minutes) == 0 {
+		return group
+	}
+
+	sorted := append([]float64(nil), minutes...)
+	sort.Float64s(sorted)
+	total := 0.0
+	for _, value := range sorted {
+		total += value
+		bucket := sort.SearchFloat64s(serviceTimeBuckets, value)
+		if bucket < len(serviceTimeBuckets) && serviceTimeBuckets[bucket] == value {
+			bucket++
+		}
+		group.Histogram[bucket].Count++
+	}
+
+	group.Average = roundTo(total/float64(len(sorted)), 1)
+	group.Median = roundTo(percentile(sorted, 0.5), 1)
+	group.P90 = roundTo(percentile(sorted, 0.9), 1)
+	group.Max = roundTo(sorted[len(sorted)-1], 1)
+	return group
+}
+
+// percentile interpolates the pth percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower+1 >= len(sorted) {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[lower+1]-sorted[lower])*(rank-float64(lower))
+}
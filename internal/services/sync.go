@@ -0,0 +1,360 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxSyncBatchSize is the largest number of events accepted in one sync request
+	MaxSyncBatchSize = 500
+	// syncClockSkew is how far in the future a device clock may run before events are refused
+	syncClockSkew = 5 * time.Minute
+	// syncMergeWindow is how close two visits to the same room must be for the
+	// later one to be treated as the same visit marked from another device
+	syncMergeWindow = 15 * time.Minute
+)
+
+// Errors returned for a sync batch as a whole
+var (
+	ErrSyncDeviceRequired = errors.New("device_id is required")
+	ErrSyncBatchEmpty     = errors.New("sync batch contains no events")
+	ErrSyncBatchTooLarge  = fmt.Errorf("sync batch exceeds %d events", MaxSyncBatchSize)
+)
+
+// Outcomes of a synced event
+const (
+	SyncStatusApplied  = "applied"  // A new visit was recorded
+	SyncStatusMerged   = "merged"   // The event duplicated a visit already recorded for the room
+	SyncStatusRejected = "rejected" // The event cannot be applied; re-sending it gives the same result
+	SyncStatusError    = "error"    // The event failed on the server and can be retried
+)
+
+// SyncService applies consumption events that devices recorded while offline
+type SyncService struct {
+	db       *gorm.DB
+	listener ConsumptionListener
+}
+
+// SyncEventRequest is a consumption recorded by a device at a client timestamp
+type SyncEventRequest struct {
+	ClientEventID string    `json:"client_event_id"`
+	RoomNumber    string    `json:"room_number"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	OutletID      uint      `json:"outlet_id"`
+	CoverRequest
+}
+
+// SyncBatchRequest is the queue of events a device uploads when it comes back online
+type SyncBatchRequest struct {
+	PropertyID string
+	DeviceID   string
+	StaffID    uint
+	Events     []SyncEventRequest
+}
+
+// SyncEventResult tells the device what happened to one of its events
+type SyncEventResult struct {
+	ClientEventID string `json:"client_event_id"`
+	RoomNumber    string `json:"room_number"`
+	Status        string `json:"status"`             // applied, merged, rejected, error
+	Replayed      bool   `json:"replayed,omitempty"` // Result of an earlier sync of the same event
+	ConsumptionID *uint  `json:"consumption_id,omitempty"`
+	BusinessDate  string `json:"business_date,omitempty"`
+	ErrorCode     string `json:"error_code,omitempty"`
+	ErrorMessage  string `json:"error_message,omitempty"`
+}
+
+// SyncBatchResult holds the per-event results in the order the events were applied
+type SyncBatchResult struct {
+	DeviceID string            `json:"device_id"`
+	SyncedAt time.Time         `json:"synced_at"`
+	Applied  int               `json:"applied"`
+	Merged   int               `json:"merged"`
+	Rejected int               `json:"rejected"`
+	Errors   int               `json:"errors"`
+	Results  []SyncEventResult `json:"results"`
+}
+
+// NewSyncService creates a new sync service
+func NewSyncService(db *gorm.DB) *SyncService {
+	return &SyncService{
+		db: db,
+	}
+}
+
+// SetConsumptionListener sets who is told when synced visits are applied
+func (s *SyncService) SetConsumptionListener(listener ConsumptionListener) {
+	s.listener = listener
+}
+
+// SyncBatch applies a device's queued consumption events.
+//
+// Events are applied in client timestamp order, with the client event ID
+// breaking ties, and each is checked against the guest staying in the room and
+// the covers already served on the business date the event falls in. When two
+// devices mark the same room, the visit synced first stands; a later event that
+// finds the entitlement used up by a visit within syncMergeWindow of it is
+// merged into that visit rather than rejected. Applied and merged results are
+// stored per device and client event ID, so re-sending an event replays them.
+func (s *SyncService) SyncBatch(ctx context.Context, req SyncBatchRequest) (*SyncBatchResult, error) {
+	if strings.TrimSpace(req.DeviceID) == "" {
+		return nil, ErrSyncDeviceRequired
+	}
+	if len(req.Events) == 0 {
+		return nil, ErrSyncBatchEmpty
+	}
+	if len(req.Events) > MaxSyncBatchSize {
+		return nil, ErrSyncBatchTooLarge
+	}
+
+	engine, err := LoadEligibilityEngine(s.db, req.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]SyncEventRequest, len(req.Events))
+	copy(events, req.Events)
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].OccurredAt.Equal(events[j].OccurredAt) {
+			return events[i].OccurredAt.Before(events[j].OccurredAt)
+		}
+		return events[i].ClientEventID < events[j].ClientEventID
+	})
+
+	result := &SyncBatchResult{
+		DeviceID: req.DeviceID,
+		SyncedAt: time.Now(),
+		Results:  make([]SyncEventResult, 0, len(events)),
+	}
+	seen := make(map[string]bool, len(events))
+	changed := make(map[string]bool)
+
+	for _, event := range events {
+		var eventResult SyncEventResult
+		if seen[event.ClientEventID] && event.ClientEventID != "" {
+			eventResult = rejectSyncEvent(event, "DUPLICATE_EVENT", "client_event_id appears more than once in the batch")
+		} else {
+			seen[event.ClientEventID] = true
+			eventResult = s.applyEvent(ctx, engine, req, event)
+		}
+
+		switch eventResult.Status {
+		case SyncStatusApplied:
+			result.Applied++
+			if !eventResult.Replayed {
+				changed[eventResult.BusinessDate] = true
+			}
+		case SyncStatusMerged:
+			result.Merged++
+		case SyncStatusRejected:
+			result.Rejected++
+		default:
+			result.Errors++
+		}
+		result.Results = append(result.Results, eventResult)
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":     "SyncService",
+		"method":      "SyncBatch",
+		"property_id": req.PropertyID,
+		"device_id":   req.DeviceID,
+		"staff_id":    req.StaffID,
+		"events":      len(events),
+		"applied":     result.Applied,
+		"merged":      result.Merged,
+		"rejected":    result.Rejected,
+		"errors":      result.Errors,
+	}).Info("Applied offline sync batch")
+
+	if s.listener != nil {
+		for dateStr := range changed {
+			if date, err := time.Parse("2006-01-02", dateStr); err == nil {
+				s.listener.ConsumptionChanged(req.PropertyID, date)
+			}
+		}
+	}
+	return result, nil
+}
+
+// applyEvent applies a single event and reports its outcome
+func (s *SyncService) applyEvent(ctx context.Context, engine *EligibilityEngine, req SyncBatchRequest, event SyncEventRequest) SyncEventResult {
+	if strings.TrimSpace(event.ClientEventID) == "" || strings.TrimSpace(event.RoomNumber) == "" || event.OccurredAt.IsZero() {
+		return rejectSyncEvent(event, "INVALID_EVENT", "client_event_id, room_number and occurred_at are required")
+	}
+
+	// Events synced before are answered from the stored outcome
+	var previous []models.SyncEvent
+	err := s.db.WithContext(ctx).
+		Where("device_id = ? AND client_event_id = ?", req.DeviceID, event.ClientEventID).
+		Limit(1).
+		Find(&previous).Error
+	if err != nil {
+		return failSyncEvent(event)
+	}
+	if len(previous) > 0 {
+		return storedSyncResult(&previous[0])
+	}
+
+	if event.OccurredAt.After(time.Now().Add(syncClockSkew)) {
+		return rejectSyncEvent(event, "FUTURE_TIMESTAMP", "occurred_at is in the future")
+	}
+
+	at := event.OccurredAt.In(engine.clock.Location)
+	record := models.SyncEvent{
+		DeviceID:      req.DeviceID,
+		ClientEventID: event.ClientEventID,
+		PropertyID:    req.PropertyID,
+		RoomNumber:    event.RoomNumber,
+		OccurredAt:    at,
+		BusinessDate:  engine.clock.BusinessDate(at),
+		SyncedBy:      req.StaffID,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Guests who have since checked out are still matched by their stay dates
+		guest, err := guestForBreakfast(tx, engine, req.PropertyID, event.RoomNumber, record.BusinessDate, &at, false)
+		if err != nil {
+			return err
+		}
+
+		visit, err := recordVisit(tx, engine, guest, visitRequest{
+			StaffID:       req.StaffID,
+			OutletID:      event.OutletID,
+			PaymentMethod: "room_charge",
+			Covers:        event.CoverRequest,
+			At:            at,
+		})
+		if err != nil {
+			if !errors.Is(err, ErrBreakfastConsumed) && !errors.Is(err, ErrCoversExceeded) {
+				return err
+			}
+
+			// The room was already served; merge into a visit marked at about the same time
+			existing, findErr := visitNear(tx, guest, record.BusinessDate, at)
+			if findErr != nil {
+				return findErr
+			}
+			if existing == nil {
+				return err
+			}
+			record.Status = SyncStatusMerged
+			record.ConsumptionID = &existing.ID
+		} else {
+			record.Status = SyncStatusApplied
+			record.ConsumptionID = &visit.Consumption.ID
+		}
+
+		return tx.Create(&record).Error
+	})
+	if err != nil {
+		if code, ok := syncRejectionCode(err); ok {
+			return rejectSyncEvent(event, code, err.Error())
+		}
+
+		// A concurrent sync of the same event may have stored it first
+		var stored []models.SyncEvent
+		if findErr := s.db.WithContext(ctx).
+			Where("device_id = ? AND client_event_id = ?", req.DeviceID, event.ClientEventID).
+			Limit(1).
+			Find(&stored).Error; findErr == nil && len(stored) > 0 {
+			return storedSyncResult(&stored[0])
+		}
+
+		logging.WithFields(logrus.Fields{
+			"service":         "SyncService",
+			"method":          "applyEvent",
+			"device_id":       req.DeviceID,
+			"client_event_id": event.ClientEventID,
+			"room_number":     event.RoomNumber,
+			"error":           err.Error(),
+		}).Error("Failed to apply sync event")
+		return failSyncEvent(event)
+	}
+
+	result := storedSyncResult(&record)
+	result.Replayed = false
+	return result
+}
+
+// visitNear returns the guest's earliest visit on a business date within syncMergeWindow of a time
+func visitNear(tx *gorm.DB, guest *models.Guest, date, at time.Time) (*models.DailyBreakfastConsumption, error) {
+	var visits []models.DailyBreakfastConsumption
+	err := tx.Where("property_id = ? AND room_number = ? AND guest_id = ? AND DATE(consumption_date) = ? AND status = 'consumed' AND consumed_at BETWEEN ? AND ?",
+		guest.PropertyID, guest.RoomNumber, guest.ID, date.Format("2006-01-02"),
+		at.Add(-syncMergeWindow), at.Add(syncMergeWindow)).
+		Order("consumed_at ASC, id ASC").
+		Limit(1).
+		Find(&visits).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch visits: %w", err)
+	}
+	if len(visits) == 0 {
+		return nil, nil
+	}
+	return &visits[0], nil
+}
+
+// syncRejectionCode maps errors that re-sending the event would not fix to a result code
+func syncRejectionCode(err error) (string, bool) {
+	switch {
+	case errors.Is(err, ErrNoGuestInRoom):
+		return "NO_GUEST", true
+	case errors.Is(err, ErrNotEligible):
+		return "NOT_ELIGIBLE", true
+	case errors.Is(err, ErrBreakfastConsumed), errors.Is(err, ErrCoversExceeded):
+		return "ALREADY_CONSUMED", true
+	case errors.Is(err, ErrInvalidCovers):
+		return "INVALID_COVERS", true
+	case errors.Is(err, ErrNoPriceConfigured):
+		return "NO_PRICE", true
+	case errors.Is(err, ErrDayClosed):
+		return "DAY_CLOSED", true
+	case errors.Is(err, ErrOutletNotFound), errors.Is(err, ErrOutletClosed),
+		errors.Is(err, ErrOutletRejectsPackage), errors.Is(err, ErrOutletPropertyMismatch):
+		return "OUTLET_UNAVAILABLE", true
+	}
+	return "", false
+}
+
+func storedSyncResult(event *models.SyncEvent) SyncEventResult {
+	return SyncEventResult{
+		ClientEventID: event.ClientEventID,
+		RoomNumber:    event.RoomNumber,
+		Status:        event.Status,
+		Replayed:      true,
+		ConsumptionID: event.ConsumptionID,
+		BusinessDate:  event.BusinessDate.Format("2006-01-02"),
+	}
+}
+
+func rejectSyncEvent(event SyncEventRequest, code, message string) SyncEventResult {
+	return SyncEventResult{
+		ClientEventID: event.ClientEventID,
+		RoomNumber:    event.RoomNumber,
+		Status:        SyncStatusRejected,
+		ErrorCode:     code,
+		ErrorMessage:  message,
+	}
+}
+
+func failSyncEvent(event SyncEventRequest) SyncEventResult {
+	return SyncEventResult{
+		ClientEventID: event.ClientEventID,
+		RoomNumber:    event.RoomNumber,
+		Status:        SyncStatusError,
+		ErrorCode:     "SYNC_ERROR",
+		ErrorMessage:  "failed to apply event, retry later",
+	}
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hudini-breakfast-module/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// syncMorning is the breakfast service the synced events belong to
+var syncMorning = time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+
+// newSyncFixture is a priced property with a two-adult package guest in room
+// 101 and nobody in room 102
+func newSyncFixture(t *testing.T) (*gorm.DB, *SyncService) {
+	t.Helper()
+	db := newTestDB(t)
+	mustCreate(t, db, &models.Property{PropertyID: "P1", Name: "Harbour Hotel", TimeZone: "UTC"})
+	mustCreate(t, db, &models.BreakfastPrice{PropertyID: "P1", GuestType: "adult", Price: 20, EffectiveFrom: syncMorning.AddDate(-1, 0, 0)})
+	mustCreate(t, db, &models.Guest{
+		PMSGuestID: "G1", ReservationID: "R1", RoomNumber: "101", FirstName: "Ada", LastName: "Guest",
+		PropertyID: "P1", IsActive: true, BreakfastPackage: true, AdultCount: 2,
+		CheckInDate: syncMorning.AddDate(0, 0, -1), CheckOutDate: syncMorning.AddDate(0, 0, 2),
+	})
+	return db, NewSyncService(db)
+}
+
+func syncEvents(t *testing.T, service *SyncService, deviceID string, events ...SyncEventRequest) *SyncBatchResult {
+	t.Helper()
+	result, err := service.SyncBatch(context.Background(), SyncBatchRequest{PropertyID: "P1", DeviceID: deviceID, StaffID: 1, Events: events})
+	if err != nil {
+		t.Fatalf("SyncBatch: %v", err)
+	}
+	return result
+}
+
+func syncEvent(id string, at time.Time) SyncEventRequest {
+	return SyncEventRequest{ClientEventID: id, RoomNumber: "101", OccurredAt: at}
+}
+
+func TestSyncBatchAppliesInClientOrder(t *testing.T) {
+	_, service := newSyncFixture(t)
+
+	// The later event is queued first; the earlier one is applied first, and
+	// the later one, half an hour on, finds the room already served
+	result := syncEvents(t, service, "tablet-1",
+		syncEvent("e-late", syncMorning.Add(30*time.Minute)),
+		syncEvent("e-b", syncMorning),
+		syncEvent("e-a", syncMorning),
+	)
+
+	want := []struct {
+		id, status, code string
+	}{
+		{"e-a", SyncStatusApplied, ""},
+		{"e-b", SyncStatusMerged, ""},
+		{"e-late", SyncStatusRejected, "ALREADY_CONSUMED"},
+	}
+	if len(result.Results) != len(want) {
+		t.Fatalf("%d results, want %d", len(result.Results), len(want))
+	}
+	for i, w := range want {
+		got := result.Results[i]
+		if got.ClientEventID != w.id || got.Status != w.status || got.ErrorCode != w.code {
+			t.Errorf("result %d = %s %s %q, want %s %s %q", i, got.ClientEventID, got.Status, got.ErrorCode, w.id, w.status, w.code)
+		}
+	}
+	if result.Applied != 1 || result.Merged != 1 || result.Rejected != 1 {
+		t.Errorf("counts = %d applied, %d merged, %d rejected, want 1, 1, 1", result.Applied, result.Merged, result.Rejected)
+	}
+	if result.Results[0].BusinessDate != "2026-10-15" {
+		t.Errorf("business date = %q, want 2026-10-15", result.Results[0].BusinessDate)
+	}
+
+	// Re-sending an event replays its stored outcome
+	replay := syncEvents(t, service, "tablet-1", syncEvent("e-a", syncMorning))
+	if got := replay.Results[0]; !got.Replayed || got.Status != SyncStatusApplied || *got.ConsumptionID != *result.Results[0].ConsumptionID {
+		t.Errorf("replayed result = %+v, want the applied visit", got)
+	}
+}
+
+func TestSyncBatchMergeWindow(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset time.Duration
+		status string
+	}{
+		{"shortly after", 5 * time.Minute, SyncStatusMerged},
+		{"just inside the window", 14 * time.Minute, SyncStatusMerged},
+		{"synced later but marked earlier", -10 * time.Minute, SyncStatusMerged},
+		{"outside the window", 16 * time.Minute, SyncStatusRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, service := newSyncFixture(t)
+
+			// Two devices mark the same room; the visit synced first stands
+			first := syncEvents(t, service, "tablet-1", syncEvent("e-1", syncMorning)).Results[0]
+			if first.Status != SyncStatusApplied {
+				t.Fatalf("first device: %+v", first)
+			}
+			second := syncEvents(t, service, "tablet-2", syncEvent("e-1", syncMorning.Add(tt.offset))).Results[0]
+			if second.Status != tt.status {
+				t.Fatalf("second device = %s %s, want %s", second.Status, second.ErrorCode, tt.status)
+			}
+			if tt.status == SyncStatusMerged && (second.ConsumptionID == nil || *second.ConsumptionID != *first.ConsumptionID) {
+				t.Errorf("merged into visit %v, want %d", second.ConsumptionID, *first.ConsumptionID)
+			}
+
+			var visits int64
+			db.Model(&models.DailyBreakfastConsumption{}).Where("status = 'consumed'").Count(&visits)
+			if visits != 1 {
+				t.Errorf("%d visits recorded, want 1", visits)
+			}
+		})
+	}
+}
+
+func TestSyncBatchRejections(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(db *gorm.DB)
+		event SyncEventRequest
+		code  string
+	}{
+		{"empty room", nil, SyncEventRequest{ClientEventID: "e-1", RoomNumber: "102", OccurredAt: syncMorning}, "NO_GUEST"},
+		{"negative covers", nil, SyncEventRequest{ClientEventID: "e-1", RoomNumber: "101", OccurredAt: syncMorning, CoverRequest: CoverRequest{Adults: -1}}, "INVALID_COVERS"},
+		{"too many covers", nil, SyncEventRequest{ClientEventID: "e-1", RoomNumber: "101", OccurredAt: syncMorning, CoverRequest: CoverRequest{Adults: 3}}, "ALREADY_CONSUMED"},
+		{"no price", func(db *gorm.DB) { db.Where("1 = 1").Delete(&models.BreakfastPrice{}) }, syncEvent("e-1", syncMorning), "NO_PRICE"},
+		{"closed day", func(db *gorm.DB) {
+			db.Create(&models.ServiceCloseOut{PropertyID: "P1", BusinessDate: calendarDate(syncMorning), Status: "closed", ClosedAt: time.Now()})
+		}, syncEvent("e-1", syncMorning), "DAY_CLOSED"},
+		{"future timestamp", nil, syncEvent("e-1", time.Now().Add(time.Hour)), "FUTURE_TIMESTAMP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, service := newSyncFixture(t)
+			if tt.setup != nil {
+				tt.setup(db)
+			}
+			got := syncEvents(t, service, "tablet-1", tt.event).Results[0]
+			if got.Status != SyncStatusRejected || got.ErrorCode != tt.code {
+				t.Errorf("result = %s %q, want rejected %q", got.Status, got.ErrorCode, tt.code)
+			}
+		})
+	}
+}
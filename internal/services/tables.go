@@ -0,0 +1,739 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Errors returned by table management and seating
+var (
+	ErrTableNotFound      = errors.New("table not found")
+	ErrTableOccupied      = errors.New("table is occupied")
+	ErrTableOutOfService  = errors.New("table is out of service")
+	ErrTableTooSmall      = errors.New("table is too small for the party")
+	ErrTableNotOccupied   = errors.New("table has no party seated")
+	ErrNoTableAvailable   = errors.New("no available table fits the party")
+	ErrGuestAlreadySeated = errors.New("guest is already seated")
+	ErrUnknownSeatingPref = errors.New("seating preference must be window, booth, patio or quiet")
+)
+
+// Table states
+const (
+	TableStatusAvailable    = "available"
+	TableStatusOccupied     = "occupied"
+	TableStatusOutOfService = "out_of_service"
+)
+
+// Table turn states
+const (
+	TurnStatusSeated  = "seated"
+	TurnStatusCleared = "cleared"
+)
+
+// SeatingAttributes are the table attributes a guest's seating preference can ask for
+var SeatingAttributes = []string{"window", "booth", "patio", "quiet"}
+
+// BoardPublisher pushes live board updates to websocket clients subscribed to a room
+type BoardPublisher interface {
+	BroadcastToRoom(room string, data map[string]interface{})
+}
+
+// TableListener is told when a table becomes free, so a waiting party can be called
+type TableListener interface {
+	TableFreed(ctx context.Context, table *models.DiningTable)
+}
+
+// SeatingListener is told when a party sits down at or leaves a table
+type SeatingListener interface {
+	PartySeated(turn *models.TableTurn)
+	PartyLeft(turn *models.TableTurn)
+}
+
+// TableBoardRoom returns the websocket room an outlet's table board is pushed to
+func TableBoardRoom(outletID uint) string {
+	return fmt.Sprintf("tables:%d", outletID)
+}
+
+// RoomOutlet returns the outlet a table board or kitchen display room belongs
+// to. It reports false for any other room name.
+func RoomOutlet(room string) (uint, bool) {
+	parts := strings.SplitN(room, ":", 3)
+	if len(parts) < 2 || (parts[0] != "tables" && parts[0] != "kitchen") {
+		return 0, false
+	}
+	if parts[0] == "tables" && len(parts) > 2 {
+		return 0, false
+	}
+	outletID, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil || outletID == 0 {
+		return 0, false
+	}
+	return uint(outletID), true
+}
+
+// TableService manages outlet table maps and seats parties at them
+type TableService struct {
+	db        *gorm.DB
+	publisher BoardPublisher
+	listener  TableListener
+	seating   SeatingListener
+}
+
+// SeatingRequest describes a party to seat. An in-house party is identified by
+// guest ID or room number; without either it is seated as a walk-in.
+type SeatingRequest struct {
+	TableID    uint   `json:"table_id"` // Seat at this table instead of the best suggestion
+	GuestID    uint   `json:"guest_id"`
+	RoomNumber string `json:"room_number"`
+	PartyName  string `json:"party_name"`         // Name to call a walk-in party by
+	PartySize  int    `json:"party_size"`         // Defaults to the guests on the booking
+	Preference string `json:"seating_preference"` // Defaults to the guest's stored preference
+}
+
+// TableSuggestion is an available table that fits a party
+type TableSuggestion struct {
+	Table             models.DiningTable `json:"table"`
+	PreferenceMatched bool               `json:"preference_matched"`
+	SpareSeats        int                `json:"spare_seats"`
+}
+
+// SeatingSuggestions are the tables suggested for a party, best first
+type SeatingSuggestions struct {
+	GuestID     *uint             `json:"guest_id,omitempty"`
+	GuestName   string            `json:"guest_name,omitempty"`
+	PartySize   int               `json:"party_size"`
+	Preference  string            `json:"seating_preference,omitempty"`
+	Suggestions []TableSuggestion `json:"suggestions"`
+}
+
+// BoardTable is a table on the live board with the party seated at it
+type BoardTable struct {
+	models.DiningTable
+	Turn          *models.TableTurn `json:"turn,omitempty"`
+	SeatedMinutes int               `json:"seated_minutes,omitempty"`
+}
+
+// TableBoard is the live state of an outlet's tables
+type TableBoard struct {
+	OutletID           uint         `json:"outlet_id"`
+	OutletName         string       `json:"outlet_name"`
+	Capacity           int          `json:"capacity"`
+	BusinessDate       string       `json:"business_date"`
+	SeatsTotal         int          `json:"seats_total"`
+	SeatsOccupied      int          `json:"seats_occupied"`
+	TablesAvailable    int          `json:"tables_available"`
+	TablesOccupied     int          `json:"tables_occupied"`
+	TurnsToday         int          `json:"turns_today"`
+	AverageTurnMinutes float64      `json:"average_turn_minutes"`
+	Tables             []BoardTable `json:"tables"`
+	UpdatedAt          time.Time    `json:"updated_at"`
+}
+
+// seatingParty is a seating request resolved against the guest record
+type seatingParty struct {
+	GuestID    *uint
+	RoomNumber string
+	GuestName  string
+	PartySize  int
+	Preference string
+}
+
+// NewTableService creates a new table service
+func NewTableService(db *gorm.DB) *TableService {
+	return &TableService{
+		db: db,
+	}
+}
+
+// SetPublisher sets where table board updates are pushed
+func (s *TableService) SetPublisher(publisher BoardPublisher) {
+	s.publisher = publisher
+}
+
+// SetTableListener sets who is told when tables become free
+func (s *TableService) SetTableListener(listener TableListener) {
+	s.listener = listener
+}
+
+// SetSeatingListener sets who is told about parties being seated and leaving
+func (s *TableService) SetSeatingListener(listener SeatingListener) {
+	s.seating = listener
+}
+
+// GetTables retrieves an outlet's table map
+func (s *TableService) GetTables(outletID uint) ([]models.DiningTable, error) {
+	var tables []models.DiningTable
+	err := s.db.Where("outlet_id = ?", outletID).
+		Order("section ASC, name ASC").
+		Find(&tables).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tables: %w", err)
+	}
+	return tables, nil
+}
+
+// CreateTable adds a table to an outlet's table map
+func (s *TableService) CreateTable(table *models.DiningTable) error {
+	var outlet models.Outlet
+	if err := s.db.First(&outlet, table.OutletID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOutletNotFound
+		}
+		return fmt.Errorf("failed to fetch outlet: %w", err)
+	}
+
+	if strings.TrimSpace(table.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if table.Capacity <= 0 {
+		return fmt.Errorf("capacity must be positive")
+	}
+	attributes, err := normalizeSeatingAttributes(table.Attributes)
+	if err != nil {
+		return err
+	}
+
+	table.PropertyID = outlet.PropertyID
+	table.Attributes = attributes
+	table.Status = TableStatusAvailable
+	table.CurrentTurnID = nil
+	if err := s.db.Create(table).Error; err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":   "TableService",
+		"method":    "CreateTable",
+		"outlet_id": table.OutletID,
+		"table_id":  table.ID,
+	}).Info("Successfully created table")
+
+	s.publishBoard(table.OutletID)
+	return nil
+}
+
+// UpdateTable applies column updates to a table. Only available and
+// out_of_service can be set as a status; seating and clearing set the rest.
+func (s *TableService) UpdateTable(tableID uint, updates map[string]interface{}) (*models.DiningTable, error) {
+	table, err := s.getTable(s.db, tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	if value, ok := updates["capacity"].(int); ok && value <= 0 {
+		return nil, fmt.Errorf("capacity must be positive")
+	}
+	if value, ok := updates["name"].(string); ok && strings.TrimSpace(value) == "" {
+		return nil, fmt.Errorf("name cannot be empty")
+	}
+	if value, ok := updates["attributes"].(string); ok {
+		attributes, err := normalizeSeatingAttributes(value)
+		if err != nil {
+			return nil, err
+		}
+		updates["attributes"] = attributes
+	}
+	if value, ok := updates["status"].(string); ok {
+		if value != TableStatusAvailable && value != TableStatusOutOfService {
+			return nil, fmt.Errorf("status must be available or out_of_service")
+		}
+		if table.Status == TableStatusOccupied {
+			return nil, ErrTableOccupied
+		}
+	}
+
+	// Tables cannot move between outlets, and their turns are managed by seating
+	delete(updates, "id")
+	delete(updates, "outlet_id")
+	delete(updates, "property_id")
+	delete(updates, "current_turn_id")
+
+	if len(updates) > 0 {
+		if err := s.db.Model(table).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update table: %w", err)
+		}
+	}
+
+	s.publishBoard(table.OutletID)
+
+	updated, err := s.getTable(s.db, tableID)
+	if err != nil {
+		return nil, err
+	}
+	if table.Status == TableStatusOutOfService && updated.Status == TableStatusAvailable {
+		s.tableFreed(updated)
+	}
+	return updated, nil
+}
+
+// DeleteTable soft-deletes a table that has no party seated
+func (s *TableService) DeleteTable(tableID uint) error {
+	table, err := s.getTable(s.db, tableID)
+	if err != nil {
+		return err
+	}
+	if table.Status == TableStatusOccupied {
+		return ErrTableOccupied
+	}
+
+	if err := s.db.Delete(table).Error; err != nil {
+		return fmt.Errorf("failed to delete table: %w", err)
+	}
+
+	s.publishBoard(table.OutletID)
+	return nil
+}
+
+// SuggestTables ranks an outlet's available tables for a party. Tables with the
+// party's preferred attribute come first, then the tables with fewest spare seats.
+func (s *TableService) SuggestTables(outletID uint, req SeatingRequest) (*SeatingSuggestions, error) {
+	outlet, err := s.getOutlet(s.db, outletID)
+	if err != nil {
+		return nil, err
+	}
+	party, err := s.resolveParty(s.db, outlet, req)
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := s.GetTables(outletID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SeatingSuggestions{
+		GuestID:     party.GuestID,
+		GuestName:   party.GuestName,
+		PartySize:   party.PartySize,
+		Preference:  party.Preference,
+		Suggestions: rankTables(tables, party),
+	}, nil
+}
+
+// SeatParty seats a party at the requested table, or at the best suggested one.
+// The table is claimed with a conditional update, so two hosts cannot seat
+// parties at the same table.
+func (s *TableService) SeatParty(outletID uint, req SeatingRequest, staffID uint) (*models.TableTurn, error) {
+	var turn models.TableTurn
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		outlet, err := s.getOutlet(tx, outletID)
+		if err != nil {
+			return err
+		}
+		party, err := s.resolveParty(tx, outlet, req)
+		if err != nil {
+			return err
+		}
+
+		if party.GuestID != nil {
+			var seated int64
+			err := tx.Model(&models.TableTurn{}).
+				Where("guest_id = ? AND status = ?", *party.GuestID, TurnStatusSeated).
+				Count(&seated).Error
+			if err != nil {
+				return fmt.Errorf("failed to check seated parties: %w", err)
+			}
+			if seated > 0 {
+				return ErrGuestAlreadySeated
+			}
+		}
+
+		var table *models.DiningTable
+		if req.TableID != 0 {
+			table, err = s.getTable(tx, req.TableID)
+			if err != nil {
+				return err
+			}
+			switch {
+			case table.OutletID != outletID:
+				return ErrTableNotFound
+			case table.Status == TableStatusOccupied:
+				return ErrTableOccupied
+			case table.Status == TableStatusOutOfService:
+				return ErrTableOutOfService
+			case table.Capacity < party.PartySize:
+				return ErrTableTooSmall
+			}
+		} else {
+			var tables []models.DiningTable
+			if err := tx.Where("outlet_id = ?", outletID).Find(&tables).Error; err != nil {
+				return fmt.Errorf("failed to fetch tables: %w", err)
+			}
+			suggestions := rankTables(tables, party)
+			if len(suggestions) == 0 {
+				return ErrNoTableAvailable
+			}
+			table = &suggestions[0].Table
+		}
+
+		claim := tx.Model(&models.DiningTable{}).
+			Where("id = ? AND status = ?", table.ID, TableStatusAvailable).
+			Update("status", TableStatusOccupied)
+		if claim.Error != nil {
+			return fmt.Errorf("failed to claim table: %w", claim.Error)
+		}
+		if claim.RowsAffected == 0 {
+			return ErrTableOccupied
+		}
+
+		clock, err := LoadPropertyClock(tx, outlet.PropertyID)
+		if err != nil {
+			return err
+		}
+		now := clock.Now()
+
+		turn = models.TableTurn{
+			TableID:      table.ID,
+			OutletID:     outletID,
+			PropertyID:   outlet.PropertyID,
+			BusinessDate: clock.BusinessDate(now),
+			GuestID:      party.GuestID,
+			RoomNumber:   party.RoomNumber,
+			GuestName:    party.GuestName,
+			PartySize:    party.PartySize,
+			SeatingPref:  party.Preference,
+			PrefMatched:  party.Preference != "" && hasSeatingAttribute(table, party.Preference),
+			Status:       TurnStatusSeated,
+			SeatedAt:     now,
+			SeatedBy:     staffID,
+		}
+		if err := tx.Create(&turn).Error; err != nil {
+			return fmt.Errorf("failed to create table turn: %w", err)
+		}
+		return tx.Model(&models.DiningTable{}).Where("id = ?", table.ID).Update("current_turn_id", turn.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":    "TableService",
+		"method":     "SeatParty",
+		"outlet_id":  outletID,
+		"table_id":   turn.TableID,
+		"turn_id":    turn.ID,
+		"party_size": turn.PartySize,
+		"staff_id":   staffID,
+	}).Info("Seated party")
+
+	s.publishBoard(outletID)
+	if s.seating != nil {
+		s.seating.PartySeated(&turn)
+	}
+	return &turn, nil
+}
+
+// ClearTable ends the turn at a table and makes it available again
+func (s *TableService) ClearTable(tableID, staffID uint) (*models.TableTurn, error) {
+	var turn models.TableTurn
+	var table *models.DiningTable
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		table, err = s.getTable(tx, tableID)
+		if err != nil {
+			return err
+		}
+		if table.Status != TableStatusOccupied || table.CurrentTurnID == nil {
+			return ErrTableNotOccupied
+		}
+
+		release := tx.Model(&models.DiningTable{}).
+			Where("id = ? AND status = ? AND current_turn_id = ?", table.ID, TableStatusOccupied, *table.CurrentTurnID).
+			Updates(map[string]interface{}{
+				"status":          TableStatusAvailable,
+				"current_turn_id": nil,
+			})
+		if release.Error != nil {
+			return fmt.Errorf("failed to release table: %w", release.Error)
+		}
+		if release.RowsAffected == 0 {
+			return ErrTableNotOccupied
+		}
+
+		if err := tx.First(&turn, *table.CurrentTurnID).Error; err != nil {
+			return fmt.Errorf("failed to fetch table turn: %w", err)
+		}
+		now := time.Now()
+		turn.Status = TurnStatusCleared
+		turn.ClearedAt = &now
+		turn.ClearedBy = &staffID
+		return tx.Save(&turn).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	table.Status = TableStatusAvailable
+	table.CurrentTurnID = nil
+	s.publishBoard(table.OutletID)
+	if s.seating != nil {
+		s.seating.PartyLeft(&turn)
+	}
+	s.tableFreed(table)
+	return &turn, nil
+}
+
+// GetBoard returns the live state of an outlet's tables and today's turns
+func (s *TableService) GetBoard(outletID uint) (*TableBoard, error) {
+	outlet, err := s.getOutlet(s.db, outletID)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := LoadPropertyClock(s.db, outlet.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+	now := clock.Now()
+	today := clock.BusinessDate(now)
+
+	tables, err := s.GetTables(outletID)
+	if err != nil {
+		return nil, err
+	}
+
+	var seated []models.TableTurn
+	if err := s.db.Where("outlet_id = ? AND status = ?", outletID, TurnStatusSeated).Find(&seated).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch seated parties: %w", err)
+	}
+	turnsByID := make(map[uint]*models.TableTurn, len(seated))
+	for i := range seated {
+		turnsByID[seated[i].ID] = &seated[i]
+	}
+
+	board := &TableBoard{
+		OutletID:     outlet.ID,
+		OutletName:   outlet.Name,
+		Capacity:     outlet.Capacity,
+		BusinessDate: today.Format("2006-01-02"),
+		Tables:       make([]BoardTable, 0, len(tables)),
+		UpdatedAt:    now,
+	}
+	for _, table := range tables {
+		entry := BoardTable{DiningTable: table}
+		if table.CurrentTurnID != nil {
+			if turn, ok := turnsByID[*table.CurrentTurnID]; ok {
+				entry.Turn = turn
+				entry.SeatedMinutes = int(now.Sub(turn.SeatedAt).Minutes())
+				board.SeatsOccupied += turn.PartySize
+			}
+		}
+		switch table.Status {
+		case TableStatusAvailable:
+			board.TablesAvailable++
+		case TableStatusOccupied:
+			board.TablesOccupied++
+		}
+		if table.Status != TableStatusOutOfService {
+			board.SeatsTotal += table.Capacity
+		}
+		board.Tables = append(board.Tables, entry)
+	}
+
+	var turns []models.TableTurn
+	err = s.db.Where("outlet_id = ? AND DATE(business_date) = ?", outletID, today.Format("2006-01-02")).
+		Find(&turns).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch table turns: %w", err)
+	}
+	var cleared int
+	var minutes float64
+	for _, turn := range turns {
+		if turn.ClearedAt != nil {
+			cleared++
+			minutes += turn.ClearedAt.Sub(turn.SeatedAt).Minutes()
+		}
+	}
+	board.TurnsToday = len(turns)
+	if cleared > 0 {
+		board.AverageTurnMinutes = minutes / float64(cleared)
+	}
+
+	return board, nil
+}
+
+// publishBoard pushes an outlet's table board to its websocket room
+func (s *TableService) publishBoard(outletID uint) {
+	if s.publisher == nil {
+		return
+	}
+
+	board, err := s.GetBoard(outletID)
+	if err != nil {
+		logging.WithError(err).WithField("outlet_id", outletID).Warn("Failed to build table board")
+		return
+	}
+	s.publisher.BroadcastToRoom(TableBoardRoom(outletID), map[string]interface{}{
+		"type":      "table_board",
+		"outlet_id": outletID,
+		"data":      board,
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// tableFreed tells the listener a table is free
+func (s *TableService) tableFreed(table *models.DiningTable) {
+	if s.listener != nil {
+		s.listener.TableFreed(context.Background(), table)
+	}
+}
+
+// resolveParty fills in a seating request from the guest's booking and preferences
+func (s *TableService) resolveParty(tx *gorm.DB, outlet *models.Outlet, req SeatingRequest) (*seatingParty, error) {
+	if req.PartySize < 0 {
+		return nil, fmt.Errorf("party_size cannot be negative")
+	}
+	party := &seatingParty{
+		RoomNumber: req.RoomNumber,
+		GuestName:  strings.TrimSpace(req.PartyName),
+		PartySize:  req.PartySize,
+		Preference: strings.ToLower(strings.TrimSpace(req.Preference)),
+	}
+	if party.Preference != "" && !isSeatingAttribute(party.Preference) {
+		return nil, ErrUnknownSeatingPref
+	}
+
+	var guest *models.Guest
+	switch {
+	case req.GuestID != 0:
+		var found models.Guest
+		if err := tx.First(&found, req.GuestID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrNoGuestInRoom
+			}
+			return nil, fmt.Errorf("failed to fetch guest: %w", err)
+		}
+		if found.PropertyID != outlet.PropertyID {
+			return nil, ErrOutletPropertyMismatch
+		}
+		guest = &found
+	case req.RoomNumber != "":
+		engine, err := LoadEligibilityEngine(tx, outlet.PropertyID)
+		if err != nil {
+			return nil, err
+		}
+		now := engine.Now()
+		guest, err = guestForBreakfast(tx, engine, outlet.PropertyID, req.RoomNumber, engine.clock.BusinessDate(now), &now, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if guest != nil {
+		party.GuestID = &guest.ID
+		party.RoomNumber = guest.RoomNumber
+		party.GuestName = guest.FirstName + " " + guest.LastName
+		if party.PartySize == 0 {
+			party.PartySize = guest.AdultCount + guest.ChildCount
+		}
+		if party.Preference == "" {
+			var prefs []models.GuestPreference
+			if err := tx.Where("guest_id = ?", guest.ID).Limit(1).Find(&prefs).Error; err != nil {
+				return nil, fmt.Errorf("failed to fetch guest preferences: %w", err)
+			}
+			if len(prefs) > 0 && isSeatingAttribute(strings.ToLower(prefs[0].SeatingPref)) {
+				party.Preference = strings.ToLower(prefs[0].SeatingPref)
+			}
+		}
+	}
+
+	if party.PartySize <= 0 {
+		return nil, fmt.Errorf("party_size is required")
+	}
+	return party, nil
+}
+
+func (s *TableService) getOutlet(tx *gorm.DB, outletID uint) (*models.Outlet, error) {
+	var outlet models.Outlet
+	if err := tx.First(&outlet, outletID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOutletNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch outlet: %w", err)
+	}
+	return &outlet, nil
+}
+
+func (s *TableService) getTable(tx *gorm.DB, tableID uint) (*models.DiningTable, error) {
+	var table models.DiningTable
+	if err := tx.First(&table, tableID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTableNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch table: %w", err)
+	}
+	return &table, nil
+}
+
+// rankTables returns the available tables that fit a party, best first
+func rankTables(tables []models.DiningTable, party *seatingParty) []TableSuggestion {
+	var suggestions []TableSuggestion
+	for _, table := range tables {
+		if table.Status != TableStatusAvailable || table.Capacity < party.PartySize {
+			continue
+		}
+		suggestions = append(suggestions, TableSuggestion{
+			Table:             table,
+			PreferenceMatched: party.Preference != "" && hasSeatingAttribute(&table, party.Preference),
+			SpareSeats:        table.Capacity - party.PartySize,
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.PreferenceMatched != b.PreferenceMatched {
+			return a.PreferenceMatched
+		}
+		if a.SpareSeats != b.SpareSeats {
+			return a.SpareSeats < b.SpareSeats
+		}
+		return a.Table.Name < b.Table.Name
+	})
+	return suggestions
+}
+
+func hasSeatingAttribute(table *models.DiningTable, attribute string) bool {
+	for _, value := range strings.Split(table.Attributes, ",") {
+		if value == attribute {
+			return true
+		}
+	}
+	return false
+}
+
+func isSeatingAttribute(value string) bool {
+	for _, attribute := range SeatingAttributes {
+		if value == attribute {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSeatingAttributes validates a comma-separated attribute list and returns it lower-cased without duplicates
+func normalizeSeatingAttributes(value string) (string, error) {
+	var attributes []string
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ",") {
+		attribute := strings.ToLower(strings.TrimSpace(part))
+		if attribute == "" || seen[attribute] {
+			continue
+		}
+		if !isSeatingAttribute(attribute) {
+			return "", fmt.Errorf("unknown table attribute %q, use window, booth, patio or quiet", attribute)
+		}
+		seen[attribute] = true
+		attributes = append(attributes, attribute)
+	}
+	return strings.Join(attributes, ","), nil
+}
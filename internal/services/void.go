@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hudini-breakfast-module/internal/audit"
+	"hudini-breakfast-module/internal/config"
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Errors returned when a void is refused
+var (
+	ErrConsumptionNotFound = errors.New("consumption not found")
+	ErrNotVoidable         = errors.New("only consumed breakfasts can be voided")
+	ErrVoidWindowExpired   = errors.New("void window has expired for this consumption")
+	ErrVoidReasonRequired  = errors.New("a reason is required to void a consumption")
+	ErrVoidOtherProperty   = errors.New("consumption belongs to another property")
+)
+
+// ChargeReverser reverses a charge previously posted to the PMS
+type ChargeReverser interface {
+	VoidCharge(ctx context.Context, transactionID string) error
+}
+
+// VoidService reverses mistaken breakfast consumptions
+type VoidService struct {
+	db            *gorm.DB
+	ohipService   *OHIPService
+	auditService  *AuditService
+	visitReverser ChargeReverser
+	orderReverser ChargeReverser
+	listener      ConsumptionListener
+	config        config.VoidConfig
+}
+
+// VoidRequest describes who is voiding a consumption and why
+type VoidRequest struct {
+	ConsumptionID uint
+	StaffID       uint
+	Role          string
+	PropertyID    string // Property of the staff member; admins may void at any property
+	Reason        string
+	IPAddress     string
+	UserAgent     string
+}
+
+// NewVoidService creates a new void service
+func NewVoidService(db *gorm.DB, ohipService *OHIPService, auditService *AuditService, cfg config.VoidConfig) *VoidService {
+	return &VoidService{
+		db:           db,
+		ohipService:  ohipService,
+		auditService: auditService,
+		config:       cfg,
+	}
+}
+
+// SetVisitChargeReverser sets the PMS client used to reverse visit charges.
+// It must be the client the room grid posts visit charges through.
+func (s *VoidService) SetVisitChargeReverser(reverser ChargeReverser) {
+	s.visitReverser = reverser
+}
+
+// SetOrderChargeReverser sets the PMS client used to reverse the overage
+// charges of a visit's orders, which the order service posts
+func (s *VoidService) SetOrderChargeReverser(reverser ChargeReverser) {
+	s.orderReverser = reverser
+}
+
+// SetConsumptionListener sets who is told when visits are voided
+func (s *VoidService) SetConsumptionListener(listener ConsumptionListener) {
+	s.listener = listener
+}
+
+// VoidConsumption voids a consumption, reversing its PMS charges and pending
+// OHIP claims. The visit is first marked void_pending so that a void which
+// fails part way through the external calls can be retried; each reversal is
+// recorded as it succeeds and is not repeated on a retry.
+func (s *VoidService) VoidConsumption(ctx context.Context, req VoidRequest) (*models.DailyBreakfastConsumption, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, ErrVoidReasonRequired
+	}
+
+	before, err := s.beginVoid(ctx, req)
+	if err == nil {
+		err = s.reverseCharges(ctx, before)
+	}
+
+	var after models.DailyBreakfastConsumption
+	if err == nil {
+		after, err = s.completeVoid(ctx, before, req)
+	}
+
+	resourceID := strconv.FormatUint(uint64(req.ConsumptionID), 10)
+	if err != nil {
+		if s.auditService != nil {
+			s.auditService.LogFailure(ctx, &req.StaffID, audit.ActionVoid, audit.ResourceConsumption, resourceID, req.IPAddress, req.UserAgent, err)
+		}
+		return nil, err
+	}
+
+	if s.auditService != nil {
+		if err := s.auditService.LogSuccess(ctx, &req.StaffID, audit.ActionVoid, audit.ResourceConsumption, resourceID, req.IPAddress, req.UserAgent, before, after); err != nil {
+			logging.WithError(err).Warn("Failed to write void audit entry")
+		}
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":        "VoidService",
+		"consumption_id": req.ConsumptionID,
+		"staff_id":       req.StaffID,
+		"reason":         req.Reason,
+	}).Info("Breakfast consumption voided")
+
+	if s.listener != nil {
+		s.listener.ConsumptionChanged(after.PropertyID, after.ConsumptionDate)
+	}
+	return &after, nil
+}
+
+// beginVoid checks the consumption may be voided and marks it void_pending,
+// returning it as it was before. A void left pending by an earlier failure is
+// picked up again once the same checks pass.
+func (s *VoidService) beginVoid(ctx context.Context, req VoidRequest) (models.DailyBreakfastConsumption, error) {
+	var before models.DailyBreakfastConsumption
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var consumption models.DailyBreakfastConsumption
+		if err := tx.First(&consumption, req.ConsumptionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrConsumptionNotFound
+			}
+			return fmt.Errorf("failed to load consumption: %w", err)
+		}
+
+		before = consumption
+		if consumption.Status != "consumed" && consumption.Status != "void_pending" {
+			return ErrNotVoidable
+		}
+		if req.Role != "admin" && consumption.PropertyID != req.PropertyID {
+			return ErrVoidOtherProperty
+		}
+
+		if !s.canVoid(&consumption, req.Role) {
+			return ErrVoidWindowExpired
+		}
+
+		// Closed-out days are locked until a manager re-opens them
+		if err := ensureServiceOpen(tx, consumption.PropertyID, consumption.OutletID, consumption.ConsumptionDate); err != nil {
+			return err
+		}
+
+		if consumption.Status == "void_pending" {
+			return nil
+		}
+		return tx.Model(&consumption).Updates(map[string]interface{}{
+			"status":      "void_pending",
+			"voided_by":   req.StaffID,
+			"void_reason": req.Reason,
+		}).Error
+	})
+	return before, err
+}
+
+// reverseCharges reverses the visit's room charge, the room charges for its
+// à-la-carte overages and its pending OHIP claims. None of this runs inside a
+// database transaction: each reversal is saved as soon as the PMS or OHIP
+// accepts it, so a retry only reverses what is still outstanding.
+func (s *VoidService) reverseCharges(ctx context.Context, consumption models.DailyBreakfastConsumption) error {
+	db := s.db.WithContext(ctx)
+
+	if consumption.PMSPosted && consumption.PMSTransactionID != "" {
+		if s.visitReverser == nil {
+			return errors.New("no PMS configured to reverse the posted charge")
+		}
+		if err := s.visitReverser.VoidCharge(ctx, consumption.PMSTransactionID); err != nil {
+			return fmt.Errorf("failed to reverse PMS charge: %w", err)
+		}
+		if err := db.Model(&consumption).Update("pms_posted", false).Error; err != nil {
+			return fmt.Errorf("failed to record PMS charge reversal: %w", err)
+		}
+	}
+
+	var orders []models.Order
+	if err := db.Where("consumption_id = ? AND status <> ? AND pms_posted = ?", consumption.ID, OrderStatusCancelled, true).Find(&orders).Error; err != nil {
+		return fmt.Errorf("failed to load orders: %w", err)
+	}
+	for _, order := range orders {
+		if order.PMSTransactionID == "" {
+			continue
+		}
+		if s.orderReverser == nil {
+			return errors.New("no PMS configured to reverse the posted charge")
+		}
+		if err := s.orderReverser.VoidCharge(ctx, order.PMSTransactionID); err != nil {
+			return fmt.Errorf("failed to reverse PMS charge for order %d: %w", order.ID, err)
+		}
+		if err := db.Model(&order).Update("pms_posted", false).Error; err != nil {
+			return fmt.Errorf("failed to record PMS charge reversal for order %d: %w", order.ID, err)
+		}
+	}
+
+	var claims []models.OHIPTransaction
+	if err := db.Where("consumption_id = ? AND status = ?", consumption.ID, "pending").Find(&claims).Error; err != nil {
+		return fmt.Errorf("failed to load OHIP claims: %w", err)
+	}
+	for _, claim := range claims {
+		if s.ohipService != nil {
+			if err := s.ohipService.CancelClaim(claim.ID); err != nil {
+				return fmt.Errorf("failed to cancel OHIP claim %s: %w", claim.ID, err)
+			}
+		}
+		if err := db.Model(&claim).Update("status", "cancelled").Error; err != nil {
+			return fmt.Errorf("failed to update OHIP claim %s: %w", claim.ID, err)
+		}
+	}
+	return nil
+}
+
+// completeVoid closes the visit's orders, puts back its stock and marks it voided
+func (s *VoidService) completeVoid(ctx context.Context, consumption models.DailyBreakfastConsumption, req VoidRequest) (models.DailyBreakfastConsumption, error) {
+	var after models.DailyBreakfastConsumption
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&models.Order{}).
+			Where("consumption_id = ? AND status <> ?", consumption.ID, OrderStatusCancelled).
+			Updates(map[string]interface{}{
+				"status":       OrderStatusCancelled,
+				"cancelled_at": now,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to cancel orders: %w", err)
+		}
+
+		// Put back the supplies the visit took from the outlet's stock
+		if err := restoreInventory(tx, consumption.ID); err != nil {
+			return err
+		}
+
+		// The reason given on the retry that completes the void is the one kept
+		err = tx.Model(&consumption).Updates(map[string]interface{}{
+			"status":      "voided",
+			"voided_at":   now,
+			"voided_by":   req.StaffID,
+			"void_reason": req.Reason,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to void consumption: %w", err)
+		}
+		return tx.First(&after, consumption.ID).Error
+	})
+	return after, err
+}
+
+// canVoid reports whether the role may void the consumption at this time
+func (s *VoidService) canVoid(consumption *models.DailyBreakfastConsumption, role string) bool {
+	for _, overrideRole := range s.config.OverrideRoles {
+		if strings.TrimSpace(overrideRole) == role {
+			return true
+		}
+	}
+
+	consumedAt := consumption.CreatedAt
+	if consumption.ConsumedAt != nil {
+		consumedAt = *consumption.ConsumedAt
+	}
+	return s.config.Window > 0 && time.Since(consumedAt) <= s.config.Window
+}
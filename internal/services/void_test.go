@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"hudini-breakfast-module/internal/config"
+	"hudini-breakfast-module/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// fakeReverser records the charges it is asked to reverse and fails the
+// first attempt at any charge listed in failOnce
+type fakeReverser struct {
+	calls    map[string]int
+	failOnce map[string]bool
+}
+
+func newFakeReverser(failOnce ...string) *fakeReverser {
+	r := &fakeReverser{calls: make(map[string]int), failOnce: make(map[string]bool)}
+	for _, id := range failOnce {
+		r.failOnce[id] = true
+	}
+	return r
+}
+
+func (r *fakeReverser) VoidCharge(ctx context.Context, transactionID string) error {
+	r.calls[transactionID]++
+	if r.failOnce[transactionID] {
+		delete(r.failOnce, transactionID)
+		return errors.New("PMS unavailable")
+	}
+	return nil
+}
+
+// voidFixture is a visit charged to the room, with two posted à-la-carte
+// orders, a pending OHIP claim and stock taken from the outlet
+type voidFixture struct {
+	db       *gorm.DB
+	visit    models.DailyBreakfastConsumption
+	orders   []models.Order
+	claim    models.OHIPTransaction
+	item     models.InventoryItem
+	outletID uint
+}
+
+func newVoidFixture(t *testing.T, consumedAt time.Time) *voidFixture {
+	t.Helper()
+	db := newTestDB(t)
+	f := &voidFixture{db: db}
+
+	mustCreate(t, db, &models.Property{PropertyID: "P1", Name: "Harbour Hotel"})
+	outlet := models.Outlet{PropertyID: "P1", Name: "Terrace", AcceptsPackage: true, IsActive: true}
+	mustCreate(t, db, &outlet)
+	f.outletID = outlet.ID
+
+	f.visit = models.DailyBreakfastConsumption{
+		PropertyID: "P1", RoomNumber: "101", GuestID: 1, ConsumptionDate: serviceDate,
+		ConsumedAt: &consumedAt, OutletID: &f.outletID, Status: "consumed", AdultCovers: 2,
+		PMSPosted: true, PMSTransactionID: "V1", Amount: 40,
+	}
+	mustCreate(t, db, &f.visit)
+
+	f.orders = []models.Order{
+		{ConsumptionID: f.visit.ID, OutletID: f.outletID, PropertyID: "P1", Status: OrderStatusServed, OverageAmount: 8, PMSPosted: true, PMSTransactionID: "O1"},
+		{ConsumptionID: f.visit.ID, OutletID: f.outletID, PropertyID: "P1", Status: OrderStatusServed, OverageAmount: 5, PMSPosted: true, PMSTransactionID: "O2"},
+	}
+	for i := range f.orders {
+		mustCreate(t, db, &f.orders[i])
+	}
+
+	f.claim = models.OHIPTransaction{ID: "C1", ConsumptionID: f.visit.ID, OHIPNumber: "1234", Amount: 40, Status: "pending"}
+	mustCreate(t, db, &f.claim)
+
+	// The visit took more eggs than were on hand, so only the three there were are put back
+	f.item = models.InventoryItem{OutletID: f.outletID, PropertyID: "P1", Code: "EGG", Name: "Eggs", Unit: "each", OnHand: 3, IsActive: true}
+	mustCreate(t, db, &f.item)
+	if err := moveStockTx(db, &f.item, MovementDepletion, -4, f.visit.ID); err != nil {
+		t.Fatalf("depleting stock: %v", err)
+	}
+	return f
+}
+
+func (f *voidFixture) service(visits, orders ChargeReverser) *VoidService {
+	service := NewVoidService(f.db, nil, nil, config.VoidConfig{Window: time.Hour, OverrideRoles: []string{"manager"}})
+	service.SetVisitChargeReverser(visits)
+	service.SetOrderChargeReverser(orders)
+	return service
+}
+
+func (f *voidFixture) request(role string) VoidRequest {
+	return VoidRequest{ConsumptionID: f.visit.ID, StaffID: 9, Role: role, PropertyID: "P1", Reason: "Wrong room"}
+}
+
+func (f *voidFixture) reload(t *testing.T) {
+	t.Helper()
+	if err := f.db.First(&f.visit, f.visit.ID).Error; err != nil {
+		t.Fatalf("reloading visit: %v", err)
+	}
+	if err := f.db.Order("id").Find(&f.orders).Error; err != nil {
+		t.Fatalf("reloading orders: %v", err)
+	}
+	if err := f.db.First(&f.claim, "id = ?", f.claim.ID).Error; err != nil {
+		t.Fatalf("reloading claim: %v", err)
+	}
+	if err := f.db.First(&f.item, f.item.ID).Error; err != nil {
+		t.Fatalf("reloading stock: %v", err)
+	}
+}
+
+func TestVoidConsumption(t *testing.T) {
+	f := newVoidFixture(t, time.Now().Add(-10*time.Minute))
+	visits, orders := newFakeReverser(), newFakeReverser()
+
+	voided, err := f.service(visits, orders).VoidConsumption(context.Background(), f.request("staff"))
+	if err != nil {
+		t.Fatalf("VoidConsumption: %v", err)
+	}
+	if voided.Status != "voided" || voided.VoidedAt == nil || voided.VoidReason != "Wrong room" {
+		t.Errorf("voided visit = status %q, voided at %v, reason %q", voided.Status, voided.VoidedAt, voided.VoidReason)
+	}
+
+	f.reload(t)
+	if visits.calls["V1"] != 1 || orders.calls["O1"] != 1 || orders.calls["O2"] != 1 || len(visits.calls) != 1 {
+		t.Errorf("reversals: visits %v, orders %v, want V1 through the visit client and O1, O2 through the order client", visits.calls, orders.calls)
+	}
+	if f.visit.PMSPosted {
+		t.Error("visit charge still marked posted")
+	}
+	for _, order := range f.orders {
+		if order.PMSPosted || order.Status != OrderStatusCancelled || order.CancelledAt == nil {
+			t.Errorf("order %d = posted %v, status %q", order.ID, order.PMSPosted, order.Status)
+		}
+	}
+	if f.claim.Status != "cancelled" {
+		t.Errorf("OHIP claim status = %q, want cancelled", f.claim.Status)
+	}
+	if f.item.OnHand != 3 {
+		t.Errorf("stock after void = %v, want the 3 taken put back", f.item.OnHand)
+	}
+
+	var movements []models.InventoryMovement
+	f.db.Order("id").Find(&movements)
+	if len(movements) != 2 || movements[0].Quantity != -3 || movements[1].Type != MovementReversal || movements[1].Quantity != 3 {
+		t.Errorf("stock movements = %+v, want -3 then a reversal of 3", movements)
+	}
+
+	if _, err := f.service(visits, orders).VoidConsumption(context.Background(), f.request("staff")); !errors.Is(err, ErrNotVoidable) {
+		t.Errorf("voiding twice: error = %v, want ErrNotVoidable", err)
+	}
+}
+
+func TestVoidConsumptionRetriesOutstandingReversals(t *testing.T) {
+	f := newVoidFixture(t, time.Now().Add(-10*time.Minute))
+	visits, orders := newFakeReverser(), newFakeReverser("O2")
+
+	if _, err := f.service(visits, orders).VoidConsumption(context.Background(), f.request("staff")); err == nil {
+		t.Fatal("VoidConsumption succeeded while the PMS refused a reversal")
+	}
+
+	// The visit is left pending, with the reversals that went through recorded
+	f.reload(t)
+	if f.visit.Status != "void_pending" || f.visit.VoidedAt != nil {
+		t.Fatalf("visit after a failed reversal = status %q, voided at %v, want void_pending", f.visit.Status, f.visit.VoidedAt)
+	}
+	if f.visit.PMSPosted || f.orders[0].PMSPosted || !f.orders[1].PMSPosted {
+		t.Errorf("posted after a failed reversal: visit %v, O1 %v, O2 %v, want false, false, true",
+			f.visit.PMSPosted, f.orders[0].PMSPosted, f.orders[1].PMSPosted)
+	}
+	if f.orders[0].Status == OrderStatusCancelled || f.claim.Status != "pending" || f.item.OnHand != 0 {
+		t.Errorf("void completed part way: order %q, claim %q, stock %v", f.orders[0].Status, f.claim.Status, f.item.OnHand)
+	}
+
+	// A retry is checked like a first attempt, so once the void window has
+	// passed only a manager can finish it
+	f.db.Model(&f.visit).Update("consumed_at", time.Now().Add(-2*time.Hour))
+	if _, err := f.service(visits, orders).VoidConsumption(context.Background(), f.request("staff")); !errors.Is(err, ErrVoidWindowExpired) {
+		t.Fatalf("staff retrying outside the window: error = %v, want ErrVoidWindowExpired", err)
+	}
+	if orders.calls["O2"] != 1 {
+		t.Errorf("refused retry reversed O2 again: %v", orders.calls)
+	}
+
+	// The retry only reverses what is outstanding
+	voided, err := f.service(visits, orders).VoidConsumption(context.Background(), f.request("manager"))
+	if err != nil {
+		t.Fatalf("retrying the void: %v", err)
+	}
+	if voided.Status != "voided" {
+		t.Errorf("visit after retrying = %q, want voided", voided.Status)
+	}
+	if visits.calls["V1"] != 1 || orders.calls["O1"] != 1 || orders.calls["O2"] != 2 {
+		t.Errorf("reversal calls = visits %v, orders %v, want V1 and O1 once and O2 retried", visits.calls, orders.calls)
+	}
+	f.reload(t)
+	if f.orders[1].PMSPosted || f.claim.Status != "cancelled" || f.item.OnHand != 3 {
+		t.Errorf("after retrying: O2 posted %v, claim %q, stock %v", f.orders[1].PMSPosted, f.claim.Status, f.item.OnHand)
+	}
+}
+
+func TestVoidConsumptionRules(t *testing.T) {
+	t.Run("reason required", func(t *testing.T) {
+		f := newVoidFixture(t, time.Now())
+		req := f.request("staff")
+		req.Reason = "  "
+		if _, err := f.service(newFakeReverser(), newFakeReverser()).VoidConsumption(context.Background(), req); !errors.Is(err, ErrVoidReasonRequired) {
+			t.Errorf("error = %v, want ErrVoidReasonRequired", err)
+		}
+	})
+
+	t.Run("outside the window", func(t *testing.T) {
+		f := newVoidFixture(t, time.Now().Add(-2*time.Hour))
+		visits := newFakeReverser()
+		if _, err := f.service(visits, newFakeReverser()).VoidConsumption(context.Background(), f.request("staff")); !errors.Is(err, ErrVoidWindowExpired) {
+			t.Fatalf("staff outside the window: error = %v, want ErrVoidWindowExpired", err)
+		}
+		f.reload(t)
+		if f.visit.Status != "consumed" || len(visits.calls) != 0 {
+			t.Errorf("refused void changed the visit: status %q, reversals %v", f.visit.Status, visits.calls)
+		}
+		if _, err := f.service(visits, newFakeReverser()).VoidConsumption(context.Background(), f.request("manager")); err != nil {
+			t.Errorf("manager outside the window: %v", err)
+		}
+	})
+
+	t.Run("another property", func(t *testing.T) {
+		f := newVoidFixture(t, time.Now())
+		visits := newFakeReverser()
+		req := f.request("manager")
+		req.PropertyID = "P2"
+		if _, err := f.service(visits, newFakeReverser()).VoidConsumption(context.Background(), req); !errors.Is(err, ErrVoidOtherProperty) {
+			t.Fatalf("manager of another property: error = %v, want ErrVoidOtherProperty", err)
+		}
+		if len(visits.calls) != 0 {
+			t.Errorf("charges reversed for another property: %v", visits.calls)
+		}
+		req.Role = "admin"
+		if _, err := f.service(visits, newFakeReverser()).VoidConsumption(context.Background(), req); err != nil {
+			t.Errorf("admin: %v", err)
+		}
+	})
+
+	t.Run("closed day", func(t *testing.T) {
+		f := newVoidFixture(t, time.Now())
+		mustCreate(t, f.db, &models.ServiceCloseOut{PropertyID: "P1", OutletID: &f.outletID, BusinessDate: serviceDate, Status: "closed", ClosedAt: time.Now()})
+		visits := newFakeReverser()
+		if _, err := f.service(visits, newFakeReverser()).VoidConsumption(context.Background(), f.request("manager")); !errors.Is(err, ErrDayClosed) {
+			t.Fatalf("error = %v, want ErrDayClosed", err)
+		}
+		if len(visits.calls) != 0 {
+			t.Errorf("charges reversed on a closed day: %v", visits.calls)
+		}
+	})
+
+	t.Run("not consumed", func(t *testing.T) {
+		f := newVoidFixture(t, time.Now())
+		f.db.Model(&f.visit).Update("status", "no_show")
+		if _, err := f.service(newFakeReverser(), newFakeReverser()).VoidConsumption(context.Background(), f.request("manager")); !errors.Is(err, ErrNotVoidable) {
+			t.Errorf("error = %v, want ErrNotVoidable", err)
+		}
+	})
+
+	t.Run("unknown visit", func(t *testing.T) {
+		f := newVoidFixture(t, time.Now())
+		req := f.request("manager")
+		req.ConsumptionID = 999
+		if _, err := f.service(newFakeReverser(), newFakeReverser()).VoidConsumption(context.Background(), req); !errors.Is(err, ErrConsumptionNotFound) {
+			t.Errorf("error = %v, want ErrConsumptionNotFound", err)
+		}
+	})
+}
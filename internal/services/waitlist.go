@@ -0,0 +1,637 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"hudini-breakfast-module/internal/logging"
+	"hudini-breakfast-module/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Errors returned by the waitlist
+var (
+	ErrWaitlistEntryNotFound = errors.New("waitlist entry not found")
+	ErrWaitlistEntryClosed   = errors.New("party is no longer waiting")
+	ErrPartyNameRequired     = errors.New("party_name is required for walk-ins")
+)
+
+// Waitlist entry states
+const (
+	WaitlistStatusWaiting   = "waiting"
+	WaitlistStatusNotified  = "notified"
+	WaitlistStatusSeated    = "seated"
+	WaitlistStatusCancelled = "cancelled"
+	WaitlistStatusNoShow    = "no_show"
+)
+
+const (
+	// defaultTurnMinutes is the table turn time assumed before an outlet has turn history
+	defaultTurnMinutes = 45.0
+	// turnHistoryDays is how far back turn durations are averaged for wait estimates
+	turnHistoryDays = 14
+)
+
+// WaitlistService queues parties for outlet tables and calls them when one frees up
+type WaitlistService struct {
+	db            *gorm.DB
+	tables        *TableService
+	notifications *NotificationService
+	publisher     BoardPublisher
+}
+
+// WaitlistRequest describes a party joining the waitlist. In-house parties are
+// identified by guest ID or room number; walk-ins need a party name and size.
+type WaitlistRequest struct {
+	GuestID    uint   `json:"guest_id"`
+	RoomNumber string `json:"room_number"`
+	PartyName  string `json:"party_name"`
+	PartySize  int    `json:"party_size"`
+	Phone      string `json:"phone"` // Defaults to the guest's phone on file
+	Preference string `json:"seating_preference"`
+	Notes      string `json:"notes"`
+}
+
+// WaitlistView is a queued party with its place in line and current estimate
+type WaitlistView struct {
+	models.WaitlistEntry
+	Position             int     `json:"position"`
+	WaitingMinutes       float64 `json:"waiting_minutes"`
+	EstimatedWaitMinutes int     `json:"estimated_wait_minutes"`
+}
+
+// Waitlist is an outlet's current queue
+type Waitlist struct {
+	OutletID           uint           `json:"outlet_id"`
+	BusinessDate       string         `json:"business_date"`
+	AverageTurnMinutes float64        `json:"average_turn_minutes"`
+	Parties            []WaitlistView `json:"parties"`
+}
+
+// WaitTimeStats summarizes how long parties waited for a table over a period
+type WaitTimeStats struct {
+	Period        string    `json:"period"`
+	Labels        []string  `json:"labels"`
+	AverageWaits  []float64 `json:"average_waits"` // Average wait in minutes per label
+	Parties       []int     `json:"parties"`       // Parties called per label
+	AverageWait   float64   `json:"average_wait"`
+	LongestWait   float64   `json:"longest_wait"`
+	PartiesWaited int       `json:"parties_waited"`
+	NoShows       int       `json:"no_shows"`
+}
+
+// NewWaitlistService creates a new waitlist service
+func NewWaitlistService(db *gorm.DB, tables *TableService, notifications *NotificationService) *WaitlistService {
+	return &WaitlistService{
+		db:            db,
+		tables:        tables,
+		notifications: notifications,
+	}
+}
+
+// SetPublisher sets where waitlist updates are pushed
+func (s *WaitlistService) SetPublisher(publisher BoardPublisher) {
+	s.publisher = publisher
+}
+
+// AddParty adds a party to an outlet's waitlist and quotes its estimated wait
+func (s *WaitlistService) AddParty(outletID uint, req WaitlistRequest, staffID uint) (*WaitlistView, error) {
+	outlet, err := s.tables.getOutlet(s.db, outletID)
+	if err != nil {
+		return nil, err
+	}
+	if req.GuestID == 0 && req.RoomNumber == "" && strings.TrimSpace(req.PartyName) == "" {
+		return nil, ErrPartyNameRequired
+	}
+
+	party, err := s.tables.resolveParty(s.db, outlet, SeatingRequest{
+		GuestID:    req.GuestID,
+		RoomNumber: req.RoomNumber,
+		PartyName:  req.PartyName,
+		PartySize:  req.PartySize,
+		Preference: req.Preference,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	phone := strings.TrimSpace(req.Phone)
+	if phone == "" && party.GuestID != nil {
+		var guest models.Guest
+		if err := s.db.Select("phone").First(&guest, *party.GuestID).Error; err == nil {
+			phone = guest.Phone
+		}
+	}
+
+	clock, err := LoadPropertyClock(s.db, outlet.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+	now := clock.Now()
+
+	entry := models.WaitlistEntry{
+		OutletID:     outlet.ID,
+		PropertyID:   outlet.PropertyID,
+		BusinessDate: clock.BusinessDate(now),
+		GuestID:      party.GuestID,
+		RoomNumber:   party.RoomNumber,
+		PartyName:    party.GuestName,
+		PartySize:    party.PartySize,
+		Phone:        phone,
+		SeatingPref:  party.Preference,
+		Notes:        req.Notes,
+		Status:       WaitlistStatusWaiting,
+		AddedAt:      now,
+		AddedBy:      staffID,
+	}
+	if err := s.db.Create(&entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to add party to waitlist: %w", err)
+	}
+
+	// Quote the estimate the party gets in the queue as it now stands
+	waitlist, err := s.queue(outlet, clock)
+	if err != nil {
+		return nil, err
+	}
+	view := WaitlistView{WaitlistEntry: entry}
+	for _, queued := range waitlist.Parties {
+		if queued.ID == entry.ID {
+			view = queued
+			break
+		}
+	}
+	entry.QuotedWaitMinutes = view.EstimatedWaitMinutes
+	view.QuotedWaitMinutes = view.EstimatedWaitMinutes
+	if err := s.db.Model(&entry).Update("quoted_wait_minutes", entry.QuotedWaitMinutes).Error; err != nil {
+		return nil, fmt.Errorf("failed to record quoted wait: %w", err)
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":      "WaitlistService",
+		"method":       "AddParty",
+		"outlet_id":    outlet.ID,
+		"entry_id":     entry.ID,
+		"party_size":   entry.PartySize,
+		"quoted_wait":  entry.QuotedWaitMinutes,
+		"queue_length": len(waitlist.Parties),
+	}).Info("Added party to waitlist")
+
+	s.publishWaitlist(outlet.ID)
+	return &view, nil
+}
+
+// GetWaitlist returns an outlet's waiting and called parties with current estimates
+func (s *WaitlistService) GetWaitlist(outletID uint) (*Waitlist, error) {
+	outlet, err := s.tables.getOutlet(s.db, outletID)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := LoadPropertyClock(s.db, outlet.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+	return s.queue(outlet, clock)
+}
+
+// TableFreed calls the longest-waiting party that fits a table that has just become free
+func (s *WaitlistService) TableFreed(ctx context.Context, table *models.DiningTable) {
+	clock, err := LoadPropertyClock(s.db, table.PropertyID)
+	if err != nil {
+		logging.WithError(err).WithField("table_id", table.ID).Warn("Failed to load property clock for waitlist")
+		return
+	}
+
+	var entries []models.WaitlistEntry
+	err = s.db.Where("outlet_id = ? AND DATE(business_date) = ? AND status = ? AND party_size <= ?",
+		table.OutletID, clock.Today().Format("2006-01-02"), WaitlistStatusWaiting, table.Capacity).
+		Order("added_at ASC, id ASC").
+		Limit(1).
+		Find(&entries).Error
+	if err != nil {
+		logging.WithError(err).WithField("table_id", table.ID).Warn("Failed to find waiting party")
+		return
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	if _, err := s.notify(ctx, &entries[0], table); err != nil && !errors.Is(err, ErrWaitlistEntryClosed) {
+		logging.WithError(err).WithField("entry_id", entries[0].ID).Warn("Failed to call waiting party")
+	}
+}
+
+// NotifyParty calls a party because a table is ready for them, optionally naming the table
+func (s *WaitlistService) NotifyParty(ctx context.Context, entryID, tableID uint) (*models.WaitlistEntry, error) {
+	entry, err := s.getEntry(entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	var table *models.DiningTable
+	if tableID != 0 {
+		table, err = s.tables.getTable(s.db, tableID)
+		if err != nil {
+			return nil, err
+		}
+		if table.OutletID != entry.OutletID {
+			return nil, ErrTableNotFound
+		}
+	}
+	return s.notify(ctx, entry, table)
+}
+
+// SeatEntry seats a queued party, at the table they were called for unless another is given
+func (s *WaitlistService) SeatEntry(entryID, tableID, staffID uint) (*models.TableTurn, error) {
+	entry, err := s.getEntry(entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status != WaitlistStatusWaiting && entry.Status != WaitlistStatusNotified {
+		return nil, ErrWaitlistEntryClosed
+	}
+	if tableID == 0 && entry.NotifiedTableID != nil {
+		tableID = *entry.NotifiedTableID
+	}
+
+	req := SeatingRequest{
+		TableID:    tableID,
+		PartyName:  entry.PartyName,
+		PartySize:  entry.PartySize,
+		Preference: entry.SeatingPref,
+	}
+	if entry.GuestID != nil {
+		req.GuestID = *entry.GuestID
+	}
+	turn, err := s.tables.SeatParty(entry.OutletID, req, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := turn.SeatedAt
+	updates := map[string]interface{}{
+		"status":    WaitlistStatusSeated,
+		"seated_at": now,
+		"turn_id":   turn.ID,
+	}
+	if entry.WaitMinutes == nil {
+		updates["wait_minutes"] = now.Sub(entry.AddedAt).Minutes()
+	}
+	if err := s.db.Model(entry).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update waitlist entry: %w", err)
+	}
+
+	s.publishWaitlist(entry.OutletID)
+	return turn, nil
+}
+
+// CloseEntry takes a party off the waitlist as cancelled, or as a no-show when they did not answer the call
+func (s *WaitlistService) CloseEntry(entryID uint, noShow bool) (*models.WaitlistEntry, error) {
+	entry, err := s.getEntry(entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status != WaitlistStatusWaiting && entry.Status != WaitlistStatusNotified {
+		return nil, ErrWaitlistEntryClosed
+	}
+
+	now := time.Now()
+	entry.Status = WaitlistStatusCancelled
+	if noShow {
+		entry.Status = WaitlistStatusNoShow
+	}
+	entry.ClosedAt = &now
+	if err := s.db.Model(entry).Updates(map[string]interface{}{"status": entry.Status, "closed_at": now}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update waitlist entry: %w", err)
+	}
+
+	s.publishWaitlist(entry.OutletID)
+	return entry, nil
+}
+
+// WaitTimeStats summarizes waits at a property's outlets for today (by hour of
+// service), this week (by day) or the last four weeks (by week)
+func (s *WaitlistService) WaitTimeStats(propertyID, period string) (*WaitTimeStats, error) {
+	clock, err := LoadPropertyClock(s.db, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	today := clock.Today()
+
+	stats := &WaitTimeStats{Period: period}
+	var from time.Time
+	var bucket func(entry *models.WaitlistEntry) int
+
+	switch period {
+	case "week":
+		from = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7)) // Monday
+		stats.Labels = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+		bucket = func(entry *models.WaitlistEntry) int {
+			return int(calendarDate(entry.BusinessDate).Sub(from).Hours() / 24)
+		}
+	case "month":
+		from = today.AddDate(0, 0, -((int(today.Weekday())+6)%7)-21)
+		stats.Labels = []string{"Week 1", "Week 2", "Week 3", "Week 4"}
+		bucket = func(entry *models.WaitlistEntry) int {
+			return int(calendarDate(entry.BusinessDate).Sub(from).Hours() / (24 * 7))
+		}
+	default:
+		stats.Period = "today"
+		from = today
+		stats.Labels = []string{"6AM", "7AM", "8AM", "9AM", "10AM", "11AM"}
+		bucket = func(entry *models.WaitlistEntry) int {
+			return entry.AddedAt.In(clock.Location).Hour() - 6
+		}
+	}
+
+	var entries []models.WaitlistEntry
+	err = s.db.Where("property_id = ? AND DATE(business_date) >= ? AND DATE(business_date) <= ?",
+		propertyID, from.Format("2006-01-02"), today.Format("2006-01-02")).
+		Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch waitlist history: %w", err)
+	}
+
+	totals := make([]float64, len(stats.Labels))
+	stats.Parties = make([]int, len(stats.Labels))
+	stats.AverageWaits = make([]float64, len(stats.Labels))
+	var total float64
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Status == WaitlistStatusNoShow {
+			stats.NoShows++
+		}
+		if entry.WaitMinutes == nil {
+			continue
+		}
+
+		wait := *entry.WaitMinutes
+		stats.PartiesWaited++
+		total += wait
+		if wait > stats.LongestWait {
+			stats.LongestWait = wait
+		}
+		if index := bucket(entry); index >= 0 && index < len(totals) {
+			totals[index] += wait
+			stats.Parties[index]++
+		}
+	}
+
+	for i := range totals {
+		if stats.Parties[i] > 0 {
+			stats.AverageWaits[i] = roundMinutes(totals[i] / float64(stats.Parties[i]))
+		}
+	}
+	if stats.PartiesWaited > 0 {
+		stats.AverageWait = roundMinutes(total / float64(stats.PartiesWaited))
+	}
+	stats.LongestWait = roundMinutes(stats.LongestWait)
+	return stats, nil
+}
+
+// notify marks a party as called and texts them that their table is ready
+func (s *WaitlistService) notify(ctx context.Context, entry *models.WaitlistEntry, table *models.DiningTable) (*models.WaitlistEntry, error) {
+	if entry.Status != WaitlistStatusWaiting && entry.Status != WaitlistStatusNotified {
+		return nil, ErrWaitlistEntryClosed
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":      WaitlistStatusNotified,
+		"notified_at": now,
+	}
+	if entry.WaitMinutes == nil {
+		wait := now.Sub(entry.AddedAt).Minutes()
+		updates["wait_minutes"] = wait
+		entry.WaitMinutes = &wait
+	}
+	if table != nil {
+		updates["notified_table_id"] = table.ID
+		entry.NotifiedTableID = &table.ID
+	}
+
+	// Only one caller may move a party off the waiting list
+	result := s.db.Model(&models.WaitlistEntry{}).
+		Where("id = ? AND status IN ?", entry.ID, []string{WaitlistStatusWaiting, WaitlistStatusNotified}).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update waitlist entry: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrWaitlistEntryClosed
+	}
+	entry.Status = WaitlistStatusNotified
+	entry.NotifiedAt = &now
+
+	if entry.Phone != "" && s.notifications != nil {
+		var outlet models.Outlet
+		s.db.Select("name").First(&outlet, entry.OutletID)
+		tableName := ""
+		if table != nil {
+			tableName = table.Name
+		}
+		if err := s.notifications.NotifyTableReady(ctx, entry, outlet.Name, tableName); err != nil {
+			logging.WithError(err).WithField("entry_id", entry.ID).Warn("Failed to text waiting party")
+		}
+	}
+
+	logging.WithFields(logrus.Fields{
+		"service":      "WaitlistService",
+		"method":       "notify",
+		"outlet_id":    entry.OutletID,
+		"entry_id":     entry.ID,
+		"wait_minutes": *entry.WaitMinutes,
+		"texted":       entry.Phone != "",
+	}).Info("Called waiting party")
+
+	s.publishWaitlist(entry.OutletID)
+	return entry, nil
+}
+
+// queue returns an outlet's open waitlist entries in order with their estimated waits.
+//
+// With a table map, each party in turn takes the fitting table expected to free
+// up first: free tables now, occupied ones once they reach the outlet's average
+// turn time, and tables promised to an earlier party one turn later. Without a
+// table map the estimate falls back to seats: covers served within the last
+// turn are taken to still be seated, and seats free up evenly over a turn.
+func (s *WaitlistService) queue(outlet *models.Outlet, clock *PropertyClock) (*Waitlist, error) {
+	now := clock.Now()
+	today := clock.BusinessDate(now)
+
+	var entries []models.WaitlistEntry
+	err := s.db.Where("outlet_id = ? AND DATE(business_date) = ? AND status IN ?",
+		outlet.ID, today.Format("2006-01-02"), []string{WaitlistStatusWaiting, WaitlistStatusNotified}).
+		Order("added_at ASC, id ASC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch waitlist: %w", err)
+	}
+
+	turnMinutes, err := s.averageTurnMinutes(outlet.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := s.tables.GetTables(outlet.ID)
+	if err != nil {
+		return nil, err
+	}
+	var seated []models.TableTurn
+	if err := s.db.Where("outlet_id = ? AND status = ?", outlet.ID, TurnStatusSeated).Find(&seated).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch seated parties: %w", err)
+	}
+	seatedAt := make(map[uint]time.Time, len(seated))
+	for _, turn := range seated {
+		seatedAt[turn.ID] = turn.SeatedAt
+	}
+
+	// Minutes from now until each table is expected to be free
+	freeIn := make(map[uint]float64)
+	for _, table := range tables {
+		switch table.Status {
+		case TableStatusAvailable:
+			freeIn[table.ID] = 0
+		case TableStatusOccupied:
+			remaining := turnMinutes
+			if table.CurrentTurnID != nil {
+				if at, ok := seatedAt[*table.CurrentTurnID]; ok {
+					remaining = math.Max(turnMinutes-now.Sub(at).Minutes(), 0)
+				}
+			}
+			freeIn[table.ID] = remaining
+		}
+	}
+	for _, entry := range entries {
+		if entry.Status == WaitlistStatusNotified && entry.NotifiedTableID != nil {
+			if _, ok := freeIn[*entry.NotifiedTableID]; ok {
+				freeIn[*entry.NotifiedTableID] += turnMinutes
+			}
+		}
+	}
+
+	// Seat-based fallback for outlets without a table map
+	var seatsInUse int64
+	if len(freeIn) == 0 && outlet.Capacity > 0 {
+		err := s.db.Model(&models.DailyBreakfastConsumption{}).
+			Where("outlet_id = ? AND status = 'consumed' AND consumed_at >= ?", outlet.ID, now.Add(-time.Duration(turnMinutes)*time.Minute)).
+			Select("COALESCE(SUM(adult_covers + child_covers), 0)").
+			Row().Scan(&seatsInUse)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count seated covers: %w", err)
+		}
+	}
+
+	waitlist := &Waitlist{
+		OutletID:           outlet.ID,
+		BusinessDate:       today.Format("2006-01-02"),
+		AverageTurnMinutes: roundMinutes(turnMinutes),
+		Parties:            make([]WaitlistView, 0, len(entries)),
+	}
+	seatsAhead := 0
+	position := 0
+	for _, entry := range entries {
+		view := WaitlistView{
+			WaitlistEntry:  entry,
+			WaitingMinutes: roundMinutes(now.Sub(entry.AddedAt).Minutes()),
+		}
+		if entry.Status == WaitlistStatusWaiting {
+			position++
+			view.Position = position
+
+			var best *models.DiningTable
+			for i := range tables {
+				table := &tables[i]
+				free, ok := freeIn[table.ID]
+				if !ok || table.Capacity < entry.PartySize {
+					continue
+				}
+				if best == nil || free < freeIn[best.ID] || (free == freeIn[best.ID] && table.Capacity < best.Capacity) {
+					best = table
+				}
+			}
+
+			switch {
+			case best != nil:
+				view.EstimatedWaitMinutes = int(math.Ceil(freeIn[best.ID]))
+				freeIn[best.ID] += turnMinutes
+			case outlet.Capacity > 0:
+				seatsAhead += entry.PartySize
+				over := int(seatsInUse) + seatsAhead - outlet.Capacity
+				if over > 0 {
+					view.EstimatedWaitMinutes = int(math.Ceil(turnMinutes * float64(over) / float64(outlet.Capacity)))
+				}
+			default:
+				view.EstimatedWaitMinutes = int(math.Ceil(turnMinutes))
+			}
+		}
+		waitlist.Parties = append(waitlist.Parties, view)
+	}
+
+	return waitlist, nil
+}
+
+// averageTurnMinutes returns how long parties have recently held a table at an outlet
+func (s *WaitlistService) averageTurnMinutes(outletID uint, now time.Time) (float64, error) {
+	var turns []models.TableTurn
+	err := s.db.Where("outlet_id = ? AND status = ? AND seated_at >= ?", outletID, TurnStatusCleared, now.AddDate(0, 0, -turnHistoryDays)).
+		Order("seated_at DESC").
+		Limit(500).
+		Find(&turns).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch table turns: %w", err)
+	}
+
+	var total float64
+	var count int
+	for _, turn := range turns {
+		if turn.ClearedAt == nil {
+			continue
+		}
+		total += turn.ClearedAt.Sub(turn.SeatedAt).Minutes()
+		count++
+	}
+	if count == 0 {
+		return defaultTurnMinutes, nil
+	}
+	return total / float64(count), nil
+}
+
+func (s *WaitlistService) getEntry(entryID uint) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	if err := s.db.First(&entry, entryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWaitlistEntryNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch waitlist entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// publishWaitlist pushes an outlet's waitlist to its table board room
+func (s *WaitlistService) publishWaitlist(outletID uint) {
+	if s.publisher == nil {
+		return
+	}
+
+	waitlist, err := s.GetWaitlist(outletID)
+	if err != nil {
+		logging.WithError(err).WithField("outlet_id", outletID).Warn("Failed to build waitlist")
+		return
+	}
+	s.publisher.BroadcastToRoom(TableBoardRoom(outletID), map[string]interface{}{
+		"type":      "waitlist",
+		"outlet_id": outletID,
+		"data":      waitlist,
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+func roundMinutes(minutes float64) float64 {
+	return math.Round(minutes*10) / 10
+}
@@ -65,6 +65,12 @@ func (h *Hub) Run() {
 				delete(h.clients, client)
 				close(client.send)
 			}
+			for room, roomClients := range h.rooms {
+				delete(roomClients, client)
+				if len(roomClients) == 0 {
+					delete(h.rooms, room)
+				}
+			}
 			h.mutex.Unlock()
 			log.Printf("Client disconnected. Total clients: %d", len(h.clients))
 
@@ -172,13 +178,14 @@ func (h *Hub) BroadcastToRoom(room string, data map[string]interface{}) {
 	defer h.mutex.RUnlock()
 	
 	for client := range roomClients {
+		if !h.clients[client] {
+			continue // Disconnected; removed from its rooms on unregister
+		}
 		select {
 		case client.send <- jsonMessage:
 		default:
-			// Client's send channel is full, close it
-			close(client.send)
-			delete(h.clients, client)
-			delete(roomClients, client)
+			// Client's send channel is full; it is dropped by Run if it stays stuck
+			log.Printf("Client send buffer full, dropping message for room %s", room)
 		}
 	}
 }
@@ -207,8 +214,10 @@ func (h *Hub) RemoveClientFromRoom(client *Client, room string) {
 	}
 }
 
-// ServeWS handles websocket requests from the peer
-func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
+// ServeWS handles websocket requests from the peer and subscribes the client
+// to rooms, such as an outlet's table board or a kitchen station's display.
+// The caller must have checked the peer may join each room.
+func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request, rooms []string) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("WebSocket upgrade error: %v", err)
@@ -222,6 +231,11 @@ func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client.hub.register <- client
+	for _, room := range rooms {
+		if room != "" {
+			h.AddClientToRoom(client, room)
+		}
+	}
 
 	// Allow collection of memory referenced by the caller by doing all work in new goroutines
 	go client.writePump()
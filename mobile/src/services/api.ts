@@ -45,6 +45,10 @@ export const roomGridAPI = {
     apiClient.get(`/room-grid/${propertyId}?date=${date}`),
   markBreakfastConsumed: (data: any) => 
     apiClient.post('/room-grid/consume', data),
+  syncConsumptions: (data: any) => 
+    apiClient.post('/sync/consumptions', data),
+  redeemPass: (data: any) => 
+    apiClient.post('/passes/redeem', data),
   syncFromPMS: (propertyId: string) => 
     apiClient.post(`/room-grid/sync/${propertyId}`),
   getConsumptionHistory: (propertyId?: string, startDate?: string, endDate?: string) => {